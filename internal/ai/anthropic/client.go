@@ -0,0 +1,253 @@
+package anthropic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"td/internal/ai"
+	"td/internal/timeutil"
+)
+
+const (
+	DefaultEndpoint = "https://api.anthropic.com/v1/messages"
+	DefaultModel    = "claude-haiku-4-5"
+
+	apiVersion       = "2023-06-01"
+	defaultMaxTokens = 1024
+)
+
+// Client calls the Anthropic Messages API.
+type Client struct {
+	Endpoint   string
+	APIKey     string
+	Model      string
+	MaxTokens  int
+	Cache      ai.Cache
+	HTTPClient *http.Client
+	Clock      timeutil.Clock
+}
+
+// block is a content block of a message: text, tool_use or tool_result.
+type block struct {
+	Type      string          `json:"type"`
+	Text      string          `json:"text,omitempty"`
+	ID        string          `json:"id,omitempty"`
+	Name      string          `json:"name,omitempty"`
+	Input     json.RawMessage `json:"input,omitempty"`
+	ToolUseID string          `json:"tool_use_id,omitempty"`
+	Content   string          `json:"content,omitempty"`
+	IsError   bool            `json:"is_error,omitempty"`
+}
+
+type message struct {
+	Role    string  `json:"role"`
+	Content []block `json:"content"`
+}
+
+type tool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	InputSchema map[string]any `json:"input_schema"`
+}
+
+type toolChoice struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+}
+
+type request struct {
+	Model      string     `json:"model"`
+	MaxTokens  int        `json:"max_tokens"`
+	System     string     `json:"system"`
+	Messages   []message  `json:"messages"`
+	Tools      []tool     `json:"tools"`
+	ToolChoice toolChoice `json:"tool_choice"`
+}
+
+type response struct {
+	Content    []block `json:"content"`
+	StopReason string  `json:"stop_reason"`
+}
+
+func (c *Client) ParseTask(ctx context.Context, input string) (string, error) {
+	return c.extract(ctx, input, ai.TaskExtraction)
+}
+
+func (c *Client) ParseTasks(ctx context.Context, input string) (string, error) {
+	return c.extract(ctx, input, ai.TaskListExtraction)
+}
+
+// extract makes the model call the tool of e, so the reply arrives as tool
+// input that follows the schema rather than as free text.
+func (c *Client) extract(ctx context.Context, input string, e ai.Extraction) (string, error) {
+	if c.APIKey == "" {
+		return "", errors.New("anthropic api key is empty")
+	}
+	endpoint := resolveMessagesEndpoint(c.Endpoint)
+	model := strings.TrimSpace(c.Model)
+	if model == "" {
+		model = DefaultModel
+	}
+	maxTokens := c.MaxTokens
+	if maxTokens <= 0 {
+		maxTokens = defaultMaxTokens
+	}
+	now := ai.Now(c.Clock)
+
+	cacheKey := e.CacheKey(model, input, now)
+	if c.Cache != nil {
+		if cached, ok := c.Cache.Get(ctx, cacheKey); ok {
+			return cached, nil
+		}
+	}
+
+	req := request{
+		Model:     model,
+		MaxTokens: maxTokens,
+		System:    e.Prompt(now),
+		Messages:  []message{{Role: "user", Content: []block{{Type: "text", Text: input}}}},
+		Tools: []tool{{
+			Name:        e.Name,
+			Description: e.Description,
+			InputSchema: e.Schema(),
+		}},
+		ToolChoice: toolChoice{Type: "tool", Name: e.Name},
+	}
+	result, err := c.send(ctx, endpoint, req)
+	if err != nil {
+		return "", err
+	}
+	content, err := e.Check(reply(result, e.Name))
+	if err != nil {
+		req.Messages = append(req.Messages,
+			message{Role: "assistant", Content: result.Content},
+			message{Role: "user", Content: []block{retryBlock(result, e.Name, err)}},
+		)
+		if result, err = c.send(ctx, endpoint, req); err != nil {
+			return "", err
+		}
+		if content, err = e.Check(reply(result, e.Name)); err != nil {
+			return "", fmt.Errorf("anthropic api returned an invalid reply: %w", err)
+		}
+	}
+	if c.Cache != nil {
+		c.Cache.Put(ctx, cacheKey, content)
+	}
+	return content, nil
+}
+
+func (c *Client) send(ctx context.Context, endpoint string, payload request) (response, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return response{}, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return response{}, err
+	}
+	req.Header.Set("x-api-key", c.APIKey)
+	req.Header.Set("anthropic-version", apiVersion)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 20 * time.Second}
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return response{}, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return response{}, err
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return response{}, fmt.Errorf("anthropic api error: %s", extractAPIError(respBody, resp.Status))
+	}
+
+	var result response
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return response{}, err
+	}
+	if result.StopReason == "max_tokens" {
+		return response{}, fmt.Errorf("anthropic api response was cut off at %d tokens", payload.MaxTokens)
+	}
+	if len(result.Content) == 0 {
+		return response{}, errors.New("anthropic api returned no content")
+	}
+	return result, nil
+}
+
+// reply is the input of the named tool, or the text blocks when the
+// model answered in text.
+func reply(result response, name string) string {
+	var text strings.Builder
+	for _, b := range result.Content {
+		switch {
+		case b.Type == "tool_use" && b.Name == name:
+			return string(b.Input)
+		case b.Type == "text":
+			text.WriteString(b.Text)
+		}
+	}
+	return text.String()
+}
+
+// retryBlock feeds err back as the tool result, or as text when the model
+// did not call the tool.
+func retryBlock(result response, name string, err error) block {
+	for _, b := range result.Content {
+		if b.Type == "tool_use" && b.Name == name {
+			return block{Type: "tool_result", ToolUseID: b.ID, Content: ai.RetryPrompt(err), IsError: true}
+		}
+	}
+	return block{Type: "text", Text: ai.RetryPrompt(err)}
+}
+
+// resolveMessagesEndpoint accepts the API host, a /v1 base URL or the full
+// /v1/messages URL.
+func resolveMessagesEndpoint(raw string) string {
+	text := strings.TrimRight(strings.TrimSpace(raw), "/")
+	switch {
+	case text == "":
+		return DefaultEndpoint
+	case strings.HasSuffix(text, "/messages"):
+		return text
+	case strings.HasSuffix(text, "/v1"):
+		return text + "/messages"
+	default:
+		return text + "/v1/messages"
+	}
+}
+
+func extractAPIError(body []byte, fallback string) string {
+	var payload struct {
+		Error struct {
+			Type    string `json:"type"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(body, &payload); err == nil {
+		if msg := strings.TrimSpace(payload.Error.Message); msg != "" {
+			if payload.Error.Type != "" {
+				return payload.Error.Type + ": " + msg
+			}
+			return msg
+		}
+	}
+	raw := strings.TrimSpace(string(body))
+	if raw != "" {
+		return raw
+	}
+	return fallback
+}
@@ -0,0 +1,172 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"td/internal/ai"
+)
+
+func TestParseTaskShouldForceTaskToolCall(t *testing.T) {
+	var (
+		gotPath    string
+		gotKey     string
+		gotVersion string
+		gotAuth    string
+		payload    request
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotKey = r.Header.Get("x-api-key")
+		gotVersion = r.Header.Get("anthropic-version")
+		gotAuth = r.Header.Get("Authorization")
+		defer r.Body.Close()
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"type":"message","role":"assistant","content":[`+
+			`{"type":"tool_use","id":"toolu_1","name":"record_task","input":{"title":"AI task","priority":"P2"}}],"stop_reason":"tool_use"}`)
+	}))
+	defer server.Close()
+
+	client := &Client{
+		Endpoint:   server.URL,
+		APIKey:     "sk-ant-test",
+		Model:      "claude-test",
+		HTTPClient: server.Client(),
+	}
+	raw, err := client.ParseTask(context.Background(), "buy milk")
+	if err != nil {
+		t.Fatalf("ParseTask error = %v, want nil", err)
+	}
+	if gotPath != "/v1/messages" {
+		t.Fatalf("path = %q, want /v1/messages", gotPath)
+	}
+	if gotKey != "sk-ant-test" || gotAuth != "" {
+		t.Fatalf("x-api-key = %q, authorization = %q", gotKey, gotAuth)
+	}
+	if gotVersion != apiVersion {
+		t.Fatalf("anthropic-version = %q, want %q", gotVersion, apiVersion)
+	}
+	if payload.Model != "claude-test" || payload.MaxTokens != defaultMaxTokens {
+		t.Fatalf("payload = %+v", payload)
+	}
+	if !strings.HasPrefix(payload.System, "Extract one todo") {
+		t.Fatalf("system = %q, want the parse prompt", payload.System)
+	}
+	if len(payload.Messages) != 1 || payload.Messages[0].Role != "user" || payload.Messages[0].Content[0].Text != "buy milk" {
+		t.Fatalf("messages = %+v", payload.Messages)
+	}
+	if len(payload.Tools) != 1 || payload.Tools[0].Name != ai.TaskExtraction.Name || payload.Tools[0].InputSchema["type"] != "object" {
+		t.Fatalf("tools = %+v", payload.Tools)
+	}
+	if payload.ToolChoice != (toolChoice{Type: "tool", Name: ai.TaskExtraction.Name}) {
+		t.Fatalf("tool_choice = %+v", payload.ToolChoice)
+	}
+	if raw != `{"title":"AI task","priority":"P2"}` {
+		t.Fatalf("raw = %q, want tool input", raw)
+	}
+}
+
+func TestParseTaskShouldReportAPIErrorAndUseCache(t *testing.T) {
+	fail := true
+	hits := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Header().Set("Content-Type", "application/json")
+		if fail {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = io.WriteString(w, `{"type":"error","error":{"type":"authentication_error","message":"invalid x-api-key"}}`)
+			return
+		}
+		_, _ = io.WriteString(w, `{"content":[{"type":"text","text":"{\"title\":\"cached\"}"}],"stop_reason":"end_turn"}`)
+	}))
+	defer server.Close()
+
+	client := &Client{
+		Endpoint:   server.URL + "/v1/messages",
+		APIKey:     "sk-ant-test",
+		Cache:      ai.NewMemoryCache(0),
+		HTTPClient: server.Client(),
+	}
+	_, err := client.ParseTask(context.Background(), "same input")
+	if err == nil || !strings.Contains(err.Error(), "authentication_error: invalid x-api-key") {
+		t.Fatalf("error = %v, want api error message", err)
+	}
+
+	fail = false
+	for i := 0; i < 2; i++ {
+		if raw, err := client.ParseTask(context.Background(), "same input"); err != nil || raw != `{"title":"cached"}` {
+			t.Fatalf("ParseTask = %q, %v", raw, err)
+		}
+	}
+	if hits != 2 {
+		t.Fatalf("server hits = %d, want 2", hits)
+	}
+}
+
+func TestParseTaskShouldRetryWithValidationErrorAsToolResult(t *testing.T) {
+	var requests []request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload request
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		requests = append(requests, payload)
+		w.Header().Set("Content-Type", "application/json")
+		if len(requests) == 1 {
+			_, _ = io.WriteString(w, `{"content":[{"type":"tool_use","id":"toolu_1","name":"record_task","input":{"title":"","priority":"high"}}],"stop_reason":"tool_use"}`)
+			return
+		}
+		_, _ = io.WriteString(w, `{"content":[{"type":"tool_use","id":"toolu_2","name":"record_task","input":{"title":"fixed","priority":"P1"}}],"stop_reason":"tool_use"}`)
+	}))
+	defer server.Close()
+
+	client := &Client{Endpoint: server.URL, APIKey: "sk-ant-test", HTTPClient: server.Client()}
+	raw, err := client.ParseTask(context.Background(), "urgent: fix it")
+	if err != nil {
+		t.Fatalf("ParseTask error = %v, want nil", err)
+	}
+	if raw != `{"title":"fixed","priority":"P1"}` {
+		t.Fatalf("raw = %q", raw)
+	}
+	if len(requests) != 2 || len(requests[1].Messages) != 3 {
+		t.Fatalf("requests = %+v", requests)
+	}
+	result := requests[1].Messages[2].Content[0]
+	if result.Type != "tool_result" || result.ToolUseID != "toolu_1" || !result.IsError || !strings.Contains(result.Content, "title is required") {
+		t.Fatalf("retry block = %+v", result)
+	}
+}
+
+func TestParseTasksShouldForceTaskListTool(t *testing.T) {
+	var payload request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"content":[{"type":"tool_use","id":"toolu_1","name":"record_tasks",`+
+			`"input":{"project":"weekly","due":"","tasks":[{"title":"write minutes"},{"title":"book room"}]}}],"stop_reason":"tool_use"}`)
+	}))
+	defer server.Close()
+
+	client := &Client{Endpoint: server.URL, APIKey: "sk-ant-test", HTTPClient: server.Client()}
+	raw, err := client.ParseTasks(context.Background(), "- write minutes\n- book room")
+	if err != nil {
+		t.Fatalf("ParseTasks error = %v, want nil", err)
+	}
+	if payload.ToolChoice.Name != ai.TaskListExtraction.Name || payload.Tools[0].InputSchema["required"] == nil {
+		t.Fatalf("tools = %+v, tool_choice = %+v", payload.Tools, payload.ToolChoice)
+	}
+	if !strings.HasPrefix(payload.System, "Extract every todo") {
+		t.Fatalf("system = %q, want the task list prompt", payload.System)
+	}
+	if !strings.Contains(raw, `"book room"`) {
+		t.Fatalf("raw = %q, want tool input", raw)
+	}
+}
@@ -1,27 +1,112 @@
 package ai
 
-import "sync"
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+)
 
-type Cache struct {
-	mu   sync.RWMutex
-	data map[string]string
+// DefaultMemoryCacheSize bounds the in-memory layer in front of the
+// persistent cache.
+const DefaultMemoryCacheSize = 128
+
+// Cache stores raw model responses by key. A cache that cannot be read or
+// written reports a miss instead of failing the parse.
+type Cache interface {
+	Get(ctx context.Context, key string) (string, bool)
+	Put(ctx context.Context, key, value string)
+}
+
+// CacheKey hashes what decides a response: the model, the prompt version
+// and the input.
+func CacheKey(model, promptVersion, input string) string {
+	sum := sha256.Sum256([]byte(model + "\x00" + promptVersion + "\x00" + input))
+	return hex.EncodeToString(sum[:])
+}
+
+type memoryEntry struct {
+	key   string
+	value string
+}
+
+// MemoryCache keeps the most recently used size entries.
+type MemoryCache struct {
+	mu    sync.Mutex
+	size  int
+	order *list.List
+	items map[string]*list.Element
 }
 
-func NewCache() *Cache {
-	return &Cache{
-		data: make(map[string]string),
+func NewMemoryCache(size int) *MemoryCache {
+	if size <= 0 {
+		size = DefaultMemoryCacheSize
+	}
+	return &MemoryCache{
+		size:  size,
+		order: list.New(),
+		items: make(map[string]*list.Element),
 	}
 }
 
-func (c *Cache) Get(key string) (string, bool) {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-	val, ok := c.data[key]
-	return val, ok
+func (c *MemoryCache) Get(_ context.Context, key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.items[key]
+	if !ok {
+		return "", false
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*memoryEntry).value, true
 }
 
-func (c *Cache) Put(key, value string) {
+func (c *MemoryCache) Put(_ context.Context, key, value string) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	c.data[key] = value
+	if el, ok := c.items[key]; ok {
+		el.Value.(*memoryEntry).value = value
+		c.order.MoveToFront(el)
+		return
+	}
+	c.items[key] = c.order.PushFront(&memoryEntry{key: key, value: value})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*memoryEntry).key)
+	}
+}
+
+func (c *MemoryCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// TieredCache answers from Front first and falls back to Back, copying
+// its hits to Front. Writes go to both.
+type TieredCache struct {
+	Front *MemoryCache
+	Back  Cache
+}
+
+func (c TieredCache) Get(ctx context.Context, key string) (string, bool) {
+	if value, ok := c.Front.Get(ctx, key); ok {
+		return value, true
+	}
+	if c.Back == nil {
+		return "", false
+	}
+	value, ok := c.Back.Get(ctx, key)
+	if ok {
+		c.Front.Put(ctx, key, value)
+	}
+	return value, ok
+}
+
+func (c TieredCache) Put(ctx context.Context, key, value string) {
+	c.Front.Put(ctx, key, value)
+	if c.Back != nil {
+		c.Back.Put(ctx, key, value)
+	}
 }
@@ -0,0 +1,39 @@
+package ai
+
+import (
+	"context"
+	"testing"
+)
+
+func TestMemoryCacheShouldEvictLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	cache := NewMemoryCache(2)
+	cache.Put(ctx, "a", "1")
+	cache.Put(ctx, "b", "2")
+	if _, ok := cache.Get(ctx, "a"); !ok {
+		t.Fatalf("a should be cached")
+	}
+	cache.Put(ctx, "c", "3")
+	if _, ok := cache.Get(ctx, "b"); ok {
+		t.Fatalf("b should be evicted")
+	}
+	if cache.Len() != 2 {
+		t.Fatalf("len = %d, want 2", cache.Len())
+	}
+}
+
+func TestTieredCacheShouldFillFrontFromBack(t *testing.T) {
+	ctx := context.Background()
+	back := NewMemoryCache(0)
+	back.Put(ctx, "k", "v")
+	cache := TieredCache{Front: NewMemoryCache(0), Back: back}
+	if value, ok := cache.Get(ctx, "k"); !ok || value != "v" {
+		t.Fatalf("get = %q, %v", value, ok)
+	}
+	if _, ok := cache.Front.Get(ctx, "k"); !ok {
+		t.Fatalf("back hit should be copied to the front")
+	}
+	if CacheKey("m", "v1", "x") == CacheKey("m", "v2", "x") {
+		t.Fatalf("prompt version should change the key")
+	}
+}
@@ -0,0 +1,29 @@
+package ai
+
+import (
+	"net"
+	"net/url"
+	"strings"
+)
+
+// IsLoopbackURL reports whether raw points at this machine, such as
+// http://localhost:11434 or http://127.0.0.1:8080/v1.
+func IsLoopbackURL(raw string) bool {
+	text := strings.TrimSpace(raw)
+	if text == "" {
+		return false
+	}
+	if !strings.Contains(text, "://") {
+		text = "http://" + text
+	}
+	u, err := url.Parse(text)
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
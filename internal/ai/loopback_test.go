@@ -0,0 +1,20 @@
+package ai
+
+import "testing"
+
+func TestIsLoopbackURL(t *testing.T) {
+	for raw, want := range map[string]bool{
+		"http://localhost:11434":        true,
+		"http://127.0.0.1:8080/v1":      true,
+		"http://[::1]:8080":             true,
+		"localhost:1234/v1":             true,
+		"https://api.openai.com/v1":     false,
+		"http://192.168.1.10:11434":     false,
+		"http://localhost.example.com/": false,
+		"":                              false,
+	} {
+		if got := IsLoopbackURL(raw); got != want {
+			t.Fatalf("IsLoopbackURL(%q) = %v, want %v", raw, got, want)
+		}
+	}
+}
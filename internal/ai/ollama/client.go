@@ -0,0 +1,159 @@
+package ollama
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"td/internal/ai"
+	"td/internal/timeutil"
+)
+
+const (
+	DefaultEndpoint = "http://localhost:11434"
+	DefaultModel    = "qwen2.5"
+)
+
+// Client calls the native chat API of an Ollama server. Replies are
+// constrained to the JSON schema of the extraction through format.
+type Client struct {
+	Endpoint   string
+	Model      string
+	Cache      ai.Cache
+	HTTPClient *http.Client
+	Clock      timeutil.Clock
+}
+
+type message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type request struct {
+	Model    string         `json:"model"`
+	Messages []message      `json:"messages"`
+	Format   map[string]any `json:"format"`
+	Stream   bool           `json:"stream"`
+}
+
+func (c *Client) ParseTask(ctx context.Context, input string) (string, error) {
+	return c.extract(ctx, input, ai.TaskExtraction)
+}
+
+func (c *Client) ParseTasks(ctx context.Context, input string) (string, error) {
+	return c.extract(ctx, input, ai.TaskListExtraction)
+}
+
+func (c *Client) extract(ctx context.Context, input string, e ai.Extraction) (string, error) {
+	endpoint := resolveChatEndpoint(c.Endpoint)
+	model := strings.TrimSpace(c.Model)
+	if model == "" {
+		model = DefaultModel
+	}
+	now := ai.Now(c.Clock)
+
+	cacheKey := e.CacheKey(model, input, now)
+	if c.Cache != nil {
+		if cached, ok := c.Cache.Get(ctx, cacheKey); ok {
+			return cached, nil
+		}
+	}
+
+	req := request{
+		Model: model,
+		Messages: []message{
+			{Role: "system", Content: e.Prompt(now)},
+			{Role: "user", Content: input},
+		},
+		Format: e.Schema(),
+	}
+	reply, err := c.chat(ctx, endpoint, req)
+	if err != nil {
+		return "", err
+	}
+	content, err := e.Check(reply)
+	if err != nil {
+		req.Messages = append(req.Messages,
+			message{Role: "assistant", Content: reply},
+			message{Role: "user", Content: ai.RetryPrompt(err)},
+		)
+		if reply, err = c.chat(ctx, endpoint, req); err != nil {
+			return "", err
+		}
+		if content, err = e.Check(reply); err != nil {
+			return "", fmt.Errorf("ollama api returned an invalid reply: %w", err)
+		}
+	}
+	if c.Cache != nil {
+		c.Cache.Put(ctx, cacheKey, content)
+	}
+	return content, nil
+}
+
+func (c *Client) chat(ctx context.Context, endpoint string, payload request) (string, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 60 * time.Second}
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	var result struct {
+		Message message `json:"message"`
+		Error   string  `json:"error"`
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		if json.Unmarshal(respBody, &result) == nil && strings.TrimSpace(result.Error) != "" {
+			return "", fmt.Errorf("ollama api error: %s", strings.TrimSpace(result.Error))
+		}
+		return "", fmt.Errorf("ollama api error: %s", resp.Status)
+	}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return "", err
+	}
+	content := strings.TrimSpace(result.Message.Content)
+	if content == "" {
+		return "", errors.New("ollama api returned empty content")
+	}
+	return content, nil
+}
+
+// resolveChatEndpoint accepts the server address, its /api base or the
+// full /api/chat URL.
+func resolveChatEndpoint(raw string) string {
+	text := strings.TrimRight(strings.TrimSpace(raw), "/")
+	switch {
+	case text == "":
+		return DefaultEndpoint + "/api/chat"
+	case strings.HasSuffix(text, "/api/chat"):
+		return text
+	case strings.HasSuffix(text, "/api"):
+		return text + "/chat"
+	default:
+		return text + "/api/chat"
+	}
+}
@@ -0,0 +1,63 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParseTaskShouldCallNativeChatWithSchemaFormat(t *testing.T) {
+	var (
+		gotPath string
+		gotAuth string
+		payload request
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotAuth = r.Header.Get("Authorization")
+		defer r.Body.Close()
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"model":"qwen2.5","message":{"role":"assistant","content":"{\"title\":\"local task\"}"},"done":true}`)
+	}))
+	defer server.Close()
+
+	client := &Client{Endpoint: server.URL, Model: "llama3.2", HTTPClient: server.Client()}
+	raw, err := client.ParseTask(context.Background(), "buy milk")
+	if err != nil {
+		t.Fatalf("ParseTask error = %v, want nil", err)
+	}
+	if gotPath != "/api/chat" || gotAuth != "" {
+		t.Fatalf("path = %q, authorization = %q", gotPath, gotAuth)
+	}
+	if payload.Model != "llama3.2" || payload.Format["type"] != "object" || payload.Stream {
+		t.Fatalf("payload = %+v", payload)
+	}
+	if len(payload.Messages) != 2 || payload.Messages[0].Role != "system" || payload.Messages[1].Content != "buy milk" {
+		t.Fatalf("messages = %+v", payload.Messages)
+	}
+	if raw != `{"title":"local task"}` {
+		t.Fatalf("raw = %q", raw)
+	}
+}
+
+func TestParseTaskShouldReportMissingModel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = io.WriteString(w, `{"error":"model \"qwen2.5\" not found, try pulling it first"}`)
+	}))
+	defer server.Close()
+
+	client := &Client{Endpoint: server.URL + "/api", HTTPClient: server.Client()}
+	_, err := client.ParseTask(context.Background(), "buy milk")
+	if err == nil || !strings.Contains(err.Error(), "try pulling it first") {
+		t.Fatalf("error = %v, want ollama error message", err)
+	}
+}
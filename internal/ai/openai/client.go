@@ -12,79 +12,99 @@ import (
 	"time"
 
 	"td/internal/ai"
+	"td/internal/timeutil"
+)
+
+// Response formats that constrain replies to JSON.
+const (
+	FormatJSONSchema = "json_schema"
+	FormatJSONObject = "json_object"
 )
 
 type Client struct {
-	Endpoint   string
-	APIKey     string
-	Model      string
-	Cache      *ai.Cache
-	HTTPClient *http.Client
+	Endpoint string
+	APIKey   string
+	Model    string
+	// ResponseFormat is FormatJSONSchema, FormatJSONObject or empty for
+	// servers that support neither.
+	ResponseFormat string
+	Cache          ai.Cache
+	HTTPClient     *http.Client
+	Clock          timeutil.Clock
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
 }
 
 func (c *Client) ParseTask(ctx context.Context, input string) (string, error) {
-	if c.APIKey == "" {
+	return c.extract(ctx, input, ai.TaskExtraction)
+}
+
+func (c *Client) ParseTasks(ctx context.Context, input string) (string, error) {
+	return c.extract(ctx, input, ai.TaskListExtraction)
+}
+
+func (c *Client) extract(ctx context.Context, input string, e ai.Extraction) (string, error) {
+	endpoint := resolveChatCompletionsEndpoint(c.Endpoint)
+	// Local servers such as llama.cpp or LM Studio take no key.
+	if c.APIKey == "" && !ai.IsLoopbackURL(endpoint) {
 		return "", errors.New("openai api key is empty")
 	}
-	endpoint := resolveChatCompletionsEndpoint(c.Endpoint)
 	model := strings.TrimSpace(c.Model)
 	if model == "" {
 		model = "deepseek-chat"
 	}
-	nowText := time.Now().Local().Format("2006-01-02 15:04")
+	now := ai.Now(c.Clock)
 
-	cacheKey := model + "\n" + input
+	cacheKey := e.CacheKey(model, input, now)
 	if c.Cache != nil {
-		if cached, ok := c.Cache.Get(cacheKey); ok {
+		if cached, ok := c.Cache.Get(ctx, cacheKey); ok {
 			return cached, nil
 		}
 	}
 
-	payload := map[string]any{
-		"model": model,
-		"messages": []map[string]string{
-			{
-				"role": "system",
-				"content": "Extract one todo from user text. Return only JSON with keys title,notes,project,priority,due,links. " +
-					"priority must be one of P1,P2,P3,P4. due must be empty string or local datetime in YYYY-MM-DD HH:MM. " +
-					"Extract project name when text indicates ownership, such as 在XXX项目下/归属XXX/for XXX project; otherwise project should be empty. " +
-					"Resolve relative time phrases (today/tomorrow/明天) using local time " + nowText + ".",
-			},
-			{
-				"role":    "user",
-				"content": input,
-			},
-		},
+	messages := []chatMessage{
+		{Role: "system", Content: e.Prompt(now)},
+		{Role: "user", Content: input},
 	}
-	body, err := json.Marshal(payload)
+	reply, err := c.complete(ctx, endpoint, model, messages, e)
 	if err != nil {
 		return "", err
 	}
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	content, err := e.Check(reply)
 	if err != nil {
-		return "", err
-	}
-	req.Header.Set("Authorization", "Bearer "+c.APIKey)
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept", "application/json")
-
-	httpClient := c.HTTPClient
-	if httpClient == nil {
-		httpClient = &http.Client{Timeout: 20 * time.Second}
+		messages = append(messages,
+			chatMessage{Role: "assistant", Content: reply},
+			chatMessage{Role: "user", Content: ai.RetryPrompt(err)},
+		)
+		if reply, err = c.complete(ctx, endpoint, model, messages, e); err != nil {
+			return "", err
+		}
+		if content, err = e.Check(reply); err != nil {
+			return "", fmt.Errorf("openai api returned an invalid reply: %w", err)
+		}
 	}
-	resp, err := httpClient.Do(req)
-	if err != nil {
-		return "", err
+	if c.Cache != nil {
+		c.Cache.Put(ctx, cacheKey, content)
 	}
-	defer resp.Body.Close()
+	return content, nil
+}
 
-	respBody, err := io.ReadAll(resp.Body)
+// complete sends one chat request and returns the reply. A server that
+// rejects the response format is asked again without it.
+func (c *Client) complete(ctx context.Context, endpoint, model string, messages []chatMessage, e ai.Extraction) (string, error) {
+	format := c.ResponseFormat
+	status, respBody, err := c.post(ctx, endpoint, chatPayload(model, messages, format, e))
+	if err == nil && status == http.StatusBadRequest && format != "" {
+		status, respBody, err = c.post(ctx, endpoint, chatPayload(model, messages, "", e))
+	}
 	if err != nil {
 		return "", err
 	}
-	if resp.StatusCode >= http.StatusBadRequest {
-		return "", fmt.Errorf("openai api error: %s", extractAPIError(respBody, resp.Status))
+	if status >= http.StatusBadRequest {
+		return "", fmt.Errorf("openai api error: %s", extractAPIError(respBody, fmt.Sprintf("%d %s", status, http.StatusText(status))))
 	}
 
 	var result struct {
@@ -100,16 +120,65 @@ func (c *Client) ParseTask(ctx context.Context, input string) (string, error) {
 	if len(result.Choices) == 0 {
 		return "", errors.New("openai api returned empty choices")
 	}
-	content := trimCodeFence(result.Choices[0].Message.Content)
+	content := strings.TrimSpace(result.Choices[0].Message.Content)
 	if content == "" {
 		return "", errors.New("openai api returned empty content")
 	}
-	if c.Cache != nil {
-		c.Cache.Put(cacheKey, content)
-	}
 	return content, nil
 }
 
+func chatPayload(model string, messages []chatMessage, format string, e ai.Extraction) map[string]any {
+	payload := map[string]any{
+		"model":    model,
+		"messages": messages,
+	}
+	switch format {
+	case FormatJSONSchema:
+		payload["response_format"] = map[string]any{
+			"type": FormatJSONSchema,
+			"json_schema": map[string]any{
+				"name":   e.Name,
+				"strict": true,
+				"schema": e.Schema(),
+			},
+		}
+	case FormatJSONObject:
+		payload["response_format"] = map[string]any{"type": FormatJSONObject}
+	}
+	return payload
+}
+
+func (c *Client) post(ctx context.Context, endpoint string, payload map[string]any) (int, []byte, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return 0, nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return 0, nil, err
+	}
+	if c.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.APIKey)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 20 * time.Second}
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, err
+	}
+	return resp.StatusCode, respBody, nil
+}
+
 func resolveChatCompletionsEndpoint(raw string) string {
 	text := strings.TrimSpace(raw)
 	if text == "" {
@@ -125,23 +194,6 @@ func resolveChatCompletionsEndpoint(raw string) string {
 	return text + "/chat/completions"
 }
 
-func trimCodeFence(raw string) string {
-	text := strings.TrimSpace(raw)
-	if !strings.HasPrefix(text, "```") {
-		return text
-	}
-	lines := strings.Split(text, "\n")
-	if len(lines) < 2 {
-		return text
-	}
-	lines = lines[1:]
-	last := strings.TrimSpace(lines[len(lines)-1])
-	if strings.HasPrefix(last, "```") {
-		lines = lines[:len(lines)-1]
-	}
-	return strings.TrimSpace(strings.Join(lines, "\n"))
-}
-
 func extractAPIError(body []byte, fallback string) string {
 	var payload struct {
 		Error struct {
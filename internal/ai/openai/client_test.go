@@ -8,8 +8,10 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"td/internal/ai"
+	"td/internal/timeutil"
 )
 
 func TestParseTaskShouldCallCompatibleAPIAndReturnMessageContent(t *testing.T) {
@@ -76,7 +78,7 @@ func TestParseTaskShouldHitCacheForSameInput(t *testing.T) {
 		Endpoint:   server.URL + "/v1/chat/completions",
 		APIKey:     "sk-test",
 		Model:      "deepseek-chat",
-		Cache:      ai.NewCache(),
+		Cache:      ai.NewMemoryCache(0),
 		HTTPClient: server.Client(),
 	}
 
@@ -96,3 +98,129 @@ func TestParseTaskShouldHitCacheForSameInput(t *testing.T) {
 		t.Fatalf("server hits = %d, want 1", hits)
 	}
 }
+
+func TestParseTaskShouldAllowEmptyKeyOnlyForLoopback(t *testing.T) {
+	gotAuth := "unset"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"choices":[{"message":{"content":"{\"title\":\"local\"}"}}]}`)
+	}))
+	defer server.Close()
+
+	client := &Client{Endpoint: server.URL + "/v1", HTTPClient: server.Client()}
+	if _, err := client.ParseTask(context.Background(), "buy milk"); err != nil {
+		t.Fatalf("ParseTask on %s error = %v, want nil", server.URL, err)
+	}
+	if gotAuth != "" {
+		t.Fatalf("authorization = %q, want none", gotAuth)
+	}
+
+	client.Endpoint = "https://api.openai.com/v1"
+	if _, err := client.ParseTask(context.Background(), "buy milk"); err == nil || !strings.Contains(err.Error(), "api key is empty") {
+		t.Fatalf("remote endpoint without key error = %v", err)
+	}
+}
+
+func TestParseTaskShouldRequestSchemaAndDropItWhenRejected(t *testing.T) {
+	var formats []any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		formats = append(formats, payload["response_format"])
+		w.Header().Set("Content-Type", "application/json")
+		if payload["response_format"] != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = io.WriteString(w, `{"error":{"message":"response_format is not supported"}}`)
+			return
+		}
+		_, _ = io.WriteString(w, `{"choices":[{"message":{"content":"{\"title\":\"plain\"}"}}]}`)
+	}))
+	defer server.Close()
+
+	client := &Client{
+		Endpoint:       server.URL,
+		APIKey:         "sk-test",
+		ResponseFormat: FormatJSONSchema,
+		HTTPClient:     server.Client(),
+	}
+	raw, err := client.ParseTask(context.Background(), "buy milk")
+	if err != nil || raw != `{"title":"plain"}` {
+		t.Fatalf("ParseTask = %q, %v", raw, err)
+	}
+	if len(formats) != 2 || formats[1] != nil {
+		t.Fatalf("formats = %+v, want schema then none", formats)
+	}
+	format, _ := formats[0].(map[string]any)
+	spec, _ := format["json_schema"].(map[string]any)
+	if format["type"] != FormatJSONSchema || spec["name"] != ai.TaskExtraction.Name || spec["strict"] != true {
+		t.Fatalf("response_format = %+v", formats[0])
+	}
+}
+
+func TestParseTaskShouldRepairThenRetryWithValidationError(t *testing.T) {
+	var requests [][]chatMessage
+	replies := []string{
+		"Sure! {\"title\": \"\", \"priority\": \"P2\",}",
+		"```json\n{\"title\": \"fixed\", \"priority\": \"P1\",}\n```",
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			Messages []chatMessage `json:"messages"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		requests = append(requests, payload.Messages)
+		body, _ := json.Marshal(map[string]any{
+			"choices": []any{map[string]any{"message": map[string]string{"content": replies[len(requests)-1]}}},
+		})
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(body)
+	}))
+	defer server.Close()
+
+	client := &Client{Endpoint: server.URL, APIKey: "sk-test", HTTPClient: server.Client()}
+	raw, err := client.ParseTask(context.Background(), "fix it")
+	if err != nil {
+		t.Fatalf("ParseTask error = %v, want nil", err)
+	}
+	if raw != `{"title": "fixed", "priority": "P1"}` {
+		t.Fatalf("raw = %q, want repaired json", raw)
+	}
+	if len(requests) != 2 || len(requests[1]) != 4 {
+		t.Fatalf("requests = %+v", requests)
+	}
+	if requests[1][2].Role != "assistant" || !strings.Contains(requests[1][3].Content, "title is required") {
+		t.Fatalf("retry messages = %+v", requests[1][2:])
+	}
+}
+
+func TestParseTaskShouldResolveDatesAgainstTheClock(t *testing.T) {
+	var system string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+		var payload struct {
+			Messages []chatMessage `json:"messages"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil || len(payload.Messages) == 0 {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		system = payload.Messages[0].Content
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"choices":[{"message":{"content":"{\"title\":\"AI task\",\"priority\":\"P2\"}"}}]}`)
+	}))
+	defer server.Close()
+
+	client := &Client{
+		Endpoint:   server.URL + "/v1",
+		APIKey:     "sk-test",
+		HTTPClient: server.Client(),
+		Clock:      timeutil.ClockFunc(func() time.Time { return time.Date(2026, 3, 4, 10, 15, 0, 0, time.Local) }),
+	}
+	if _, err := client.ParseTask(context.Background(), "call Bob tomorrow"); err != nil {
+		t.Fatalf("ParseTask error = %v", err)
+	}
+	if !strings.Contains(system, "2026-03-04 10:15") {
+		t.Fatalf("prompt should use the clock, got %q", system)
+	}
+}
@@ -0,0 +1,107 @@
+package ai
+
+import (
+	"time"
+
+	"td/internal/ai/schema"
+	"td/internal/timeutil"
+)
+
+// PromptVersion is part of the cache key; bump it when the prompt changes
+// so cached responses to the old prompt are not reused.
+const PromptVersion = "parse-task-v2"
+
+// Extraction is one kind of structured reply a provider can be asked for:
+// the prompt, the schema the reply follows and the check it must pass.
+type Extraction struct {
+	// Name names the schema, and the tool for providers that take the
+	// reply as a tool call.
+	Name        string
+	Description string
+	Version     string
+	Prompt      func(now time.Time) string
+	Schema      func() map[string]any
+	// Check repairs a near-JSON reply and validates it. It returns the
+	// JSON to use, and the error to feed back to the model when the reply
+	// is still unusable.
+	Check func(raw string) (string, error)
+}
+
+// TaskExtraction extracts one todo as a schema.ParseTaskPayload.
+var TaskExtraction = Extraction{
+	Name:        "record_task",
+	Description: "Record the todo extracted from the user text.",
+	Version:     PromptVersion,
+	Prompt:      ParseTaskPrompt,
+	Schema:      schema.ParseTaskJSONSchema,
+	Check:       CheckParseTaskReply,
+}
+
+// TaskListExtraction extracts every todo in a text, such as meeting
+// notes, as a schema.ParseTasksPayload.
+var TaskListExtraction = Extraction{
+	Name:        "record_tasks",
+	Description: "Record every todo extracted from the user text.",
+	Version:     "parse-tasks-v1",
+	Prompt:      ParseTasksPrompt,
+	Schema:      schema.ParseTasksJSONSchema,
+	Check:       CheckParseTasksReply,
+}
+
+// Now is the time prompts resolve relative dates against, read from clk
+// or from the system clock when clk is nil.
+func Now(clk timeutil.Clock) time.Time {
+	if clk == nil {
+		clk = timeutil.SystemClock{}
+	}
+	return clk.Now().Local()
+}
+
+// CacheKey keys a reply. The prompt resolves relative dates against
+// today, so a reply is only reused on the same day.
+func (e Extraction) CacheKey(model, input string, now time.Time) string {
+	return CacheKey(model, e.Version+" "+now.Format("2006-01-02"), input)
+}
+
+// RetryPrompt asks the model to answer again after err.
+func RetryPrompt(err error) string {
+	return "That reply was not valid: " + err.Error() + ". " +
+		"Reply again with only the JSON object, in the shape asked for."
+}
+
+// ParseTaskPrompt is the system prompt that asks a model to extract one
+// todo as JSON, resolving relative dates against now.
+func ParseTaskPrompt(now time.Time) string {
+	return "Extract one todo from user text. Return only JSON with keys title,notes,project,priority,due,links. " +
+		"priority must be one of P1,P2,P3,P4. due must be empty string or local datetime in YYYY-MM-DD HH:MM. " +
+		"Extract project name when text indicates ownership, such as 在XXX项目下/归属XXX/for XXX project; otherwise project should be empty. " +
+		"Resolve relative time phrases (today/tomorrow/明天) using local time " + now.Format("2006-01-02 15:04") + "."
+}
+
+// ParseTasksPrompt is ParseTaskPrompt for texts that hold several todos.
+func ParseTasksPrompt(now time.Time) string {
+	return "Extract every todo from user text, such as action items in meeting notes. " +
+		"Return only JSON with keys project,due,tasks. tasks is an array with one object per todo, with keys title,notes,project,priority,due,links. " +
+		"Put a project or due that applies to all todos in the top-level project and due, and leave it empty in the tasks that share it. " +
+		"Give each todo its own short title, notes and priority. Skip lines that are not todos, such as attendees or decisions already done. " +
+		"priority must be one of P1,P2,P3,P4. due must be empty string or local datetime in YYYY-MM-DD HH:MM. " +
+		"Resolve relative time phrases (today/tomorrow/明天) using local time " + now.Format("2006-01-02 15:04") + "."
+}
+
+// CheckParseTaskReply is the Check of TaskExtraction.
+func CheckParseTaskReply(raw string) (string, error) {
+	repaired := schema.RepairParseTaskJSON(raw)
+	if _, err := schema.DecodeParseTaskJSON(repaired); err != nil {
+		return repaired, err
+	}
+	return repaired, nil
+}
+
+// CheckParseTasksReply is the Check of TaskListExtraction.
+func CheckParseTasksReply(raw string) (string, error) {
+	repaired := schema.RepairParseTaskJSON(raw)
+	if _, err := schema.DecodeParseTasksJSON(repaired); err != nil {
+		return repaired, err
+	}
+	return repaired, nil
+}
@@ -5,3 +5,10 @@ import "context"
 type Provider interface {
 	ParseTask(ctx context.Context, input string) (string, error)
 }
+
+// MultiProvider is a Provider that can also extract every todo in a text
+// as the JSON of a schema.ParseTasksPayload.
+type MultiProvider interface {
+	Provider
+	ParseTasks(ctx context.Context, input string) (string, error)
+}
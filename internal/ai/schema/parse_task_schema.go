@@ -15,6 +15,34 @@ type ParseTaskPayload struct {
 	Links    []string `json:"links"`
 }
 
+// ParseTaskJSONSchema describes ParseTaskPayload for providers that can
+// constrain their output to a JSON schema. Every field is required so the
+// schema also works in strict mode.
+func ParseTaskJSONSchema() map[string]any {
+	str := func(description string) map[string]any {
+		return map[string]any{"type": "string", "description": description}
+	}
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"title":   str("short task title"),
+			"notes":   str("details worth keeping, or empty"),
+			"project": str("project the task belongs to, or empty"),
+			"priority": map[string]any{
+				"type": "string",
+				"enum": []string{"P1", "P2", "P3", "P4"},
+			},
+			"due": str("empty, or local datetime as YYYY-MM-DD HH:MM"),
+			"links": map[string]any{
+				"type":  "array",
+				"items": map[string]any{"type": "string"},
+			},
+		},
+		"required":             []string{"title", "notes", "project", "priority", "due", "links"},
+		"additionalProperties": false,
+	}
+}
+
 func ValidateParseTaskJSON(raw string) error {
 	_, err := DecodeParseTaskJSON(raw)
 	return err
@@ -15,3 +15,46 @@ func TestParseTaskSchema(t *testing.T) {
 		t.Fatalf("validate schema: %v", err)
 	}
 }
+
+func TestRepairParseTaskJSONShouldFixNearJSON(t *testing.T) {
+	for name, raw := range map[string]string{
+		"fence":          "```json\n{\"title\":\"Buy milk\"}\n```",
+		"prose":          "Here is the task:\n{\"title\":\"Buy milk\"}\nLet me know!",
+		"trailing comma": "{\"title\":\"Buy milk\",\"links\":[\"https://a.example\",],}",
+		"raw newline":    "{\"title\":\"Buy milk\",\"notes\":\"line 1\nline 2, ]\"}",
+	} {
+		repaired := RepairParseTaskJSON(raw)
+		payload, err := DecodeParseTaskJSON(repaired)
+		if err != nil {
+			t.Fatalf("%s: decode %q: %v", name, repaired, err)
+		}
+		if payload.Title != "Buy milk" {
+			t.Fatalf("%s: title = %q", name, payload.Title)
+		}
+	}
+	valid := `{"title":"a, }"}`
+	if got := RepairParseTaskJSON(valid); got != valid {
+		t.Fatalf("valid json changed to %q", got)
+	}
+}
+
+func TestDecodeParseTasksJSONShouldCheckEveryTask(t *testing.T) {
+	payload, err := DecodeParseTasksJSON(`{"project":"weekly","due":"2026-03-06 17:00","tasks":[{"title":"a"},{"title":"b","project":"office"}]}`)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	tasks := payload.Resolved()
+	if tasks[0].Project != "weekly" || tasks[0].Due != "2026-03-06 17:00" || tasks[1].Project != "office" {
+		t.Fatalf("resolved = %+v", tasks)
+	}
+
+	for raw, want := range map[string]string{
+		`{"tasks":[]}`: "tasks is empty",
+		`{"tasks":[{"title":"a"},{"title":" "}]}`:       "tasks[1]: title is required",
+		`{"tasks":[{"title":"a","priority":"urgent"}]}`: "tasks[0]: invalid priority",
+	} {
+		if _, err := DecodeParseTasksJSON(raw); err == nil || err.Error() != want {
+			t.Fatalf("decode %s error = %v, want %q", raw, err, want)
+		}
+	}
+}
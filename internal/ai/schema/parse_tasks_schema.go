@@ -0,0 +1,72 @@
+package schema
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ParseTasksPayload is every todo found in one text. Project and Due apply
+// to the tasks that leave their own empty.
+type ParseTasksPayload struct {
+	Project string             `json:"project"`
+	Due     string             `json:"due"`
+	Tasks   []ParseTaskPayload `json:"tasks"`
+}
+
+// Resolved returns the tasks with the shared project and due filled in.
+func (p ParseTasksPayload) Resolved() []ParseTaskPayload {
+	tasks := make([]ParseTaskPayload, 0, len(p.Tasks))
+	for _, task := range p.Tasks {
+		if strings.TrimSpace(task.Project) == "" {
+			task.Project = p.Project
+		}
+		if strings.TrimSpace(task.Due) == "" {
+			task.Due = p.Due
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks
+}
+
+// ParseTasksJSONSchema describes ParseTasksPayload the way
+// ParseTaskJSONSchema describes one task.
+func ParseTasksJSONSchema() map[string]any {
+	task := ParseTaskJSONSchema()
+	properties := task["properties"].(map[string]any)
+	properties["project"] = map[string]any{"type": "string", "description": "project of this task when it differs from the shared one, or empty"}
+	properties["due"] = map[string]any{"type": "string", "description": "due of this task when it differs from the shared one, or empty"}
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"project": map[string]any{"type": "string", "description": "project shared by the tasks, or empty"},
+			"due":     map[string]any{"type": "string", "description": "due shared by the tasks as YYYY-MM-DD HH:MM, or empty"},
+			"tasks": map[string]any{
+				"type":  "array",
+				"items": task,
+			},
+		},
+		"required":             []string{"project", "due", "tasks"},
+		"additionalProperties": false,
+	}
+}
+
+func DecodeParseTasksJSON(raw string) (ParseTasksPayload, error) {
+	var payload ParseTasksPayload
+	if err := json.Unmarshal([]byte(raw), &payload); err != nil {
+		return ParseTasksPayload{}, err
+	}
+	if len(payload.Tasks) == 0 {
+		return ParseTasksPayload{}, errors.New("tasks is empty")
+	}
+	for i, task := range payload.Tasks {
+		if strings.TrimSpace(task.Title) == "" {
+			return ParseTasksPayload{}, fmt.Errorf("tasks[%d]: title is required", i)
+		}
+		if task.Priority != "" && !isPriority(task.Priority) {
+			return ParseTasksPayload{}, fmt.Errorf("tasks[%d]: invalid priority", i)
+		}
+	}
+	return payload, nil
+}
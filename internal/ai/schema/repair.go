@@ -0,0 +1,84 @@
+package schema
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// RepairParseTaskJSON fixes the near-JSON replies models tend to give: a
+// ``` fence around the object, prose before or after it, trailing commas
+// and raw newlines inside strings. Valid JSON is returned unchanged.
+func RepairParseTaskJSON(raw string) string {
+	text := trimCodeFence(raw)
+	if json.Valid([]byte(text)) {
+		return text
+	}
+	if start, end := strings.Index(text, "{"), strings.LastIndex(text, "}"); start >= 0 && end > start {
+		text = text[start : end+1]
+	}
+	return fixJSONSyntax(text)
+}
+
+func trimCodeFence(raw string) string {
+	text := strings.TrimSpace(raw)
+	if !strings.HasPrefix(text, "```") {
+		return text
+	}
+	lines := strings.Split(text, "\n")
+	if len(lines) < 2 {
+		return text
+	}
+	lines = lines[1:]
+	last := strings.TrimSpace(lines[len(lines)-1])
+	if strings.HasPrefix(last, "```") {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// fixJSONSyntax drops commas that close an object or array and escapes
+// control characters inside strings.
+func fixJSONSyntax(text string) string {
+	var (
+		b        strings.Builder
+		inString bool
+		escaped  bool
+	)
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			case c == '\n':
+				b.WriteString(`\n`)
+				continue
+			case c == '\r':
+				continue
+			case c == '\t':
+				b.WriteString(`\t`)
+				continue
+			}
+			b.WriteByte(c)
+			continue
+		}
+		if c == '"' {
+			inString = true
+		}
+		if c == ',' {
+			j := i + 1
+			for j < len(text) && strings.IndexByte(" \t\r\n", text[j]) >= 0 {
+				j++
+			}
+			if j < len(text) && (text[j] == '}' || text[j] == ']') {
+				continue
+			}
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
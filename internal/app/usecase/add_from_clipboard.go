@@ -9,6 +9,7 @@ import (
 	"td/internal/clipboard"
 	"td/internal/domain"
 	"td/internal/repo"
+	"td/internal/timeutil"
 )
 
 type AddFromClipboardUseCase struct {
@@ -18,30 +19,29 @@ type AddFromClipboardUseCase struct {
 	Project       string
 	Priority      string
 	DueAt         *time.Time
+	Tags          []string
+	Recurrence    domain.Recurrence
+	Clock         timeutil.Clock
 }
 
 func (u AddFromClipboardUseCase) AddFromClipboard(ctx context.Context, text string, useAI bool) (domain.Task, error) {
-	parsed, _, err := u.ParseInput(ctx, text, useAI)
+	parsed, source, err := u.ParseInput(ctx, text, useAI)
 	if err != nil {
 		return domain.Task{}, err
 	}
+	if source == "ai" {
+		ctx = repo.WithSource(ctx, domain.SourceAI)
+	}
 	return u.CreateFromParsed(ctx, parsed)
 }
 
 func (u AddFromClipboardUseCase) ParseInput(ctx context.Context, text string, useAI bool) (clipboard.ParsedTask, string, error) {
-	if strings.TrimSpace(text) == "" {
-		reader := u.ReadClipboard
-		if reader == nil {
-			reader = clipboard.ReadText
-		}
-		raw, err := reader()
-		if err != nil {
-			return clipboard.ParsedTask{}, "", err
-		}
-		text = raw
+	text, err := u.readText(text)
+	if err != nil {
+		return clipboard.ParsedTask{}, "", err
 	}
 
-	parsed := clipboard.ParseByRule(text)
+	parsed := clipboard.ParseByRule(text, u.Clock)
 	source := "fallback"
 	if useAI && u.AIParser != nil {
 		aiParsed, aiSource, err := u.AIParser.ParseTaskWithSource(ctx, text)
@@ -59,7 +59,90 @@ func (u AddFromClipboardUseCase) ParseInput(ctx context.Context, text string, us
 	return parsed, source, nil
 }
 
+// ParseInputs is ParseInput for text that may hold several tasks, such as
+// meeting notes.
+func (u AddFromClipboardUseCase) ParseInputs(ctx context.Context, text string, useAI bool) ([]clipboard.ParsedTask, string, error) {
+	text, err := u.readText(text)
+	if err != nil {
+		return nil, "", err
+	}
+
+	parsed := clipboard.ParseListByRule(text, u.Clock)
+	source := "fallback"
+	if useAI && u.AIParser != nil {
+		aiParsed, aiSource, err := u.AIParser.ParseTasksWithSource(ctx, text)
+		if err == nil {
+			parsed = aiParsed
+			source = aiSource
+		}
+	}
+	out := make([]clipboard.ParsedTask, 0, len(parsed))
+	for _, task := range parsed {
+		if strings.TrimSpace(task.Title) == "" {
+			continue
+		}
+		if strings.TrimSpace(task.Priority) == "" {
+			task.Priority = "P2"
+		}
+		out = append(out, task)
+	}
+	if len(out) == 0 {
+		return nil, "", errors.New("clipboard text is empty")
+	}
+	return out, source, nil
+}
+
+func (u AddFromClipboardUseCase) readText(text string) (string, error) {
+	if strings.TrimSpace(text) != "" {
+		return text, nil
+	}
+	reader := u.ReadClipboard
+	if reader == nil {
+		reader = clipboard.ReadText
+	}
+	return reader()
+}
+
 func (u AddFromClipboardUseCase) CreateFromParsed(ctx context.Context, parsed clipboard.ParsedTask) (domain.Task, error) {
+	task, err := u.Draft(parsed)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	id, err := u.Repo.Create(ctx, task)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	return u.Repo.GetByID(ctx, id)
+}
+
+// CreateManyFromParsed creates the tasks in one operation, so one undo
+// removes them all.
+func (u AddFromClipboardUseCase) CreateManyFromParsed(ctx context.Context, parsed []clipboard.ParsedTask) ([]domain.Task, error) {
+	tasks := make([]domain.Task, 0, len(parsed))
+	for _, item := range parsed {
+		task, err := u.Draft(item)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+	ids, err := u.Repo.CreateMany(ctx, tasks)
+	if err != nil {
+		return nil, err
+	}
+	created := make([]domain.Task, 0, len(ids))
+	for _, id := range ids {
+		task, err := u.Repo.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		created = append(created, task)
+	}
+	return created, nil
+}
+
+// Draft returns the task CreateFromParsed would create from parsed.
+func (u AddFromClipboardUseCase) Draft(parsed clipboard.ParsedTask) (domain.Task, error) {
 	if strings.TrimSpace(parsed.Title) == "" {
 		return domain.Task{}, errors.New("clipboard text is empty")
 	}
@@ -77,7 +160,7 @@ func (u AddFromClipboardUseCase) CreateFromParsed(ctx context.Context, parsed cl
 	}
 	dueAt := u.DueAt
 	if dueAt == nil {
-		if parsedDue, ok := parseDueText(parsed.Due, time.Local); ok {
+		if parsedDue, err := timeutil.ParseDue(parsed.Due, u.Clock, time.Local); err == nil {
 			dueAt = &parsedDue
 		}
 	}
@@ -85,39 +168,14 @@ func (u AddFromClipboardUseCase) CreateFromParsed(ctx context.Context, parsed cl
 	if project != "" || dueAt != nil {
 		status = domain.StatusTodo
 	}
-	id, err := u.Repo.Create(ctx, domain.Task{
-		Title:    parsed.Title,
-		Notes:    parsed.Notes,
-		Status:   status,
-		Project:  project,
-		Priority: priority,
-		DueAt:    dueAt,
-	})
-	if err != nil {
-		return domain.Task{}, err
-	}
-	return u.Repo.GetByID(ctx, id)
-}
-
-func parseDueText(raw string, loc *time.Location) (time.Time, bool) {
-	text := strings.TrimSpace(raw)
-	if text == "" {
-		return time.Time{}, false
-	}
-	if t, err := time.Parse(time.RFC3339, text); err == nil {
-		return t.In(loc), true
-	}
-	if t, err := time.ParseInLocation("2006-01-02 15:04", text, loc); err == nil {
-		return t, true
-	}
-	if t, err := time.ParseInLocation("2006-01-02T15:04", text, loc); err == nil {
-		return t, true
-	}
-	if t, err := time.ParseInLocation("200601021504", text, loc); err == nil {
-		return t, true
-	}
-	if t, err := time.ParseInLocation("2006-01-02", text, loc); err == nil {
-		return t, true
-	}
-	return time.Time{}, false
+	return domain.Task{
+		Title:      parsed.Title,
+		Notes:      parsed.Notes,
+		Status:     status,
+		Project:    project,
+		Priority:   priority,
+		DueAt:      dueAt,
+		Tags:       u.Tags,
+		Recurrence: u.Recurrence,
+	}, nil
 }
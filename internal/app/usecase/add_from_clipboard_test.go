@@ -19,7 +19,7 @@ func TestAddFromClipboardRule(t *testing.T) {
 		t.Fatalf("migrate: %v", err)
 	}
 
-	repo := sqlite.NewTaskRepository(db)
+	repo := sqlite.NewTaskRepository(db, domain.DefaultWorkflow())
 	uc := AddFromClipboardUseCase{
 		Repo: repo,
 	}
@@ -40,7 +40,7 @@ func TestAddFromClipboardAIShouldSetDueAndTodoStatus(t *testing.T) {
 		t.Fatalf("migrate: %v", err)
 	}
 
-	repo := sqlite.NewTaskRepository(db)
+	repo := sqlite.NewTaskRepository(db, domain.DefaultWorkflow())
 	uc := AddFromClipboardUseCase{
 		Repo: repo,
 		AIParser: &AIParseTaskUseCase{
@@ -69,6 +69,42 @@ func TestAddFromClipboardAIShouldSetDueAndTodoStatus(t *testing.T) {
 	}
 }
 
+func TestCreateManyFromParsedShouldCreateAcceptedTasks(t *testing.T) {
+	db := sqliteOpenTestDB(t)
+	defer db.Close()
+	if err := sqlite.Migrate(db); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	repo := sqlite.NewTaskRepository(db, domain.DefaultWorkflow())
+	uc := AddFromClipboardUseCase{
+		Repo:     repo,
+		Priority: "P1",
+		AIParser: &AIParseTaskUseCase{
+			Provider: fakeMultiParseProvider{
+				raw: `{"project":"weekly","due":"","tasks":[{"title":"write minutes"},{"title":"book room","priority":"P3"}]}`,
+			},
+		},
+	}
+
+	parsed, source, err := uc.ParseInputs(context.Background(), "meeting notes", true)
+	if err != nil || source != "ai" || len(parsed) != 2 {
+		t.Fatalf("parse inputs = %+v, %q, %v", parsed, source, err)
+	}
+	tasks, err := uc.CreateManyFromParsed(context.Background(), parsed)
+	if err != nil {
+		t.Fatalf("create many: %v", err)
+	}
+	if len(tasks) != 2 || tasks[1].Title != "book room" {
+		t.Fatalf("tasks = %+v", tasks)
+	}
+	for _, task := range tasks {
+		if task.Project != "weekly" || task.Priority != "P1" || task.Status != domain.StatusTodo {
+			t.Fatalf("task = %+v", task)
+		}
+	}
+}
+
 func TestParseInputShouldReturnAISourceAndProject(t *testing.T) {
 	uc := AddFromClipboardUseCase{
 		AIParser: &AIParseTaskUseCase{
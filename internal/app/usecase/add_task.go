@@ -2,6 +2,7 @@ package usecase
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"td/internal/domain"
@@ -13,6 +14,12 @@ type AddTaskInput struct {
 	Project  string
 	Priority string
 	DueAt    *time.Time
+	Tags     []string
+	// Recurrence makes completing the task create the next occurrence.
+	Recurrence domain.Recurrence
+	// ParentID makes the task a subtask; without a project it inherits the
+	// parent's.
+	ParentID int64
 }
 
 type AddTaskUseCase struct {
@@ -24,17 +31,27 @@ func (u AddTaskUseCase) Execute(ctx context.Context, in AddTaskInput) (domain.Ta
 	if priority == "" {
 		priority = "P2"
 	}
+	if in.ParentID != 0 && in.Project == "" {
+		parent, err := u.Repo.GetByID(ctx, in.ParentID)
+		if err != nil {
+			return domain.Task{}, fmt.Errorf("parent #%d: %w", in.ParentID, err)
+		}
+		in.Project = parent.Project
+	}
 	status := domain.StatusInbox
 	if in.Project != "" {
 		status = domain.StatusTodo
 	}
 
 	id, err := u.Repo.Create(ctx, domain.Task{
-		Title:    in.Title,
-		Status:   status,
-		Project:  in.Project,
-		Priority: priority,
-		DueAt:    in.DueAt,
+		ParentID:   in.ParentID,
+		Title:      in.Title,
+		Status:     status,
+		Project:    in.Project,
+		Priority:   priority,
+		DueAt:      in.DueAt,
+		Tags:       in.Tags,
+		Recurrence: in.Recurrence,
 	})
 	if err != nil {
 		return domain.Task{}, err
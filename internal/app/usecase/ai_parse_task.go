@@ -7,10 +7,12 @@ import (
 	"td/internal/ai"
 	"td/internal/ai/schema"
 	"td/internal/clipboard"
+	"td/internal/timeutil"
 )
 
 type AIParseTaskUseCase struct {
 	Provider ai.Provider
+	Clock    timeutil.Clock
 }
 
 func (u AIParseTaskUseCase) ParseTask(ctx context.Context, input string) (clipboard.ParsedTask, error) {
@@ -19,7 +21,7 @@ func (u AIParseTaskUseCase) ParseTask(ctx context.Context, input string) (clipbo
 }
 
 func (u AIParseTaskUseCase) ParseTaskWithSource(ctx context.Context, input string) (clipboard.ParsedTask, string, error) {
-	fallback := clipboard.ParseByRule(input)
+	fallback := clipboard.ParseByRule(input, u.Clock)
 	if u.Provider == nil {
 		return fallback, "fallback", nil
 	}
@@ -34,6 +36,56 @@ func (u AIParseTaskUseCase) ParseTaskWithSource(ctx context.Context, input strin
 		return fallback, "fallback", nil
 	}
 
+	parsed := parsedFromPayload(payload)
+	if parsed.Title == "" {
+		return fallback, "fallback", nil
+	}
+	if parsed.Notes == "" {
+		parsed.Notes = fallback.Notes
+	}
+	if len(parsed.Links) == 0 {
+		parsed.Links = fallback.Links
+	}
+	return parsed, "ai", nil
+}
+
+// ParseTasksWithSource extracts every task in input. A provider that can
+// only extract one task gives one; when the provider fails the list items
+// of input are parsed by rule.
+func (u AIParseTaskUseCase) ParseTasksWithSource(ctx context.Context, input string) ([]clipboard.ParsedTask, string, error) {
+	multi, ok := u.Provider.(ai.MultiProvider)
+	if !ok {
+		if u.Provider == nil {
+			return clipboard.ParseListByRule(input, u.Clock), "fallback", nil
+		}
+		parsed, source, err := u.ParseTaskWithSource(ctx, input)
+		if err != nil {
+			return nil, "", err
+		}
+		return []clipboard.ParsedTask{parsed}, source, nil
+	}
+
+	raw, err := multi.ParseTasks(ctx, ai.RedactInput(input))
+	if err != nil {
+		return clipboard.ParseListByRule(input, u.Clock), "fallback", nil
+	}
+	payload, err := schema.DecodeParseTasksJSON(raw)
+	if err != nil {
+		return clipboard.ParseListByRule(input, u.Clock), "fallback", nil
+	}
+	tasks := make([]clipboard.ParsedTask, 0, len(payload.Tasks))
+	for _, item := range payload.Resolved() {
+		if parsed := parsedFromPayload(item); parsed.Title != "" {
+			tasks = append(tasks, parsed)
+		}
+	}
+	if len(tasks) == 0 {
+		return clipboard.ParseListByRule(input, u.Clock), "fallback", nil
+	}
+	return tasks, "ai", nil
+}
+
+func parsedFromPayload(payload schema.ParseTaskPayload) clipboard.ParsedTask {
 	parsed := clipboard.ParsedTask{
 		Title:    strings.TrimSpace(payload.Title),
 		Notes:    strings.TrimSpace(payload.Notes),
@@ -42,17 +94,8 @@ func (u AIParseTaskUseCase) ParseTaskWithSource(ctx context.Context, input strin
 		Due:      strings.TrimSpace(payload.Due),
 		Links:    payload.Links,
 	}
-	if parsed.Title == "" {
-		return fallback, "fallback", nil
-	}
-	if parsed.Notes == "" {
-		parsed.Notes = fallback.Notes
-	}
 	if parsed.Priority == "" {
 		parsed.Priority = "P2"
 	}
-	if len(parsed.Links) == 0 {
-		parsed.Links = fallback.Links
-	}
-	return parsed, "ai", nil
+	return parsed
 }
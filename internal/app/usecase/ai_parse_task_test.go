@@ -2,6 +2,7 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"testing"
 )
 
@@ -44,6 +45,46 @@ func TestParseTaskWithSource(t *testing.T) {
 	}
 }
 
+func TestParseTasksWithSourceShouldInheritSharedFields(t *testing.T) {
+	uc := AIParseTaskUseCase{
+		Provider: fakeMultiParseProvider{
+			raw: `{"project":"weekly","due":"2026-03-06 17:00","tasks":[
+				{"title":"write minutes","notes":"","project":"","priority":"P1","due":"","links":[]},
+				{"title":"book room","notes":"for friday","project":"office","priority":"","due":"2026-03-05 12:00","links":[]}
+			]}`,
+		},
+	}
+
+	got, source, err := uc.ParseTasksWithSource(context.Background(), "notes")
+	if err != nil {
+		t.Fatalf("parse tasks: %v", err)
+	}
+	if source != "ai" || len(got) != 2 {
+		t.Fatalf("source = %q, tasks = %+v", source, got)
+	}
+	if got[0].Project != "weekly" || got[0].Due != "2026-03-06 17:00" || got[0].Priority != "P1" {
+		t.Fatalf("first task = %+v", got[0])
+	}
+	if got[1].Project != "office" || got[1].Due != "2026-03-05 12:00" || got[1].Priority != "P2" || got[1].Notes != "for friday" {
+		t.Fatalf("second task = %+v", got[1])
+	}
+}
+
+func TestParseTasksWithSourceShouldFallBack(t *testing.T) {
+	notes := "- write minutes\n- book room"
+	uc := AIParseTaskUseCase{Provider: fakeMultiParseProvider{err: errors.New("offline")}}
+	got, source, err := uc.ParseTasksWithSource(context.Background(), notes)
+	if err != nil || source != "fallback" || len(got) != 2 || got[1].Title != "book room" {
+		t.Fatalf("failing provider = %+v, %q, %v", got, source, err)
+	}
+
+	uc = AIParseTaskUseCase{Provider: fakeParseProvider{raw: `{"title":"AI title","priority":"P3"}`}}
+	got, source, err = uc.ParseTasksWithSource(context.Background(), notes)
+	if err != nil || source != "ai" || len(got) != 1 || got[0].Title != "AI title" {
+		t.Fatalf("single task provider = %+v, %q, %v", got, source, err)
+	}
+}
+
 type fakeParseProvider struct {
 	raw string
 	err error
@@ -52,3 +93,16 @@ type fakeParseProvider struct {
 func (f fakeParseProvider) ParseTask(_ context.Context, _ string) (string, error) {
 	return f.raw, f.err
 }
+
+type fakeMultiParseProvider struct {
+	raw string
+	err error
+}
+
+func (f fakeMultiParseProvider) ParseTask(_ context.Context, _ string) (string, error) {
+	return "", errors.New("not used")
+}
+
+func (f fakeMultiParseProvider) ParseTasks(_ context.Context, _ string) (string, error) {
+	return f.raw, f.err
+}
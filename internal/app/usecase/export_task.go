@@ -0,0 +1,53 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"td/internal/domain"
+	"td/internal/repo"
+)
+
+type ExportInput struct {
+	View    domain.View
+	Project string
+	Now     time.Time
+}
+
+type ExportData struct {
+	Tasks    []domain.Task
+	Projects []string
+	Tags     []string
+}
+
+type ExportTaskUseCase struct {
+	Repo     repo.TaskRepository
+	Workflow domain.Workflow
+}
+
+// Execute returns every task, including deleted ones, with all projects and
+// tags so an import can rebuild the same state. A View narrows the tasks to
+// what that nav view shows at Now.
+func (u ExportTaskUseCase) Execute(ctx context.Context, in ExportInput) (ExportData, error) {
+	var (
+		tasks []domain.Task
+		err   error
+	)
+	if in.View != "" {
+		tasks, err = NewNavQueryUseCase(u.Repo, u.Workflow).ListByView(ctx, in.View, in.Now, in.Project, false)
+	} else {
+		tasks, err = u.Repo.List(ctx, repo.TaskListFilter{})
+	}
+	if err != nil {
+		return ExportData{}, err
+	}
+	projects, err := u.Repo.ListProjects(ctx)
+	if err != nil {
+		return ExportData{}, err
+	}
+	tags, err := u.Repo.ListTags(ctx)
+	if err != nil {
+		return ExportData{}, err
+	}
+	return ExportData{Tasks: tasks, Projects: projects, Tags: tags}, nil
+}
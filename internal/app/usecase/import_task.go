@@ -0,0 +1,167 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"td/internal/domain"
+	"td/internal/repo"
+)
+
+type ImportStrategy string
+
+const (
+	ImportSkip      ImportStrategy = "skip"
+	ImportOverwrite ImportStrategy = "overwrite"
+	ImportDuplicate ImportStrategy = "duplicate"
+)
+
+func ParseImportStrategy(raw string) (ImportStrategy, error) {
+	switch strategy := ImportStrategy(strings.ToLower(strings.TrimSpace(raw))); strategy {
+	case "":
+		return ImportSkip, nil
+	case ImportSkip, ImportOverwrite, ImportDuplicate:
+		return strategy, nil
+	default:
+		return "", fmt.Errorf("unsupported strategy %q, use skip, overwrite or duplicate", raw)
+	}
+}
+
+type ImportAction string
+
+const (
+	ImportActionCreate    ImportAction = "create"
+	ImportActionOverwrite ImportAction = "overwrite"
+	ImportActionDuplicate ImportAction = "duplicate"
+	ImportActionSkip      ImportAction = "skip"
+)
+
+type ImportInput struct {
+	Tasks    []domain.Task
+	Projects []string
+	Strategy ImportStrategy
+	DryRun   bool
+}
+
+type ImportItem struct {
+	SourceID int64
+	TargetID int64
+	Title    string
+	Action   ImportAction
+}
+
+type ImportResult struct {
+	Items []ImportItem
+}
+
+func (r ImportResult) Count(action ImportAction) int {
+	n := 0
+	for _, item := range r.Items {
+		if item.Action == action {
+			n++
+		}
+	}
+	return n
+}
+
+type ImportTaskUseCase struct {
+	Repo repo.TaskRepository
+}
+
+// Execute matches incoming tasks to existing ones by ID. Tasks whose ID is
+// free are created under that ID; conflicts follow the strategy. Subtasks
+// and tasks blocked by a duplicated task point at its copy. With DryRun nothing is written
+// and TargetID is only known for overwrites.
+func (u ImportTaskUseCase) Execute(ctx context.Context, in ImportInput) (ImportResult, error) {
+	strategy := in.Strategy
+	if strategy == "" {
+		strategy = ImportSkip
+	}
+	existing, err := u.Repo.List(ctx, repo.TaskListFilter{})
+	if err != nil {
+		return ImportResult{}, err
+	}
+	taken := make(map[int64]struct{}, len(existing))
+	for _, task := range existing {
+		taken[task.ID] = struct{}{}
+	}
+
+	items := make([]ImportItem, 0, len(in.Tasks))
+	var fixed, fresh []domain.Task
+	var fixedItems, freshItems []int
+	// A duplicate has no ID until it is written; Upsert takes a negative
+	// stand-in, which its subtasks and the tasks it blocks in the batch
+	// point at instead.
+	copies := make(map[int64]int64)
+	for _, task := range in.Tasks {
+		item := ImportItem{SourceID: task.ID, Title: task.Title}
+		_, conflict := taken[task.ID]
+		switch {
+		case task.ID <= 0:
+			item.Action = ImportActionCreate
+			task.ID = 0
+		case !conflict:
+			item.Action = ImportActionCreate
+			item.TargetID = task.ID
+			taken[task.ID] = struct{}{}
+		case strategy == ImportOverwrite:
+			item.Action = ImportActionOverwrite
+			item.TargetID = task.ID
+		case strategy == ImportDuplicate:
+			item.Action = ImportActionDuplicate
+			task.ID = -int64(len(items) + 1)
+			copies[item.SourceID] = task.ID
+		default:
+			item.Action = ImportActionSkip
+			item.TargetID = task.ID
+		}
+		items = append(items, item)
+		switch {
+		case item.Action == ImportActionSkip:
+		case task.ID > 0:
+			fixed = append(fixed, task)
+			fixedItems = append(fixedItems, len(items)-1)
+		default:
+			fresh = append(fresh, task)
+			freshItems = append(freshItems, len(items)-1)
+		}
+	}
+	if in.DryRun {
+		return ImportResult{Items: items}, nil
+	}
+	for _, batch := range [][]domain.Task{fixed, fresh} {
+		for i := range batch {
+			if id, ok := copies[batch[i].ParentID]; ok {
+				batch[i].ParentID = id
+			}
+			blockers := make([]int64, 0, len(batch[i].BlockedBy))
+			for _, blockerID := range batch[i].BlockedBy {
+				if id, ok := copies[blockerID]; ok {
+					blockerID = id
+				}
+				blockers = append(blockers, blockerID)
+			}
+			batch[i].BlockedBy = blockers
+		}
+	}
+
+	for _, project := range in.Projects {
+		if strings.TrimSpace(project) == "" {
+			continue
+		}
+		if err := u.Repo.CreateProject(ctx, project); err != nil {
+			return ImportResult{}, err
+		}
+	}
+	// Rows keeping their ID go first so new IDs cannot take one of them.
+	ids, err := u.Repo.Upsert(ctx, append(fixed, fresh...))
+	if err != nil {
+		return ImportResult{}, err
+	}
+	order := append(fixedItems, freshItems...)
+	for i, id := range ids {
+		items[order[i]].TargetID = id
+	}
+	return ImportResult{Items: items}, nil
+}
@@ -0,0 +1,175 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"td/internal/domain"
+	"td/internal/repo/sqlite"
+)
+
+func TestImportTaskUseCaseStrategies(t *testing.T) {
+	db := openNavTestDB(t)
+	defer db.Close()
+	if err := sqlite.Migrate(db); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	repo := sqlite.NewTaskRepository(db, domain.DefaultWorkflow())
+	ctx := context.Background()
+
+	existingID, err := repo.Create(ctx, domain.Task{Title: "local", Status: domain.StatusTodo})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	doneAt := time.Date(2026, 2, 20, 9, 0, 0, 0, time.UTC)
+	incoming := []domain.Task{
+		{ID: existingID, Title: "remote", Status: domain.StatusDone, Project: "work", DoneAt: &doneAt, Tags: []string{"sync"}},
+		{ID: existingID + 5, Title: "fresh", Status: domain.StatusInbox},
+	}
+	uc := ImportTaskUseCase{Repo: repo}
+
+	result, err := uc.Execute(ctx, ImportInput{Tasks: incoming, Strategy: ImportOverwrite, DryRun: true})
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if result.Count(ImportActionOverwrite) != 1 || result.Count(ImportActionCreate) != 1 {
+		t.Fatalf("dry run items = %+v", result.Items)
+	}
+	if task, _ := repo.GetByID(ctx, existingID); task.Title != "local" {
+		t.Fatalf("dry run should not write, title = %q", task.Title)
+	}
+
+	result, err = uc.Execute(ctx, ImportInput{Tasks: incoming, Strategy: ImportSkip})
+	if err != nil {
+		t.Fatalf("skip: %v", err)
+	}
+	if result.Count(ImportActionSkip) != 1 || result.Count(ImportActionCreate) != 1 {
+		t.Fatalf("skip items = %+v", result.Items)
+	}
+	fresh, err := repo.GetByID(ctx, existingID+5)
+	if err != nil || fresh.Title != "fresh" {
+		t.Fatalf("fresh task should keep its id, got %+v, err=%v", fresh, err)
+	}
+
+	if _, err := uc.Execute(ctx, ImportInput{Tasks: incoming[:1], Strategy: ImportOverwrite}); err != nil {
+		t.Fatalf("overwrite: %v", err)
+	}
+	task, err := repo.GetByID(ctx, existingID)
+	if err != nil {
+		t.Fatalf("get overwritten: %v", err)
+	}
+	if task.Title != "remote" || task.Status != domain.StatusDone || task.DoneAt == nil || !task.DoneAt.Equal(doneAt) {
+		t.Fatalf("overwritten task = %+v", task)
+	}
+	if len(task.Tags) != 1 || task.Tags[0] != "sync" {
+		t.Fatalf("overwritten tags = %v", task.Tags)
+	}
+
+	result, err = uc.Execute(ctx, ImportInput{Tasks: incoming, Strategy: ImportDuplicate})
+	if err != nil {
+		t.Fatalf("duplicate: %v", err)
+	}
+	if result.Count(ImportActionDuplicate) != 2 {
+		t.Fatalf("duplicate items = %+v", result.Items)
+	}
+	for _, item := range result.Items {
+		if item.TargetID == item.SourceID || item.TargetID == 0 {
+			t.Fatalf("duplicate should get a new id, item = %+v", item)
+		}
+	}
+}
+
+func TestImportDuplicateShouldMoveSubtasksUnderTheCopies(t *testing.T) {
+	db := openNavTestDB(t)
+	defer db.Close()
+	if err := sqlite.Migrate(db); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	repo := sqlite.NewTaskRepository(db, domain.DefaultWorkflow())
+	ctx := context.Background()
+
+	parentID, _ := repo.Create(ctx, domain.Task{Title: "plan trip", Status: domain.StatusTodo})
+	childID, _ := repo.Create(ctx, domain.Task{Title: "book hotel", Status: domain.StatusTodo, ParentID: parentID})
+	outsideID, _ := repo.Create(ctx, domain.Task{Title: "errands", Status: domain.StatusTodo})
+	incoming := []domain.Task{
+		{ID: childID, Title: "book hotel", Status: domain.StatusTodo, ParentID: parentID},
+		{ID: parentID, Title: "plan trip", Status: domain.StatusTodo},
+		{ID: outsideID + 10, Title: "buy stamps", Status: domain.StatusTodo, ParentID: outsideID},
+	}
+	uc := ImportTaskUseCase{Repo: repo}
+
+	result, err := uc.Execute(ctx, ImportInput{Tasks: incoming, Strategy: ImportDuplicate})
+	if err != nil {
+		t.Fatalf("duplicate: %v", err)
+	}
+	copyOfChild, copyOfParent := result.Items[0].TargetID, result.Items[1].TargetID
+	child, err := repo.GetByID(ctx, copyOfChild)
+	if err != nil || child.ParentID != copyOfParent {
+		t.Fatalf("copied subtask = %+v, %v, want parent #%d", child, err, copyOfParent)
+	}
+	if original, _ := repo.GetByID(ctx, childID); original.ParentID != parentID {
+		t.Fatalf("original subtask moved to #%d", original.ParentID)
+	}
+	if stamps, _ := repo.GetByID(ctx, outsideID+10); stamps.ParentID != outsideID {
+		t.Fatalf("parent outside the batch = #%d, want #%d", stamps.ParentID, outsideID)
+	}
+
+	orphan := []domain.Task{{Title: "lost", Status: domain.StatusTodo, ParentID: outsideID + 99}}
+	if _, err := uc.Execute(ctx, ImportInput{Tasks: orphan, Strategy: ImportDuplicate}); !errors.Is(err, domain.ErrTaskNotFound) {
+		t.Fatalf("missing parent err = %v", err)
+	}
+	if err := repo.SoftDelete(ctx, []int64{outsideID}); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	orphan[0].ParentID = outsideID
+	if _, err := uc.Execute(ctx, ImportInput{Tasks: orphan, Strategy: ImportDuplicate}); err == nil {
+		t.Fatalf("deleted parent should be refused")
+	}
+}
+
+func TestImportShouldRestoreBlockersAndFollowDuplicates(t *testing.T) {
+	db := openNavTestDB(t)
+	defer db.Close()
+	if err := sqlite.Migrate(db); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	repo := sqlite.NewTaskRepository(db, domain.DefaultWorkflow())
+	ctx := context.Background()
+
+	blockerID, _ := repo.Create(ctx, domain.Task{Title: "get quote", Status: domain.StatusTodo})
+	blockedID, _ := repo.Create(ctx, domain.Task{Title: "sign contract", Status: domain.StatusTodo})
+	incoming := []domain.Task{
+		{ID: blockedID, Title: "sign contract", Status: domain.StatusTodo, BlockedBy: []int64{blockerID}},
+		{ID: blockerID, Title: "get quote", Status: domain.StatusTodo},
+	}
+	uc := ImportTaskUseCase{Repo: repo}
+
+	if _, err := uc.Execute(ctx, ImportInput{Tasks: incoming, Strategy: ImportOverwrite}); err != nil {
+		t.Fatalf("overwrite: %v", err)
+	}
+	if task, _ := repo.GetByID(ctx, blockedID); len(task.BlockedBy) != 1 || task.BlockedBy[0] != blockerID {
+		t.Fatalf("restored blockers = %v", task.BlockedBy)
+	}
+
+	result, err := uc.Execute(ctx, ImportInput{Tasks: incoming, Strategy: ImportDuplicate})
+	if err != nil {
+		t.Fatalf("duplicate: %v", err)
+	}
+	copyOfBlocked, copyOfBlocker := result.Items[0].TargetID, result.Items[1].TargetID
+	if task, _ := repo.GetByID(ctx, copyOfBlocked); len(task.BlockedBy) != 1 || task.BlockedBy[0] != copyOfBlocker {
+		t.Fatalf("copied blockers = %v, want [%d]", task.BlockedBy, copyOfBlocker)
+	}
+
+	cycle := []domain.Task{
+		{ID: blockerID, Title: "get quote", Status: domain.StatusTodo, BlockedBy: []int64{blockedID}},
+	}
+	if _, err := uc.Execute(ctx, ImportInput{Tasks: cycle, Strategy: ImportOverwrite}); !errors.Is(err, domain.ErrDependencyCycle) {
+		t.Fatalf("cycle err = %v", err)
+	}
+	missing := []domain.Task{{Title: "orphan", Status: domain.StatusTodo, BlockedBy: []int64{blockedID + 99}}}
+	if _, err := uc.Execute(ctx, ImportInput{Tasks: missing}); !errors.Is(err, domain.ErrTaskNotFound) {
+		t.Fatalf("missing blocker err = %v", err)
+	}
+}
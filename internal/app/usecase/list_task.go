@@ -2,14 +2,17 @@ package usecase
 
 import (
 	"context"
+	"errors"
 
 	"td/internal/domain"
+	"td/internal/query"
 	"td/internal/repo"
 )
 
 type ListTaskInput struct {
-	Project string
-	Limit   int
+	Statuses []domain.Status
+	Project  string
+	Limit    int
 }
 
 type ListTaskUseCase struct {
@@ -18,7 +21,45 @@ type ListTaskUseCase struct {
 
 func (u ListTaskUseCase) Execute(ctx context.Context, in ListTaskInput) ([]domain.Task, error) {
 	return u.Repo.List(ctx, repo.TaskListFilter{
-		Project: in.Project,
-		Limit:   in.Limit,
+		Statuses: in.Statuses,
+		Project:  in.Project,
+		Limit:    in.Limit,
 	})
 }
+
+func (u ListTaskUseCase) Query(ctx context.Context, q query.Query) ([]domain.Task, error) {
+	tasks, err := u.Repo.List(ctx, q.Filter())
+	if err != nil {
+		return nil, err
+	}
+	out := make([]domain.Task, 0, len(tasks))
+	for _, task := range tasks {
+		if q.Match(task) {
+			out = append(out, task)
+		}
+	}
+	return out, nil
+}
+
+// Search runs the text terms of q through the full-text index, best match
+// first, and applies the remaining predicates like Query does.
+func (u ListTaskUseCase) Search(ctx context.Context, q query.Query, limit int) ([]repo.SearchHit, error) {
+	if len(q.Text) == 0 {
+		return nil, errors.New("search needs at least one word to look for")
+	}
+	hits, err := u.Repo.Search(ctx, q.Text, q.Filter())
+	if err != nil {
+		return nil, err
+	}
+	out := make([]repo.SearchHit, 0, len(hits))
+	for _, hit := range hits {
+		if !q.Match(hit.Task) {
+			continue
+		}
+		out = append(out, hit)
+		if limit > 0 && len(out) == limit {
+			break
+		}
+	}
+	return out, nil
+}
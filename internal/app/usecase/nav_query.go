@@ -3,7 +3,6 @@ package usecase
 import (
 	"context"
 	"sort"
-	"strings"
 	"time"
 
 	"td/internal/domain"
@@ -12,28 +11,40 @@ import (
 
 type NavQueryUseCase struct {
 	Repo          repo.TaskRepository
+	Workflow      domain.Workflow
 	LogWindowDays int
 }
 
-func NewNavQueryUseCase(repo repo.TaskRepository) NavQueryUseCase {
+func NewNavQueryUseCase(repo repo.TaskRepository, workflow domain.Workflow) NavQueryUseCase {
 	return NavQueryUseCase{
 		Repo:          repo,
+		Workflow:      workflow,
 		LogWindowDays: domain.DefaultLogWindowDays,
 	}
 }
 
 func (u NavQueryUseCase) ListByView(ctx context.Context, view domain.View, now time.Time, project string, includeDone bool) ([]domain.Task, error) {
-	tasks, err := u.Repo.List(ctx, repo.TaskListFilter{})
-	if err != nil {
-		return nil, err
-	}
-
-	out := make([]domain.Task, 0, len(tasks))
-	for _, task := range tasks {
-		if u.matchView(task, view, now, project, includeDone) {
+	filters := u.viewFilters(view, now, project, includeDone)
+	out := make([]domain.Task, 0, 16)
+	seen := make(map[int64]struct{}, 16)
+	for _, filter := range filters {
+		tasks, err := u.Repo.List(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		for _, task := range tasks {
+			if _, ok := seen[task.ID]; ok {
+				continue
+			}
+			seen[task.ID] = struct{}{}
 			out = append(out, task)
 		}
 	}
+	if view == domain.ViewInbox {
+		sort.SliceStable(out, func(i, j int) bool {
+			return out[i].ID < out[j].ID
+		})
+	}
 	if view == domain.ViewToday {
 		sort.SliceStable(out, func(i, j int) bool {
 			left := out[i]
@@ -57,58 +68,58 @@ func (u NavQueryUseCase) ListByView(ctx context.Context, view domain.View, now t
 			return left.ID < right.ID
 		})
 	}
-	if view == domain.ViewLog {
-		sort.SliceStable(out, func(i, j int) bool {
-			left := out[i]
-			right := out[j]
-			if left.DoneAt == nil && right.DoneAt == nil {
-				return left.ID > right.ID
-			}
-			if left.DoneAt == nil {
-				return false
-			}
-			if right.DoneAt == nil {
-				return true
-			}
-			if left.DoneAt.Equal(*right.DoneAt) {
-				return left.ID > right.ID
-			}
-			return left.DoneAt.After(*right.DoneAt)
-		})
-	}
-	if view == domain.ViewTrash {
-		sort.SliceStable(out, func(i, j int) bool {
-			left := out[i]
-			right := out[j]
-			if left.UpdatedAt.Equal(right.UpdatedAt) {
-				return left.ID > right.ID
-			}
-			return left.UpdatedAt.After(right.UpdatedAt)
-		})
-	}
 	return out, nil
 }
 
-func (u NavQueryUseCase) matchView(task domain.Task, view domain.View, now time.Time, project string, includeDone bool) bool {
+func (u NavQueryUseCase) ListByTag(ctx context.Context, tag string, includeDone bool) ([]domain.Task, error) {
+	return u.Repo.List(ctx, repo.TaskListFilter{
+		Tag:      tag,
+		Statuses: projectStatuses(u.Workflow, includeDone),
+	})
+}
+
+// viewFilters translates a nav view into repository filters; views that
+// span several statuses with different predicates use one filter each.
+func (u NavQueryUseCase) viewFilters(view domain.View, now time.Time, project string, includeDone bool) []repo.TaskListFilter {
 	switch view {
 	case domain.ViewInbox:
-		if task.Status == domain.StatusInbox {
-			return true
+		return []repo.TaskListFilter{
+			{Statuses: []domain.Status{domain.StatusInbox}, StartedBy: &now},
+			{Statuses: []domain.Status{domain.StatusTodo}, NoProject: true, StartedBy: &now},
 		}
-		return task.Status == domain.StatusTodo && strings.TrimSpace(task.Project) == ""
 	case domain.ViewToday:
-		return isTodayTask(task, now)
+		dayEnd := startOfDay(now.UTC()).Add(24 * time.Hour)
+		return []repo.TaskListFilter{
+			{Statuses: []domain.Status{domain.StatusDoing}, Unblocked: true, StartedBy: &now, Sort: repo.SortByPriority},
+			{Statuses: TodayDueStatuses(u.Workflow), DueTo: &dayEnd, Unblocked: true, StartedBy: &now, Sort: repo.SortByPriority},
+			{Statuses: []domain.Status{domain.StatusWaiting}, FollowUpTo: &dayEnd, Sort: repo.SortByPriority},
+		}
+	case domain.ViewUpcoming:
+		return []repo.TaskListFilter{
+			{Statuses: projectStatuses(u.Workflow, false), StartFrom: &now, Sort: repo.SortByStart},
+		}
+	case domain.ViewWaiting:
+		return []repo.TaskListFilter{
+			{Statuses: []domain.Status{domain.StatusWaiting}, Sort: repo.SortByFollowUp},
+		}
 	case domain.ViewLog:
-		return isLogTask(task, now, u.logWindowDays())
+		windowStart := now.UTC().Add(-time.Duration(u.logWindowDays()) * 24 * time.Hour)
+		return []repo.TaskListFilter{
+			{Statuses: u.Workflow.ClosedStatuses(), DoneFrom: &windowStart, Sort: repo.SortByDoneDesc},
+		}
 	case domain.ViewProject:
 		if project == "" {
-			return false
+			return nil
+		}
+		return []repo.TaskListFilter{
+			{Project: project, Statuses: projectStatuses(u.Workflow, includeDone), StartedBy: &now},
 		}
-		return task.Project == project && isProjectStatus(task.Status, includeDone)
 	case domain.ViewTrash:
-		return task.Status == domain.StatusDeleted
+		return []repo.TaskListFilter{
+			{Statuses: []domain.Status{domain.StatusDeleted}, Sort: repo.SortByUpdatedDesc},
+		}
 	default:
-		return false
+		return nil
 	}
 }
 
@@ -119,39 +130,28 @@ func (u NavQueryUseCase) logWindowDays() int {
 	return domain.DefaultLogWindowDays
 }
 
-func isTodayTask(task domain.Task, now time.Time) bool {
-	if task.Status != domain.StatusTodo && task.Status != domain.StatusDoing {
-		return false
-	}
-	if task.Status == domain.StatusDoing {
-		return true
-	}
-	if task.DueAt == nil {
-		return false
-	}
-
-	due := task.DueAt.UTC()
-	dayStart := startOfDay(now.UTC())
-	dayEnd := dayStart.Add(24 * time.Hour)
-	if due.Before(dayStart) {
-		return true
-	}
-	return !due.Before(dayStart) && due.Before(dayEnd)
-}
-
-func isLogTask(task domain.Task, now time.Time, windowDays int) bool {
-	if task.Status != domain.StatusDone || task.DoneAt == nil {
-		return false
+// TodayDueStatuses are the open statuses whose tasks show in Today once
+// they are due: all but doing, which shows anyway, waiting, which shows on
+// its follow-up date, and inbox, which is sorted out in its own view.
+func TodayDueStatuses(workflow domain.Workflow) []domain.Status {
+	out := make([]domain.Status, 0, 4)
+	for _, status := range workflow.OpenStatuses() {
+		if status == domain.StatusDoing || status == domain.StatusWaiting || status == domain.StatusInbox {
+			continue
+		}
+		out = append(out, status)
 	}
-	windowStart := now.UTC().Add(-time.Duration(windowDays) * 24 * time.Hour)
-	return !task.DoneAt.UTC().Before(windowStart)
+	return out
 }
 
-func isProjectStatus(status domain.Status, includeDone bool) bool {
-	if status == domain.StatusInbox || status == domain.StatusTodo || status == domain.StatusDoing {
-		return true
+// projectStatuses returns the open statuses of the workflow, and the
+// closed ones too with includeDone.
+func projectStatuses(workflow domain.Workflow, includeDone bool) []domain.Status {
+	statuses := workflow.OpenStatuses()
+	if includeDone {
+		statuses = append(statuses, workflow.ClosedStatuses()...)
 	}
-	return includeDone && status == domain.StatusDone
+	return statuses
 }
 
 func startOfDay(t time.Time) time.Time {
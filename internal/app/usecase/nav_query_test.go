@@ -3,12 +3,14 @@ package usecase
 import (
 	"context"
 	"database/sql"
+	"strings"
 	"testing"
 	"time"
 
 	_ "modernc.org/sqlite"
 
 	"td/internal/domain"
+	taskrepo "td/internal/repo"
 	"td/internal/repo/sqlite"
 )
 
@@ -27,8 +29,8 @@ func TestTodayView(t *testing.T) {
 	seedNavTask(t, db, "inbox-today", domain.StatusInbox, "", ptrTime(now.Add(1*time.Hour)), nil)
 	seedNavTask(t, db, "done-overdue", domain.StatusDone, "", ptrTime(now.Add(-2*time.Hour)), ptrTime(now.Add(-1*time.Hour)))
 
-	repo := sqlite.NewTaskRepository(db)
-	uc := NewNavQueryUseCase(repo)
+	repo := sqlite.NewTaskRepository(db, domain.DefaultWorkflow())
+	uc := NewNavQueryUseCase(repo, domain.DefaultWorkflow())
 
 	tasks, err := uc.ListByView(context.Background(), domain.ViewToday, now, "", false)
 	if err != nil {
@@ -43,6 +45,41 @@ func TestTodayView(t *testing.T) {
 	assertNotContains(t, got, "done-overdue")
 }
 
+func TestTodayViewShouldIncludeDueTasksInCustomOpenStatuses(t *testing.T) {
+	db := openNavTestDB(t)
+	defer db.Close()
+	if err := sqlite.Migrate(db); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	workflow, err := domain.NewWorkflow(domain.WorkflowSpec{
+		Statuses: []domain.Status{"review", "cancelled"},
+		Closed:   []domain.Status{"cancelled"},
+		Transitions: map[domain.Status][]domain.Status{
+			domain.StatusDoing: {"review", "cancelled", domain.StatusDone},
+		},
+	})
+	if err != nil {
+		t.Fatalf("workflow: %v", err)
+	}
+
+	now := time.Date(2026, 2, 23, 10, 0, 0, 0, time.UTC)
+	seedNavTask(t, db, "review-overdue", "review", "work", ptrTime(now.Add(-26*time.Hour)), nil)
+	seedNavTask(t, db, "review-today", "review", "work", ptrTime(now.Add(2*time.Hour)), nil)
+	seedNavTask(t, db, "review-future", "review", "work", ptrTime(now.Add(30*time.Hour)), nil)
+	seedNavTask(t, db, "cancelled-today", "cancelled", "work", ptrTime(now.Add(2*time.Hour)), ptrTime(now))
+
+	uc := NewNavQueryUseCase(sqlite.NewTaskRepository(db, workflow), workflow)
+	tasks, err := uc.ListByView(context.Background(), domain.ViewToday, now, "", false)
+	if err != nil {
+		t.Fatalf("list today: %v", err)
+	}
+	got := titles(tasks)
+	assertContains(t, got, "review-overdue")
+	assertContains(t, got, "review-today")
+	assertNotContains(t, got, "review-future")
+	assertNotContains(t, got, "cancelled-today")
+}
+
 func TestTodayViewShouldSortByPriorityThenDue(t *testing.T) {
 	db := openNavTestDB(t)
 	defer db.Close()
@@ -55,8 +92,8 @@ func TestTodayViewShouldSortByPriorityThenDue(t *testing.T) {
 	seedNavTaskWithPriority(t, db, "task-p1", domain.StatusTodo, "work", "P1", ptrTime(now.Add(6*time.Hour)), nil)
 	seedNavTaskWithPriority(t, db, "task-p2", domain.StatusDoing, "work", "P2", nil, nil)
 
-	repo := sqlite.NewTaskRepository(db)
-	uc := NewNavQueryUseCase(repo)
+	repo := sqlite.NewTaskRepository(db, domain.DefaultWorkflow())
+	uc := NewNavQueryUseCase(repo, domain.DefaultWorkflow())
 
 	tasks, err := uc.ListByView(context.Background(), domain.ViewToday, now, "", false)
 	if err != nil {
@@ -87,8 +124,8 @@ func TestProjectViewShowDoneToggle(t *testing.T) {
 	seedNavTask(t, db, "work-done", domain.StatusDone, "work", nil, ptrTime(now))
 	seedNavTask(t, db, "home-todo", domain.StatusTodo, "home", nil, nil)
 
-	repo := sqlite.NewTaskRepository(db)
-	uc := NewNavQueryUseCase(repo)
+	repo := sqlite.NewTaskRepository(db, domain.DefaultWorkflow())
+	uc := NewNavQueryUseCase(repo, domain.DefaultWorkflow())
 
 	hiddenDone, err := uc.ListByView(context.Background(), domain.ViewProject, now, "work", false)
 	if err != nil {
@@ -123,8 +160,8 @@ func TestInboxViewShouldIncludeTodoWithoutProject(t *testing.T) {
 	seedNavTask(t, db, "todo-with-project", domain.StatusTodo, "work", nil, nil)
 	seedNavTask(t, db, "doing-no-project", domain.StatusDoing, "", nil, nil)
 
-	repo := sqlite.NewTaskRepository(db)
-	uc := NewNavQueryUseCase(repo)
+	repo := sqlite.NewTaskRepository(db, domain.DefaultWorkflow())
+	uc := NewNavQueryUseCase(repo, domain.DefaultWorkflow())
 
 	tasks, err := uc.ListByView(context.Background(), domain.ViewInbox, now, "", false)
 	if err != nil {
@@ -137,6 +174,80 @@ func TestInboxViewShouldIncludeTodoWithoutProject(t *testing.T) {
 	assertNotContains(t, got, "doing-no-project")
 }
 
+func TestDeferredTasksShouldMoveToUpcomingUntilStart(t *testing.T) {
+	db := openNavTestDB(t)
+	defer db.Close()
+	if err := sqlite.Migrate(db); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	now := time.Date(2026, 2, 23, 10, 0, 0, 0, time.UTC)
+	seedNavTask(t, db, "inbox-now", domain.StatusInbox, "", nil, nil)
+	seedNavTask(t, db, "inbox-later", domain.StatusInbox, "", nil, nil)
+	seedNavTask(t, db, "inbox-started", domain.StatusInbox, "", nil, nil)
+	seedNavTask(t, db, "doing-later", domain.StatusDoing, "", nil, nil)
+	seedNavTask(t, db, "work-soon", domain.StatusTodo, "work", nil, nil)
+	seedNavTask(t, db, "done-later", domain.StatusDone, "work", nil, ptrTime(now))
+
+	repo := sqlite.NewTaskRepository(db, domain.DefaultWorkflow())
+	ctx := context.Background()
+	starts := map[string]time.Time{
+		"inbox-later":   now.Add(72 * time.Hour),
+		"inbox-started": now.Add(-time.Hour),
+		"doing-later":   now.Add(48 * time.Hour),
+		"work-soon":     now.Add(2 * time.Hour),
+		"done-later":    now.Add(24 * time.Hour),
+	}
+	all, err := repo.List(ctx, taskrepo.TaskListFilter{})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	for _, task := range all {
+		if start, ok := starts[task.Title]; ok {
+			if err := repo.UpdateStartAt(ctx, task.ID, &start); err != nil {
+				t.Fatalf("defer %q: %v", task.Title, err)
+			}
+		}
+	}
+
+	uc := NewNavQueryUseCase(repo, domain.DefaultWorkflow())
+	inbox, err := uc.ListByView(ctx, domain.ViewInbox, now, "", false)
+	if err != nil {
+		t.Fatalf("list inbox: %v", err)
+	}
+	got := titles(inbox)
+	assertContains(t, got, "inbox-now")
+	assertContains(t, got, "inbox-started")
+	assertNotContains(t, got, "inbox-later")
+
+	today, err := uc.ListByView(ctx, domain.ViewToday, now, "", false)
+	if err != nil {
+		t.Fatalf("list today: %v", err)
+	}
+	assertNotContains(t, titles(today), "doing-later")
+
+	project, err := uc.ListByView(ctx, domain.ViewProject, now, "work", true)
+	if err != nil {
+		t.Fatalf("list project: %v", err)
+	}
+	if len(project) != 0 {
+		t.Fatalf("project view = %v, want deferred tasks hidden", titles(project))
+	}
+	later, err := uc.ListByView(ctx, domain.ViewProject, now.Add(3*time.Hour), "work", false)
+	if err != nil {
+		t.Fatalf("list project later: %v", err)
+	}
+	assertContains(t, titles(later), "work-soon")
+
+	upcoming, err := uc.ListByView(ctx, domain.ViewUpcoming, now, "", false)
+	if err != nil {
+		t.Fatalf("list upcoming: %v", err)
+	}
+	if got, want := strings.Join(titles(upcoming), ","), "work-soon,doing-later,inbox-later"; got != want {
+		t.Fatalf("upcoming = %s, want %s", got, want)
+	}
+}
+
 func TestLogViewShouldSortByLatestDoneAt(t *testing.T) {
 	db := openNavTestDB(t)
 	defer db.Close()
@@ -149,8 +260,8 @@ func TestLogViewShouldSortByLatestDoneAt(t *testing.T) {
 	seedNavTask(t, db, "done-latest", domain.StatusDone, "work", nil, ptrTime(now.Add(-30*time.Minute)))
 	seedNavTask(t, db, "done-middle", domain.StatusDone, "work", nil, ptrTime(now.Add(-90*time.Minute)))
 
-	repo := sqlite.NewTaskRepository(db)
-	uc := NewNavQueryUseCase(repo)
+	repo := sqlite.NewTaskRepository(db, domain.DefaultWorkflow())
+	uc := NewNavQueryUseCase(repo, domain.DefaultWorkflow())
 
 	tasks, err := uc.ListByView(context.Background(), domain.ViewLog, now, "", false)
 	if err != nil {
@@ -185,8 +296,8 @@ func TestTrashViewShouldSortByLatestDeletedAt(t *testing.T) {
 	setNavTaskUpdatedAtByTitle(t, db, "trash-latest", now.Add(-30*time.Minute))
 	setNavTaskUpdatedAtByTitle(t, db, "trash-middle", now.Add(-90*time.Minute))
 
-	repo := sqlite.NewTaskRepository(db)
-	uc := NewNavQueryUseCase(repo)
+	repo := sqlite.NewTaskRepository(db, domain.DefaultWorkflow())
+	uc := NewNavQueryUseCase(repo, domain.DefaultWorkflow())
 
 	tasks, err := uc.ListByView(context.Background(), domain.ViewTrash, now, "", false)
 	if err != nil {
@@ -204,6 +315,42 @@ func TestTrashViewShouldSortByLatestDeletedAt(t *testing.T) {
 	}
 }
 
+func TestTagViewShouldListOpenTasksWithTag(t *testing.T) {
+	db := openNavTestDB(t)
+	defer db.Close()
+	if err := sqlite.Migrate(db); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	repo := sqlite.NewTaskRepository(db, domain.DefaultWorkflow())
+	ctx := context.Background()
+	for _, task := range []domain.Task{
+		{Title: "tagged-todo", Status: domain.StatusTodo, Tags: []string{"work"}},
+		{Title: "tagged-done", Status: domain.StatusDone, Tags: []string{"work"}},
+		{Title: "other-tag", Status: domain.StatusTodo, Tags: []string{"home"}},
+	} {
+		if _, err := repo.Create(ctx, task); err != nil {
+			t.Fatalf("create %q: %v", task.Title, err)
+		}
+	}
+
+	uc := NewNavQueryUseCase(repo, domain.DefaultWorkflow())
+	tasks, err := uc.ListByTag(ctx, "work", false)
+	if err != nil {
+		t.Fatalf("list by tag: %v", err)
+	}
+	got := titles(tasks)
+	assertContains(t, got, "tagged-todo")
+	assertNotContains(t, got, "tagged-done")
+	assertNotContains(t, got, "other-tag")
+
+	tasks, err = uc.ListByTag(ctx, "work", true)
+	if err != nil {
+		t.Fatalf("list by tag with done: %v", err)
+	}
+	assertContains(t, titles(tasks), "tagged-done")
+}
+
 func openNavTestDB(t *testing.T) *sql.DB {
 	t.Helper()
 	db, err := sql.Open("sqlite", ":memory:")
@@ -276,3 +423,61 @@ func assertNotContains(t *testing.T, list []string, target string) {
 		}
 	}
 }
+
+func TestWaitingTasksShouldReturnToTodayOnFollowUp(t *testing.T) {
+	db := openNavTestDB(t)
+	defer db.Close()
+	if err := sqlite.Migrate(db); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	now := time.Date(2026, 2, 23, 10, 0, 0, 0, time.UTC)
+	seedNavTask(t, db, "quote-from-alice", domain.StatusDoing, "home", nil, nil)
+	seedNavTask(t, db, "reply-from-bob", domain.StatusTodo, "work", nil, nil)
+	seedNavTask(t, db, "invoice", domain.StatusTodo, "work", nil, nil)
+
+	repo := sqlite.NewTaskRepository(db, domain.DefaultWorkflow())
+	ctx := context.Background()
+	followUps := map[string]*time.Time{
+		"quote-from-alice": ptrTime(now.Add(-time.Hour)),
+		"reply-from-bob":   ptrTime(now.Add(72 * time.Hour)),
+		"invoice":          nil,
+	}
+	all, err := repo.List(ctx, taskrepo.TaskListFilter{})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	uc := UpdateTaskUseCase{Repo: repo}
+	for _, task := range all {
+		if err := uc.Wait(ctx, task.ID, " someone ", followUps[task.Title]); err != nil {
+			t.Fatalf("wait %q: %v", task.Title, err)
+		}
+	}
+
+	nav := NewNavQueryUseCase(repo, domain.DefaultWorkflow())
+	today, err := nav.ListByView(ctx, domain.ViewToday, now, "", false)
+	if err != nil {
+		t.Fatalf("list today: %v", err)
+	}
+	if got := titles(today); strings.Join(got, ",") != "quote-from-alice" {
+		t.Fatalf("today = %v, want only the due follow-up", got)
+	}
+	if today[0].WaitingOn != "someone" {
+		t.Fatalf("waiting on = %q, want trimmed", today[0].WaitingOn)
+	}
+
+	waiting, err := nav.ListByView(ctx, domain.ViewWaiting, now, "", false)
+	if err != nil {
+		t.Fatalf("list waiting: %v", err)
+	}
+	if got := titles(waiting); strings.Join(got, ",") != "quote-from-alice,reply-from-bob,invoice" {
+		t.Fatalf("waiting = %v, want ordered by follow-up", got)
+	}
+	project, err := nav.ListByView(ctx, domain.ViewProject, now, "work", false)
+	if err != nil {
+		t.Fatalf("list project: %v", err)
+	}
+	if len(project) != 2 {
+		t.Fatalf("project view = %v, want waiting tasks kept", titles(project))
+	}
+}
@@ -57,6 +57,10 @@ func (s *projectRepoStub) Create(context.Context, domain.Task) (int64, error) {
 	return 0, nil
 }
 
+func (s *projectRepoStub) CreateMany(context.Context, []domain.Task) ([]int64, error) {
+	return nil, nil
+}
+
 func (s *projectRepoStub) GetByID(context.Context, int64) (domain.Task, error) {
 	return domain.Task{}, nil
 }
@@ -65,6 +69,14 @@ func (s *projectRepoStub) List(context.Context, repo.TaskListFilter) ([]domain.T
 	return nil, nil
 }
 
+func (s *projectRepoStub) Upsert(context.Context, []domain.Task) ([]int64, error) {
+	return nil, nil
+}
+
+func (s *projectRepoStub) Count(context.Context, repo.TaskListFilter) (int, error) {
+	return 0, nil
+}
+
 func (s *projectRepoStub) CreateProject(_ context.Context, name string) error {
 	s.created = name
 	return nil
@@ -94,11 +106,52 @@ func (s *projectRepoStub) UpdateProject(context.Context, int64, string) error {
 func (s *projectRepoStub) UpdateDueAt(context.Context, int64, *time.Time) error {
 	return nil
 }
+func (s *projectRepoStub) UpdateStartAt(context.Context, int64, *time.Time) error {
+	return nil
+}
+func (s *projectRepoStub) Snooze(context.Context, map[int64]time.Time) error {
+	return nil
+}
+func (s *projectRepoStub) Transit(context.Context, []int64, domain.Status) error {
+	return nil
+}
+func (s *projectRepoStub) UpdateRecurrence(context.Context, int64, domain.Recurrence) error {
+	return nil
+}
 func (s *projectRepoStub) UpdatePriority(context.Context, int64, string) error   { return nil }
 func (s *projectRepoStub) SetStatus(context.Context, int64, domain.Status) error { return nil }
+func (s *projectRepoStub) Wait(context.Context, int64, string, *time.Time) error { return nil }
 func (s *projectRepoStub) MarkDone(context.Context, []int64) error               { return nil }
+func (s *projectRepoStub) MarkDoneWithSubtasks(context.Context, []int64) error   { return nil }
 func (s *projectRepoStub) MarkDoing(context.Context, []int64) error              { return nil }
 func (s *projectRepoStub) Reopen(context.Context, []int64) error                 { return nil }
 func (s *projectRepoStub) SoftDelete(context.Context, []int64) error             { return nil }
 func (s *projectRepoStub) Restore(context.Context, []int64) error                { return nil }
 func (s *projectRepoStub) Purge(context.Context, []int64) error                  { return nil }
+func (s *projectRepoStub) AddTags(context.Context, int64, []string) error        { return nil }
+func (s *projectRepoStub) RemoveTags(context.Context, int64, []string) error     { return nil }
+func (s *projectRepoStub) ListTags(context.Context) ([]string, error)            { return nil, nil }
+func (s *projectRepoStub) RenameTag(context.Context, string, string) error       { return nil }
+func (s *projectRepoStub) MergeTags(context.Context, []string, string) error     { return nil }
+func (s *projectRepoStub) AddDependencies(context.Context, int64, []int64) error {
+	return nil
+}
+func (s *projectRepoStub) RemoveDependencies(context.Context, int64, []int64) error {
+	return nil
+}
+
+func (s *projectRepoStub) Undo(context.Context) (domain.Operation, error) {
+	return domain.Operation{}, domain.ErrNothingToUndo
+}
+
+func (s *projectRepoStub) Redo(context.Context) (domain.Operation, error) {
+	return domain.Operation{}, domain.ErrNothingToRedo
+}
+
+func (s *projectRepoStub) ListOperations(context.Context, int) ([]domain.Operation, error) {
+	return nil, nil
+}
+
+func (s *projectRepoStub) Search(context.Context, []string, repo.TaskListFilter) ([]repo.SearchHit, error) {
+	return nil, nil
+}
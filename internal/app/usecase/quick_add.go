@@ -0,0 +1,99 @@
+package usecase
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"td/internal/domain"
+	"td/internal/timeutil"
+)
+
+// maxDueTokenWords bounds how many words after ^ are tried as one due
+// phrase, as in "^next monday 9am".
+const maxDueTokenWords = 4
+
+// ParseQuickAdd strips the inline tokens out of a new task's text:
+//
+//	+work or #work   project
+//	!1 .. !4         priority P1 .. P4
+//	@phone           tag, repeatable
+//	^tomorrow 17:00  due, the longest run of words that parses
+//
+// Words that only look like tokens, such as "#123", "!5" or "a@b.com", are
+// kept in the title. When several project or priority tokens are given the
+// last one wins.
+func ParseQuickAdd(text string, clk timeutil.Clock, loc *time.Location) (AddTaskInput, error) {
+	var (
+		in    AddTaskInput
+		title []string
+	)
+	words := strings.Fields(text)
+	for i := 0; i < len(words); i++ {
+		word := words[i]
+		switch {
+		case (word[0] == '+' || word[0] == '#') && isTokenName(word[1:]):
+			in.Project = word[1:]
+		case word[0] == '@' && isTokenName(word[1:]):
+			in.Tags = append(in.Tags, domain.NormalizeTag(word))
+		case len(word) == 2 && word[0] == '!' && word[1] >= '1' && word[1] <= '4':
+			in.Priority = "P" + word[1:]
+		case word[0] == '^' && len(word) > 1:
+			dueAt, n, err := parseDueToken(words[i:], clk, loc)
+			if err != nil {
+				return AddTaskInput{}, err
+			}
+			in.DueAt = &dueAt
+			i += n - 1
+		default:
+			title = append(title, word)
+		}
+	}
+	in.Title = strings.Join(title, " ")
+	if in.Title == "" {
+		return AddTaskInput{}, fmt.Errorf("title is empty after removing tokens from %q", strings.TrimSpace(text))
+	}
+	return in, nil
+}
+
+// parseDueToken parses the due token at the head of words and reports how
+// many words it used.
+func parseDueToken(words []string, clk timeutil.Clock, loc *time.Location) (time.Time, int, error) {
+	end := 1
+	for end < len(words) && end < maxDueTokenWords && !isTokenStart(words[end]) {
+		end++
+	}
+	var lastErr error
+	for ; end > 0; end-- {
+		phrase := strings.TrimPrefix(strings.Join(words[:end], " "), "^")
+		dueAt, err := timeutil.ParseDue(phrase, clk, loc)
+		if err == nil {
+			return dueAt, end, nil
+		}
+		lastErr = err
+	}
+	return time.Time{}, 0, lastErr
+}
+
+func isTokenStart(word string) bool {
+	switch word[0] {
+	case '+', '#', '@', '!', '^':
+		return true
+	}
+	return false
+}
+
+// isTokenName accepts names that are not just a number, so "#123" and "+1"
+// stay in the title.
+func isTokenName(name string) bool {
+	if name == "" || !domain.IsValidTag(name) {
+		return false
+	}
+	for _, r := range name {
+		if !unicode.IsDigit(r) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,50 @@
+package usecase
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"td/internal/timeutil"
+)
+
+func TestParseQuickAddShouldStripTokens(t *testing.T) {
+	loc := time.FixedZone("CST", 8*3600)
+	clk := timeutil.ClockFunc(func() time.Time { return time.Date(2026, 3, 4, 10, 0, 0, 0, loc) })
+
+	cases := []struct {
+		text     string
+		title    string
+		project  string
+		priority string
+		tags     string
+		due      string
+	}{
+		{text: "buy milk", title: "buy milk"},
+		{text: "write report +work !1 @deep", title: "write report", project: "work", priority: "P1", tags: "deep"},
+		{text: "#home fix sink !4 !2", title: "fix sink", project: "home", priority: "P2"},
+		{text: "call bob ^tomorrow 17:00 @phone", title: "call bob", tags: "phone", due: "2026-03-05 17:00"},
+		{text: "ship ^next monday 9am now", title: "ship now", due: "2026-03-09 09:00"},
+		{text: "交周报 ^明天下午3点", title: "交周报", due: "2026-03-05 15:00"},
+		{text: "see #123 and !5, mail a@b.com +1", title: "see #123 and !5, mail a@b.com +1"},
+	}
+	for _, tc := range cases {
+		in, err := ParseQuickAdd(tc.text, clk, loc)
+		if err != nil {
+			t.Fatalf("ParseQuickAdd(%q): %v", tc.text, err)
+		}
+		due := ""
+		if in.DueAt != nil {
+			due = in.DueAt.In(loc).Format("2006-01-02 15:04")
+		}
+		if in.Title != tc.title || in.Project != tc.project || in.Priority != tc.priority || strings.Join(in.Tags, ",") != tc.tags || due != tc.due {
+			t.Fatalf("ParseQuickAdd(%q) = %+v (due %q)", tc.text, in, due)
+		}
+	}
+
+	for _, text := range []string{"+work !1", "meet ^someday"} {
+		if _, err := ParseQuickAdd(text, clk, loc); err == nil {
+			t.Fatalf("ParseQuickAdd(%q) should fail", text)
+		}
+	}
+}
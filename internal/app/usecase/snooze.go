@@ -0,0 +1,106 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"td/internal/domain"
+	"td/internal/timeutil"
+)
+
+// DefaultSnooze is what td snooze does without a rule.
+const DefaultSnooze = "1d"
+
+var snoozeOffsetRegexp = regexp.MustCompile(`^\+?(\d+)\s*(h|d|w)$`)
+
+// Snooze says how far to push due times: by an offset from each task's
+// due time, or to one fixed time.
+type Snooze struct {
+	Hours int
+	Days  int
+	Until *time.Time
+}
+
+// ParseSnooze reads "2h", "1d", "2w" as offsets and anything else as a
+// due datetime such as "next week" or "2026-03-10".
+func ParseSnooze(raw string, clk timeutil.Clock, loc *time.Location) (Snooze, error) {
+	text := strings.ToLower(strings.TrimSpace(raw))
+	if text == "" {
+		text = DefaultSnooze
+	}
+	if m := snoozeOffsetRegexp.FindStringSubmatch(text); m != nil {
+		n, err := strconv.Atoi(m[1])
+		if err != nil || n == 0 {
+			return Snooze{}, fmt.Errorf("invalid snooze %q", raw)
+		}
+		switch m[2] {
+		case "h":
+			return Snooze{Hours: n}, nil
+		case "w":
+			return Snooze{Days: 7 * n}, nil
+		default:
+			return Snooze{Days: n}, nil
+		}
+	}
+	until, err := timeutil.ParseDue(raw, clk, loc)
+	if err != nil {
+		return Snooze{}, fmt.Errorf("invalid snooze %q, expect 2h, 1d, 2w or a due datetime", raw)
+	}
+	return Snooze{Until: &until}, nil
+}
+
+// Next returns the new due time for a task due at dueAt. Offsets count
+// from the due time, or for overdue tasks from the same time today, so
+// snoozing never leaves a task in the past; a task without a due time
+// starts from the end of today.
+func (s Snooze) Next(dueAt *time.Time, now time.Time) time.Time {
+	if s.Until != nil {
+		return *s.Until
+	}
+	loc := now.Location()
+	var base time.Time
+	if dueAt == nil {
+		base = time.Date(now.Year(), now.Month(), now.Day(), 23, 59, 0, 0, loc)
+	} else {
+		base = dueAt.In(loc)
+	}
+	if s.Hours > 0 {
+		if base.Before(now) {
+			base = now
+		}
+		return base.Add(time.Duration(s.Hours) * time.Hour).UTC()
+	}
+	if base.Before(now) {
+		base = time.Date(now.Year(), now.Month(), now.Day(), base.Hour(), base.Minute(), 0, 0, loc)
+	}
+	return base.AddDate(0, 0, s.Days).UTC()
+}
+
+// Snooze pushes the due time of ids back and returns the tasks as they are
+// afterwards.
+func (u UpdateTaskUseCase) Snooze(ctx context.Context, ids []int64, snooze Snooze, now time.Time) ([]domain.Task, error) {
+	dueAts := make(map[int64]time.Time, len(ids))
+	for _, id := range ids {
+		task, err := u.Repo.GetByID(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("#%d: %w", id, err)
+		}
+		dueAts[id] = snooze.Next(task.DueAt, now)
+	}
+	if err := u.Repo.Snooze(ctx, dueAts); err != nil {
+		return nil, err
+	}
+	tasks := make([]domain.Task, 0, len(ids))
+	for _, id := range ids {
+		task, err := u.Repo.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, nil
+}
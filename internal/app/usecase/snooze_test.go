@@ -0,0 +1,46 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"td/internal/timeutil"
+)
+
+func TestSnoozeShouldPushDueFromDueOrNow(t *testing.T) {
+	loc := time.FixedZone("CST", 8*3600)
+	now := time.Date(2026, 3, 4, 10, 0, 0, 0, loc)
+	clk := timeutil.ClockFunc(func() time.Time { return now })
+	at := func(day, hour, minute int) *time.Time {
+		v := time.Date(2026, 3, day, hour, minute, 0, 0, loc)
+		return &v
+	}
+
+	cases := []struct {
+		raw   string
+		dueAt *time.Time
+		want  string
+	}{
+		{raw: "", dueAt: at(6, 9, 0), want: "2026-03-07 09:00"},
+		{raw: "2w", dueAt: at(6, 9, 0), want: "2026-03-20 09:00"},
+		{raw: "1d", dueAt: at(1, 18, 0), want: "2026-03-05 18:00"},
+		{raw: "2h", dueAt: at(1, 18, 0), want: "2026-03-04 12:00"},
+		{raw: "+3h", dueAt: at(4, 17, 0), want: "2026-03-04 20:00"},
+		{raw: "1d", dueAt: nil, want: "2026-03-05 23:59"},
+		{raw: "next monday", dueAt: at(6, 9, 0), want: "2026-03-09 23:59"},
+	}
+	for _, tc := range cases {
+		s, err := ParseSnooze(tc.raw, clk, loc)
+		if err != nil {
+			t.Fatalf("ParseSnooze(%q): %v", tc.raw, err)
+		}
+		if got := s.Next(tc.dueAt, now).In(loc).Format("2006-01-02 15:04"); got != tc.want {
+			t.Fatalf("snooze %q from %v = %s, want %s", tc.raw, tc.dueAt, got, tc.want)
+		}
+	}
+	for _, raw := range []string{"0d", "someday"} {
+		if _, err := ParseSnooze(raw, clk, loc); err == nil {
+			t.Fatalf("ParseSnooze(%q) should fail", raw)
+		}
+	}
+}
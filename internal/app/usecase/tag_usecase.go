@@ -0,0 +1,23 @@
+package usecase
+
+import (
+	"context"
+
+	"td/internal/repo"
+)
+
+type TagUseCase struct {
+	Repo repo.TaskRepository
+}
+
+func (u TagUseCase) List(ctx context.Context) ([]string, error) {
+	return u.Repo.ListTags(ctx)
+}
+
+func (u TagUseCase) Rename(ctx context.Context, oldName, newName string) error {
+	return u.Repo.RenameTag(ctx, oldName, newName)
+}
+
+func (u TagUseCase) Merge(ctx context.Context, sources []string, target string) error {
+	return u.Repo.MergeTags(ctx, sources, target)
+}
@@ -2,6 +2,8 @@ package usecase
 
 import (
 	"context"
+	"sort"
+	"strings"
 	"time"
 
 	"td/internal/domain"
@@ -9,11 +11,85 @@ import (
 )
 
 type UpdateTaskUseCase struct {
-	Repo repo.TaskRepository
+	Repo     repo.TaskRepository
+	Workflow domain.Workflow
 }
 
-func (u UpdateTaskUseCase) MarkDone(ctx context.Context, ids []int64) error {
-	return u.Repo.MarkDone(ctx, ids)
+// MarkDone completes ids and returns the tasks that no longer wait on
+// anything because of it.
+func (u UpdateTaskUseCase) MarkDone(ctx context.Context, ids []int64) ([]domain.Task, error) {
+	return u.completeAndUnblock(ctx, ids, u.Repo.MarkDone)
+}
+
+func (u UpdateTaskUseCase) MarkDoneWithSubtasks(ctx context.Context, ids []int64) ([]domain.Task, error) {
+	return u.completeAndUnblock(ctx, ids, u.Repo.MarkDoneWithSubtasks)
+}
+
+func (u UpdateTaskUseCase) completeAndUnblock(ctx context.Context, ids []int64, complete func(context.Context, []int64) error) ([]domain.Task, error) {
+	waiting := make(map[int64]bool)
+	for _, id := range ids {
+		dependents, err := u.Repo.List(ctx, repo.TaskListFilter{BlockerID: id})
+		if err != nil {
+			return nil, err
+		}
+		for _, task := range dependents {
+			waiting[task.ID] = true
+		}
+	}
+	if err := complete(ctx, ids); err != nil {
+		return nil, err
+	}
+	if len(waiting) == 0 {
+		return nil, nil
+	}
+	unblocked := make([]domain.Task, 0, len(waiting))
+	for id := range waiting {
+		task, err := u.Repo.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if !task.IsBlocked() && u.Workflow.IsOpen(task.Status) {
+			unblocked = append(unblocked, task)
+		}
+	}
+	sort.Slice(unblocked, func(i, j int) bool { return unblocked[i].ID < unblocked[j].ID })
+	return unblocked, nil
+}
+
+func (u UpdateTaskUseCase) Block(ctx context.Context, id int64, blockerIDs []int64) error {
+	return u.Repo.AddDependencies(ctx, id, blockerIDs)
+}
+
+func (u UpdateTaskUseCase) Unblock(ctx context.Context, id int64, blockerIDs []int64) error {
+	return u.Repo.RemoveDependencies(ctx, id, blockerIDs)
+}
+
+// OpenSubtasks returns the descendants of id that are still open.
+func (u UpdateTaskUseCase) OpenSubtasks(ctx context.Context, id int64) ([]domain.Task, error) {
+	out := make([]domain.Task, 0, 4)
+	seen := map[int64]bool{id: true}
+	queue := []int64{id}
+	for len(queue) > 0 {
+		children, err := u.Repo.List(ctx, repo.TaskListFilter{
+			ParentID: queue[0],
+			Statuses: projectStatuses(u.Workflow, true),
+		})
+		if err != nil {
+			return nil, err
+		}
+		queue = queue[1:]
+		for _, child := range children {
+			if seen[child.ID] {
+				continue
+			}
+			seen[child.ID] = true
+			queue = append(queue, child.ID)
+			if u.Workflow.IsOpen(child.Status) {
+				out = append(out, child)
+			}
+		}
+	}
+	return out, nil
 }
 
 func (u UpdateTaskUseCase) Reopen(ctx context.Context, ids []int64) error {
@@ -32,38 +108,78 @@ func (u UpdateTaskUseCase) SetDueAt(ctx context.Context, id int64, dueAt *time.T
 	return u.Repo.UpdateDueAt(ctx, id, dueAt)
 }
 
+func (u UpdateTaskUseCase) SetStartAt(ctx context.Context, id int64, startAt *time.Time) error {
+	return u.Repo.UpdateStartAt(ctx, id, startAt)
+}
+
+func (u UpdateTaskUseCase) SetRecurrence(ctx context.Context, id int64, recurrence domain.Recurrence) error {
+	return u.Repo.UpdateRecurrence(ctx, id, recurrence)
+}
+
 func (u UpdateTaskUseCase) SetPriority(ctx context.Context, id int64, priority string) error {
 	return u.Repo.UpdatePriority(ctx, id, priority)
 }
 
+func (u UpdateTaskUseCase) AddTags(ctx context.Context, id int64, tags []string) error {
+	return u.Repo.AddTags(ctx, id, tags)
+}
+
+func (u UpdateTaskUseCase) RemoveTags(ctx context.Context, id int64, tags []string) error {
+	return u.Repo.RemoveTags(ctx, id, tags)
+}
+
 func (u UpdateTaskUseCase) SetStatus(ctx context.Context, id int64, status domain.Status) error {
 	return u.Repo.SetStatus(ctx, id, status)
 }
 
+// Wait parks the task until on gets back; followUpAt, when set, brings it
+// back to Today.
+func (u UpdateTaskUseCase) Wait(ctx context.Context, id int64, on string, followUpAt *time.Time) error {
+	return u.Repo.Wait(ctx, id, strings.TrimSpace(on), followUpAt)
+}
+
+// Transit moves ids to any status the workflow allows.
+func (u UpdateTaskUseCase) Transit(ctx context.Context, ids []int64, status domain.Status) error {
+	return u.Repo.Transit(ctx, ids, status)
+}
+
 func (u UpdateTaskUseCase) MarkToday(ctx context.Context, ids []int64) error {
 	return u.Repo.MarkDoing(ctx, ids)
 }
 
 func (u UpdateTaskUseCase) MarkProjectDone(ctx context.Context, project string) (int, error) {
-	tasks, err := u.Repo.List(ctx, repo.TaskListFilter{Project: project})
+	tasks, err := u.Repo.List(ctx, repo.TaskListFilter{
+		Project:  project,
+		Statuses: projectStatuses(u.Workflow, false),
+	})
 	if err != nil {
 		return 0, err
 	}
 	ids := make([]int64, 0, len(tasks))
 	for _, task := range tasks {
-		if task.Status == domain.StatusInbox || task.Status == domain.StatusTodo || task.Status == domain.StatusDoing {
-			ids = append(ids, task.ID)
-		}
+		ids = append(ids, task.ID)
 	}
 	if len(ids) == 0 {
 		return 0, nil
 	}
-	if err := u.Repo.MarkDone(ctx, ids); err != nil {
+	if _, err := u.MarkDone(ctx, ids); err != nil {
 		return 0, err
 	}
 	return len(ids), nil
 }
 
+func (u UpdateTaskUseCase) Undo(ctx context.Context) (domain.Operation, error) {
+	return u.Repo.Undo(ctx)
+}
+
+func (u UpdateTaskUseCase) Redo(ctx context.Context) (domain.Operation, error) {
+	return u.Repo.Redo(ctx)
+}
+
+func (u UpdateTaskUseCase) Operations(ctx context.Context, limit int) ([]domain.Operation, error) {
+	return u.Repo.ListOperations(ctx, limit)
+}
+
 func (u UpdateTaskUseCase) Remove(ctx context.Context, ids []int64) error {
 	return u.Repo.SoftDelete(ctx, ids)
 }
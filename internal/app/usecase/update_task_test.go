@@ -98,6 +98,24 @@ func TestUpdateTaskUseCaseMarkProjectDone(t *testing.T) {
 	}
 }
 
+func TestUpdateTaskUseCaseAddAndRemoveTags(t *testing.T) {
+	stub := &updateTaskRepoStub{}
+	uc := UpdateTaskUseCase{Repo: stub}
+
+	if err := uc.AddTags(context.Background(), 3, []string{"work", "urgent"}); err != nil {
+		t.Fatalf("add tags: %v", err)
+	}
+	if stub.tagID != 3 || len(stub.addedTags) != 2 {
+		t.Fatalf("add tags = (id=%d, tags=%v), want (3, [work urgent])", stub.tagID, stub.addedTags)
+	}
+	if err := uc.RemoveTags(context.Background(), 3, []string{"urgent"}); err != nil {
+		t.Fatalf("remove tags: %v", err)
+	}
+	if len(stub.removedTags) != 1 || stub.removedTags[0] != "urgent" {
+		t.Fatalf("removed tags = %v, want [urgent]", stub.removedTags)
+	}
+}
+
 type updateTaskRepoStub struct {
 	projectID    int64
 	project      string
@@ -109,6 +127,9 @@ type updateTaskRepoStub struct {
 	status       domain.Status
 	markDoingIDs []int64
 	markDoneIDs  []int64
+	tagID        int64
+	addedTags    []string
+	removedTags  []string
 	tasks        []domain.Task
 }
 
@@ -116,6 +137,10 @@ func (s *updateTaskRepoStub) Create(context.Context, domain.Task) (int64, error)
 	return 0, nil
 }
 
+func (s *updateTaskRepoStub) CreateMany(context.Context, []domain.Task) ([]int64, error) {
+	return nil, nil
+}
+
 func (s *updateTaskRepoStub) GetByID(context.Context, int64) (domain.Task, error) {
 	return domain.Task{}, nil
 }
@@ -123,14 +148,22 @@ func (s *updateTaskRepoStub) GetByID(context.Context, int64) (domain.Task, error
 func (s *updateTaskRepoStub) List(_ context.Context, filter repo.TaskListFilter) ([]domain.Task, error) {
 	out := make([]domain.Task, 0, len(s.tasks))
 	for _, task := range s.tasks {
-		if filter.Project != "" && task.Project != filter.Project {
-			continue
+		if filter.Match(task) {
+			out = append(out, task)
 		}
-		out = append(out, task)
 	}
 	return out, nil
 }
 
+func (s *updateTaskRepoStub) Upsert(context.Context, []domain.Task) ([]int64, error) {
+	return nil, nil
+}
+
+func (s *updateTaskRepoStub) Count(ctx context.Context, filter repo.TaskListFilter) (int, error) {
+	tasks, err := s.List(ctx, filter)
+	return len(tasks), err
+}
+
 func (s *updateTaskRepoStub) CreateProject(context.Context, string) error {
 	return nil
 }
@@ -163,12 +196,32 @@ func (s *updateTaskRepoStub) UpdateDueAt(_ context.Context, id int64, dueAt *tim
 	return nil
 }
 
+func (s *updateTaskRepoStub) UpdateStartAt(context.Context, int64, *time.Time) error {
+	return nil
+}
+
+func (s *updateTaskRepoStub) Snooze(context.Context, map[int64]time.Time) error {
+	return nil
+}
+
+func (s *updateTaskRepoStub) Transit(context.Context, []int64, domain.Status) error {
+	return nil
+}
+
 func (s *updateTaskRepoStub) UpdatePriority(_ context.Context, id int64, priority string) error {
 	s.priorityID = id
 	s.priority = priority
 	return nil
 }
 
+func (s *updateTaskRepoStub) UpdateRecurrence(context.Context, int64, domain.Recurrence) error {
+	return nil
+}
+
+func (s *updateTaskRepoStub) Wait(context.Context, int64, string, *time.Time) error {
+	return nil
+}
+
 func (s *updateTaskRepoStub) SetStatus(_ context.Context, id int64, status domain.Status) error {
 	s.statusID = id
 	s.status = status
@@ -180,6 +233,11 @@ func (s *updateTaskRepoStub) MarkDone(_ context.Context, ids []int64) error {
 	return nil
 }
 
+func (s *updateTaskRepoStub) MarkDoneWithSubtasks(_ context.Context, ids []int64) error {
+	s.markDoneIDs = append([]int64(nil), ids...)
+	return nil
+}
+
 func (s *updateTaskRepoStub) MarkDoing(_ context.Context, ids []int64) error {
 	s.markDoingIDs = append([]int64(nil), ids...)
 	return nil
@@ -200,3 +258,51 @@ func (s *updateTaskRepoStub) Restore(context.Context, []int64) error {
 func (s *updateTaskRepoStub) Purge(context.Context, []int64) error {
 	return nil
 }
+
+func (s *updateTaskRepoStub) AddTags(_ context.Context, id int64, tags []string) error {
+	s.tagID = id
+	s.addedTags = append([]string(nil), tags...)
+	return nil
+}
+
+func (s *updateTaskRepoStub) RemoveTags(_ context.Context, id int64, tags []string) error {
+	s.tagID = id
+	s.removedTags = append([]string(nil), tags...)
+	return nil
+}
+
+func (s *updateTaskRepoStub) ListTags(context.Context) ([]string, error) {
+	return nil, nil
+}
+
+func (s *updateTaskRepoStub) RenameTag(context.Context, string, string) error {
+	return nil
+}
+
+func (s *updateTaskRepoStub) MergeTags(context.Context, []string, string) error {
+	return nil
+}
+
+func (s *updateTaskRepoStub) AddDependencies(context.Context, int64, []int64) error {
+	return nil
+}
+
+func (s *updateTaskRepoStub) RemoveDependencies(context.Context, int64, []int64) error {
+	return nil
+}
+
+func (s *updateTaskRepoStub) Undo(context.Context) (domain.Operation, error) {
+	return domain.Operation{}, domain.ErrNothingToUndo
+}
+
+func (s *updateTaskRepoStub) Redo(context.Context) (domain.Operation, error) {
+	return domain.Operation{}, domain.ErrNothingToRedo
+}
+
+func (s *updateTaskRepoStub) ListOperations(context.Context, int) ([]domain.Operation, error) {
+	return nil, nil
+}
+
+func (s *updateTaskRepoStub) Search(context.Context, []string, repo.TaskListFilter) ([]repo.SearchHit, error) {
+	return nil, nil
+}
@@ -1,27 +1,50 @@
 package cli
 
 import (
+	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
 
 	"td/internal/app/usecase"
+	"td/internal/clipboard"
 	"td/internal/config"
+	"td/internal/domain"
+	"td/internal/repo"
 )
 
 func newAddCmd(cfg config.Config) *cobra.Command {
 	var (
-		project  string
-		priority string
-		fromClip bool
-		useAI    bool
-		dueRaw   string
+		project   string
+		priority  string
+		fromClip  bool
+		useAI     bool
+		dueRaw    string
+		tags      []string
+		every     string
+		afterDone bool
+		parentID  int64
+		raw       bool
+		multi     bool
+		yes       bool
 	)
 
 	cmd := &cobra.Command{
 		Use:   "add <text>",
 		Short: "Add a task",
+		Long: `Add a task. Inline tokens in the text set its fields:
+
+  td add 'write report +work !1 @deep ^friday 17:00'
+
++work or #work sets the project, !1..!4 the priority, @tag adds a tag and
+^<datetime> the due date. Flags win over tokens; --raw keeps the text as is.
+
+--clip --multi turns text with several todos, such as meeting notes, into
+one task each. The tasks are listed first; answer y to create all, or the
+numbers of the ones to keep, such as 1,3. --yes creates them all without
+asking.`,
 		Args: func(cmd *cobra.Command, args []string) error {
 			if fromClip {
 				return nil
@@ -29,6 +52,16 @@ func newAddCmd(cfg config.Config) *cobra.Command {
 			return cobra.MinimumNArgs(1)(cmd, args)
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
+			recurrence, err := parseEveryInput(every, afterDone)
+			if err != nil {
+				return err
+			}
+			if fromClip && parentID != 0 {
+				return fmt.Errorf("--parent cannot be combined with --clip")
+			}
+			if multi && !fromClip {
+				return fmt.Errorf("--multi requires --clip")
+			}
 			repo, closer, err := openTaskRepo(cfg)
 			if err != nil {
 				return err
@@ -37,7 +70,7 @@ func newAddCmd(cfg config.Config) *cobra.Command {
 
 			var dueAt *time.Time
 			if strings.TrimSpace(dueRaw) != "" {
-				parsed, err := parseDueInput(dueRaw, time.Local)
+				parsed, err := parseDueInput(cfg, dueRaw, time.Local)
 				if err != nil {
 					return err
 				}
@@ -49,16 +82,22 @@ func newAddCmd(cfg config.Config) *cobra.Command {
 			if fromClip {
 				var aiParser *usecase.AIParseTaskUseCase
 				if useAI {
-					aiParser = newAIParseTaskUseCase(cfg)
+					aiParser = newAIParseTaskUseCase(cfg, newAICacheStore(cfg, repo))
 				}
 				uc := usecase.AddFromClipboardUseCase{
-					Repo:     repo,
-					AIParser: aiParser,
-					Project:  project,
-					Priority: priority,
-					DueAt:    dueAt,
+					Repo:       repo,
+					AIParser:   aiParser,
+					Project:    project,
+					Priority:   priority,
+					DueAt:      dueAt,
+					Tags:       tags,
+					Recurrence: recurrence,
+					Clock:      cfg.Clock,
 				}
 				clipText := strings.Join(args, " ")
+				if multi {
+					return addManyFromClipboard(cmd, uc, clipText, useAI, yes)
+				}
 				task, err := uc.AddFromClipboard(cmd.Context(), clipText, useAI)
 				if err != nil {
 					return err
@@ -66,13 +105,29 @@ func newAddCmd(cfg config.Config) *cobra.Command {
 				taskID = task.ID
 				taskTitle = task.Title
 			} else {
+				in := usecase.AddTaskInput{Title: strings.Join(args, " ")}
+				if !raw {
+					in, err = usecase.ParseQuickAdd(in.Title, cfg.Clock, time.Local)
+					if err != nil {
+						return err
+					}
+				}
+				flags := cmd.Flags()
+				if flags.Changed("project") || in.Project == "" {
+					in.Project = project
+				}
+				if flags.Changed("priority") || in.Priority == "" {
+					in.Priority = priority
+				}
+				if dueAt != nil {
+					in.DueAt = dueAt
+				}
+				in.Tags = append(in.Tags, tags...)
+				in.Recurrence = recurrence
+				in.ParentID = parentID
+
 				uc := usecase.AddTaskUseCase{Repo: repo}
-				task, err := uc.Execute(cmd.Context(), usecase.AddTaskInput{
-					Title:    strings.Join(args, " "),
-					Project:  project,
-					Priority: priority,
-					DueAt:    dueAt,
-				})
+				task, err := uc.Execute(cmd.Context(), in)
 				if err != nil {
 					return err
 				}
@@ -87,8 +142,93 @@ func newAddCmd(cfg config.Config) *cobra.Command {
 
 	cmd.Flags().StringVarP(&project, "project", "p", "", "project")
 	cmd.Flags().StringVarP(&priority, "priority", "P", "P2", "priority")
-	cmd.Flags().StringVar(&dueRaw, "due", "", "due datetime, supports YYYY-MM-DD or YYYY-MM-DD HH:MM")
+	cmd.Flags().StringVar(&dueRaw, "due", "", `due datetime, e.g. "2026-03-01 09:30", "tomorrow 9am", "next monday" or "明天下午3点"`)
+	cmd.Flags().StringVar(&every, "every", "", `repeat rule, e.g. "weekday", "2w", "mon,thu" or "FREQ=MONTHLY"`)
+	cmd.Flags().BoolVar(&afterDone, "after-completion", false, "schedule the next occurrence from the completion day (with --every)")
+	cmd.Flags().Int64Var(&parentID, "parent", 0, "create as a subtask of this task id")
+	cmd.Flags().StringArrayVarP(&tags, "tag", "t", nil, "tag, repeatable")
+	cmd.Flags().BoolVar(&raw, "raw", false, "keep +project, !priority, @tag and ^due tokens in the title")
 	cmd.Flags().BoolVar(&fromClip, "clip", false, "create from clipboard")
 	cmd.Flags().BoolVar(&useAI, "ai", false, "parse clipboard with AI and fallback to rules")
+	cmd.Flags().BoolVar(&multi, "multi", false, "create one task per todo in the clipboard text (with --clip)")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "create the tasks found by --multi without asking")
 	return cmd
 }
+
+// addManyFromClipboard lists the tasks found in text and creates the ones
+// confirmed, as one operation for undo.
+func addManyFromClipboard(cmd *cobra.Command, uc usecase.AddFromClipboardUseCase, text string, useAI, yes bool) error {
+	ctx := cmd.Context()
+	parsed, source, err := uc.ParseInputs(ctx, text, useAI)
+	if err != nil {
+		return err
+	}
+	for i, item := range parsed {
+		draft, err := uc.Draft(item)
+		if err != nil {
+			return err
+		}
+		cmd.Println(formatTaskLine(int64(i+1), string(draft.Status), draft.Title, draft.Project, draft.DueAt, draft.Priority))
+	}
+	if !yes {
+		if !isTerminal(cmd.InOrStdin()) {
+			return fmt.Errorf("found %d task(s), use --yes to create them", len(parsed))
+		}
+		cmd.Printf("Create %d task(s)? [y/N or numbers like 1,3] ", len(parsed))
+		picked, err := parseSelection(readAnswer(cmd.InOrStdin()), len(parsed))
+		if err != nil {
+			return err
+		}
+		accepted := make([]clipboard.ParsedTask, 0, len(picked))
+		for _, n := range picked {
+			accepted = append(accepted, parsed[n-1])
+		}
+		parsed = accepted
+	}
+	if len(parsed) == 0 {
+		cmd.Println("no task created")
+		return nil
+	}
+
+	if source == "ai" {
+		ctx = repo.WithSource(ctx, domain.SourceAI)
+	}
+	tasks, err := uc.CreateManyFromParsed(ctx, parsed)
+	if err != nil {
+		return err
+	}
+	for _, task := range tasks {
+		cmd.Printf("created #%d %s\n", task.ID, task.Title)
+	}
+	return nil
+}
+
+// parseSelection reads the answer to a confirm over n items: y for all,
+// empty or n for none, or item numbers such as "1,3" or "1 3".
+func parseSelection(answer string, n int) ([]int, error) {
+	switch answer {
+	case "y", "yes":
+		all := make([]int, n)
+		for i := range all {
+			all[i] = i + 1
+		}
+		return all, nil
+	case "", "n", "no":
+		return nil, nil
+	}
+	picked := make([]bool, n+1)
+	for _, field := range strings.FieldsFunc(answer, func(r rune) bool { return r == ',' || r == ' ' }) {
+		i, err := strconv.Atoi(field)
+		if err != nil || i < 1 || i > n {
+			return nil, fmt.Errorf("invalid answer %q, expect y, n or numbers from 1 to %d", answer, n)
+		}
+		picked[i] = true
+	}
+	var out []int
+	for i := 1; i <= n; i++ {
+		if picked[i] {
+			out = append(out, i)
+		}
+	}
+	return out, nil
+}
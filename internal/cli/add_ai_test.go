@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -86,3 +87,98 @@ func TestAddClipAIShouldWriteDueAndTodo(t *testing.T) {
 		t.Fatalf("ls output = %q, want due", ls)
 	}
 }
+
+func TestAddClipAIShouldReuseCachedResponseAcrossRuns(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"choices":[{"message":{"content":"{\"title\":\"cached title\"}"}}]}`)
+	}))
+	defer server.Close()
+
+	t.Setenv("TD_AI_PROVIDER", "deepseek")
+	t.Setenv("TD_AI_API_KEY", "sk-test")
+	t.Setenv("TD_AI_BASE_URL", server.URL+"/v1")
+	t.Setenv("TD_AI_MODEL", "deepseek-chat")
+	cfg := testConfigInDir(t, t.TempDir())
+
+	for i := 0; i < 2; i++ {
+		if out := runCLI(t, cfg, "add", "--clip", "--ai", "same clip text"); !strings.Contains(out, "cached title") {
+			t.Fatalf("add output = %q", out)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("api calls = %d, want 1", calls)
+	}
+
+	out := runCLI(t, cfg, "ai", "cache", "stats")
+	if !strings.Contains(out, "entries: 1 (0 expired)") || !strings.Contains(out, "hits: 1") {
+		t.Fatalf("stats output = %q", out)
+	}
+	if out := runCLI(t, cfg, "ai", "cache", "clear"); strings.TrimSpace(out) != "cleared 1 cached response(s)" {
+		t.Fatalf("clear output = %q", out)
+	}
+	_ = runCLI(t, cfg, "add", "--clip", "--ai", "same clip text")
+	if calls != 2 {
+		t.Fatalf("api calls after clear = %d, want 2", calls)
+	}
+}
+
+func TestAddClipAIMultiShouldPreviewThenCreateWithYes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"choices":[{"message":{"content":"{\"project\":\"weekly\",\"due\":\"2026-03-06 17:00\",\"tasks\":[`+
+			`{\"title\":\"write minutes\",\"priority\":\"P1\"},{\"title\":\"book room\",\"due\":\"2026-03-05 12:00\"}]}"}}]}`)
+	}))
+	defer server.Close()
+
+	t.Setenv("TD_AI_PROVIDER", "deepseek")
+	t.Setenv("TD_AI_API_KEY", "sk-test")
+	t.Setenv("TD_AI_BASE_URL", server.URL+"/v1")
+	t.Setenv("TD_AI_MODEL", "deepseek-chat")
+	cfg := testConfigInDir(t, t.TempDir())
+
+	out, err := runCLIWithErr(cfg, "add", "--clip", "--ai", "--multi", "meeting notes")
+	if err == nil || !strings.Contains(err.Error(), "--yes") {
+		t.Fatalf("multi without a terminal or --yes = %v, want an error", err)
+	}
+	if !strings.Contains(out, "write minutes") || !strings.Contains(out, "book room") {
+		t.Fatalf("preview = %q", out)
+	}
+	if ls := runCLI(t, cfg, "ls"); strings.Contains(ls, "write minutes") {
+		t.Fatalf("preview should not create tasks, ls = %q", ls)
+	}
+
+	out = runCLI(t, cfg, "add", "--clip", "--ai", "--multi", "--yes", "meeting notes")
+	if strings.Count(out, "created #") != 2 {
+		t.Fatalf("add output = %q", out)
+	}
+	ls := runCLI(t, cfg, "ls", "project:weekly")
+	if !strings.Contains(ls, "2026-03-06 17:00") || !strings.Contains(ls, "2026-03-05 12:00") {
+		t.Fatalf("ls output = %q, want shared and own due", ls)
+	}
+
+	_ = runCLI(t, cfg, "undo")
+	if ls := runCLI(t, cfg, "ls"); strings.Contains(ls, "write minutes") || strings.Contains(ls, "book room") {
+		t.Fatalf("one undo should remove both tasks, ls = %q", ls)
+	}
+}
+
+func TestParseSelectionShouldAcceptNumbers(t *testing.T) {
+	for answer, want := range map[string]string{
+		"y":     "[1 2 3]",
+		"":      "[]",
+		"no":    "[]",
+		"3,1":   "[1 3]",
+		"2 2,3": "[2 3]",
+	} {
+		got, err := parseSelection(answer, 3)
+		if err != nil || fmt.Sprint(got) != want {
+			t.Fatalf("parseSelection(%q) = %v, %v, want %s", answer, got, err, want)
+		}
+	}
+	if _, err := parseSelection("4", 3); err == nil {
+		t.Fatalf("out of range answer should fail")
+	}
+}
@@ -6,9 +6,12 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"td/internal/config"
+	"td/internal/domain"
 	taskrepo "td/internal/repo"
+	"td/internal/timeutil"
 )
 
 func TestAddAndList(t *testing.T) {
@@ -62,6 +65,54 @@ func TestAddWithProjectShouldCreateTodo(t *testing.T) {
 	}
 }
 
+func TestAddShouldParseQuickAddTokens(t *testing.T) {
+	cfg := testConfigInDir(t, t.TempDir())
+	now := time.Date(2026, 3, 4, 10, 0, 0, 0, time.Local)
+	cfg.Clock = timeutil.ClockFunc(func() time.Time { return now })
+
+	_ = runCLI(t, cfg, "add", "write report +work !1 @deep ^tomorrow 17:00 fix #123")
+	_ = runCLI(t, cfg, "add", "plan +home !3 @Deep", "-p", "work", "-t", "later")
+	_ = runCLI(t, cfg, "add", "--raw", "email a@b.com +1 !2 #c")
+
+	taskRepo, closer, err := openTaskRepo(cfg)
+	if err != nil {
+		t.Fatalf("open repo: %v", err)
+	}
+	defer closeDB(closer)
+
+	tasks, err := taskRepo.List(context.Background(), taskrepo.TaskListFilter{})
+	if err != nil {
+		t.Fatalf("list tasks: %v", err)
+	}
+	byTitle := make(map[string]domain.Task, len(tasks))
+	for _, task := range tasks {
+		byTitle[task.Title] = task
+	}
+
+	task, ok := byTitle["write report fix #123"]
+	if !ok {
+		t.Fatalf("titles = %v, want tokens stripped", byTitle)
+	}
+	wantDue := time.Date(2026, 3, 5, 17, 0, 0, 0, time.Local)
+	if task.Project != "work" || task.Priority != "P1" || strings.Join(task.Tags, ",") != "deep" || task.DueAt == nil || !task.DueAt.Equal(wantDue) {
+		t.Fatalf("parsed task = %+v", task)
+	}
+
+	task = byTitle["plan"]
+	if task.Project != "work" || task.Priority != "P3" || strings.Join(task.Tags, ",") != "deep,later" {
+		t.Fatalf("flags should win over tokens: %+v", task)
+	}
+
+	task, ok = byTitle["email a@b.com +1 !2 #c"]
+	if !ok || task.Project != "" || task.Priority != "P2" {
+		t.Fatalf("--raw should keep the title: %v", byTitle)
+	}
+
+	if _, err := runCLIWithErr(cfg, "add", "+work !1"); err == nil {
+		t.Fatalf("tokens without a title should fail")
+	}
+}
+
 func runCLI(t *testing.T, cfg config.Config, args ...string) string {
 	t.Helper()
 	cmd := NewRootCmd(cfg)
@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"td/internal/config"
+)
+
+func newAICmd(cfg config.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ai",
+		Short: "Manage AI parsing",
+	}
+	cmd.AddCommand(newAICacheCmd(cfg))
+	return cmd
+}
+
+func newAICacheCmd(cfg config.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Inspect or clear cached AI responses",
+		Long: `AI responses are cached in the database, keyed by model, prompt version
+and input, so parsing the same text again on the same day does not call
+the API. ai.cache_ttl (hours) and ai.cache_size in config.toml bound the
+cache.`,
+	}
+	cmd.AddCommand(newAICacheStatsCmd(cfg))
+	cmd.AddCommand(newAICacheClearCmd(cfg))
+	return cmd
+}
+
+func newAICacheStatsCmd(cfg config.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "stats",
+		Short: "Show AI cache usage",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repo, closer, err := openTaskRepo(cfg)
+			if err != nil {
+				return err
+			}
+			defer closeDB(closer)
+
+			cache := newAICacheStore(cfg, repo)
+			stats, err := cache.Stats(cmd.Context())
+			if err != nil {
+				return err
+			}
+			cmd.Printf("entries: %d (%d expired)\n", stats.Entries, stats.Expired)
+			cmd.Printf("size: %s\n", formatBytes(stats.Bytes))
+			cmd.Printf("hits: %d\n", stats.Hits)
+			cmd.Printf("oldest: %s\n", formatDue(stats.Oldest))
+			cmd.Printf("newest: %s\n", formatDue(stats.Newest))
+			cmd.Printf("limits: %s ttl, %d entries\n", cache.TTL, cache.MaxEntries)
+			return nil
+		},
+	}
+}
+
+func newAICacheClearCmd(cfg config.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "clear",
+		Short: "Remove all cached AI responses",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repo, closer, err := openTaskRepo(cfg)
+			if err != nil {
+				return err
+			}
+			defer closeDB(closer)
+
+			n, err := newAICacheStore(cfg, repo).Clear(cmd.Context())
+			if err != nil {
+				return err
+			}
+			cmd.Printf("cleared %d cached response(s)\n", n)
+			return nil
+		},
+	}
+}
+
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MiB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KiB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
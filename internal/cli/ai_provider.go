@@ -2,33 +2,50 @@ package cli
 
 import (
 	"net/http"
+	"net/url"
 	"os"
 	"strconv"
 	"strings"
 	"time"
 
 	"td/internal/ai"
+	"td/internal/ai/anthropic"
+	"td/internal/ai/ollama"
 	"td/internal/ai/openai"
 	"td/internal/app/usecase"
 	"td/internal/config"
+	"td/internal/repo/sqlite"
 )
 
 const (
-	defaultDeepSeekEndpoint = "https://api.deepseek.com/v1/chat/completions"
-	defaultDeepSeekModel    = "deepseek-chat"
-	defaultOpenAIEndpoint   = "https://api.openai.com/v1/chat/completions"
-	defaultOpenAIModel      = "gpt-4o-mini"
+	defaultDeepSeekEndpoint  = "https://api.deepseek.com/v1/chat/completions"
+	defaultDeepSeekModel     = "deepseek-chat"
+	defaultOpenAIEndpoint    = "https://api.openai.com/v1/chat/completions"
+	defaultOpenAIModel       = "gpt-4o-mini"
+	defaultAnthropicEndpoint = anthropic.DefaultEndpoint
+	defaultAnthropicModel    = anthropic.DefaultModel
+	defaultOllamaEndpoint    = ollama.DefaultEndpoint
+	defaultOllamaModel       = ollama.DefaultModel
 )
 
-func newAIParseTaskUseCase(cfg config.Config) *usecase.AIParseTaskUseCase {
-	provider := newAIProviderFromConfig(cfg)
+func newAIParseTaskUseCase(cfg config.Config, store ai.Cache) *usecase.AIParseTaskUseCase {
+	provider := newAIProviderFromConfig(cfg, store)
 	if provider == nil {
 		return nil
 	}
-	return &usecase.AIParseTaskUseCase{Provider: provider}
+	return &usecase.AIParseTaskUseCase{Provider: provider, Clock: cfg.Clock}
 }
 
-func newAIProviderFromConfig(cfg config.Config) ai.Provider {
+// newAICacheStore opens the persistent AI response cache with the ttl
+// and size from config.toml.
+func newAICacheStore(cfg config.Config, taskRepo *sqlite.TaskRepository) *sqlite.AICache {
+	userCfg, _ := config.LoadUserConfig(cfg.ConfigToml)
+	return taskRepo.AICache(time.Duration(userCfg.AI.CacheTTL)*time.Hour, userCfg.AI.CacheSize)
+}
+
+// newAIProviderFromConfig builds the configured provider; its responses
+// are cached in memory in front of store, which may be nil.
+func newAIProviderFromConfig(cfg config.Config, store ai.Cache) ai.Provider {
 	userCfg, _ := config.LoadUserConfig(cfg.ConfigToml)
 	provider := strings.ToLower(strings.TrimSpace(userCfg.AI.Provider))
 	providerFromEnv := false
@@ -37,14 +54,11 @@ func newAIProviderFromConfig(cfg config.Config) ai.Provider {
 		providerFromEnv = true
 	}
 	if provider == "" {
-		switch {
-		case strings.TrimSpace(os.Getenv("DEEPSEEK_API_KEY")) != "":
-			provider = "deepseek"
-		case strings.TrimSpace(os.Getenv("OPENAI_API_KEY")) != "":
-			provider = "openai"
-		default:
-			provider = "deepseek"
+		baseURL := strings.TrimSpace(userCfg.AI.BaseURL)
+		if fromEnv := strings.TrimSpace(os.Getenv("TD_AI_BASE_URL")); fromEnv != "" {
+			baseURL = fromEnv
 		}
+		provider = detectAIProvider(baseURL)
 	}
 
 	apiKey := strings.TrimSpace(os.Getenv("TD_AI_API_KEY"))
@@ -55,13 +69,13 @@ func newAIProviderFromConfig(cfg config.Config) ai.Provider {
 		switch provider {
 		case "openai":
 			apiKey = strings.TrimSpace(os.Getenv("OPENAI_API_KEY"))
+		case "anthropic":
+			apiKey = strings.TrimSpace(os.Getenv("ANTHROPIC_API_KEY"))
+		case "ollama":
 		default:
 			apiKey = strings.TrimSpace(os.Getenv("DEEPSEEK_API_KEY"))
 		}
 	}
-	if apiKey == "" {
-		return nil
-	}
 
 	endpoint := ""
 	if !providerFromEnv {
@@ -92,11 +106,34 @@ func newAIProviderFromConfig(cfg config.Config) ai.Provider {
 		if model == "" {
 			model = defaultOpenAIModel
 		}
+	case "anthropic":
+		if endpoint == "" {
+			endpoint = defaultAnthropicEndpoint
+		}
+		if model == "" {
+			model = defaultAnthropicModel
+		}
+	case "ollama":
+		if endpoint == "" {
+			endpoint = defaultOllamaEndpoint
+		}
+		if model == "" {
+			model = defaultOllamaModel
+		}
 	default:
 		return nil
 	}
+	// Ollama and OpenAI-compatible servers on this machine take no key.
+	keyless := provider == "ollama" || (provider != "anthropic" && ai.IsLoopbackURL(endpoint))
+	if apiKey == "" && !keyless {
+		return nil
+	}
 
 	timeoutSec := 20
+	if provider == "ollama" {
+		// Local models can take a while to load on the first request.
+		timeoutSec = 60
+	}
 	if userCfg.AI.Timeout > 0 {
 		timeoutSec = userCfg.AI.Timeout
 	}
@@ -106,14 +143,81 @@ func newAIProviderFromConfig(cfg config.Config) ai.Provider {
 		}
 	}
 	timeout := time.Duration(timeoutSec) * time.Second
+	cache := ai.TieredCache{Front: ai.NewMemoryCache(0), Back: store}
 
+	switch provider {
+	case "ollama":
+		return &ollama.Client{
+			Endpoint: endpoint,
+			Model:    model,
+			Cache:    cache,
+			HTTPClient: &http.Client{
+				Timeout: timeout,
+			},
+			Clock: cfg.Clock,
+		}
+	case "anthropic":
+		return &anthropic.Client{
+			Endpoint: endpoint,
+			APIKey:   apiKey,
+			Model:    model,
+			Cache:    cache,
+			HTTPClient: &http.Client{
+				Timeout: timeout,
+			},
+			Clock: cfg.Clock,
+		}
+	}
+	// DeepSeek only has JSON mode; other compatible servers are asked for
+	// the schema and fall back to a plain request if they reject it.
+	format := openai.FormatJSONSchema
+	if provider == "deepseek" {
+		format = openai.FormatJSONObject
+	}
 	return &openai.Client{
-		Endpoint: endpoint,
-		APIKey:   apiKey,
-		Model:    model,
-		Cache:    ai.NewCache(),
+		Endpoint:       endpoint,
+		APIKey:         apiKey,
+		Model:          model,
+		ResponseFormat: format,
+		Cache:          cache,
 		HTTPClient: &http.Client{
 			Timeout: timeout,
 		},
+		Clock: cfg.Clock,
+	}
+}
+
+// detectAIProvider picks a provider when none is configured. A loopback
+// base URL means a local server: Ollama on its default port or /api path,
+// an OpenAI-compatible one such as llama.cpp otherwise. Without one the
+// first cloud API key found in the environment decides.
+func detectAIProvider(baseURL string) string {
+	if ai.IsLoopbackURL(baseURL) {
+		if isOllamaURL(baseURL) {
+			return "ollama"
+		}
+		return "openai"
+	}
+	switch {
+	case strings.TrimSpace(os.Getenv("DEEPSEEK_API_KEY")) != "":
+		return "deepseek"
+	case strings.TrimSpace(os.Getenv("OPENAI_API_KEY")) != "":
+		return "openai"
+	case strings.TrimSpace(os.Getenv("ANTHROPIC_API_KEY")) != "":
+		return "anthropic"
+	default:
+		return "deepseek"
+	}
+}
+
+func isOllamaURL(raw string) bool {
+	text := strings.TrimRight(strings.TrimSpace(raw), "/")
+	if !strings.Contains(text, "://") {
+		text = "http://" + text
+	}
+	u, err := url.Parse(text)
+	if err != nil {
+		return false
 	}
+	return u.Port() == "11434" || u.Path == "/api" || strings.HasPrefix(u.Path, "/api/")
 }
@@ -4,6 +4,8 @@ import (
 	"path/filepath"
 	"testing"
 
+	"td/internal/ai/anthropic"
+	"td/internal/ai/ollama"
 	"td/internal/ai/openai"
 	"td/internal/config"
 )
@@ -19,7 +21,7 @@ func TestNewAIProviderFromEnvDeepSeekDefaults(t *testing.T) {
 	t.Setenv("DEEPSEEK_API_KEY", "")
 	t.Setenv("OPENAI_API_KEY", "")
 
-	provider := newAIProviderFromConfig(cfg)
+	provider := newAIProviderFromConfig(cfg, nil)
 	client, ok := provider.(*openai.Client)
 	if !ok {
 		t.Fatalf("provider type = %T, want *openai.Client", provider)
@@ -30,6 +32,9 @@ func TestNewAIProviderFromEnvDeepSeekDefaults(t *testing.T) {
 	if client.Model != defaultDeepSeekModel {
 		t.Fatalf("model = %q, want %q", client.Model, defaultDeepSeekModel)
 	}
+	if client.ResponseFormat != openai.FormatJSONObject {
+		t.Fatalf("response format = %q, want %q", client.ResponseFormat, openai.FormatJSONObject)
+	}
 }
 
 func TestNewAIProviderFromEnvShouldReturnNilWithoutAPIKey(t *testing.T) {
@@ -40,7 +45,63 @@ func TestNewAIProviderFromEnvShouldReturnNilWithoutAPIKey(t *testing.T) {
 	t.Setenv("DEEPSEEK_API_KEY", "")
 	t.Setenv("OPENAI_API_KEY", "")
 
-	if provider := newAIProviderFromConfig(cfg); provider != nil {
+	if provider := newAIProviderFromConfig(cfg, nil); provider != nil {
 		t.Fatalf("provider = %T, want nil", provider)
 	}
 }
+
+func TestNewAIProviderShouldBuildAnthropicClient(t *testing.T) {
+	cfg := testConfigForAI(t)
+	t.Setenv("TD_AI_PROVIDER", "")
+	t.Setenv("TD_AI_API_KEY", "")
+	t.Setenv("TD_AI_BASE_URL", "")
+	t.Setenv("TD_AI_MODEL", "")
+	t.Setenv("DEEPSEEK_API_KEY", "")
+	t.Setenv("OPENAI_API_KEY", "")
+	t.Setenv("ANTHROPIC_API_KEY", "sk-ant-env")
+
+	provider := newAIProviderFromConfig(cfg, nil)
+	client, ok := provider.(*anthropic.Client)
+	if !ok {
+		t.Fatalf("provider type = %T, want *anthropic.Client", provider)
+	}
+	if client.APIKey != "sk-ant-env" || client.Endpoint != defaultAnthropicEndpoint || client.Model != defaultAnthropicModel {
+		t.Fatalf("client = %+v", client)
+	}
+
+	t.Setenv("ANTHROPIC_API_KEY", "")
+	runCLI(t, cfg, "config", "ai", "set", "provider", "anthropic")
+	runCLI(t, cfg, "config", "ai", "set", "api-key", "sk-ant-config")
+	client, ok = newAIProviderFromConfig(cfg, nil).(*anthropic.Client)
+	if !ok || client.APIKey != "sk-ant-config" || client.Model != defaultAnthropicModel {
+		t.Fatalf("provider from config = %+v", client)
+	}
+}
+
+func TestNewAIProviderShouldDetectLocalServersWithoutKey(t *testing.T) {
+	cfg := testConfigForAI(t)
+	for _, env := range []string{"TD_AI_PROVIDER", "TD_AI_API_KEY", "TD_AI_MODEL", "DEEPSEEK_API_KEY", "OPENAI_API_KEY", "ANTHROPIC_API_KEY"} {
+		t.Setenv(env, "")
+	}
+
+	t.Setenv("TD_AI_BASE_URL", "http://localhost:11434")
+	if _, ok := newAIProviderFromConfig(cfg, nil).(*ollama.Client); !ok {
+		t.Fatalf("localhost:11434 should select ollama")
+	}
+	t.Setenv("TD_AI_BASE_URL", "http://127.0.0.1:8080/v1")
+	client, ok := newAIProviderFromConfig(cfg, nil).(*openai.Client)
+	if !ok || client.APIKey != "" || client.Endpoint != "http://127.0.0.1:8080/v1" || client.ResponseFormat != openai.FormatJSONSchema {
+		t.Fatalf("llama.cpp server should use the openai client without key, got %+v", client)
+	}
+	t.Setenv("TD_AI_BASE_URL", "https://api.openai.com/v1")
+	if provider := newAIProviderFromConfig(cfg, nil); provider != nil {
+		t.Fatalf("remote endpoint without key = %T, want nil", provider)
+	}
+
+	t.Setenv("TD_AI_BASE_URL", "")
+	runCLI(t, cfg, "config", "ai", "set", "provider", "ollama")
+	local, ok := newAIProviderFromConfig(cfg, nil).(*ollama.Client)
+	if !ok || local.Endpoint != defaultOllamaEndpoint || local.Model != defaultOllamaModel {
+		t.Fatalf("provider from config = %+v", local)
+	}
+}
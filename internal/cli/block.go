@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"github.com/spf13/cobra"
+
+	"td/internal/app/usecase"
+	"td/internal/config"
+)
+
+func newBlockCmd(cfg config.Config) *cobra.Command {
+	var on []string
+	cmd := &cobra.Command{
+		Use:   "block <id> --on <id...>",
+		Short: "Mark a task as waiting on other tasks",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ids, err := parseIDs(args)
+			if err != nil {
+				return err
+			}
+			blockerIDs, err := parseIDs(on)
+			if err != nil {
+				return err
+			}
+			repo, closer, err := openTaskRepo(cfg)
+			if err != nil {
+				return err
+			}
+			defer closeDB(closer)
+
+			uc := usecase.UpdateTaskUseCase{Repo: repo, Workflow: cfg.Workflow}
+			if err := uc.Block(cmd.Context(), ids[0], blockerIDs); err != nil {
+				return err
+			}
+			cmd.Printf("blocked #%d by %s\n", ids[0], formatIDList(blockerIDs))
+			return nil
+		},
+	}
+	cmd.Flags().StringSliceVar(&on, "on", nil, "blocker task ids (repeatable or comma separated)")
+	_ = cmd.MarkFlagRequired("on")
+	return cmd
+}
+
+func newUnblockCmd(cfg config.Config) *cobra.Command {
+	var on []string
+	cmd := &cobra.Command{
+		Use:   "unblock <id>",
+		Short: "Remove task dependencies",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ids, err := parseIDs(args)
+			if err != nil {
+				return err
+			}
+			var blockerIDs []int64
+			if len(on) > 0 {
+				blockerIDs, err = parseIDs(on)
+				if err != nil {
+					return err
+				}
+			}
+			repo, closer, err := openTaskRepo(cfg)
+			if err != nil {
+				return err
+			}
+			defer closeDB(closer)
+
+			uc := usecase.UpdateTaskUseCase{Repo: repo, Workflow: cfg.Workflow}
+			if err := uc.Unblock(cmd.Context(), ids[0], blockerIDs); err != nil {
+				return err
+			}
+			if len(blockerIDs) == 0 {
+				cmd.Printf("unblocked #%d\n", ids[0])
+			} else {
+				cmd.Printf("unblocked #%d from %s\n", ids[0], formatIDList(blockerIDs))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringSliceVar(&on, "on", nil, "only remove these blockers (default: all)")
+	return cmd
+}
@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"td/internal/domain"
+)
+
+func TestBlockShouldHideTaskFromTodayUntilBlockerIsDone(t *testing.T) {
+	cfg := testConfigInDir(t, t.TempDir())
+	blocker := createViaCLIWithArgs(t, cfg, "order parts", "-p", "home")
+	waiting := createViaCLIWithArgs(t, cfg, "assemble shelf", "-p", "home")
+	blockerText := strconv.FormatInt(blocker, 10)
+	waitingText := strconv.FormatInt(waiting, 10)
+	today := time.Now().Format("2006-01-02")
+	_ = runCLI(t, cfg, "due", blockerText, today)
+	_ = runCLI(t, cfg, "due", waitingText, today)
+
+	out := runCLI(t, cfg, "block", waitingText, "--on", blockerText)
+	if !strings.Contains(out, "blocked #"+waitingText+" by #"+blockerText) {
+		t.Fatalf("block output = %q", out)
+	}
+	if _, err := runCLIWithErr(cfg, "block", blockerText, "--on", waitingText); !errors.Is(err, domain.ErrDependencyCycle) {
+		t.Fatalf("reverse dependency err = %v, want cycle", err)
+	}
+	if _, err := runCLIWithErr(cfg, "today", waitingText); !errors.Is(err, domain.ErrTaskBlocked) {
+		t.Fatalf("starting blocked task err = %v", err)
+	}
+
+	out = runCLI(t, cfg, "ls", "status:todo")
+	if !strings.Contains(out, "blocked by #"+blockerText) {
+		t.Fatalf("ls should mark blocked task: %q", out)
+	}
+	out = runCLI(t, cfg, "ls", "today")
+	if strings.Contains(out, "assemble shelf") || !strings.Contains(out, "order parts") {
+		t.Fatalf("today should hide blocked task: %q", out)
+	}
+
+	out = runCLI(t, cfg, "done", blockerText)
+	if !strings.Contains(out, "unblocked #"+waitingText+" assemble shelf") {
+		t.Fatalf("done should report unblocked tasks: %q", out)
+	}
+	out = runCLI(t, cfg, "ls", "today")
+	if !strings.Contains(out, "assemble shelf") || strings.Contains(out, "blocked by") {
+		t.Fatalf("today should show unblocked task: %q", out)
+	}
+
+	_ = runCLI(t, cfg, "reopen", blockerText)
+	out = runCLI(t, cfg, "show", waitingText)
+	if !strings.Contains(out, "blocked by: #"+blockerText) {
+		t.Fatalf("show output = %q", out)
+	}
+	_ = runCLI(t, cfg, "unblock", waitingText)
+	out = runCLI(t, cfg, "ls", "status:todo")
+	if strings.Contains(out, "blocked by") {
+		t.Fatalf("unblock should remove dependencies: %q", out)
+	}
+}
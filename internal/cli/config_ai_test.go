@@ -91,7 +91,7 @@ func TestAIProviderShouldReadFromConfigAndEnvPriority(t *testing.T) {
 	runCLI(t, cfg, "config", "ai", "set", "provider", "openai")
 	runCLI(t, cfg, "config", "ai", "set", "api-key", "sk-openai")
 
-	provider := newAIProviderFromConfig(cfg)
+	provider := newAIProviderFromConfig(cfg, nil)
 	client, ok := provider.(*openai.Client)
 	if !ok {
 		t.Fatalf("provider type = %T, want *openai.Client", provider)
@@ -105,7 +105,7 @@ func TestAIProviderShouldReadFromConfigAndEnvPriority(t *testing.T) {
 
 	t.Setenv("TD_AI_PROVIDER", "deepseek")
 	t.Setenv("TD_AI_API_KEY", "sk-deepseek")
-	provider = newAIProviderFromConfig(cfg)
+	provider = newAIProviderFromConfig(cfg, nil)
 	client, ok = provider.(*openai.Client)
 	if !ok {
 		t.Fatalf("env override provider type = %T, want *openai.Client", provider)
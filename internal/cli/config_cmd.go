@@ -18,6 +18,8 @@ const (
 	aiFieldBaseURL  aiField = "base_url"
 	aiFieldModel    aiField = "model"
 	aiFieldTimeout  aiField = "timeout"
+	aiFieldCacheTTL aiField = "cache_ttl"
+	aiFieldCacheMax aiField = "cache_size"
 )
 
 type githubField string
@@ -118,16 +120,48 @@ func newConfigAIUnsetCmd(cfg config.Config) *cobra.Command {
 	}
 }
 
+var aiShowFields = []string{"provider", "api_key", "base_url", "model", "timeout", "cache_ttl", "cache_size"}
+
 func newConfigAIShowCmd(cfg config.Config) *cobra.Command {
-	return &cobra.Command{
+	var output outputOptions
+	cmd := &cobra.Command{
 		Use:   "show",
 		Short: "Show AI config",
 		Args:  cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := output.validate(aiShowFields); err != nil {
+				return err
+			}
 			userCfg, err := config.LoadUserConfig(cfg.ConfigToml)
 			if err != nil {
 				return err
 			}
+			if !output.legacy() {
+				var timeout, cacheTTL, cacheSize any
+				if userCfg.AI.Timeout > 0 {
+					timeout = userCfg.AI.Timeout
+				}
+				if userCfg.AI.CacheTTL > 0 {
+					cacheTTL = userCfg.AI.CacheTTL
+				}
+				if userCfg.AI.CacheSize > 0 {
+					cacheSize = userCfg.AI.CacheSize
+				}
+				apiKey := ""
+				if strings.TrimSpace(userCfg.AI.APIKey) != "" {
+					apiKey = maskSecret(userCfg.AI.APIKey)
+				}
+				record := outputRecord{
+					{Name: "provider", Value: userCfg.AI.Provider},
+					{Name: "api_key", Value: apiKey},
+					{Name: "base_url", Value: userCfg.AI.BaseURL},
+					{Name: "model", Value: userCfg.AI.Model},
+					{Name: "timeout", Value: timeout},
+					{Name: "cache_ttl", Value: cacheTTL},
+					{Name: "cache_size", Value: cacheSize},
+				}
+				return writeRecords(cmd.OutOrStdout(), output, aiShowFields, []outputRecord{record}, true)
+			}
 			cmd.Printf("provider: %s\n", fallbackDash(userCfg.AI.Provider))
 			cmd.Printf("api_key: %s\n", maskSecret(userCfg.AI.APIKey))
 			cmd.Printf("base_url: %s\n", fallbackDash(userCfg.AI.BaseURL))
@@ -137,9 +171,13 @@ func newConfigAIShowCmd(cfg config.Config) *cobra.Command {
 			} else {
 				cmd.Printf("timeout: -\n")
 			}
+			cmd.Printf("cache_ttl: %s\n", fallbackDash(getAIField(userCfg.AI, aiFieldCacheTTL)))
+			cmd.Printf("cache_size: %s\n", fallbackDash(getAIField(userCfg.AI, aiFieldCacheMax)))
 			return nil
 		},
 	}
+	bindOutputFlags(cmd, &output)
+	return cmd
 }
 
 func newConfigGitHubCmd(cfg config.Config) *cobra.Command {
@@ -252,6 +290,10 @@ func parseAIField(raw string) (aiField, error) {
 		return aiFieldModel, nil
 	case "timeout":
 		return aiFieldTimeout, nil
+	case "cache_ttl":
+		return aiFieldCacheTTL, nil
+	case "cache_size":
+		return aiFieldCacheMax, nil
 	default:
 		return "", fmt.Errorf("unsupported ai key: %s", raw)
 	}
@@ -272,17 +314,23 @@ func setAIField(aiCfg *config.AIConfig, field aiField, value string) error {
 	switch field {
 	case aiFieldProvider:
 		provider := strings.ToLower(strings.TrimSpace(value))
-		if provider != "deepseek" && provider != "openai" {
-			return fmt.Errorf("provider must be deepseek or openai")
-		}
-		aiCfg.Provider = provider
-		if provider == "openai" {
+		switch provider {
+		case "openai":
 			aiCfg.BaseURL = defaultOpenAIEndpoint
 			aiCfg.Model = defaultOpenAIModel
-		} else {
+		case "anthropic":
+			aiCfg.BaseURL = defaultAnthropicEndpoint
+			aiCfg.Model = defaultAnthropicModel
+		case "ollama":
+			aiCfg.BaseURL = defaultOllamaEndpoint
+			aiCfg.Model = defaultOllamaModel
+		case "deepseek":
 			aiCfg.BaseURL = defaultDeepSeekEndpoint
 			aiCfg.Model = defaultDeepSeekModel
+		default:
+			return fmt.Errorf("provider must be deepseek, openai, anthropic or ollama")
 		}
+		aiCfg.Provider = provider
 	case aiFieldAPIKey:
 		aiCfg.APIKey = strings.TrimSpace(value)
 	case aiFieldBaseURL:
@@ -295,6 +343,16 @@ func setAIField(aiCfg *config.AIConfig, field aiField, value string) error {
 			return fmt.Errorf("timeout must be a positive integer")
 		}
 		aiCfg.Timeout = timeout
+	case aiFieldCacheTTL, aiFieldCacheMax:
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || n <= 0 {
+			return fmt.Errorf("%s must be a positive integer", field)
+		}
+		if field == aiFieldCacheTTL {
+			aiCfg.CacheTTL = n
+		} else {
+			aiCfg.CacheSize = n
+		}
 	default:
 		return fmt.Errorf("unsupported ai key: %s", field)
 	}
@@ -323,6 +381,16 @@ func getAIField(aiCfg config.AIConfig, field aiField) string {
 			return ""
 		}
 		return strconv.Itoa(aiCfg.Timeout)
+	case aiFieldCacheTTL:
+		if aiCfg.CacheTTL <= 0 {
+			return ""
+		}
+		return strconv.Itoa(aiCfg.CacheTTL)
+	case aiFieldCacheMax:
+		if aiCfg.CacheSize <= 0 {
+			return ""
+		}
+		return strconv.Itoa(aiCfg.CacheSize)
 	default:
 		return ""
 	}
@@ -349,6 +417,10 @@ func unsetAIField(aiCfg *config.AIConfig, field aiField) {
 		aiCfg.Model = ""
 	case aiFieldTimeout:
 		aiCfg.Timeout = 0
+	case aiFieldCacheTTL:
+		aiCfg.CacheTTL = 0
+	case aiFieldCacheMax:
+		aiCfg.CacheSize = 0
 	}
 }
 
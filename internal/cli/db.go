@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"td/internal/config"
+	"td/internal/repo/sqlite"
+)
+
+func newDBCmd(cfg config.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "db",
+		Short: "Manage td database",
+	}
+	cmd.AddCommand(newDBMigrateCmd(cfg))
+	return cmd
+}
+
+func newDBMigrateCmd(cfg config.Config) *cobra.Command {
+	var status bool
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Apply pending schema migrations",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := os.MkdirAll(cfg.DataDir, 0o755); err != nil {
+				return err
+			}
+			db, err := sqlite.Connect(cfg.DBPath)
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			if status {
+				statuses, err := sqlite.MigrationStatuses(cmd.Context(), db)
+				if err != nil {
+					return err
+				}
+				for _, item := range statuses {
+					state := "pending"
+					if item.Applied {
+						state = "applied"
+					}
+					cmd.Printf("%04d %-24s %s\n", item.Version, item.Name, state)
+				}
+				return nil
+			}
+
+			applied, err := sqlite.ApplyMigrations(cmd.Context(), db)
+			if err != nil {
+				return err
+			}
+			if len(applied) == 0 {
+				cmd.Println("schema is up to date")
+				return nil
+			}
+			for _, m := range applied {
+				cmd.Printf("applied %04d %s\n", m.Version, m.Name)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&status, "status", false, "show applied and pending migrations")
+	return cmd
+}
@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"td/internal/app/usecase"
+	"td/internal/config"
+	"td/internal/timeutil"
+)
+
+func newDeferCmd(cfg config.Config) *cobra.Command {
+	var clear bool
+	cmd := &cobra.Command{
+		Use:   "defer <id> <datetime...>",
+		Short: "Hide a task until a start datetime",
+		Long: `Hide a task from Inbox, Today and its project until a start datetime;
+meanwhile it is listed in the Upcoming view (td ls upcoming).
+
+  td defer 12 next monday
+  td defer 12 2026-03-01 09:00
+  td defer 12 --clear
+
+A day without a time starts at 00:00.`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if clear {
+				return cobra.ExactArgs(1)(cmd, args)
+			}
+			return cobra.MinimumNArgs(2)(cmd, args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ids, err := parseIDs(args[:1])
+			if err != nil {
+				return err
+			}
+			repo, closer, err := openTaskRepo(cfg)
+			if err != nil {
+				return err
+			}
+			defer closeDB(closer)
+
+			var startAt *time.Time
+			if !clear {
+				parsed, err := timeutil.ParseStart(strings.Join(args[1:], " "), cfg.Clock, time.Local)
+				if err != nil {
+					return err
+				}
+				startAt = &parsed
+			}
+
+			uc := usecase.UpdateTaskUseCase{Repo: repo, Workflow: cfg.Workflow}
+			if err := uc.SetStartAt(cmd.Context(), ids[0], startAt); err != nil {
+				return err
+			}
+			if clear {
+				cmd.Printf("cleared start #%d\n", ids[0])
+			} else {
+				cmd.Printf("deferred #%d until %s\n", ids[0], formatDue(startAt))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&clear, "clear", false, "clear start datetime")
+	return cmd
+}
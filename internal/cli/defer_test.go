@@ -0,0 +1,35 @@
+package cli
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestDeferShouldMoveTaskToUpcoming(t *testing.T) {
+	cfg := testConfigInDir(t, t.TempDir())
+	id := createViaCLIWithArgs(t, cfg, "renew passport", "-p", "home")
+	idStr := strconv.FormatInt(id, 10)
+
+	out := runCLI(t, cfg, "defer", idStr, "in", "3", "days")
+	if !strings.Contains(out, "deferred #"+idStr+" until ") || !strings.HasSuffix(strings.TrimSpace(out), "00:00") {
+		t.Fatalf("defer output = %q", out)
+	}
+	out = runCLI(t, cfg, "ls", "upcoming")
+	if !strings.Contains(out, "renew passport") || !strings.Contains(out, "starts ") {
+		t.Fatalf("upcoming output = %q", out)
+	}
+	out = runCLI(t, cfg, "show", idStr)
+	if !strings.Contains(out, "start: ") || !strings.Contains(out, "start: - -> ") {
+		t.Fatalf("show output = %q", out)
+	}
+
+	_ = runCLI(t, cfg, "defer", idStr, "--clear")
+	out = runCLI(t, cfg, "ls", "upcoming")
+	if strings.Contains(out, "renew passport") {
+		t.Fatalf("upcoming after clear = %q", out)
+	}
+	if _, err := runCLIWithErr(cfg, "defer", idStr, "someday"); err == nil {
+		t.Fatalf("unknown phrase should fail")
+	}
+}
@@ -1,13 +1,22 @@
 package cli
 
 import (
+	"bufio"
+	"context"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/mattn/go-isatty"
 	"github.com/spf13/cobra"
 
 	"td/internal/app/usecase"
 	"td/internal/config"
+	"td/internal/domain"
 )
 
 func newDoneCmd(cfg config.Config) *cobra.Command {
+	var subtasks bool
 	cmd := &cobra.Command{
 		Use:   "done <id...>",
 		Short: "Mark tasks done",
@@ -23,13 +32,79 @@ func newDoneCmd(cfg config.Config) *cobra.Command {
 			}
 			defer closeDB(closer)
 
-			uc := usecase.UpdateTaskUseCase{Repo: repo}
-			if err := uc.MarkDone(cmd.Context(), ids); err != nil {
+			uc := usecase.UpdateTaskUseCase{Repo: repo, Workflow: cfg.Workflow}
+			open, err := countOpenSubtasks(cmd.Context(), uc, ids)
+			if err != nil {
 				return err
 			}
-			cmd.Printf("done %d task(s)\n", len(ids))
+			if open > 0 && !cmd.Flags().Changed("subtasks") {
+				if isTerminal(cmd.InOrStdin()) {
+					cmd.Printf("%d open subtask(s). Complete them too? [y/N] ", open)
+					subtasks = readYes(cmd.InOrStdin())
+				} else {
+					cmd.Printf("note: %d open subtask(s) left open, use --subtasks to complete them\n", open)
+				}
+			}
+			var unblocked []domain.Task
+			if subtasks && open > 0 {
+				unblocked, err = uc.MarkDoneWithSubtasks(cmd.Context(), ids)
+				if err != nil {
+					return err
+				}
+				cmd.Printf("done %d task(s) and %d subtask(s)\n", len(ids), open)
+			} else {
+				unblocked, err = uc.MarkDone(cmd.Context(), ids)
+				if err != nil {
+					return err
+				}
+				cmd.Printf("done %d task(s)\n", len(ids))
+			}
+			for _, task := range unblocked {
+				cmd.Printf("unblocked #%d %s\n", task.ID, task.Title)
+			}
 			return nil
 		},
 	}
+	cmd.Flags().BoolVar(&subtasks, "subtasks", false, "also complete open subtasks without asking (--subtasks=false to leave them)")
 	return cmd
 }
+
+func countOpenSubtasks(ctx context.Context, uc usecase.UpdateTaskUseCase, ids []int64) (int, error) {
+	selected := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		selected[id] = true
+	}
+	counted := make(map[int64]bool)
+	for _, id := range ids {
+		children, err := uc.OpenSubtasks(ctx, id)
+		if err != nil {
+			return 0, err
+		}
+		for _, child := range children {
+			if !selected[child.ID] {
+				counted[child.ID] = true
+			}
+		}
+	}
+	return len(counted), nil
+}
+
+// isTerminal reports whether stream, an input or output, is a terminal.
+func isTerminal(stream any) bool {
+	f, ok := stream.(*os.File)
+	if !ok {
+		return false
+	}
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
+
+func readYes(in io.Reader) bool {
+	answer := readAnswer(in)
+	return answer == "y" || answer == "yes"
+}
+
+// readAnswer reads one line of input, trimmed and lower-cased.
+func readAnswer(in io.Reader) string {
+	line, _ := bufio.NewReader(in).ReadString('\n')
+	return strings.ToLower(strings.TrimSpace(line))
+}
@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -12,13 +13,13 @@ import (
 func newDueCmd(cfg config.Config) *cobra.Command {
 	var clear bool
 	cmd := &cobra.Command{
-		Use:   "due <id> <datetime>",
+		Use:   "due <id> <datetime...>",
 		Short: "Set task due datetime",
 		Args: func(cmd *cobra.Command, args []string) error {
 			if clear {
 				return cobra.ExactArgs(1)(cmd, args)
 			}
-			return cobra.ExactArgs(2)(cmd, args)
+			return cobra.MinimumNArgs(2)(cmd, args)
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ids, err := parseIDs(args[:1])
@@ -33,14 +34,14 @@ func newDueCmd(cfg config.Config) *cobra.Command {
 
 			var dueAt *time.Time
 			if !clear {
-				parsed, err := parseDueInput(args[1], time.Local)
+				parsed, err := parseDueInput(cfg, strings.Join(args[1:], " "), time.Local)
 				if err != nil {
 					return err
 				}
 				dueAt = &parsed
 			}
 
-			uc := usecase.UpdateTaskUseCase{Repo: repo}
+			uc := usecase.UpdateTaskUseCase{Repo: repo, Workflow: cfg.Workflow}
 			if err := uc.SetDueAt(cmd.Context(), ids[0], dueAt); err != nil {
 				return err
 			}
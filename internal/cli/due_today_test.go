@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"td/internal/config"
+	"td/internal/timeutil"
 )
 
 func TestAddWithDueFlag(t *testing.T) {
@@ -107,3 +108,35 @@ func TestDueCommandShouldSupportCompactDateTime(t *testing.T) {
 		t.Fatalf("due_at = %s, want %s", task.DueAt.UTC(), want)
 	}
 }
+
+func TestDueCommandShouldAcceptNaturalLanguage(t *testing.T) {
+	cfg := testConfigInDir(t, t.TempDir())
+	now := time.Date(2026, 3, 4, 10, 0, 0, 0, time.Local)
+	cfg.Clock = timeutil.ClockFunc(func() time.Time { return now })
+
+	id := createViaCLIWithArgs(t, cfg, "weekly sync", "--due", "fri 9am")
+	idStr := strconv.FormatInt(id, 10)
+
+	repo, closer, err := openTaskRepo(cfg)
+	if err != nil {
+		t.Fatalf("open repo: %v", err)
+	}
+	defer closeDB(closer)
+
+	task, err := repo.GetByID(context.Background(), id)
+	if err != nil {
+		t.Fatalf("get task: %v", err)
+	}
+	if want := time.Date(2026, 3, 6, 9, 0, 0, 0, time.Local); task.DueAt == nil || !task.DueAt.Equal(want) {
+		t.Fatalf("add --due = %v, want %s", task.DueAt, want)
+	}
+
+	_ = runCLI(t, cfg, "due", idStr, "next", "monday")
+	task, _ = repo.GetByID(context.Background(), id)
+	if want := time.Date(2026, 3, 9, 23, 59, 0, 0, time.Local); task.DueAt == nil || !task.DueAt.Equal(want) {
+		t.Fatalf("due next monday = %v, want %s", task.DueAt, want)
+	}
+	if _, err := runCLIWithErr(cfg, "due", idStr, "someday"); err == nil {
+		t.Fatalf("unknown phrase should fail")
+	}
+}
@@ -27,7 +27,7 @@ func newEditCmd(cfg config.Config) *cobra.Command {
 			}
 			defer closeDB(closer)
 
-			uc := usecase.UpdateTaskUseCase{Repo: repo}
+			uc := usecase.UpdateTaskUseCase{Repo: repo, Workflow: cfg.Workflow}
 			if err := uc.EditTitle(cmd.Context(), ids[0], title); err != nil {
 				return err
 			}
@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"td/internal/app/usecase"
+	"td/internal/config"
+	"td/internal/domain"
+)
+
+func newEveryCmd(cfg config.Config) *cobra.Command {
+	var (
+		clear     bool
+		afterDone bool
+	)
+	cmd := &cobra.Command{
+		Use:   "every <id> <rule>",
+		Short: "Set task recurrence",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if clear {
+				return cobra.ExactArgs(1)(cmd, args)
+			}
+			return cobra.MinimumNArgs(2)(cmd, args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ids, err := parseIDs(args[:1])
+			if err != nil {
+				return err
+			}
+			var recurrence domain.Recurrence
+			if !clear {
+				recurrence, err = parseEveryInput(strings.Join(args[1:], " "), afterDone)
+				if err != nil {
+					return err
+				}
+			}
+
+			repo, closer, err := openTaskRepo(cfg)
+			if err != nil {
+				return err
+			}
+			defer closeDB(closer)
+
+			uc := usecase.UpdateTaskUseCase{Repo: repo, Workflow: cfg.Workflow}
+			if err := uc.SetRecurrence(cmd.Context(), ids[0], recurrence); err != nil {
+				return err
+			}
+			if clear {
+				cmd.Printf("cleared recurrence #%d\n", ids[0])
+			} else {
+				cmd.Printf("set recurrence #%d: %s\n", ids[0], recurrence.Describe())
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&clear, "clear", false, "stop repeating the task")
+	cmd.Flags().BoolVar(&afterDone, "after-completion", false, "schedule the next occurrence from the completion day")
+	return cmd
+}
+
+func parseEveryInput(raw string, afterDone bool) (domain.Recurrence, error) {
+	if strings.TrimSpace(raw) == "" {
+		if afterDone {
+			return domain.Recurrence{}, fmt.Errorf("--after-completion requires --every")
+		}
+		return domain.Recurrence{}, nil
+	}
+	recurrence, err := domain.ParseRecurrence(raw)
+	if err != nil {
+		return domain.Recurrence{}, err
+	}
+	if afterDone {
+		recurrence.AfterCompletion = true
+	}
+	return recurrence, nil
+}
@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAddEveryShouldRepeatOnDone(t *testing.T) {
+	cfg := testConfigInDir(t, t.TempDir())
+	due := time.Now().Local().AddDate(0, 0, 1).Format("2006-01-02") + " 08:00"
+	id := createViaCLIWithArgs(t, cfg, "water plants", "-p", "home", "--due", due, "--every", "2w")
+
+	out := runCLI(t, cfg, "show", strconv.FormatInt(id, 10))
+	if !strings.Contains(out, "repeat: every 2 weeks\n") {
+		t.Fatalf("show output = %q", out)
+	}
+	_ = runCLI(t, cfg, "done", strconv.FormatInt(id, 10))
+
+	repo, closer, err := openTaskRepo(cfg)
+	if err != nil {
+		t.Fatalf("open repo: %v", err)
+	}
+	defer closeDB(closer)
+	next, err := repo.GetByID(context.Background(), id+1)
+	if err != nil {
+		t.Fatalf("next occurrence: %v", err)
+	}
+	wantDue, err := parseDueInput(cfg, due, time.Local)
+	if err != nil {
+		t.Fatalf("parse due: %v", err)
+	}
+	if next.Title != "water plants" || next.DueAt == nil || !next.DueAt.Equal(wantDue.AddDate(0, 0, 14)) {
+		t.Fatalf("next occurrence = %+v", next)
+	}
+}
+
+func TestEveryCommandShouldSetAndClearRecurrence(t *testing.T) {
+	cfg := testConfigInDir(t, t.TempDir())
+	id := createViaCLI(t, cfg, "standup notes")
+	idText := strconv.FormatInt(id, 10)
+
+	out := runCLI(t, cfg, "every", idText, "weekday", "--after-completion")
+	if !strings.Contains(out, "every weekday after completion") {
+		t.Fatalf("every output = %q", out)
+	}
+	out = runCLI(t, cfg, "show", idText, "-o", "json", "--fields", "recurrence")
+	if !strings.Contains(out, `"recurrence": "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR;X-TD-FROM=DONE"`) {
+		t.Fatalf("show json = %q", out)
+	}
+
+	_ = runCLI(t, cfg, "every", idText, "--clear")
+	out = runCLI(t, cfg, "show", idText)
+	if strings.Contains(out, "repeat:") {
+		t.Fatalf("recurrence should be cleared: %q", out)
+	}
+	if _, err := runCLIWithError(cfg, "every", idText, "every", "fortnight"); err == nil {
+		t.Fatalf("unknown rule should fail")
+	}
+}
@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"td/internal/app/usecase"
+	"td/internal/buildinfo"
+	"td/internal/config"
+	"td/internal/domain"
+	"td/internal/taskio"
+)
+
+func newExportCmd(cfg config.Config) *cobra.Command {
+	var (
+		format        string
+		view          string
+		project       string
+		events        bool
+		eventDuration time.Duration
+	)
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export tasks to stdout",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format = strings.ToLower(strings.TrimSpace(format))
+			switch format {
+			case "json", "todotxt", "ics":
+			default:
+				return fmt.Errorf("unsupported export format %q, use json, todotxt or ics", format)
+			}
+			if events && format != "ics" {
+				return fmt.Errorf("--events only applies to --format ics")
+			}
+			exportView, err := parseExportView(view, project)
+			if err != nil {
+				return err
+			}
+
+			repo, closer, err := openTaskRepo(cfg)
+			if err != nil {
+				return err
+			}
+			defer closeDB(closer)
+
+			uc := usecase.ExportTaskUseCase{Repo: repo, Workflow: cfg.Workflow}
+			data, err := uc.Execute(cmd.Context(), usecase.ExportInput{
+				View:    exportView,
+				Project: project,
+				Now:     cfg.Clock.Now().Local(),
+			})
+			if err != nil {
+				return err
+			}
+
+			switch format {
+			case "todotxt":
+				return taskio.EncodeTodoTxt(cmd.OutOrStdout(), data.Tasks, time.Local, cfg.Workflow)
+			case "ics":
+				tasks := data.Tasks
+				if exportView == "" {
+					tasks = withoutDeleted(tasks)
+				}
+				return taskio.EncodeICS(cmd.OutOrStdout(), tasks, taskio.ICSOptions{
+					Events:        events,
+					EventDuration: eventDuration,
+					TDVersion:     buildinfo.Version,
+					Workflow:      cfg.Workflow,
+				})
+			default:
+				bundle := taskio.NewBundle(data.Tasks, data.Projects, data.Tags, cfg.Clock.Now(), buildinfo.Version)
+				return taskio.EncodeBundle(cmd.OutOrStdout(), bundle)
+			}
+		},
+	}
+	cmd.Flags().StringVar(&format, "format", "json", "export format: json, todotxt or ics")
+	cmd.Flags().StringVar(&view, "view", "", "only export a view: today, inbox, upcoming, waiting, log, project or trash")
+	cmd.Flags().StringVar(&project, "project", "", "project name for --view project")
+	cmd.Flags().BoolVar(&events, "events", false, "emit VEVENTs at due times instead of VTODOs (ics)")
+	cmd.Flags().DurationVar(&eventDuration, "event-duration", 30*time.Minute, "length of each VEVENT (ics)")
+	return cmd
+}
+
+func parseExportView(raw, project string) (domain.View, error) {
+	view := domain.View(strings.ToLower(strings.TrimSpace(raw)))
+	switch view {
+	case "":
+		if strings.TrimSpace(project) != "" {
+			return domain.ViewProject, nil
+		}
+		return "", nil
+	case domain.ViewToday, domain.ViewInbox, domain.ViewUpcoming, domain.ViewWaiting, domain.ViewLog, domain.ViewTrash:
+		return view, nil
+	case domain.ViewProject:
+		if strings.TrimSpace(project) == "" {
+			return "", fmt.Errorf("--view project requires --project")
+		}
+		return view, nil
+	default:
+		return "", fmt.Errorf("unsupported view %q, use today, inbox, upcoming, waiting, log, project or trash", raw)
+	}
+}
+
+func withoutDeleted(tasks []domain.Task) []domain.Task {
+	out := make([]domain.Task, 0, len(tasks))
+	for _, task := range tasks {
+		if task.Status != domain.StatusDeleted {
+			out = append(out, task)
+		}
+	}
+	return out
+}
@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"td/internal/config"
+	"td/internal/timeutil"
+)
+
+func TestExportImportJSONShouldMoveTasksBetweenDatabases(t *testing.T) {
+	src := testConfigInDir(t, t.TempDir())
+	src.Clock = timeutil.ClockFunc(func() time.Time { return time.Date(2026, 3, 4, 10, 0, 0, 0, time.UTC) })
+	id := createViaCLIWithArgs(t, src, "write report", "-p", "work", "-t", "urgent")
+	doneID := createViaCLI(t, src, "ship it")
+	_ = runCLI(t, src, "done", strconv.FormatInt(doneID, 10))
+	_ = runCLI(t, src, "project", "add", "empty")
+
+	bundle := runCLI(t, src, "export", "--format", "json")
+	if !strings.Contains(bundle, `"format": "td-export"`) || !strings.Contains(bundle, `"version": 2`) {
+		t.Fatalf("export should write a versioned envelope, got %s", bundle)
+	}
+	if !strings.Contains(bundle, `"exported_at": "2026-03-04T10:00:00Z"`) {
+		t.Fatalf("export should stamp the time of the configured clock, got %s", bundle)
+	}
+	path := filepath.Join(t.TempDir(), "backup.json")
+	if err := os.WriteFile(path, []byte(bundle), 0o600); err != nil {
+		t.Fatalf("write bundle: %v", err)
+	}
+
+	dst := testConfigInDir(t, t.TempDir())
+	out := runCLI(t, dst, "import", path, "--dry-run")
+	if !strings.Contains(out, "dry run: 2 created, 0 overwritten, 0 duplicated, 0 skipped") {
+		t.Fatalf("dry run output = %q", out)
+	}
+	if ls := runCLI(t, dst, "ls"); strings.TrimSpace(ls) != "" {
+		t.Fatalf("dry run should not write, ls = %q", ls)
+	}
+
+	out = runCLI(t, dst, "import", path)
+	if !strings.Contains(out, "imported: 2 created") {
+		t.Fatalf("import output = %q", out)
+	}
+	show := runCLI(t, dst, "show", strconv.FormatInt(id, 10))
+	if !strings.Contains(show, "title: write report") || !strings.Contains(show, "tags: urgent") {
+		t.Fatalf("imported task = %q", show)
+	}
+	if done := runCLI(t, dst, "show", strconv.FormatInt(doneID, 10)); !strings.Contains(done, "status: done") {
+		t.Fatalf("imported done task = %q", done)
+	}
+	if projects := runCLI(t, dst, "project", "ls"); !strings.Contains(projects, "empty") {
+		t.Fatalf("imported projects = %q", projects)
+	}
+
+	out = runCLI(t, dst, "import", path)
+	if !strings.Contains(out, "0 created, 0 overwritten, 0 duplicated, 2 skipped") {
+		t.Fatalf("re-import output = %q", out)
+	}
+	out = runCLI(t, dst, "import", path, "--strategy", "duplicate")
+	if !strings.Contains(out, "2 duplicated") {
+		t.Fatalf("duplicate output = %q", out)
+	}
+	if _, err := runCLIWithErr(dst, "import", path, "--strategy", "merge"); err == nil {
+		t.Fatalf("unknown strategy should fail")
+	}
+}
+
+func TestExportImportTodoTxt(t *testing.T) {
+	src := testConfigInDir(t, t.TempDir())
+	createViaCLIWithArgs(t, src, "write report", "-p", "work", "-P", "P1", "-t", "urgent")
+	doneID := createViaCLI(t, src, "ship it")
+	_ = runCLI(t, src, "done", strconv.FormatInt(doneID, 10))
+
+	out := runCLI(t, src, "export", "--format", "todotxt")
+	lines := nonEmptyLines(out)
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "(A) ") || !strings.Contains(lines[0], "write report +work @urgent") {
+		t.Fatalf("todotxt export = %q", out)
+	}
+	if !strings.HasPrefix(lines[1], "x ") || !strings.HasSuffix(lines[1], "ship it pri:B") {
+		t.Fatalf("todotxt done line = %q", lines[1])
+	}
+
+	path := filepath.Join(t.TempDir(), "todo.txt")
+	if err := os.WriteFile(path, []byte(out+"(C) call mom @phone due:2026-02-25\n"), 0o600); err != nil {
+		t.Fatalf("write todo.txt: %v", err)
+	}
+	dst := testConfigInDir(t, t.TempDir())
+	if out := runCLI(t, dst, "import", "--format", "todotxt", path); !strings.Contains(out, "imported: 3 created") {
+		t.Fatalf("todotxt import output = %q", out)
+	}
+	again := runCLI(t, dst, "export", "--format", "todotxt")
+	if !strings.HasPrefix(again, out) || !strings.Contains(again, "(C) ") || !strings.Contains(again, "call mom @phone due:2026-02-25") {
+		t.Fatalf("todotxt re-export = %q, want prefix %q", again, out)
+	}
+}
+
+func TestExportICSShouldSupportViews(t *testing.T) {
+	cfg := testConfigInDir(t, t.TempDir())
+	todayID := createViaCLIWithArgs(t, cfg, "today task", "-p", "work")
+	laterID := createViaCLIWithArgs(t, cfg, "later task", "-p", "work")
+	dueToday := time.Now().Local().Format("2006-01-02") + " 12:00"
+	_ = runCLI(t, cfg, "due", strconv.FormatInt(todayID, 10), dueToday)
+	_ = runCLI(t, cfg, "due", strconv.FormatInt(laterID, 10), time.Now().Local().AddDate(0, 0, 3).Format("2006-01-02")+" 12:00")
+
+	out := runCLI(t, cfg, "export", "--format", "ics")
+	if strings.Count(out, "BEGIN:VTODO") != 2 {
+		t.Fatalf("ics export = %q", out)
+	}
+
+	out = runCLI(t, cfg, "export", "--format", "ics", "--view", "today", "--events")
+	if strings.Count(out, "BEGIN:VEVENT") != 1 || !strings.Contains(out, "UID:task-"+strconv.FormatInt(todayID, 10)+"@td") {
+		t.Fatalf("ics today events = %q", out)
+	}
+	if _, err := runCLIWithErr(cfg, "export", "--view", "someday"); err == nil {
+		t.Fatalf("unknown view should fail")
+	}
+}
+
+func testConfigInDir(t *testing.T, tdHome string) config.Config {
+	t.Helper()
+	cfg := config.Default()
+	cfg.HomeDir = tdHome
+	cfg.DataDir = filepath.Join(tdHome, "data")
+	cfg.DBPath = filepath.Join(cfg.DataDir, "td.db")
+	cfg.ConfigToml = filepath.Join(tdHome, "config.toml")
+	return cfg
+}
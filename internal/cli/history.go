@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"td/internal/config"
+	"td/internal/domain"
+)
+
+// showHistoryLimit is how many recent events td show prints.
+const showHistoryLimit = 5
+
+func newHistoryCmd(cfg config.Config) *cobra.Command {
+	var limit int
+	cmd := &cobra.Command{
+		Use:   "history <id>",
+		Short: "Show task change history",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ids, err := parseIDs(args)
+			if err != nil {
+				return err
+			}
+			repo, closer, err := openTaskRepo(cfg)
+			if err != nil {
+				return err
+			}
+			defer closeDB(closer)
+
+			events, err := repo.ListEvents(cmd.Context(), ids[0], limit)
+			if err != nil {
+				return err
+			}
+			if len(events) == 0 {
+				if _, err := repo.GetByID(cmd.Context(), ids[0]); err != nil {
+					return err
+				}
+				cmd.Printf("no history for #%d\n", ids[0])
+				return nil
+			}
+			for _, event := range events {
+				cmd.Println(formatEvent(event))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().IntVarP(&limit, "limit", "n", 0, "only show the latest n events")
+	return cmd
+}
+
+func formatEvent(event domain.TaskEvent) string {
+	source := string(event.Source)
+	if source == "" {
+		source = "-"
+	}
+	line := fmt.Sprintf("%s  %-3s  ", event.CreatedAt.In(time.Local).Format("2006-01-02 15:04"), source)
+	switch event.Field {
+	case domain.EventCreated:
+		return line + "created: " + event.NewValue
+	case domain.EventPurged:
+		return line + "purged: " + event.OldValue
+	}
+	return line + event.Field + ": " + formatEventValue(event.Field, event.OldValue) + " -> " + formatEventValue(event.Field, event.NewValue)
+}
+
+func formatEventValue(field, value string) string {
+	if value == "" {
+		return "-"
+	}
+	switch field {
+	case domain.EventDue, domain.EventStart, domain.EventFollowUp:
+		if t, err := time.Parse(time.RFC3339, value); err == nil {
+			return formatDue(&t)
+		}
+	case domain.EventParent, domain.EventBlockedBy:
+		return "#" + strings.ReplaceAll(value, ",", ",#")
+	case domain.EventTitle, domain.EventNotes:
+		return strconv.Quote(value)
+	}
+	return value
+}
@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestHistoryShouldListTaskChanges(t *testing.T) {
+	cfg := testConfigInDir(t, t.TempDir())
+	id := createViaCLIWithArgs(t, cfg, "draft", "-p", "work")
+	idText := strconv.FormatInt(id, 10)
+	_ = runCLI(t, cfg, "edit", idText, "draft report")
+	_ = runCLI(t, cfg, "priority", idText, "P1")
+	_ = runCLI(t, cfg, "done", idText)
+
+	out := runCLI(t, cfg, "history", idText)
+	lines := nonEmptyLines(out)
+	if len(lines) != 4 {
+		t.Fatalf("history output = %q", out)
+	}
+	for i, want := range []string{
+		`cli  created: draft`,
+		`cli  title: "draft" -> "draft report"`,
+		`cli  priority: P2 -> P1`,
+		`cli  status: todo -> done`,
+	} {
+		if !strings.HasSuffix(lines[i], want) {
+			t.Fatalf("history line %d = %q, want suffix %q", i, lines[i], want)
+		}
+	}
+
+	out = runCLI(t, cfg, "history", idText, "-n", "1")
+	if len(nonEmptyLines(out)) != 1 || !strings.Contains(out, "status: todo -> done") {
+		t.Fatalf("history -n 1 output = %q", out)
+	}
+	out = runCLI(t, cfg, "show", idText)
+	if !strings.Contains(out, "history:\n") || !strings.Contains(out, "priority: P2 -> P1") {
+		t.Fatalf("show should include history: %q", out)
+	}
+	if _, err := runCLIWithErr(cfg, "history", "999"); err == nil {
+		t.Fatalf("history of missing task should fail")
+	}
+}
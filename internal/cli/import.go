@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"td/internal/app/usecase"
+	"td/internal/config"
+	"td/internal/domain"
+	"td/internal/taskio"
+)
+
+func newImportCmd(cfg config.Config) *cobra.Command {
+	var (
+		format   string
+		strategy string
+		dryRun   bool
+	)
+	cmd := &cobra.Command{
+		Use:   "import <file|->",
+		Short: "Import tasks from a file or stdin",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format = strings.ToLower(strings.TrimSpace(format))
+			if format != "json" && format != "todotxt" {
+				return fmt.Errorf("unsupported import format %q, use json or todotxt", format)
+			}
+			parsedStrategy, err := usecase.ParseImportStrategy(strategy)
+			if err != nil {
+				return err
+			}
+
+			var r io.Reader = cmd.InOrStdin()
+			if args[0] != "-" {
+				f, err := os.Open(args[0])
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				r = f
+			}
+			var (
+				tasks    []domain.Task
+				projects []string
+			)
+			if format == "todotxt" {
+				tasks, err = taskio.DecodeTodoTxt(r, time.Local)
+				if err != nil {
+					return err
+				}
+			} else {
+				bundle, err := taskio.DecodeBundle(r)
+				if err != nil {
+					return err
+				}
+				tasks, err = bundle.DomainTasks(cfg.Workflow)
+				if err != nil {
+					return err
+				}
+				projects = bundle.Projects
+			}
+
+			repo, closer, err := openTaskRepo(cfg)
+			if err != nil {
+				return err
+			}
+			defer closeDB(closer)
+
+			uc := usecase.ImportTaskUseCase{Repo: repo}
+			result, err := uc.Execute(cmd.Context(), usecase.ImportInput{
+				Tasks:    tasks,
+				Projects: projects,
+				Strategy: parsedStrategy,
+				DryRun:   dryRun,
+			})
+			if err != nil {
+				return err
+			}
+			printImportResult(cmd, result, dryRun)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&format, "format", "json", "import format: json or todotxt")
+	cmd.Flags().StringVar(&strategy, "strategy", string(usecase.ImportSkip), "on ID conflict: skip, overwrite or duplicate")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "report what would change without writing")
+	return cmd
+}
+
+func printImportResult(cmd *cobra.Command, result usecase.ImportResult, dryRun bool) {
+	if dryRun {
+		for _, item := range result.Items {
+			target := "new"
+			if item.TargetID > 0 {
+				target = fmt.Sprintf("#%d", item.TargetID)
+			}
+			cmd.Printf("%-9s %-6s %s\n", item.Action, target, item.Title)
+		}
+	}
+	prefix := "imported"
+	if dryRun {
+		prefix = "dry run"
+	}
+	cmd.Printf(
+		"%s: %d created, %d overwritten, %d duplicated, %d skipped\n",
+		prefix,
+		result.Count(usecase.ImportActionCreate),
+		result.Count(usecase.ImportActionOverwrite),
+		result.Count(usecase.ImportActionDuplicate),
+		result.Count(usecase.ImportActionSkip),
+	)
+}
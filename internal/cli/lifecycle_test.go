@@ -4,6 +4,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"strconv"
+	"strings"
 	"testing"
 
 	"td/internal/config"
@@ -40,3 +41,31 @@ func createViaCLI(t *testing.T, cfg config.Config, title string) int64 {
 	}
 	return id
 }
+
+func TestDBMigrateStatus(t *testing.T) {
+	tdHome := t.TempDir()
+	cfg := config.Default()
+	cfg.HomeDir = tdHome
+	cfg.DataDir = filepath.Join(tdHome, "data")
+	cfg.DBPath = filepath.Join(cfg.DataDir, "td.db")
+
+	out := runCLI(t, cfg, "db", "migrate", "--status")
+	if !strings.Contains(out, "0001 init") || !strings.Contains(out, "pending") {
+		t.Fatalf("status before migrate = %q, want pending init", out)
+	}
+
+	out = runCLI(t, cfg, "db", "migrate")
+	if !strings.Contains(out, "applied 0001 init") {
+		t.Fatalf("migrate output = %q, want applied init", out)
+	}
+
+	out = runCLI(t, cfg, "db", "migrate")
+	if !strings.Contains(out, "schema is up to date") {
+		t.Fatalf("second migrate output = %q, want up to date", out)
+	}
+
+	out = runCLI(t, cfg, "db", "migrate", "--status")
+	if strings.Contains(out, "pending") {
+		t.Fatalf("status after migrate = %q, want no pending", out)
+	}
+}
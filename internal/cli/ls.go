@@ -1,7 +1,6 @@
 package cli
 
 import (
-	"fmt"
 	"sort"
 	"strconv"
 	"strings"
@@ -13,14 +12,37 @@ import (
 	"td/internal/app/usecase"
 	"td/internal/config"
 	"td/internal/domain"
+	"td/internal/query"
+	"td/internal/taskio"
 )
 
 func newLsCmd(cfg config.Config) *cobra.Command {
+	var output outputOptions
 	cmd := &cobra.Command{
-		Use:   "ls [today]",
+		Use:   "ls [today | upcoming | waiting | query...]",
 		Short: "List tasks",
-		Args:  cobra.MaximumNArgs(1),
+		Long: `List tasks, optionally filtered by a query such as:
+
+  td ls 'project:work status:todo,doing due<friday pri<=P2 -tag:later "report"'
+
+Fields: project, status, tag, pri, due, done. Prefix a term with - to
+exclude it; bare or quoted words match title and notes. td ls today,
+td ls upcoming and td ls waiting list those views instead.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := output.validate(taskio.TaskFields); err != nil {
+				return err
+			}
+			expr := strings.TrimSpace(strings.Join(args, " "))
+			view := lsView(expr)
+			var q query.Query
+			if expr != "" && view == "" {
+				parsed, err := query.Parse(expr, cfg.Clock.Now().Local(), cfg.Workflow)
+				if err != nil {
+					return err
+				}
+				q = parsed
+			}
+
 			repo, closer, err := openTaskRepo(cfg)
 			if err != nil {
 				return err
@@ -28,41 +50,65 @@ func newLsCmd(cfg config.Config) *cobra.Command {
 			defer closeDB(closer)
 
 			var tasks []domain.Task
-			if len(args) == 0 {
-				uc := usecase.ListTaskUseCase{Repo: repo}
-				tasks, err = uc.Execute(cmd.Context(), usecase.ListTaskInput{})
+			now := cfg.Clock.Now().Local()
+			if view != "" {
+				queryUC := usecase.NewNavQueryUseCase(repo, cfg.Workflow)
+				tasks, err = queryUC.ListByView(cmd.Context(), view, now, "", false)
 				if err != nil {
 					return err
 				}
-				tasks = filterOutDeleted(tasks)
 			} else {
-				view := strings.ToLower(strings.TrimSpace(args[0]))
-				switch view {
-				case string(domain.ViewToday):
-					queryUC := usecase.NewNavQueryUseCase(repo)
-					tasks, err = queryUC.ListByView(cmd.Context(), domain.ViewToday, time.Now().Local(), "", false)
-					if err != nil {
-						return err
-					}
-				default:
-					return fmt.Errorf("unsupported ls view %q, only today is supported", args[0])
+				uc := usecase.ListTaskUseCase{Repo: repo}
+				tasks, err = uc.Query(cmd.Context(), q)
+				if err != nil {
+					return err
 				}
+				sortTasksForLS(tasks, cfg.Workflow)
 			}
-			if len(args) == 0 {
-				sortTasksForLS(tasks)
+			tasks, depths := domain.NestSubtasks(tasks)
+			if !output.legacy() {
+				return writeRecords(cmd.OutOrStdout(), output, taskio.TaskFields, taskRecords(tasks), false)
 			}
-			for _, task := range tasks {
-				cmd.Println(formatTaskLine(task.ID, string(task.Status), task.Title, task.Project, task.DueAt, task.Priority))
+			for i, task := range tasks {
+				title := strings.Repeat("  ", depths[i]) + task.Title
+				line := formatTaskLine(task.ID, string(task.Status), title, task.Project, task.DueAt, task.Priority)
+				if task.Subtasks.Total > 0 {
+					line += "  " + formatProgress(task.Subtasks)
+				}
+				if task.IsBlocked() {
+					line += "  blocked by " + formatIDList(task.BlockedBy)
+				}
+				if task.IsDeferred(now) {
+					line += "  starts " + formatDue(task.StartAt)
+				}
+				if task.Status == domain.StatusWaiting {
+					line += " " + formatWaiting(task.WaitingOn, task.FollowUpAt)
+				}
+				if len(task.Tags) > 0 {
+					line += "  " + formatTags(task.Tags)
+				}
+				cmd.Println(line)
 			}
 			return nil
 		},
 	}
+	bindOutputFlags(cmd, &output)
 	return cmd
 }
 
+// lsView returns the nav view named by expr, if it names one.
+func lsView(expr string) domain.View {
+	for _, view := range []domain.View{domain.ViewToday, domain.ViewUpcoming, domain.ViewWaiting} {
+		if strings.EqualFold(expr, string(view)) {
+			return view
+		}
+	}
+	return ""
+}
+
 const (
 	lsIDWidth       = 5
-	lsStatusWidth   = 8
+	lsStatusWidth   = 9
 	lsTitleWidth    = 34
 	lsProjectWidth  = 16
 	lsDueWidth      = 16
@@ -121,6 +167,26 @@ func formatDue(dueAt *time.Time) string {
 	return dueAt.In(time.Local).Format("2006-01-02 15:04")
 }
 
+func formatTags(tags []string) string {
+	parts := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		parts = append(parts, "#"+tag)
+	}
+	return strings.Join(parts, " ")
+}
+
+func formatProgress(progress domain.Progress) string {
+	return strconv.Itoa(progress.Done) + "/" + strconv.Itoa(progress.Total)
+}
+
+func formatIDList(ids []int64) string {
+	parts := make([]string, 0, len(ids))
+	for _, id := range ids {
+		parts = append(parts, "#"+strconv.FormatInt(id, 10))
+	}
+	return strings.Join(parts, ",")
+}
+
 func formatPriority(priority string) string {
 	priority = domain.NormalizePriority(priority)
 	if !domain.IsValidPriority(priority) {
@@ -129,18 +195,7 @@ func formatPriority(priority string) string {
 	return priority
 }
 
-func filterOutDeleted(tasks []domain.Task) []domain.Task {
-	out := make([]domain.Task, 0, len(tasks))
-	for _, task := range tasks {
-		if task.Status == domain.StatusDeleted {
-			continue
-		}
-		out = append(out, task)
-	}
-	return out
-}
-
-func sortTasksForLS(tasks []domain.Task) {
+func sortTasksForLS(tasks []domain.Task, workflow domain.Workflow) {
 	sort.SliceStable(tasks, func(i, j int) bool {
 		left := tasks[i]
 		right := tasks[j]
@@ -157,28 +212,11 @@ func sortTasksForLS(tasks []domain.Task) {
 			return lp < rp
 		}
 
-		ls := lsStatusRank(left.Status)
-		rs := lsStatusRank(right.Status)
+		ls := workflow.Rank(left.Status)
+		rs := workflow.Rank(right.Status)
 		if ls != rs {
 			return ls < rs
 		}
 		return left.ID < right.ID
 	})
 }
-
-func lsStatusRank(status domain.Status) int {
-	switch status {
-	case domain.StatusDoing:
-		return 0
-	case domain.StatusTodo:
-		return 1
-	case domain.StatusDone:
-		return 2
-	case domain.StatusInbox:
-		return 3
-	case domain.StatusDeleted:
-		return 4
-	default:
-		return 5
-	}
-}
@@ -178,6 +178,35 @@ func TestFormatTaskLineShouldAlignColumnsWithoutHeader(t *testing.T) {
 	}
 }
 
+func TestLsQueryShouldFilterTasks(t *testing.T) {
+	tdHome := t.TempDir()
+	cfg := config.Default()
+	cfg.HomeDir = tdHome
+	cfg.DataDir = filepath.Join(tdHome, "data")
+	cfg.DBPath = filepath.Join(cfg.DataDir, "td.db")
+
+	createViaCLIWithArgs(t, cfg, "weekly report", "-p", "work", "-P", "P1")
+	createViaCLIWithArgs(t, cfg, "later report", "-p", "work", "-P", "P2", "-t", "later")
+	createViaCLIWithArgs(t, cfg, "low report", "-p", "work", "-P", "P4")
+	createViaCLIWithArgs(t, cfg, "home report", "-p", "home", "-P", "P1")
+
+	out := runCLI(t, cfg, "ls", `project:work pri<=P2 -tag:later "report"`)
+	lines := nonEmptyLines(out)
+	if len(lines) != 1 || !strings.Contains(lines[0], "weekly report") {
+		t.Fatalf("ls query output = %q, want only weekly report", out)
+	}
+
+	out = runCLI(t, cfg, "ls", "project:work", "tag:later")
+	if lines := nonEmptyLines(out); len(lines) != 1 || !strings.Contains(lines[0], "later report") {
+		t.Fatalf("ls multi-arg query output = %q, want only later report", out)
+	}
+
+	_, err := runCLIWithErr(cfg, "ls", "due<someday")
+	if err == nil || !strings.Contains(err.Error(), `unknown date "someday"`) {
+		t.Fatalf("ls invalid query error = %v", err)
+	}
+}
+
 func createViaCLIWithArgs(t *testing.T, cfg config.Config, title string, args ...string) int64 {
 	t.Helper()
 	argv := []string{"add", title}
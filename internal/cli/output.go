@@ -0,0 +1,248 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"td/internal/domain"
+	"td/internal/taskio"
+)
+
+const (
+	outputTable  = "table"
+	outputJSON   = "json"
+	outputNDJSON = "ndjson"
+	outputCSV    = "csv"
+	outputTSV    = "tsv"
+)
+
+type outputOptions struct {
+	format string
+	fields []string
+}
+
+func bindOutputFlags(cmd *cobra.Command, opts *outputOptions) {
+	cmd.Flags().StringVarP(&opts.format, "output", "o", outputTable, "output format: table, json, ndjson, csv or tsv")
+	cmd.Flags().StringSliceVar(&opts.fields, "fields", nil, "comma separated fields to print")
+}
+
+// legacy reports whether the command should keep its human-oriented text.
+func (o outputOptions) legacy() bool {
+	return o.format == outputTable && len(o.fields) == 0
+}
+
+func (o *outputOptions) validate(available []string) error {
+	o.format = strings.ToLower(strings.TrimSpace(o.format))
+	switch o.format {
+	case "":
+		o.format = outputTable
+	case outputTable, outputJSON, outputNDJSON, outputCSV, outputTSV:
+	default:
+		return fmt.Errorf("unsupported output %q, use table, json, ndjson, csv or tsv", o.format)
+	}
+	fields := make([]string, 0, len(o.fields))
+	for _, field := range o.fields {
+		field = strings.ToLower(strings.TrimSpace(field))
+		if field == "" {
+			continue
+		}
+		if !containsField(available, field) {
+			return fmt.Errorf("unknown field %q, available: %s", field, strings.Join(available, ","))
+		}
+		fields = append(fields, field)
+	}
+	o.fields = fields
+	return nil
+}
+
+type outputField struct {
+	Name  string
+	Value any
+}
+
+// outputRecord is an ordered JSON object.
+type outputRecord []outputField
+
+func (r outputRecord) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, field := range r {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(field.Name)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(field.Value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func (o outputOptions) project(record outputRecord) outputRecord {
+	if len(o.fields) == 0 {
+		return record
+	}
+	out := make(outputRecord, 0, len(o.fields))
+	for _, name := range o.fields {
+		for _, field := range record {
+			if field.Name == name {
+				out = append(out, field)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// writeRecords renders records; single prints a JSON object instead of an
+// array, for commands that show exactly one item.
+func writeRecords(w io.Writer, o outputOptions, names []string, records []outputRecord, single bool) error {
+	if len(o.fields) > 0 {
+		names = o.fields
+	}
+	projected := make([]outputRecord, 0, len(records))
+	for _, record := range records {
+		projected = append(projected, o.project(record))
+	}
+
+	switch o.format {
+	case outputJSON:
+		var (
+			data []byte
+			err  error
+		)
+		if single && len(projected) == 1 {
+			data, err = json.MarshalIndent(projected[0], "", "  ")
+		} else {
+			data, err = json.MarshalIndent(projected, "", "  ")
+		}
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "%s\n", data)
+		return err
+	case outputNDJSON:
+		for _, record := range projected {
+			data, err := json.Marshal(record)
+			if err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintf(w, "%s\n", data); err != nil {
+				return err
+			}
+		}
+		return nil
+	case outputCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(names); err != nil {
+			return err
+		}
+		for _, record := range projected {
+			if err := cw.Write(recordStrings(record)); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	case outputTSV:
+		if _, err := fmt.Fprintln(w, strings.Join(names, "\t")); err != nil {
+			return err
+		}
+		for _, record := range projected {
+			values := recordStrings(record)
+			for i := range values {
+				values[i] = flattenLSField(values[i])
+			}
+			if _, err := fmt.Fprintln(w, strings.Join(values, "\t")); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		if _, err := fmt.Fprintln(tw, strings.ToUpper(strings.Join(names, "\t"))); err != nil {
+			return err
+		}
+		for _, record := range projected {
+			values := recordStrings(record)
+			for i := range values {
+				values[i] = flattenLSField(values[i])
+				if values[i] == "" {
+					values[i] = "-"
+				}
+			}
+			if _, err := fmt.Fprintln(tw, strings.Join(values, "\t")); err != nil {
+				return err
+			}
+		}
+		return tw.Flush()
+	}
+}
+
+func recordStrings(record outputRecord) []string {
+	out := make([]string, 0, len(record))
+	for _, field := range record {
+		out = append(out, outputValueString(field.Value))
+	}
+	return out
+}
+
+func outputValueString(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case []string:
+		return strings.Join(v, ",")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func taskRecord(task domain.Task) outputRecord {
+	data := taskio.FromDomain(task)
+	record := make(outputRecord, 0, len(taskio.TaskFields))
+	for _, name := range taskio.TaskFields {
+		value, _ := data.Field(name)
+		record = append(record, outputField{Name: name, Value: value})
+	}
+	return record
+}
+
+func taskRecords(tasks []domain.Task) []outputRecord {
+	out := make([]outputRecord, 0, len(tasks))
+	for _, task := range tasks {
+		out = append(out, taskRecord(task))
+	}
+	return out
+}
+
+func containsField(items []string, name string) bool {
+	for _, item := range items {
+		if item == name {
+			return true
+		}
+	}
+	return false
+}
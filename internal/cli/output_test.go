@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"td/internal/config"
+	"td/internal/taskio"
+)
+
+func TestLsAndShowShouldSupportMachineReadableOutput(t *testing.T) {
+	tdHome := t.TempDir()
+	cfg := config.Default()
+	cfg.HomeDir = tdHome
+	cfg.DataDir = filepath.Join(tdHome, "data")
+	cfg.DBPath = filepath.Join(cfg.DataDir, "td.db")
+
+	id := createViaCLIWithArgs(t, cfg, "write, report", "-p", "work", "-t", "urgent")
+	createViaCLI(t, cfg, "plain task")
+	_ = runCLI(t, cfg, "due", strconv.FormatInt(id, 10), "2026-02-24 08:00")
+
+	out := runCLI(t, cfg, "ls", "-o", "json")
+	var tasks []taskio.Task
+	if err := json.Unmarshal([]byte(out), &tasks); err != nil {
+		t.Fatalf("decode ls json: %v\n%s", err, out)
+	}
+	if len(tasks) != 2 {
+		t.Fatalf("ls json task count = %d, want 2", len(tasks))
+	}
+	var first taskio.Task
+	for _, task := range tasks {
+		if task.ID == id {
+			first = task
+		}
+	}
+	wantDue := mustParseLocalTime(t, "2026-02-24 08:00").UTC().Format(time.RFC3339)
+	if first.DueAt == nil || *first.DueAt != wantDue {
+		t.Fatalf("due_at = %v, want %s", first.DueAt, wantDue)
+	}
+	if first.DoneAt != nil || len(first.Tags) != 1 || first.Tags[0] != "urgent" {
+		t.Fatalf("task json = %+v", first)
+	}
+	if !strings.Contains(out, `"tags": []`) {
+		t.Fatalf("tags should be an empty array for untagged tasks, got %s", out)
+	}
+
+	out = runCLI(t, cfg, "ls", "--output", "ndjson", "--fields", "id,title")
+	lines := nonEmptyLines(out)
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], `{"id":`) || strings.Contains(lines[0], "status") {
+		t.Fatalf("ndjson output = %q", out)
+	}
+
+	out = runCLI(t, cfg, "ls", "-o", "csv", "--fields", "title,project", "project:work")
+	rows, err := csv.NewReader(strings.NewReader(out)).ReadAll()
+	if err != nil {
+		t.Fatalf("decode csv: %v", err)
+	}
+	if len(rows) != 2 || rows[0][0] != "title" || rows[1][0] != "write, report" || rows[1][1] != "work" {
+		t.Fatalf("csv rows = %q", rows)
+	}
+
+	out = runCLI(t, cfg, "ls", "-o", "tsv", "--fields", "id,status")
+	if lines := nonEmptyLines(out); len(lines) != 3 || lines[0] != "id\tstatus" {
+		t.Fatalf("tsv output = %q", out)
+	}
+
+	out = runCLI(t, cfg, "show", strconv.FormatInt(id, 10), "-o", "json")
+	var shown taskio.Task
+	if err := json.Unmarshal([]byte(out), &shown); err != nil {
+		t.Fatalf("decode show json: %v\n%s", err, out)
+	}
+	if shown.ID != id || shown.Title != "write, report" || shown.Project != "work" {
+		t.Fatalf("show json = %+v", shown)
+	}
+	if _, err := time.Parse(time.RFC3339, shown.CreatedAt); err != nil {
+		t.Fatalf("created_at %q should be RFC3339: %v", shown.CreatedAt, err)
+	}
+
+	out = runCLI(t, cfg, "show", strconv.FormatInt(id, 10), "--fields", "title,priority")
+	if lines := nonEmptyLines(out); len(lines) != 2 || !strings.HasPrefix(lines[0], "TITLE") {
+		t.Fatalf("show table output = %q", out)
+	}
+
+	if _, err := runCLIWithErr(cfg, "ls", "--fields", "colour"); err == nil || !strings.Contains(err.Error(), `unknown field "colour"`) {
+		t.Fatalf("unknown field error = %v", err)
+	}
+	if _, err := runCLIWithErr(cfg, "ls", "-o", "yaml"); err == nil || !strings.Contains(err.Error(), `unsupported output "yaml"`) {
+		t.Fatalf("unsupported output error = %v", err)
+	}
+}
+
+func TestProjectLsAndConfigAIShowShouldSupportJSONOutput(t *testing.T) {
+	cfg := testConfigForAI(t)
+	runCLI(t, cfg, "project", "add", "work")
+	runCLI(t, cfg, "config", "ai", "set", "provider", "deepseek")
+	runCLI(t, cfg, "config", "ai", "set", "api-key", "sk-123456789")
+
+	out := runCLI(t, cfg, "project", "ls", "-o", "json")
+	var projects []map[string]string
+	if err := json.Unmarshal([]byte(out), &projects); err != nil {
+		t.Fatalf("decode project json: %v\n%s", err, out)
+	}
+	if len(projects) != 1 || projects[0]["name"] != "work" {
+		t.Fatalf("projects = %v", projects)
+	}
+
+	out = runCLI(t, cfg, "config", "ai", "show", "-o", "json")
+	var shown map[string]any
+	if err := json.Unmarshal([]byte(out), &shown); err != nil {
+		t.Fatalf("decode ai json: %v\n%s", err, out)
+	}
+	if shown["provider"] != "deepseek" || shown["timeout"] != nil {
+		t.Fatalf("ai json = %v", shown)
+	}
+	if strings.Contains(out, "sk-123456789") {
+		t.Fatalf("ai json should mask api key, got %s", out)
+	}
+}
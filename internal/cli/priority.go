@@ -32,7 +32,7 @@ func newPriorityCmd(cfg config.Config) *cobra.Command {
 			}
 			defer closeDB(closer)
 
-			uc := usecase.UpdateTaskUseCase{Repo: repo}
+			uc := usecase.UpdateTaskUseCase{Repo: repo, Workflow: cfg.Workflow}
 			if err := uc.SetPriority(cmd.Context(), ids[0], priority); err != nil {
 				return err
 			}
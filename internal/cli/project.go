@@ -19,11 +19,17 @@ func newProjectCmd(cfg config.Config) *cobra.Command {
 	return cmd
 }
 
+var projectFields = []string{"name"}
+
 func newProjectLsCmd(cfg config.Config) *cobra.Command {
-	return &cobra.Command{
+	var output outputOptions
+	cmd := &cobra.Command{
 		Use:   "ls",
 		Short: "List projects",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := output.validate(projectFields); err != nil {
+				return err
+			}
 			repo, closer, err := openTaskRepo(cfg)
 			if err != nil {
 				return err
@@ -35,12 +41,21 @@ func newProjectLsCmd(cfg config.Config) *cobra.Command {
 			if err != nil {
 				return err
 			}
+			if !output.legacy() {
+				records := make([]outputRecord, 0, len(projects))
+				for _, name := range projects {
+					records = append(records, outputRecord{{Name: "name", Value: name}})
+				}
+				return writeRecords(cmd.OutOrStdout(), output, projectFields, records, false)
+			}
 			for _, name := range projects {
 				cmd.Println(name)
 			}
 			return nil
 		},
 	}
+	bindOutputFlags(cmd, &output)
+	return cmd
 }
 
 func newProjectAddCmd(cfg config.Config) *cobra.Command {
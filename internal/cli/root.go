@@ -2,38 +2,121 @@ package cli
 
 import (
 	"database/sql"
+	"fmt"
 	"os"
 
 	"github.com/spf13/cobra"
 
 	"td/internal/config"
+	"td/internal/domain"
+	"td/internal/repo"
 	"td/internal/repo/sqlite"
+	"td/internal/timeutil"
 )
 
 func NewRootCmd(cfg config.Config) *cobra.Command {
+	workflow, workflowErr := loadWorkflow(cfg)
+	cfg.Workflow = workflow
+	if cfg.Clock == nil {
+		cfg.Clock = timeutil.SystemClock{}
+	}
 	cmd := &cobra.Command{
 		Use: "td",
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SetContext(repo.WithSource(cmd.Context(), domain.SourceCLI))
+			if workflowErr != nil && !skipsWorkflow(cmd) {
+				fmt.Fprintf(cmd.ErrOrStderr(), "warning: %v, using the built-in workflow\n", workflowErr)
+			}
+			if !skipsWorkflow(cmd) {
+				warnUnknownStatuses(cmd, cfg)
+			}
+			return nil
+		},
 	}
 	cmd.AddCommand(newAddCmd(cfg))
 	cmd.AddCommand(newProjectCmd(cfg))
+	cmd.AddCommand(newTagCmd(cfg))
 	cmd.AddCommand(newLsCmd(cfg))
 	cmd.AddCommand(newShowCmd(cfg))
+	cmd.AddCommand(newSearchCmd(cfg))
+	cmd.AddCommand(newHistoryCmd(cfg))
 	cmd.AddCommand(newDoneCmd(cfg))
 	cmd.AddCommand(newReopenCmd(cfg))
 	cmd.AddCommand(newEditCmd(cfg))
 	cmd.AddCommand(newTodayCmd(cfg))
 	cmd.AddCommand(newDueCmd(cfg))
+	cmd.AddCommand(newDeferCmd(cfg))
+	cmd.AddCommand(newSnoozeCmd(cfg))
+	cmd.AddCommand(newWaitCmd(cfg))
+	cmd.AddCommand(newStatusCmd(cfg))
+	cmd.AddCommand(newEveryCmd(cfg))
+	cmd.AddCommand(newBlockCmd(cfg))
+	cmd.AddCommand(newUnblockCmd(cfg))
 	cmd.AddCommand(newPriorityCmd(cfg))
 	cmd.AddCommand(newRmCmd(cfg))
 	cmd.AddCommand(newRestoreCmd(cfg))
 	cmd.AddCommand(newPurgeCmd(cfg))
+	cmd.AddCommand(newUndoCmd(cfg))
+	cmd.AddCommand(newRedoCmd(cfg))
+	cmd.AddCommand(newExportCmd(cfg))
+	cmd.AddCommand(newImportCmd(cfg))
 	cmd.AddCommand(newUICmd(cfg))
 	cmd.AddCommand(newVersionCmd())
 	cmd.AddCommand(newUpgradeCmd(cfg))
 	cmd.AddCommand(newConfigCmd(cfg))
+	cmd.AddCommand(newAICmd(cfg))
+	cmd.AddCommand(newDBCmd(cfg))
 	return cmd
 }
 
+// loadWorkflow reads the [workflow] section of config.toml. A missing
+// section gives the built-in workflow, and so does a broken one, along
+// with the error to warn about.
+func loadWorkflow(cfg config.Config) (domain.Workflow, error) {
+	userCfg, err := config.LoadUserConfig(cfg.ConfigToml)
+	if err != nil {
+		return domain.DefaultWorkflow(), err
+	}
+	workflow, err := userCfg.Workflow.Workflow()
+	if err != nil {
+		return domain.DefaultWorkflow(), fmt.Errorf("%s: %w", cfg.ConfigToml, err)
+	}
+	return workflow, nil
+}
+
+// skipsWorkflow reports whether cmd does without the workflow, so td
+// config, which can fix a broken [workflow] section, and td version run
+// without warning about it.
+func skipsWorkflow(cmd *cobra.Command) bool {
+	top := cmd
+	for top.HasParent() && top.Parent().HasParent() {
+		top = top.Parent()
+	}
+	return top.Name() == "config" || top.Name() == "version"
+}
+
+// warnUnknownStatuses names the tasks left in statuses the workflow does
+// not define; they stay readable but drop out of the default views.
+func warnUnknownStatuses(cmd *cobra.Command, cfg config.Config) {
+	if _, err := os.Stat(cfg.DBPath); err != nil {
+		return
+	}
+	// Connect rather than Open: a warning must not migrate the schema
+	// behind td db migrate.
+	db, err := sqlite.Connect(cfg.DBPath)
+	if err != nil {
+		return
+	}
+	defer db.Close()
+	unknown, err := sqlite.NewTaskRepository(db, cfg.Workflow).UnknownStatuses(cmd.Context())
+	if err != nil {
+		return
+	}
+	for _, err := range unknown {
+		fmt.Fprintf(cmd.ErrOrStderr(), "warning: %v; add it to config.toml or move the tasks with td status\n", err)
+	}
+}
+
 func openTaskRepo(cfg config.Config) (*sqlite.TaskRepository, func() error, error) {
 	if err := os.MkdirAll(cfg.DataDir, 0o755); err != nil {
 		return nil, nil, err
@@ -42,7 +125,7 @@ func openTaskRepo(cfg config.Config) (*sqlite.TaskRepository, func() error, erro
 	if err != nil {
 		return nil, nil, err
 	}
-	return sqlite.NewTaskRepository(db), func() error {
+	return sqlite.NewTaskRepository(db, cfg.Workflow), func() error {
 		return db.Close()
 	}, nil
 }
@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"td/internal/app/usecase"
+	"td/internal/config"
+	"td/internal/domain"
+	"td/internal/query"
+	"td/internal/repo"
+	"td/internal/taskio"
+)
+
+func newSearchCmd(cfg config.Config) *cobra.Command {
+	var (
+		output outputOptions
+		limit  int
+	)
+	cmd := &cobra.Command{
+		Use:   "search <query...>",
+		Short: "Search task titles and notes",
+		Long: `Search task titles and notes, best match first:
+
+  td search 周报
+  td search 'report project:work -tag:later'
+
+Words are matched anywhere in the title or notes; the other query terms
+of td ls narrow the result.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := output.validate(taskio.TaskFields); err != nil {
+				return err
+			}
+			q, err := query.Parse(strings.Join(args, " "), cfg.Clock.Now().Local(), cfg.Workflow)
+			if err != nil {
+				return err
+			}
+
+			taskRepo, closer, err := openTaskRepo(cfg)
+			if err != nil {
+				return err
+			}
+			defer closeDB(closer)
+
+			uc := usecase.ListTaskUseCase{Repo: taskRepo}
+			hits, err := uc.Search(cmd.Context(), q, limit)
+			if err != nil {
+				return err
+			}
+			if !output.legacy() {
+				tasks := make([]domain.Task, 0, len(hits))
+				for _, hit := range hits {
+					tasks = append(tasks, hit.Task)
+				}
+				return writeRecords(cmd.OutOrStdout(), output, taskio.TaskFields, taskRecords(tasks), false)
+			}
+			color := isTerminal(cmd.OutOrStdout())
+			for _, hit := range hits {
+				task := hit.Task
+				cmd.Println(formatTaskLine(task.ID, string(task.Status), task.Title, task.Project, task.DueAt, task.Priority))
+				cmd.Println("      " + formatSnippet(hit.Snippet, color))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().IntVarP(&limit, "limit", "n", 20, "maximum number of results")
+	bindOutputFlags(cmd, &output)
+	return cmd
+}
+
+// formatSnippet renders the match markers as bold text on a terminal and
+// as brackets otherwise.
+func formatSnippet(snippet string, color bool) string {
+	start, end := "[", "]"
+	if color {
+		start, end = "\x1b[1m", "\x1b[0m"
+	}
+	snippet = strings.ReplaceAll(snippet, repo.SnippetStart, start)
+	snippet = strings.ReplaceAll(snippet, repo.SnippetEnd, end)
+	return flattenLSField(snippet)
+}
@@ -0,0 +1,33 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSearchShouldListHighlightedMatches(t *testing.T) {
+	cfg := testConfigInDir(t, t.TempDir())
+	_ = createViaCLIWithArgs(t, cfg, "整理周报模板", "-p", "work")
+	_ = createViaCLIWithArgs(t, cfg, "周报发给老板", "-p", "home")
+	_ = createViaCLIWithArgs(t, cfg, "buy milk")
+
+	out := runCLI(t, cfg, "search", "周报模板")
+	lines := nonEmptyLines(out)
+	if len(lines) != 2 || !strings.Contains(lines[0], "整理周报模板") || strings.TrimSpace(lines[1]) != "整理[周报模板]" {
+		t.Fatalf("search output = %q", out)
+	}
+
+	out = runCLI(t, cfg, "search", "周报", "project:home")
+	lines = nonEmptyLines(out)
+	if len(lines) != 2 || strings.TrimSpace(lines[1]) != "[周报]发给老板" {
+		t.Fatalf("search with query terms output = %q", out)
+	}
+
+	out = runCLI(t, cfg, "search", "周报", "-o", "json", "--fields", "title")
+	if !strings.Contains(out, `"title": "整理周报模板"`) || !strings.Contains(out, `"title": "周报发给老板"`) {
+		t.Fatalf("search json output = %q", out)
+	}
+	if _, err := runCLIWithErr(cfg, "search", "project:work"); err == nil {
+		t.Fatalf("search without words should fail")
+	}
+}
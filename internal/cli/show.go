@@ -2,18 +2,24 @@ package cli
 
 import (
 	"strconv"
+	"strings"
 
 	"github.com/spf13/cobra"
 
 	"td/internal/config"
+	"td/internal/taskio"
 )
 
 func newShowCmd(cfg config.Config) *cobra.Command {
+	var output outputOptions
 	cmd := &cobra.Command{
 		Use:   "show <id>",
 		Short: "Show task detail",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := output.validate(taskio.TaskFields); err != nil {
+				return err
+			}
 			id, err := strconv.ParseInt(args[0], 10, 64)
 			if err != nil {
 				return err
@@ -29,16 +35,57 @@ func newShowCmd(cfg config.Config) *cobra.Command {
 			if err != nil {
 				return err
 			}
+			if !output.legacy() {
+				return writeRecords(cmd.OutOrStdout(), output, taskio.TaskFields, []outputRecord{taskRecord(task)}, true)
+			}
 			cmd.Printf("%d\n", task.ID)
 			cmd.Printf("title: %s\n", task.Title)
 			cmd.Printf("status: %s\n", task.Status)
 			cmd.Printf("project: %s\n", task.Project)
 			cmd.Printf("priority: %s\n", task.Priority)
+			if task.StartAt != nil {
+				cmd.Printf("start: %s\n", formatDue(task.StartAt))
+			}
+			if task.WaitingOn != "" {
+				cmd.Printf("waiting on: %s\n", task.WaitingOn)
+			}
+			if task.FollowUpAt != nil {
+				cmd.Printf("follow up: %s\n", formatDue(task.FollowUpAt))
+			}
+			if task.SnoozeCount > 0 {
+				cmd.Printf("snoozed: %d\n", task.SnoozeCount)
+			}
+			if task.ParentID != 0 {
+				cmd.Printf("parent: #%d\n", task.ParentID)
+			}
+			if task.Subtasks.Total > 0 {
+				cmd.Printf("subtasks: %s\n", formatProgress(task.Subtasks))
+			}
+			if task.IsBlocked() {
+				cmd.Printf("blocked by: %s\n", formatIDList(task.BlockedBy))
+			}
+			if !task.Recurrence.IsZero() {
+				cmd.Printf("repeat: %s\n", task.Recurrence.Describe())
+			}
+			if len(task.Tags) > 0 {
+				cmd.Printf("tags: %s\n", strings.Join(task.Tags, ", "))
+			}
 			if task.Notes != "" {
 				cmd.Printf("notes: %s\n", task.Notes)
 			}
+			events, err := repo.ListEvents(cmd.Context(), task.ID, showHistoryLimit)
+			if err != nil {
+				return err
+			}
+			if len(events) > 0 {
+				cmd.Println("history:")
+				for _, event := range events {
+					cmd.Printf("  %s\n", formatEvent(event))
+				}
+			}
 			return nil
 		},
 	}
+	bindOutputFlags(cmd, &output)
 	return cmd
 }
@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"td/internal/app/usecase"
+	"td/internal/config"
+)
+
+func newSnoozeCmd(cfg config.Config) *cobra.Command {
+	var until string
+	cmd := &cobra.Command{
+		Use:   "snooze <id...> [2h|1d|2w|datetime]",
+		Short: "Push due datetimes back",
+		Long: `Push the due datetime of one or more tasks back, by 1d unless told:
+
+  td snooze 4 7 9
+  td snooze 4 2w
+  td snooze 4 7 -- next week
+  td snooze 4 --until "2026-03-15 09:00"
+
+Offsets count from each task's due time, or from the same time today when
+it is overdue. A datetime goes after -- or in --until, so numbers in it are
+never read as ids. Each snooze is counted and shown by td show.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ids, rule, separated, err := splitSnoozeArgs(args, cmd.ArgsLenAtDash(), until)
+			if err != nil {
+				return err
+			}
+			if len(ids) == 0 {
+				return fmt.Errorf("snooze needs at least one task id")
+			}
+			snooze, err := usecase.ParseSnooze(rule, cfg.Clock, time.Local)
+			if !separated && (err != nil || snooze.Until != nil) {
+				return fmt.Errorf("snooze %q is not 2h, 1d or 2w; put a datetime after -- or in --until, e.g. td snooze 4 -- next week", rule)
+			}
+			if err != nil {
+				return err
+			}
+
+			repo, closer, err := openTaskRepo(cfg)
+			if err != nil {
+				return err
+			}
+			defer closeDB(closer)
+
+			uc := usecase.UpdateTaskUseCase{Repo: repo, Workflow: cfg.Workflow}
+			tasks, err := uc.Snooze(cmd.Context(), ids, snooze, cfg.Clock.Now().Local())
+			if err != nil {
+				return err
+			}
+			for _, task := range tasks {
+				cmd.Printf("snoozed #%d to %s (%s)\n", task.ID, formatDue(task.DueAt), formatSnoozeCount(task.SnoozeCount))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&until, "until", "", `new due datetime, e.g. "2026-03-15 09:00" or "next week"`)
+	return cmd
+}
+
+// splitSnoozeArgs reads ids up to -- (dash) or through every argument when
+// until is given, and reports whether the rule was separated that way.
+// Otherwise the leading numeric arguments are ids and the rest is the rule.
+func splitSnoozeArgs(args []string, dash int, until string) ([]int64, string, bool, error) {
+	if until != "" {
+		if dash >= 0 {
+			return nil, "", false, fmt.Errorf("use either -- or --until, not both")
+		}
+		ids, err := parseIDs(args)
+		return ids, until, true, err
+	}
+	if dash >= 0 {
+		ids, err := parseIDs(args[:dash])
+		return ids, strings.Join(args[dash:], " "), true, err
+	}
+	ids := make([]int64, 0, len(args))
+	for i, arg := range args {
+		id, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil {
+			return ids, strings.Join(args[i:], " "), false, nil
+		}
+		ids = append(ids, id)
+	}
+	return ids, "", false, nil
+}
+
+func formatSnoozeCount(count int) string {
+	if count == 1 {
+		return "snoozed once"
+	}
+	return fmt.Sprintf("snoozed %d times", count)
+}
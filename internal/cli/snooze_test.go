@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestSnoozeShouldPushDueOfSeveralTasks(t *testing.T) {
+	cfg := testConfigInDir(t, t.TempDir())
+	a := strconv.FormatInt(createViaCLIWithArgs(t, cfg, "pay rent", "--due", "2026-03-04 09:00"), 10)
+	b := strconv.FormatInt(createViaCLIWithArgs(t, cfg, "call bank", "--due", "2099-03-04 09:00"), 10)
+
+	out := runCLI(t, cfg, "snooze", a, b, "2w")
+	if !strings.Contains(out, "snoozed #"+b+" to 2099-03-18 09:00 (snoozed once)") {
+		t.Fatalf("snooze output = %q", out)
+	}
+	_ = runCLI(t, cfg, "snooze", b)
+	out = runCLI(t, cfg, "show", b)
+	if !strings.Contains(out, "snoozed: 2") || !strings.Contains(out, "2099-03-19 09:00") {
+		t.Fatalf("show output = %q", out)
+	}
+
+	out = runCLI(t, cfg, "undo")
+	if !strings.Contains(out, "snooze #"+b) {
+		t.Fatalf("undo output = %q", out)
+	}
+	if _, err := runCLIWithErr(cfg, "snooze", a, "someday"); err == nil {
+		t.Fatalf("unknown snooze should fail")
+	}
+}
+
+func TestSnoozeShouldRequireSeparatorBeforeDatetime(t *testing.T) {
+	cfg := testConfigInDir(t, t.TempDir())
+	a := strconv.FormatInt(createViaCLIWithArgs(t, cfg, "pay rent", "--due", "2099-03-04 09:00"), 10)
+	b := strconv.FormatInt(createViaCLIWithArgs(t, cfg, "call bank", "--due", "2099-03-04 09:00"), 10)
+
+	if _, err := runCLIWithErr(cfg, "snooze", a, "15", "mar", "2099"); err == nil || !strings.Contains(err.Error(), "after --") {
+		t.Fatalf("datetime without separator should fail, err=%v", err)
+	}
+	out := runCLI(t, cfg, "show", a)
+	if strings.Contains(out, "snoozed:") {
+		t.Fatalf("failed snooze should not touch the task, show=%q", out)
+	}
+
+	out = runCLI(t, cfg, "snooze", a, "--", "2099-03-15", "09:00")
+	if !strings.Contains(out, "snoozed #"+a+" to 2099-03-15 09:00") {
+		t.Fatalf("snooze -- output = %q", out)
+	}
+	out = runCLI(t, cfg, "snooze", a, b, "--until", "2099-03-20 10:00")
+	if !strings.Contains(out, "snoozed #"+a+" to 2099-03-20 10:00") || !strings.Contains(out, "snoozed #"+b+" to 2099-03-20 10:00") {
+		t.Fatalf("snooze --until output = %q", out)
+	}
+	if _, err := runCLIWithErr(cfg, "snooze", a, "--until", "fri", "--", "2h"); err == nil {
+		t.Fatalf("-- with --until should fail")
+	}
+}
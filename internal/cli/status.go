@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"td/internal/app/usecase"
+	"td/internal/config"
+	"td/internal/domain"
+)
+
+func newStatusCmd(cfg config.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "status <id...> <status>",
+		Short: "Move tasks to a status of the workflow",
+		Long: `Move tasks to any status of the workflow, including custom ones from
+the [workflow] section of config.toml:
+
+  td status 12 review
+  td status 12 13 doing
+
+Moves the workflow does not allow are refused.`,
+		Args: cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ids, err := parseIDs(args[:len(args)-1])
+			if err != nil {
+				return err
+			}
+			raw := strings.ToLower(args[len(args)-1])
+			status, err := cfg.Workflow.ParseStatus(raw)
+			if err != nil {
+				return fmt.Errorf("unknown status %q, expect one of %s", raw, formatStatuses(cfg.Workflow.Statuses()))
+			}
+
+			repo, closer, err := openTaskRepo(cfg)
+			if err != nil {
+				return err
+			}
+			defer closeDB(closer)
+
+			uc := usecase.UpdateTaskUseCase{Repo: repo, Workflow: cfg.Workflow}
+			if err := uc.Transit(cmd.Context(), ids, status); err != nil {
+				return err
+			}
+			for _, id := range ids {
+				cmd.Printf("%s #%d\n", status, id)
+			}
+			return nil
+		},
+	}
+}
+
+func formatStatuses(statuses []domain.Status) string {
+	names := make([]string, 0, len(statuses))
+	for _, s := range statuses {
+		names = append(names, string(s))
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,106 @@
+package cli
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestStatusShouldMoveTaskThroughCustomWorkflow(t *testing.T) {
+	cfg := testConfigInDir(t, t.TempDir())
+	body := `[workflow]
+statuses = ["review", "cancelled"]
+closed = ["cancelled"]
+
+[workflow.transitions]
+doing = ["review", "todo", "done", "deleted"]
+review = ["doing", "done"]
+todo = ["doing", "cancelled", "deleted"]
+`
+	if err := os.WriteFile(cfg.ConfigToml, []byte(body), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	id := createViaCLIWithArgs(t, cfg, "write design doc", "-p", "work")
+	idStr := strconv.FormatInt(id, 10)
+
+	if _, err := runCLIWithErr(cfg, "status", idStr, "review"); err == nil {
+		t.Fatalf("inbox -> review should be refused")
+	}
+	_ = runCLI(t, cfg, "status", idStr, "doing")
+	out := runCLI(t, cfg, "status", idStr, "review")
+	if strings.TrimSpace(out) != "review #"+idStr {
+		t.Fatalf("status output = %q", out)
+	}
+	out = runCLI(t, cfg, "ls", "status:review")
+	if !strings.Contains(out, "[review]") || !strings.Contains(out, "write design doc") {
+		t.Fatalf("ls review = %q", out)
+	}
+
+	cancelled := createViaCLIWithArgs(t, cfg, "old idea")
+	_ = runCLI(t, cfg, "status", strconv.FormatInt(cancelled, 10), "todo")
+	_ = runCLI(t, cfg, "status", strconv.FormatInt(cancelled, 10), "cancelled")
+	out = runCLI(t, cfg, "ls", "status:cancelled", "-o", "json")
+	if !strings.Contains(out, "old idea") || !strings.Contains(out, `"done_at": "`) {
+		t.Fatalf("closed custom status should get a done time: %q", out)
+	}
+	if _, err := runCLIWithErr(cfg, "status", idStr, "qa"); err == nil {
+		t.Fatalf("unknown status should fail")
+	}
+}
+
+func TestBrokenWorkflowShouldNotBlockConfigOrOtherCommands(t *testing.T) {
+	cfg := testConfigInDir(t, t.TempDir())
+	body := "[workflow]\nstatuses = [\"review\"]\n"
+	if err := os.WriteFile(cfg.ConfigToml, []byte(body), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	out, err := runCLIWithErr(cfg, "config", "ai", "set", "model", "deepseek-chat")
+	if err != nil || strings.Contains(out, "warning") {
+		t.Fatalf("config with a broken workflow = %q, %v", out, err)
+	}
+	if out := runCLI(t, cfg, "config", "ai", "get", "model"); strings.TrimSpace(out) != "deepseek-chat" {
+		t.Fatalf("config get = %q", out)
+	}
+	if out, err := runCLIWithErr(cfg, "version"); err != nil || strings.Contains(out, "warning") {
+		t.Fatalf("version with a broken workflow = %q, %v", out, err)
+	}
+
+	out = runCLI(t, cfg, "add", "write design doc")
+	if !strings.Contains(out, "warning: ") || !strings.Contains(out, "built-in workflow") || !strings.Contains(out, "created #") {
+		t.Fatalf("add with a broken workflow = %q", out)
+	}
+}
+
+func TestRemovedStatusShouldBeReportedUntilTasksMoveOut(t *testing.T) {
+	cfg := testConfigInDir(t, t.TempDir())
+	body := `[workflow]
+statuses = ["review"]
+
+[workflow.transitions]
+doing = ["review", "todo", "done", "deleted"]
+`
+	if err := os.WriteFile(cfg.ConfigToml, []byte(body), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	id := createViaCLIWithArgs(t, cfg, "write design doc", "-p", "work")
+	idStr := strconv.FormatInt(id, 10)
+	_ = runCLI(t, cfg, "status", idStr, "doing")
+	_ = runCLI(t, cfg, "status", idStr, "review")
+
+	if err := os.WriteFile(cfg.ConfigToml, nil, 0o600); err != nil {
+		t.Fatalf("clear config: %v", err)
+	}
+	out := runCLI(t, cfg, "ls")
+	if !strings.Contains(out, `warning: status "review" is not in the workflow: #`+idStr) {
+		t.Fatalf("ls with a removed status = %q", out)
+	}
+	out = runCLI(t, cfg, "status", idStr, "todo")
+	if !strings.Contains(out, "todo #"+idStr) {
+		t.Fatalf("move out of removed status = %q", out)
+	}
+	if out := runCLI(t, cfg, "ls"); strings.Contains(out, "warning") {
+		t.Fatalf("warning should go once no task is left: %q", out)
+	}
+}
@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestSubtasksShouldNestInLsAndAskOnDone(t *testing.T) {
+	cfg := testConfigInDir(t, t.TempDir())
+	parent := createViaCLIWithArgs(t, cfg, "release v2", "-p", "work")
+	parentText := strconv.FormatInt(parent, 10)
+	first := createViaCLIWithArgs(t, cfg, "write changelog", "--parent", parentText)
+	_ = createViaCLIWithArgs(t, cfg, "tag release", "--parent", parentText)
+	_ = createViaCLIWithArgs(t, cfg, "unrelated", "-p", "work")
+	_ = runCLI(t, cfg, "done", strconv.FormatInt(first, 10))
+
+	out := runCLI(t, cfg, "ls", "status:todo,done")
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("ls output = %q", out)
+	}
+	if !strings.Contains(lines[0], "release v2") || !strings.HasSuffix(lines[0], "1/2") {
+		t.Fatalf("parent line = %q", lines[0])
+	}
+	if !strings.Contains(lines[1], "   tag release") || !strings.Contains(lines[1], "work") {
+		t.Fatalf("subtask should be indented and inherit project: %q", lines[1])
+	}
+	if !strings.Contains(lines[2], "   write changelog") || !strings.Contains(lines[3], "unrelated") {
+		t.Fatalf("subtasks should follow their parent: %q", out)
+	}
+
+	out = runCLI(t, cfg, "show", parentText)
+	if !strings.Contains(out, "subtasks: 1/2\n") {
+		t.Fatalf("show output = %q", out)
+	}
+
+	out = runCLI(t, cfg, "done", parentText)
+	if !strings.Contains(out, "1 open subtask(s) left open") {
+		t.Fatalf("done output = %q", out)
+	}
+	_ = runCLI(t, cfg, "reopen", parentText)
+	out = runCLI(t, cfg, "done", parentText, "--subtasks")
+	if !strings.Contains(out, "done 1 task(s) and 1 subtask(s)") {
+		t.Fatalf("done --subtasks output = %q", out)
+	}
+	out = runCLI(t, cfg, "ls", "status:todo")
+	if strings.Contains(out, "tag release") {
+		t.Fatalf("subtask should be done: %q", out)
+	}
+
+	_ = runCLI(t, cfg, "rm", parentText)
+	out = runCLI(t, cfg, "ls", "status:deleted")
+	if strings.Count(out, "[dele") != 3 {
+		t.Fatalf("rm should cascade to subtasks: %q", out)
+	}
+}
@@ -0,0 +1,146 @@
+package cli
+
+import (
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"td/internal/app/usecase"
+	"td/internal/config"
+)
+
+func newTagCmd(cfg config.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tag",
+		Short: "Manage tags",
+	}
+	cmd.AddCommand(newTagLsCmd(cfg))
+	cmd.AddCommand(newTagAddCmd(cfg))
+	cmd.AddCommand(newTagRmCmd(cfg))
+	cmd.AddCommand(newTagRenameCmd(cfg))
+	cmd.AddCommand(newTagMergeCmd(cfg))
+	return cmd
+}
+
+func newTagLsCmd(cfg config.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "ls",
+		Short: "List tags",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repo, closer, err := openTaskRepo(cfg)
+			if err != nil {
+				return err
+			}
+			defer closeDB(closer)
+
+			uc := usecase.TagUseCase{Repo: repo}
+			tags, err := uc.List(cmd.Context())
+			if err != nil {
+				return err
+			}
+			for _, name := range tags {
+				cmd.Println(name)
+			}
+			return nil
+		},
+	}
+}
+
+func newTagAddCmd(cfg config.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "add <id> <tag...>",
+		Short: "Add tags to task",
+		Args:  cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ids, err := parseIDs(args[:1])
+			if err != nil {
+				return err
+			}
+			repo, closer, err := openTaskRepo(cfg)
+			if err != nil {
+				return err
+			}
+			defer closeDB(closer)
+
+			uc := usecase.UpdateTaskUseCase{Repo: repo, Workflow: cfg.Workflow}
+			if err := uc.AddTags(cmd.Context(), ids[0], args[1:]); err != nil {
+				return err
+			}
+			cmd.Printf("tagged #%d %s\n", ids[0], strings.Join(args[1:], " "))
+			return nil
+		},
+	}
+}
+
+func newTagRmCmd(cfg config.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "rm <id> <tag...>",
+		Short: "Remove tags from task",
+		Args:  cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ids, err := parseIDs(args[:1])
+			if err != nil {
+				return err
+			}
+			repo, closer, err := openTaskRepo(cfg)
+			if err != nil {
+				return err
+			}
+			defer closeDB(closer)
+
+			uc := usecase.UpdateTaskUseCase{Repo: repo, Workflow: cfg.Workflow}
+			if err := uc.RemoveTags(cmd.Context(), ids[0], args[1:]); err != nil {
+				return err
+			}
+			cmd.Printf("untagged #%d %s\n", ids[0], strings.Join(args[1:], " "))
+			return nil
+		},
+	}
+}
+
+func newTagRenameCmd(cfg config.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "rename <old> <new>",
+		Short: "Rename tag",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repo, closer, err := openTaskRepo(cfg)
+			if err != nil {
+				return err
+			}
+			defer closeDB(closer)
+
+			uc := usecase.TagUseCase{Repo: repo}
+			if err := uc.Rename(cmd.Context(), args[0], args[1]); err != nil {
+				return err
+			}
+			cmd.Printf("renamed tag %s -> %s\n", args[0], args[1])
+			return nil
+		},
+	}
+}
+
+func newTagMergeCmd(cfg config.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "merge <source...> <target>",
+		Short: "Merge tags into target tag",
+		Args:  cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repo, closer, err := openTaskRepo(cfg)
+			if err != nil {
+				return err
+			}
+			defer closeDB(closer)
+
+			sources := args[:len(args)-1]
+			target := args[len(args)-1]
+			uc := usecase.TagUseCase{Repo: repo}
+			if err := uc.Merge(cmd.Context(), sources, target); err != nil {
+				return err
+			}
+			cmd.Printf("merged %s -> %s\n", strings.Join(sources, " "), target)
+			return nil
+		},
+	}
+}
@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"td/internal/config"
+)
+
+func TestTagCommands(t *testing.T) {
+	tdHome := t.TempDir()
+	cfg := config.Default()
+	cfg.HomeDir = tdHome
+	cfg.DataDir = filepath.Join(tdHome, "data")
+	cfg.DBPath = filepath.Join(cfg.DataDir, "td.db")
+
+	id := createViaCLIWithArgs(t, cfg, "write report", "-t", "work", "-t", "urgent")
+	idStr := strconv.FormatInt(id, 10)
+
+	out := runCLI(t, cfg, "ls")
+	if !strings.Contains(out, "#urgent #work") {
+		t.Fatalf("ls output = %q, want tags", out)
+	}
+	out = runCLI(t, cfg, "show", idStr)
+	if !strings.Contains(out, "tags: urgent, work") {
+		t.Fatalf("show output = %q, want tags line", out)
+	}
+
+	_ = runCLI(t, cfg, "tag", "add", idStr, "later")
+	_ = runCLI(t, cfg, "tag", "rm", idStr, "urgent")
+	_ = runCLI(t, cfg, "tag", "rename", "later", "someday")
+	out = runCLI(t, cfg, "tag", "ls")
+	if lines := nonEmptyLines(out); strings.Join(lines, ",") != "someday,work" {
+		t.Fatalf("tag ls = %q, want someday/work", out)
+	}
+
+	_ = runCLI(t, cfg, "tag", "merge", "someday", "work")
+	out = runCLI(t, cfg, "tag", "ls")
+	if lines := nonEmptyLines(out); strings.Join(lines, ",") != "work" {
+		t.Fatalf("tag ls after merge = %q, want work", out)
+	}
+}
@@ -23,7 +23,7 @@ func newTodayCmd(cfg config.Config) *cobra.Command {
 			}
 			defer closeDB(closer)
 
-			uc := usecase.UpdateTaskUseCase{Repo: repo}
+			uc := usecase.UpdateTaskUseCase{Repo: repo, Workflow: cfg.Workflow}
 			if err := uc.MarkToday(cmd.Context(), ids); err != nil {
 				return err
 			}
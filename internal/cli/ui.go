@@ -19,7 +19,7 @@ func newUICmd(cfg config.Config) *cobra.Command {
 			}
 			defer closeDB(closer)
 
-			model := tui.NewModelWithRepo(repo).WithAIParser(newAIParseTaskUseCase(cfg))
+			model := tui.NewModelWithRepo(repo, cfg.Workflow).WithClock(cfg.Clock).WithAIParser(newAIParseTaskUseCase(cfg, newAICacheStore(cfg, repo)))
 			program := tea.NewProgram(
 				model,
 				tea.WithAltScreen(),
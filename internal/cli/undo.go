@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"td/internal/app/usecase"
+	"td/internal/config"
+	"td/internal/domain"
+)
+
+func newUndoCmd(cfg config.Config) *cobra.Command {
+	var (
+		list  bool
+		limit int
+	)
+	cmd := &cobra.Command{
+		Use:   "undo",
+		Short: "Undo the last change",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repo, closer, err := openTaskRepo(cfg)
+			if err != nil {
+				return err
+			}
+			defer closeDB(closer)
+
+			uc := usecase.UpdateTaskUseCase{Repo: repo, Workflow: cfg.Workflow}
+			if list {
+				ops, err := uc.Operations(cmd.Context(), limit)
+				if err != nil {
+					return err
+				}
+				if len(ops) == 0 {
+					cmd.Println("no operations")
+				}
+				for _, op := range ops {
+					cmd.Println(formatOperation(op))
+				}
+				return nil
+			}
+			op, err := uc.Undo(cmd.Context())
+			if err != nil {
+				return err
+			}
+			cmd.Printf("undid %s\n", op.Summary)
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&list, "list", false, "list recent operations instead of undoing")
+	cmd.Flags().IntVarP(&limit, "limit", "n", 10, "number of operations to list")
+	return cmd
+}
+
+func newRedoCmd(cfg config.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "redo",
+		Short: "Redo the last undone change",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repo, closer, err := openTaskRepo(cfg)
+			if err != nil {
+				return err
+			}
+			defer closeDB(closer)
+
+			uc := usecase.UpdateTaskUseCase{Repo: repo, Workflow: cfg.Workflow}
+			op, err := uc.Redo(cmd.Context())
+			if err != nil {
+				return err
+			}
+			cmd.Printf("redid %s\n", op.Summary)
+			return nil
+		},
+	}
+}
+
+func formatOperation(op domain.Operation) string {
+	source := string(op.Source)
+	if source == "" {
+		source = "-"
+	}
+	line := fmt.Sprintf("%-5d %s  %-3s  %s", op.ID, op.CreatedAt.In(time.Local).Format("2006-01-02 15:04"), source, op.Summary)
+	if op.State == domain.OperationUndone {
+		line += "  (undone)"
+	}
+	return line
+}
@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestUndoRedoShouldWorkAcrossInvocations(t *testing.T) {
+	cfg := testConfigInDir(t, t.TempDir())
+	id := createViaCLIWithArgs(t, cfg, "draft", "-p", "work")
+	idText := strconv.FormatInt(id, 10)
+	_ = runCLI(t, cfg, "priority", idText, "P1")
+	_ = runCLI(t, cfg, "done", idText)
+
+	out := runCLI(t, cfg, "undo")
+	if strings.TrimSpace(out) != "undid done #"+idText {
+		t.Fatalf("undo output = %q", out)
+	}
+	out = runCLI(t, cfg, "show", idText)
+	if !strings.Contains(out, "status: todo") {
+		t.Fatalf("task should be reopened after undo: %q", out)
+	}
+	_ = runCLI(t, cfg, "undo")
+	out = runCLI(t, cfg, "show", idText)
+	if !strings.Contains(out, "priority: P2") {
+		t.Fatalf("priority should be restored after second undo: %q", out)
+	}
+
+	out = runCLI(t, cfg, "redo")
+	if strings.TrimSpace(out) != "redid priority #"+idText {
+		t.Fatalf("redo output = %q", out)
+	}
+	out = runCLI(t, cfg, "undo", "--list")
+	lines := nonEmptyLines(out)
+	if len(lines) != 3 {
+		t.Fatalf("undo --list output = %q", out)
+	}
+	if !strings.HasSuffix(lines[0], "cli  done #"+idText+"  (undone)") || !strings.HasSuffix(lines[1], "cli  priority #"+idText) {
+		t.Fatalf("undo --list output = %q", out)
+	}
+
+	_ = runCLI(t, cfg, "redo")
+	if _, err := runCLIWithErr(cfg, "redo"); err == nil {
+		t.Fatalf("redo with nothing undone should fail")
+	}
+}
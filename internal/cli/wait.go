@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"td/internal/app/usecase"
+	"td/internal/config"
+	"td/internal/timeutil"
+)
+
+func newWaitCmd(cfg config.Config) *cobra.Command {
+	var (
+		on       string
+		followUp string
+	)
+	cmd := &cobra.Command{
+		Use:   "wait <id> --on <who> [--followup <datetime>]",
+		Short: "Mark a task as waiting on someone",
+		Long: `Mark a task as delegated or waiting on someone; it leaves Today and is
+listed in the Waiting view (td ls waiting).
+
+  td wait 12 --on alice
+  td wait 12 --on alice --followup fri
+
+With --followup the task shows up in Today again from that day on. td
+reopen, td today or td done end the wait.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ids, err := parseIDs(args)
+			if err != nil {
+				return err
+			}
+			var followUpAt *time.Time
+			if followUp != "" {
+				parsed, err := timeutil.ParseStart(followUp, cfg.Clock, time.Local)
+				if err != nil {
+					return err
+				}
+				followUpAt = &parsed
+			}
+
+			repo, closer, err := openTaskRepo(cfg)
+			if err != nil {
+				return err
+			}
+			defer closeDB(closer)
+
+			uc := usecase.UpdateTaskUseCase{Repo: repo, Workflow: cfg.Workflow}
+			if err := uc.Wait(cmd.Context(), ids[0], on, followUpAt); err != nil {
+				return err
+			}
+			cmd.Printf("waiting #%d%s\n", ids[0], formatWaiting(on, followUpAt))
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&on, "on", "", "who or what the task waits on")
+	cmd.Flags().StringVar(&followUp, "followup", "", "datetime to follow up, such as fri or 2026-03-06")
+	return cmd
+}
+
+// formatWaiting describes a wait as " on alice, follow up 2026-03-06 00:00",
+// leaving out the parts that are not set.
+func formatWaiting(on string, followUpAt *time.Time) string {
+	out := ""
+	if on != "" {
+		out += " on " + on
+	}
+	if followUpAt != nil {
+		if out != "" {
+			out += ","
+		}
+		out += " follow up " + formatDue(followUpAt)
+	}
+	return out
+}
@@ -0,0 +1,39 @@
+package cli
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestWaitShouldMoveTaskToWaitingView(t *testing.T) {
+	cfg := testConfigInDir(t, t.TempDir())
+	id := createViaCLIWithArgs(t, cfg, "get quote", "-p", "home")
+	idStr := strconv.FormatInt(id, 10)
+
+	out := runCLI(t, cfg, "wait", idStr, "--on", "alice", "--followup", "2026-03-06")
+	if strings.TrimSpace(out) != "waiting #"+idStr+" on alice, follow up 2026-03-06 00:00" {
+		t.Fatalf("wait output = %q", out)
+	}
+	out = runCLI(t, cfg, "ls", "waiting")
+	if !strings.Contains(out, "[waiting]") || !strings.Contains(out, "on alice, follow up 2026-03-06 00:00") {
+		t.Fatalf("waiting output = %q", out)
+	}
+	out = runCLI(t, cfg, "ls", "status:waiting", "-o", "json")
+	if !strings.Contains(out, `"waiting_on": "alice"`) {
+		t.Fatalf("json output = %q", out)
+	}
+	out = runCLI(t, cfg, "show", idStr)
+	if !strings.Contains(out, "waiting on: alice") || !strings.Contains(out, "follow up: 2026-03-06 00:00") {
+		t.Fatalf("show output = %q", out)
+	}
+
+	_ = runCLI(t, cfg, "reopen", idStr)
+	out = runCLI(t, cfg, "ls", "waiting")
+	if strings.Contains(out, "get quote") {
+		t.Fatalf("waiting after reopen = %q", out)
+	}
+	if _, err := runCLIWithErr(cfg, "wait", idStr, "--followup", "someday"); err == nil {
+		t.Fatalf("unknown follow-up should fail")
+	}
+}
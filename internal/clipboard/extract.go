@@ -3,6 +3,9 @@ package clipboard
 import (
 	"regexp"
 	"strings"
+	"time"
+
+	"td/internal/timeutil"
 )
 
 var linkRegexp = regexp.MustCompile(`https?://[^\s]+`)
@@ -33,11 +36,21 @@ func ExtractLinks(text string) []string {
 	return out
 }
 
-func ParseByRule(raw string) ParsedTask {
+// ParseByRule builds a task from text without AI. A due phrase in the
+// first line, such as 明天下午3点 or fri 9am, becomes Due and is dropped
+// from the title.
+func ParseByRule(raw string, clk timeutil.Clock) ParsedTask {
 	normalized, _ := Normalize(raw)
 	links := ExtractLinks(normalized)
 
 	title := firstNonEmptyLine(normalized)
+	due := ""
+	if dueAt, phrase, ok := timeutil.FindDue(title, clk, time.Local); ok {
+		due = dueAt.In(time.Local).Format("2006-01-02 15:04")
+		if rest := strings.Join(strings.Fields(strings.Replace(title, phrase, " ", 1)), " "); rest != "" {
+			title = rest
+		}
+	}
 	if title == "" {
 		if len(links) > 0 {
 			title = links[0]
@@ -50,6 +63,7 @@ func ParseByRule(raw string) ParsedTask {
 		Title:    title,
 		Notes:    normalized,
 		Priority: "P2",
+		Due:      due,
 		Links:    links,
 	}
 }
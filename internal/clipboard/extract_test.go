@@ -0,0 +1,50 @@
+package clipboard
+
+import (
+	"testing"
+	"time"
+
+	"td/internal/timeutil"
+)
+
+func TestParseByRuleShouldExtractDuePhrase(t *testing.T) {
+	clk := timeutil.ClockFunc(func() time.Time { return time.Date(2026, 3, 4, 10, 0, 0, 0, time.Local) })
+
+	parsed := ParseByRule("明天下午3点 交周报\nhttps://example.com/report", clk)
+	if parsed.Title != "交周报" || parsed.Due != "2026-03-05 15:00" {
+		t.Fatalf("parsed = %+v", parsed)
+	}
+	if len(parsed.Links) != 1 {
+		t.Fatalf("links = %v", parsed.Links)
+	}
+
+	parsed = ParseByRule("buy milk", clk)
+	if parsed.Title != "buy milk" || parsed.Due != "" {
+		t.Fatalf("parsed without due = %+v", parsed)
+	}
+
+	parsed = ParseByRule("Review today PR", clk)
+	if parsed.Title != "Review today PR" || parsed.Due != "" {
+		t.Fatalf("a lone day word should stay in the title: %+v", parsed)
+	}
+}
+
+func TestParseListByRuleShouldSplitListItems(t *testing.T) {
+	clk := timeutil.ClockFunc(func() time.Time { return time.Date(2026, 3, 4, 10, 0, 0, 0, time.Local) })
+
+	tasks := ParseListByRule("周会纪要\n- 明天下午3点 交周报\n  附上数据\n- [x] 订会议室\n2. 更新文档\n* [ ] 回复邮件", clk)
+	if len(tasks) != 3 {
+		t.Fatalf("tasks = %+v", tasks)
+	}
+	if tasks[0].Title != "交周报" || tasks[0].Due != "2026-03-05 15:00" || tasks[0].Notes != "明天下午3点 交周报\n附上数据" {
+		t.Fatalf("first task = %+v", tasks[0])
+	}
+	if tasks[1].Title != "更新文档" || tasks[2].Title != "回复邮件" {
+		t.Fatalf("tasks = %+v", tasks)
+	}
+
+	tasks = ParseListByRule("buy milk\nlactose free", clk)
+	if len(tasks) != 1 || tasks[0].Title != "buy milk" {
+		t.Fatalf("text without a list = %+v", tasks)
+	}
+}
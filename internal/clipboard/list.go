@@ -0,0 +1,52 @@
+package clipboard
+
+import (
+	"regexp"
+	"strings"
+
+	"td/internal/timeutil"
+)
+
+var (
+	listMarkerRegexp = regexp.MustCompile(`^(?:[-*+•]\s+|\d{1,3}[.)]\s+|\d{1,3}、\s*)`)
+	checkboxRegexp   = regexp.MustCompile(`^\[([ xX])\]\s*`)
+)
+
+// ParseListByRule builds one task per item of a bullet, numbered or
+// checkbox list, so pasted meeting notes give one task per action item.
+// Lines under an item become its notes, checked items are skipped and text
+// without a list is parsed as one task by ParseByRule.
+func ParseListByRule(raw string, clk timeutil.Clock) []ParsedTask {
+	normalized, _ := Normalize(raw)
+	var (
+		items   [][]string
+		checked bool
+	)
+	for _, line := range strings.Split(normalized, "\n") {
+		marker := listMarkerRegexp.FindString(line)
+		rest := line[len(marker):]
+		box := checkboxRegexp.FindStringSubmatch(rest)
+		if marker == "" && box == nil {
+			if line != "" && len(items) > 0 && !checked {
+				items[len(items)-1] = append(items[len(items)-1], line)
+			}
+			continue
+		}
+		if box != nil {
+			rest = rest[len(box[0]):]
+		}
+		checked = box != nil && box[1] != " "
+		if checked || strings.TrimSpace(rest) == "" {
+			continue
+		}
+		items = append(items, []string{rest})
+	}
+	if len(items) == 0 {
+		return []ParsedTask{ParseByRule(raw, clk)}
+	}
+	tasks := make([]ParsedTask, 0, len(items))
+	for _, item := range items {
+		tasks = append(tasks, ParseByRule(strings.Join(item, "\n"), clk))
+	}
+	return tasks
+}
@@ -3,6 +3,9 @@ package config
 import (
 	"os"
 	"path/filepath"
+
+	"td/internal/domain"
+	"td/internal/timeutil"
 )
 
 const DefaultTimezone = "Local"
@@ -13,6 +16,11 @@ type Config struct {
 	DBPath     string
 	Timezone   string
 	ConfigToml string
+	// Workflow is the [workflow] section of ConfigToml as the CLI loaded
+	// it; the zero value is the built-in workflow.
+	Workflow domain.Workflow
+	// Clock tells the CLI what time it is; tests fix it.
+	Clock timeutil.Clock
 }
 
 func Default() Config {
@@ -30,5 +38,6 @@ func Default() Config {
 		DBPath:     filepath.Join(dataDir, "td.db"),
 		Timezone:   DefaultTimezone,
 		ConfigToml: filepath.Join(homeDir, "config.toml"),
+		Clock:      timeutil.SystemClock{},
 	}
 }
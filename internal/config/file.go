@@ -16,6 +16,10 @@ type AIConfig struct {
 	BaseURL  string
 	Model    string
 	Timeout  int
+	// CacheTTL is how many hours a cached response is kept, CacheSize how
+	// many responses at most; zero means the default.
+	CacheTTL  int
+	CacheSize int
 }
 
 type GitHubConfig struct {
@@ -23,8 +27,9 @@ type GitHubConfig struct {
 }
 
 type UserConfig struct {
-	AI     AIConfig
-	GitHub GitHubConfig
+	AI       AIConfig
+	GitHub   GitHubConfig
+	Workflow WorkflowConfig
 }
 
 func LoadUserConfig(path string) (UserConfig, error) {
@@ -68,23 +73,54 @@ func LoadUserConfig(path string) (UserConfig, error) {
 				out.AI.BaseURL = parseConfigString(val)
 			case "model":
 				out.AI.Model = parseConfigString(val)
-			case "timeout":
+			case "timeout", "cache_ttl", "cache_size":
 				raw := parseConfigString(val)
-				if strings.TrimSpace(raw) == "" {
-					out.AI.Timeout = 0
-					continue
+				n := 0
+				if strings.TrimSpace(raw) != "" {
+					parsed, err := strconv.Atoi(raw)
+					if err != nil {
+						return out, fmt.Errorf("invalid ai.%s at line %d", key, lineNo)
+					}
+					n = parsed
 				}
-				n, err := strconv.Atoi(raw)
-				if err != nil {
-					return out, fmt.Errorf("invalid ai.timeout at line %d", lineNo)
+				switch key {
+				case "timeout":
+					out.AI.Timeout = n
+				case "cache_ttl":
+					out.AI.CacheTTL = n
+				default:
+					out.AI.CacheSize = n
 				}
-				out.AI.Timeout = n
 			}
 		case "github":
 			switch key {
 			case "token":
 				out.GitHub.Token = parseConfigString(val)
 			}
+		case "workflow":
+			list, err := parseConfigList(val)
+			if err != nil {
+				return out, fmt.Errorf("invalid workflow.%s at line %d: %w", key, lineNo, err)
+			}
+			switch key {
+			case "statuses":
+				out.Workflow.Statuses = list
+			case "open":
+				out.Workflow.Open = list
+			case "closed":
+				out.Workflow.Closed = list
+			case "order":
+				out.Workflow.Order = list
+			}
+		case "workflow.transitions":
+			list, err := parseConfigList(val)
+			if err != nil {
+				return out, fmt.Errorf("invalid workflow.transitions.%s at line %d: %w", key, lineNo, err)
+			}
+			if out.Workflow.Transitions == nil {
+				out.Workflow.Transitions = make(map[string][]string)
+			}
+			out.Workflow.Transitions[key] = list
 		}
 	}
 	if err := scanner.Err(); err != nil {
@@ -109,13 +145,44 @@ func SaveUserConfig(path string, cfg UserConfig) error {
 	} else {
 		b.WriteString("timeout = 0\n")
 	}
+	if cfg.AI.CacheTTL > 0 {
+		b.WriteString(fmt.Sprintf("cache_ttl = %d\n", cfg.AI.CacheTTL))
+	}
+	if cfg.AI.CacheSize > 0 {
+		b.WriteString(fmt.Sprintf("cache_size = %d\n", cfg.AI.CacheSize))
+	}
 	b.WriteString("\n")
 	b.WriteString("[github]\n")
 	b.WriteString(`token = ` + strconv.Quote(cfg.GitHub.Token) + "\n")
+	writeWorkflowConfig(&b, cfg.Workflow)
 
 	return os.WriteFile(path, []byte(b.String()), 0o600)
 }
 
+// parseConfigList reads a one-line array of strings such as
+// ["review", "qa"].
+func parseConfigList(raw string) ([]string, error) {
+	text := strings.TrimSpace(raw)
+	if !strings.HasPrefix(text, "[") || !strings.HasSuffix(text, "]") {
+		return nil, errors.New(`expect a list like ["a", "b"]`)
+	}
+	out := make([]string, 0, 4)
+	for _, item := range strings.Split(text[1:len(text)-1], ",") {
+		if value := parseConfigString(item); value != "" {
+			out = append(out, value)
+		}
+	}
+	return out, nil
+}
+
+func formatConfigList(items []string) string {
+	quoted := make([]string, 0, len(items))
+	for _, item := range items {
+		quoted = append(quoted, strconv.Quote(item))
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
 func normalizeConfigKey(raw string) string {
 	text := strings.TrimSpace(strings.ToLower(raw))
 	text = strings.ReplaceAll(text, "-", "_")
@@ -1,7 +1,9 @@
 package config
 
 import (
+	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 )
 
@@ -58,3 +60,53 @@ func TestLoadUserConfigNotExistsShouldReturnEmpty(t *testing.T) {
 		t.Fatalf("cfg = %#v, want empty", cfg)
 	}
 }
+
+func TestSaveAndLoadUserConfigShouldKeepWorkflow(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	in := UserConfig{
+		AI: AIConfig{Provider: "deepseek"},
+		Workflow: WorkflowConfig{
+			Statuses: []string{"review", "cancelled"},
+			Closed:   []string{"cancelled"},
+			Transitions: map[string][]string{
+				"doing":  {"review", "todo", "done", "deleted"},
+				"review": {"doing", "done"},
+				"todo":   {"doing", "cancelled"},
+			},
+		},
+	}
+	if err := SaveUserConfig(path, in); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+	out, err := LoadUserConfig(path)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	if !reflect.DeepEqual(out.Workflow, in.Workflow) {
+		t.Fatalf("workflow = %#v, want %#v", out.Workflow, in.Workflow)
+	}
+	if _, err := out.Workflow.Workflow(); err != nil {
+		t.Fatalf("build workflow: %v", err)
+	}
+}
+
+func TestWorkflowConfigShouldRejectInvalidWorkflow(t *testing.T) {
+	for name, body := range map[string]string{
+		"not a list":     "[workflow]\nstatuses = \"review\"\n",
+		"unreachable":    "[workflow]\nstatuses = [\"review\"]\n",
+		"unknown target": "[workflow]\nstatuses = [\"review\"]\n\n[workflow.transitions]\ndoing = [\"review\", \"qa\"]\n",
+		"open and done":  "[workflow]\nopen = [\"done\"]\n",
+	} {
+		path := filepath.Join(t.TempDir(), "config.toml")
+		if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+			t.Fatalf("write config: %v", err)
+		}
+		cfg, err := LoadUserConfig(path)
+		if err == nil {
+			_, err = cfg.Workflow.Workflow()
+		}
+		if err == nil {
+			t.Fatalf("%s: want error", name)
+		}
+	}
+}
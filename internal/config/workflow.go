@@ -0,0 +1,92 @@
+package config
+
+import (
+	"sort"
+	"strings"
+
+	"td/internal/domain"
+)
+
+// WorkflowConfig is the [workflow] section of config.toml:
+//
+//	[workflow]
+//	statuses = ["review", "qa"]
+//	closed = ["cancelled"]
+//	order = ["doing", "review", "qa", "todo"]
+//
+//	[workflow.transitions]
+//	doing = ["review", "todo", "done", "deleted"]
+//	review = ["qa", "doing"]
+type WorkflowConfig struct {
+	Statuses    []string
+	Open        []string
+	Closed      []string
+	Order       []string
+	Transitions map[string][]string
+}
+
+func (c WorkflowConfig) IsZero() bool {
+	return len(c.Statuses) == 0 && len(c.Open) == 0 && len(c.Closed) == 0 && len(c.Order) == 0 && len(c.Transitions) == 0
+}
+
+// Workflow builds the domain workflow; an empty section gives the
+// built-in one.
+func (c WorkflowConfig) Workflow() (domain.Workflow, error) {
+	spec := domain.WorkflowSpec{
+		Statuses: toStatuses(c.Statuses),
+		Open:     toStatuses(c.Open),
+		Closed:   toStatuses(c.Closed),
+		Order:    toStatuses(c.Order),
+	}
+	if len(c.Transitions) > 0 {
+		spec.Transitions = make(map[domain.Status][]domain.Status, len(c.Transitions))
+		for from, tos := range c.Transitions {
+			spec.Transitions[toStatus(from)] = toStatuses(tos)
+		}
+	}
+	return domain.NewWorkflow(spec)
+}
+
+func writeWorkflowConfig(b *strings.Builder, c WorkflowConfig) {
+	if c.IsZero() {
+		return
+	}
+	b.WriteString("\n[workflow]\n")
+	for _, item := range []struct {
+		key    string
+		values []string
+	}{
+		{"statuses", c.Statuses},
+		{"open", c.Open},
+		{"closed", c.Closed},
+		{"order", c.Order},
+	} {
+		if len(item.values) > 0 {
+			b.WriteString(item.key + " = " + formatConfigList(item.values) + "\n")
+		}
+	}
+	if len(c.Transitions) == 0 {
+		return
+	}
+	b.WriteString("\n[workflow.transitions]\n")
+	froms := make([]string, 0, len(c.Transitions))
+	for from := range c.Transitions {
+		froms = append(froms, from)
+	}
+	sort.Strings(froms)
+	for _, from := range froms {
+		b.WriteString(from + " = " + formatConfigList(c.Transitions[from]) + "\n")
+	}
+}
+
+func toStatus(raw string) domain.Status {
+	return domain.Status(strings.ToLower(strings.TrimSpace(raw)))
+}
+
+func toStatuses(items []string) []domain.Status {
+	out := make([]domain.Status, 0, len(items))
+	for _, item := range items {
+		out = append(out, toStatus(item))
+	}
+	return out
+}
@@ -3,12 +3,19 @@ package domain
 import (
 	"errors"
 	"fmt"
+	"strings"
 )
 
 var (
 	ErrTaskNotFound    = errors.New("task not found")
 	ErrInvalidStatus   = errors.New("invalid status")
 	ErrInvalidPriority = errors.New("invalid priority")
+	ErrInvalidTag      = errors.New("invalid tag")
+	ErrTagNotFound     = errors.New("tag not found")
+	ErrDependencyCycle = errors.New("dependency cycle")
+	ErrTaskBlocked     = errors.New("task is blocked")
+	ErrNothingToUndo   = errors.New("nothing to undo")
+	ErrNothingToRedo   = errors.New("nothing to redo")
 )
 
 type InvalidTransitionError struct {
@@ -23,3 +30,18 @@ func (e InvalidTransitionError) Error() string {
 func NewInvalidTransitionError(from, to Status) error {
 	return InvalidTransitionError{From: from, To: to}
 }
+
+// UnknownStatusError reports tasks stored in a status the workflow does not
+// define, usually one renamed or removed from config.toml.
+type UnknownStatusError struct {
+	Status Status
+	IDs    []int64
+}
+
+func (e UnknownStatusError) Error() string {
+	ids := make([]string, 0, len(e.IDs))
+	for _, id := range e.IDs {
+		ids = append(ids, fmt.Sprintf("#%d", id))
+	}
+	return fmt.Sprintf("status %q is not in the workflow: %s", e.Status, strings.Join(ids, ", "))
+}
@@ -0,0 +1,44 @@
+package domain
+
+import "time"
+
+// EventSource names where a change came from.
+type EventSource string
+
+const (
+	SourceCLI EventSource = "cli"
+	SourceTUI EventSource = "tui"
+	SourceAI  EventSource = "ai"
+)
+
+// Fields recorded in task history. EventCreated and EventPurged mark the
+// start and end of a task rather than a field change.
+const (
+	EventCreated    = "created"
+	EventPurged     = "purged"
+	EventTitle      = "title"
+	EventNotes      = "notes"
+	EventStatus     = "status"
+	EventProject    = "project"
+	EventPriority   = "priority"
+	EventDue        = "due"
+	EventStart      = "start"
+	EventRecurrence = "recurrence"
+	EventSnoozes    = "snoozes"
+	EventWaitingOn  = "waiting_on"
+	EventFollowUp   = "followup"
+	EventParent     = "parent"
+	EventTags       = "tags"
+	EventBlockedBy  = "blocked_by"
+)
+
+// TaskEvent is one append-only entry of a task's history.
+type TaskEvent struct {
+	ID        int64
+	TaskID    int64
+	Field     string
+	OldValue  string
+	NewValue  string
+	Source    EventSource
+	CreatedAt time.Time
+}
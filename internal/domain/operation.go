@@ -0,0 +1,20 @@
+package domain
+
+import "time"
+
+type OperationState string
+
+const (
+	OperationDone   OperationState = "done"
+	OperationUndone OperationState = "undone"
+)
+
+// Operation is one journaled change that td undo and td redo can revert
+// and reapply.
+type Operation struct {
+	ID        int64
+	Summary   string
+	Source    EventSource
+	State     OperationState
+	CreatedAt time.Time
+}
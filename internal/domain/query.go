@@ -3,11 +3,14 @@ package domain
 type View string
 
 const (
-	ViewToday   View = "today"
-	ViewInbox   View = "inbox"
-	ViewLog     View = "log"
-	ViewProject View = "project"
-	ViewTrash   View = "trash"
+	ViewToday    View = "today"
+	ViewInbox    View = "inbox"
+	ViewUpcoming View = "upcoming"
+	ViewWaiting  View = "waiting"
+	ViewLog      View = "log"
+	ViewProject  View = "project"
+	ViewTag      View = "tag"
+	ViewTrash    View = "trash"
 )
 
 const DefaultLogWindowDays = 14
@@ -0,0 +1,483 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidRecurrence = errors.New("invalid recurrence")
+
+type RecurrenceFreq string
+
+const (
+	FreqDaily   RecurrenceFreq = "DAILY"
+	FreqWeekly  RecurrenceFreq = "WEEKLY"
+	FreqMonthly RecurrenceFreq = "MONTHLY"
+	FreqYearly  RecurrenceFreq = "YEARLY"
+)
+
+// Recurrence is the RRULE subset td understands: FREQ, INTERVAL, a plain
+// BYDAY list and a single BYMONTHDAY and BYMONTH. A fixed schedule steps
+// from the due date; with AfterCompletion the next occurrence counts from
+// the day the task is done.
+type Recurrence struct {
+	Freq            RecurrenceFreq
+	Interval        int
+	ByDay           []time.Weekday
+	ByMonthDay      int
+	ByMonth         time.Month
+	AfterCompletion bool
+	// FromDue marks ByMonthDay and ByMonth as copied from the due date by
+	// Anchored rather than given in the rule; they follow the due date.
+	FromDue bool
+}
+
+var rruleDays = []string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+var recurrenceWeekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "sun": time.Sunday, "su": time.Sunday,
+	"monday": time.Monday, "mon": time.Monday, "mo": time.Monday,
+	"tuesday": time.Tuesday, "tue": time.Tuesday, "tu": time.Tuesday,
+	"wednesday": time.Wednesday, "wed": time.Wednesday, "we": time.Wednesday,
+	"thursday": time.Thursday, "thu": time.Thursday, "th": time.Thursday,
+	"friday": time.Friday, "fri": time.Friday, "fr": time.Friday,
+	"saturday": time.Saturday, "sat": time.Saturday, "sa": time.Saturday,
+}
+
+var workWeek = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+
+func (r Recurrence) IsZero() bool {
+	return r.Freq == ""
+}
+
+// ParseRecurrence accepts daily/weekly/monthly/yearly, weekday(s), weekday
+// lists such as "mon,thu", intervals such as 3d, 2w, 1m or 1y (optionally
+// spelled "2 weeks" and prefixed with "every"), and RRULE strings like
+// FREQ=WEEKLY;INTERVAL=2;BYDAY=MO. Empty input yields the zero value.
+func ParseRecurrence(raw string) (Recurrence, error) {
+	text := strings.TrimSpace(raw)
+	if text == "" {
+		return Recurrence{}, nil
+	}
+	upper := strings.ToUpper(text)
+	if strings.HasPrefix(upper, "RRULE:") || strings.HasPrefix(upper, "FREQ=") {
+		return parseRRule(strings.TrimPrefix(upper, "RRULE:"))
+	}
+
+	lower := strings.ToLower(text)
+	lower = strings.TrimSpace(strings.TrimPrefix(lower, "every "))
+	for _, suffix := range []string{" after completion", " after done"} {
+		if strings.HasSuffix(lower, suffix) {
+			r, err := ParseRecurrence(strings.TrimSuffix(lower, suffix))
+			r.AfterCompletion = err == nil
+			return r, err
+		}
+	}
+	fail := fmt.Errorf("%w %q, use daily, weekly, weekday, mon,wed, 2w, 3d, 1m, 1y or FREQ=...", ErrInvalidRecurrence, raw)
+	switch lower {
+	case "day", "daily":
+		return Recurrence{Freq: FreqDaily, Interval: 1}, nil
+	case "week", "weekly":
+		return Recurrence{Freq: FreqWeekly, Interval: 1}, nil
+	case "month", "monthly":
+		return Recurrence{Freq: FreqMonthly, Interval: 1}, nil
+	case "year", "yearly", "annually":
+		return Recurrence{Freq: FreqYearly, Interval: 1}, nil
+	case "weekday", "weekdays", "workday", "workdays":
+		return Recurrence{Freq: FreqWeekly, Interval: 1, ByDay: append([]time.Weekday(nil), workWeek...)}, nil
+	}
+	if days, ok := parseWeekdayList(lower); ok {
+		return Recurrence{Freq: FreqWeekly, Interval: 1, ByDay: days}, nil
+	}
+
+	compact := strings.ReplaceAll(lower, " ", "")
+	i := 0
+	for i < len(compact) && compact[i] >= '0' && compact[i] <= '9' {
+		i++
+	}
+	if i == 0 || i == len(compact) {
+		return Recurrence{}, fail
+	}
+	n, err := strconv.Atoi(compact[:i])
+	if err != nil || n <= 0 {
+		return Recurrence{}, fail
+	}
+	var freq RecurrenceFreq
+	switch strings.TrimSuffix(compact[i:], "s") {
+	case "d", "day":
+		freq = FreqDaily
+	case "w", "week":
+		freq = FreqWeekly
+	case "m", "month":
+		freq = FreqMonthly
+	case "y", "year":
+		freq = FreqYearly
+	default:
+		return Recurrence{}, fail
+	}
+	return Recurrence{Freq: freq, Interval: n}, nil
+}
+
+func parseWeekdayList(text string) ([]time.Weekday, bool) {
+	parts := strings.FieldsFunc(text, func(r rune) bool { return r == ',' || r == ' ' })
+	if len(parts) == 0 {
+		return nil, false
+	}
+	days := make([]time.Weekday, 0, len(parts))
+	for _, part := range parts {
+		day, ok := recurrenceWeekdays[part]
+		if !ok {
+			return nil, false
+		}
+		days = appendWeekday(days, day)
+	}
+	return days, true
+}
+
+func parseRRule(text string) (Recurrence, error) {
+	r := Recurrence{Interval: 1}
+	for _, part := range strings.Split(text, ";") {
+		if part == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return Recurrence{}, fmt.Errorf("%w: malformed rule part %q", ErrInvalidRecurrence, part)
+		}
+		switch key {
+		case "FREQ":
+			switch RecurrenceFreq(value) {
+			case FreqDaily, FreqWeekly, FreqMonthly, FreqYearly:
+				r.Freq = RecurrenceFreq(value)
+			default:
+				return Recurrence{}, fmt.Errorf("%w: unsupported FREQ %q", ErrInvalidRecurrence, value)
+			}
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n <= 0 {
+				return Recurrence{}, fmt.Errorf("%w: INTERVAL must be a positive number", ErrInvalidRecurrence)
+			}
+			r.Interval = n
+		case "BYDAY":
+			for _, code := range strings.Split(value, ",") {
+				day := indexOf(rruleDays, code)
+				if day < 0 {
+					return Recurrence{}, fmt.Errorf("%w: unsupported BYDAY %q", ErrInvalidRecurrence, code)
+				}
+				r.ByDay = appendWeekday(r.ByDay, time.Weekday(day))
+			}
+		case "BYMONTHDAY":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 || n > 31 {
+				return Recurrence{}, fmt.Errorf("%w: BYMONTHDAY must be a day from 1 to 31", ErrInvalidRecurrence)
+			}
+			r.ByMonthDay = n
+		case "BYMONTH":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 || n > 12 {
+				return Recurrence{}, fmt.Errorf("%w: BYMONTH must be a month from 1 to 12", ErrInvalidRecurrence)
+			}
+			r.ByMonth = time.Month(n)
+		case "X-TD-FROM":
+			if value != "DONE" {
+				return Recurrence{}, fmt.Errorf("%w: unsupported X-TD-FROM %q", ErrInvalidRecurrence, value)
+			}
+			r.AfterCompletion = true
+		case "X-TD-ANCHOR":
+			if value != "DUE" {
+				return Recurrence{}, fmt.Errorf("%w: unsupported X-TD-ANCHOR %q", ErrInvalidRecurrence, value)
+			}
+			r.FromDue = true
+		default:
+			return Recurrence{}, fmt.Errorf("%w: unsupported rule part %s", ErrInvalidRecurrence, key)
+		}
+	}
+	if r.Freq == "" {
+		return Recurrence{}, fmt.Errorf("%w: FREQ is required", ErrInvalidRecurrence)
+	}
+	if len(r.ByDay) > 0 && r.Freq != FreqWeekly {
+		return Recurrence{}, fmt.Errorf("%w: BYDAY only works with FREQ=WEEKLY", ErrInvalidRecurrence)
+	}
+	if r.ByMonthDay > 0 && r.Freq != FreqMonthly && r.Freq != FreqYearly {
+		return Recurrence{}, fmt.Errorf("%w: BYMONTHDAY only works with FREQ=MONTHLY or FREQ=YEARLY", ErrInvalidRecurrence)
+	}
+	if r.ByMonth > 0 && r.Freq != FreqYearly {
+		return Recurrence{}, fmt.Errorf("%w: BYMONTH only works with FREQ=YEARLY", ErrInvalidRecurrence)
+	}
+	if r.FromDue && r.ByMonthDay == 0 {
+		return Recurrence{}, fmt.Errorf("%w: X-TD-ANCHOR needs BYMONTHDAY", ErrInvalidRecurrence)
+	}
+	return r, nil
+}
+
+func appendWeekday(days []time.Weekday, day time.Weekday) []time.Weekday {
+	for i, existing := range days {
+		if existing == day {
+			return days
+		}
+		if weekdayOrder(day) < weekdayOrder(existing) {
+			days = append(days, 0)
+			copy(days[i+1:], days[i:])
+			days[i] = day
+			return days
+		}
+	}
+	return append(days, day)
+}
+
+// weekdayOrder puts Monday first, matching how BYDAY lists are written.
+func weekdayOrder(day time.Weekday) int {
+	return (int(day) + 6) % 7
+}
+
+func indexOf(items []string, value string) int {
+	for i, item := range items {
+		if item == value {
+			return i
+		}
+	}
+	return -1
+}
+
+// String returns the rule in its stored RRULE form; after-completion rules
+// carry X-TD-FROM=DONE and anchors copied from the due date X-TD-ANCHOR=DUE.
+func (r Recurrence) String() string {
+	if r.IsZero() {
+		return ""
+	}
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		codes := make([]string, 0, len(r.ByDay))
+		for _, day := range r.ByDay {
+			codes = append(codes, rruleDays[day])
+		}
+		parts = append(parts, "BYDAY="+strings.Join(codes, ","))
+	}
+	if r.ByMonth > 0 {
+		parts = append(parts, "BYMONTH="+strconv.Itoa(int(r.ByMonth)))
+	}
+	if r.ByMonthDay > 0 {
+		parts = append(parts, "BYMONTHDAY="+strconv.Itoa(r.ByMonthDay))
+	}
+	if r.AfterCompletion {
+		parts = append(parts, "X-TD-FROM=DONE")
+	}
+	if r.FromDue {
+		parts = append(parts, "X-TD-ANCHOR=DUE")
+	}
+	return strings.Join(parts, ";")
+}
+
+// Short is a compact label for list views, such as "weekly", "2w" or
+// "mon,wed".
+func (r Recurrence) Short() string {
+	if r.IsZero() {
+		return ""
+	}
+	if len(r.ByDay) > 0 {
+		label := strings.ToLower(r.dayNames(","))
+		if r.isWorkWeek() {
+			label = "weekdays"
+		}
+		if r.Interval > 1 {
+			label = strconv.Itoa(r.Interval) + "w " + label
+		}
+		return label
+	}
+	interval := r.interval()
+	if interval == 1 {
+		switch r.Freq {
+		case FreqDaily:
+			return "daily"
+		case FreqWeekly:
+			return "weekly"
+		case FreqMonthly:
+			return "monthly"
+		default:
+			return "yearly"
+		}
+	}
+	return strconv.Itoa(interval) + strings.ToLower(string(r.Freq[:1]))
+}
+
+// Describe spells the rule out for td show.
+func (r Recurrence) Describe() string {
+	if r.IsZero() {
+		return ""
+	}
+	unit := map[RecurrenceFreq]string{
+		FreqDaily:   "day",
+		FreqWeekly:  "week",
+		FreqMonthly: "month",
+		FreqYearly:  "year",
+	}[r.Freq]
+	text := "every " + unit
+	if interval := r.interval(); interval > 1 {
+		text = fmt.Sprintf("every %d %ss", interval, unit)
+	}
+	if len(r.ByDay) > 0 {
+		if r.isWorkWeek() && r.interval() == 1 {
+			text = "every weekday"
+		} else {
+			text += " on " + r.dayNames(", ")
+		}
+	}
+	switch {
+	case r.ByMonth > 0 && r.ByMonthDay > 0:
+		text += fmt.Sprintf(" on %s %d", r.ByMonth.String()[:3], r.ByMonthDay)
+	case r.ByMonthDay > 0:
+		text += fmt.Sprintf(" on day %d", r.ByMonthDay)
+	}
+	if r.AfterCompletion {
+		return text + " after completion"
+	}
+	return text
+}
+
+// Anchored pins a monthly or yearly rule to the local day, and for yearly
+// rules the month, of due. Steps then clamp from that day instead of from
+// the previous occurrence, so Jan 31 goes on to Feb 28 and then Mar 31.
+// Rules that already have an anchor, count from completion or come without
+// a due are returned unchanged.
+func (r Recurrence) Anchored(due *time.Time) Recurrence {
+	if due == nil || r.AfterCompletion || r.ByMonthDay > 0 || r.ByMonth > 0 {
+		return r
+	}
+	local := due.In(time.Local)
+	switch r.Freq {
+	case FreqMonthly:
+		r.ByMonthDay, r.FromDue = local.Day(), true
+	case FreqYearly:
+		r.ByMonthDay, r.ByMonth, r.FromDue = local.Day(), local.Month(), true
+	}
+	return r
+}
+
+// Reanchored moves an anchor copied from the old due date to due. Anchors
+// given in the rule stay.
+func (r Recurrence) Reanchored(due *time.Time) Recurrence {
+	if r.FromDue {
+		r.ByMonthDay, r.ByMonth, r.FromDue = 0, 0, false
+	}
+	return r.Anchored(due)
+}
+
+func (r Recurrence) dayNames(sep string) string {
+	names := make([]string, 0, len(r.ByDay))
+	for _, day := range r.ByDay {
+		names = append(names, day.String()[:3])
+	}
+	return strings.Join(names, sep)
+}
+
+func (r Recurrence) isWorkWeek() bool {
+	if len(r.ByDay) != len(workWeek) {
+		return false
+	}
+	for i, day := range workWeek {
+		if r.ByDay[i] != day {
+			return false
+		}
+	}
+	return true
+}
+
+func (r Recurrence) interval() int {
+	if r.Interval < 1 {
+		return 1
+	}
+	return r.Interval
+}
+
+// Next returns the due time of the occurrence after a task completed at
+// doneAt, in doneAt's location. A fixed schedule steps from due and skips
+// occurrences that are already past; after completion it steps once from
+// the completion day. The time of day of due is kept; without a due the
+// next occurrence is due at 23:59, like a date-only `td due`.
+func (r Recurrence) Next(due *time.Time, doneAt time.Time) time.Time {
+	loc := doneAt.Location()
+	hour, minute := 23, 59
+	if due != nil {
+		local := due.In(loc)
+		hour, minute = local.Hour(), local.Minute()
+	}
+	if due == nil || r.AfterCompletion {
+		anchor := time.Date(doneAt.Year(), doneAt.Month(), doneAt.Day(), hour, minute, 0, 0, loc)
+		return r.step(anchor)
+	}
+	local := due.In(loc)
+	next := r.step(time.Date(local.Year(), local.Month(), local.Day(), hour, minute, 0, 0, loc))
+	for !next.After(doneAt) {
+		next = r.step(next)
+	}
+	return next
+}
+
+func (r Recurrence) step(t time.Time) time.Time {
+	interval := r.interval()
+	switch r.Freq {
+	case FreqDaily:
+		return t.AddDate(0, 0, interval)
+	case FreqWeekly:
+		if len(r.ByDay) == 0 {
+			return t.AddDate(0, 0, 7*interval)
+		}
+		weekStart := t.AddDate(0, 0, -weekdayOrder(t.Weekday()))
+		for i := 1; i <= 7*interval+7; i++ {
+			candidate := t.AddDate(0, 0, i)
+			if !containsWeekday(r.ByDay, candidate.Weekday()) {
+				continue
+			}
+			weeks := daysBetween(weekStart, candidate) / 7
+			if weeks%interval == 0 {
+				return candidate
+			}
+		}
+		return t.AddDate(0, 0, 7*interval)
+	case FreqMonthly:
+		return r.addMonthsClamped(t, interval)
+	default:
+		return r.addMonthsClamped(t, 12*interval)
+	}
+}
+
+// addMonthsClamped moves to the anchor day, or the day of t, of the target
+// month and keeps it within that month, so Jan 31 plus a month is the last
+// day of February instead of early March.
+func (r Recurrence) addMonthsClamped(t time.Time, months int) time.Time {
+	first := time.Date(t.Year(), t.Month(), 1, t.Hour(), t.Minute(), t.Second(), 0, t.Location())
+	target := first.AddDate(0, months, 0)
+	if r.Freq == FreqYearly && r.ByMonth > 0 {
+		target = time.Date(target.Year(), r.ByMonth, 1, t.Hour(), t.Minute(), t.Second(), 0, t.Location())
+	}
+	lastDay := target.AddDate(0, 1, -1).Day()
+	day := t.Day()
+	if r.ByMonthDay > 0 {
+		day = r.ByMonthDay
+	}
+	if day > lastDay {
+		day = lastDay
+	}
+	return time.Date(target.Year(), target.Month(), day, t.Hour(), t.Minute(), t.Second(), 0, t.Location())
+}
+
+func daysBetween(from, to time.Time) int {
+	a := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	b := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	return int(b.Sub(a).Hours() / 24)
+}
+
+func containsWeekday(days []time.Weekday, day time.Weekday) bool {
+	for _, item := range days {
+		if item == day {
+			return true
+		}
+	}
+	return false
+}
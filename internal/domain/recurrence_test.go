@@ -0,0 +1,159 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseRecurrenceShouldNormalizeRules(t *testing.T) {
+	for raw, want := range map[string]string{
+		"daily":                           "FREQ=DAILY",
+		"every 2 weeks":                   "FREQ=WEEKLY;INTERVAL=2",
+		"weekdays":                        "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR",
+		"thu,mon":                         "FREQ=WEEKLY;BYDAY=MO,TH",
+		"1m after completion":             "FREQ=MONTHLY;X-TD-FROM=DONE",
+		"FREQ=WEEKLY;INTERVAL=2;BYDAY=MO": "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO",
+		"RRULE:FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=29":  "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=29",
+		"FREQ=MONTHLY;BYMONTHDAY=31;X-TD-ANCHOR=DUE": "FREQ=MONTHLY;BYMONTHDAY=31;X-TD-ANCHOR=DUE",
+	} {
+		r, err := ParseRecurrence(raw)
+		if err != nil {
+			t.Fatalf("parse %q: %v", raw, err)
+		}
+		if got := r.String(); got != want {
+			t.Fatalf("parse %q = %q, want %q", raw, got, want)
+		}
+	}
+}
+
+func TestParseRecurrenceShouldRejectUnsupportedRules(t *testing.T) {
+	for _, raw := range []string{
+		"FREQ=HOURLY",
+		"FREQ=DAILY;INTERVAL=0",
+		"FREQ=DAILY;INTERVAL=-1",
+		"FREQ=WEEKLY;BYDAY=XX",
+		"FREQ=WEEKLY;BYDAY=1MO",
+		"FREQ=DAILY;BYDAY=MO",
+		"FREQ=WEEKLY;BYMONTHDAY=3",
+		"FREQ=MONTHLY;BYMONTHDAY=32",
+		"FREQ=MONTHLY;BYMONTH=2",
+		"FREQ=MONTHLY;X-TD-ANCHOR=DUE",
+		"INTERVAL=2",
+		"0d",
+		"fortnightly",
+	} {
+		if _, err := ParseRecurrence(raw); !errors.Is(err, ErrInvalidRecurrence) {
+			t.Fatalf("parse %q error = %v, want ErrInvalidRecurrence", raw, err)
+		}
+	}
+}
+
+func TestRecurrenceNextShouldFollowFixedSchedule(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		rule string
+		due  time.Time
+		want []string
+	}{
+		{
+			name: "month end clamps without drifting",
+			rule: "monthly",
+			due:  localTime(2026, 1, 31, 9, 0),
+			want: []string{"2026-02-28 09:00", "2026-03-31 09:00", "2026-04-30 09:00", "2026-05-31 09:00"},
+		},
+		{
+			name: "every two months from the 30th",
+			rule: "2m",
+			due:  localTime(2025, 12, 30, 9, 0),
+			want: []string{"2026-02-28 09:00", "2026-04-30 09:00", "2026-06-30 09:00"},
+		},
+		{
+			name: "feb 29 comes back in leap years",
+			rule: "yearly",
+			due:  localTime(2024, 2, 29, 9, 0),
+			want: []string{"2025-02-28 09:00", "2026-02-28 09:00", "2027-02-28 09:00", "2028-02-29 09:00"},
+		},
+		{
+			name: "byday every other week",
+			rule: "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR",
+			due:  localTime(2026, 3, 2, 9, 0),
+			want: []string{"2026-03-06 09:00", "2026-03-16 09:00", "2026-03-20 09:00", "2026-03-30 09:00"},
+		},
+		{
+			name: "weekdays skip the weekend",
+			rule: "weekdays",
+			due:  localTime(2026, 3, 5, 18, 0),
+			want: []string{"2026-03-06 18:00", "2026-03-09 18:00"},
+		},
+	} {
+		r, err := ParseRecurrence(tc.rule)
+		if err != nil {
+			t.Fatalf("%s: parse: %v", tc.name, err)
+		}
+		r = r.Anchored(&tc.due)
+		due := tc.due
+		for i, want := range tc.want {
+			due = r.Next(&due, due)
+			if got := due.Format("2006-01-02 15:04"); got != want {
+				t.Fatalf("%s: occurrence %d = %s, want %s", tc.name, i+1, got, want)
+			}
+		}
+	}
+}
+
+func TestRecurrenceNextShouldSkipPastOccurrences(t *testing.T) {
+	r, _ := ParseRecurrence("daily")
+	due := localTime(2026, 3, 1, 9, 0)
+	next := r.Next(&due, localTime(2026, 3, 5, 12, 0))
+	if want := localTime(2026, 3, 6, 9, 0); !next.Equal(want) {
+		t.Fatalf("next = %s, want %s", next, want)
+	}
+}
+
+func TestRecurrenceNextShouldCountFromCompletion(t *testing.T) {
+	r, _ := ParseRecurrence("2w after completion")
+	due := localTime(2026, 3, 1, 9, 0)
+	next := r.Anchored(&due).Next(&due, localTime(2026, 3, 10, 15, 0))
+	if want := localTime(2026, 3, 24, 9, 0); !next.Equal(want) {
+		t.Fatalf("next = %s, want %s", next, want)
+	}
+
+	r, _ = ParseRecurrence("monthly after completion")
+	if anchored := r.Anchored(&due); anchored.ByMonthDay != 0 {
+		t.Fatalf("after completion rule should not be anchored, got %s", anchored)
+	}
+	next = r.Next(&due, localTime(2026, 3, 20, 15, 0))
+	if want := localTime(2026, 4, 20, 9, 0); !next.Equal(want) {
+		t.Fatalf("next = %s, want %s", next, want)
+	}
+
+	next = r.Next(nil, localTime(2026, 3, 20, 15, 0))
+	if want := localTime(2026, 4, 20, 23, 59); !next.Equal(want) {
+		t.Fatalf("next without due = %s, want %s", next, want)
+	}
+}
+
+func TestAnchoredShouldKeepExplicitAnchor(t *testing.T) {
+	r, _ := ParseRecurrence("FREQ=MONTHLY;BYMONTHDAY=15")
+	due := localTime(2026, 1, 31, 9, 0)
+	if got := r.Anchored(&due).String(); got != "FREQ=MONTHLY;BYMONTHDAY=15" {
+		t.Fatalf("anchored = %q", got)
+	}
+	if got := r.Reanchored(&due).String(); got != "FREQ=MONTHLY;BYMONTHDAY=15" {
+		t.Fatalf("reanchored = %q", got)
+	}
+	implicit, _ := ParseRecurrence("yearly")
+	moved := localTime(2026, 3, 10, 9, 0)
+	if got := implicit.Anchored(&due).Reanchored(&moved).String(); got != "FREQ=YEARLY;BYMONTH=3;BYMONTHDAY=10;X-TD-ANCHOR=DUE" {
+		t.Fatalf("reanchored implicit = %q", got)
+	}
+	next := r.Next(&due, due)
+	if want := localTime(2026, 2, 15, 9, 0); !next.Equal(want) {
+		t.Fatalf("next = %s, want %s", next, want)
+	}
+}
+
+func localTime(year int, month time.Month, day, hour, minute int) time.Time {
+	return time.Date(year, month, day, hour, minute, 0, 0, time.Local)
+}
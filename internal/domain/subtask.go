@@ -0,0 +1,54 @@
+package domain
+
+// Progress counts the direct subtasks of a task that are not deleted.
+type Progress struct {
+	Done  int
+	Total int
+}
+
+func (p Progress) Open() int {
+	return p.Total - p.Done
+}
+
+// NestSubtasks reorders tasks so each subtask follows its parent when both
+// are present, keeping the original order among siblings and roots. The
+// returned depths are parallel to the result.
+func NestSubtasks(tasks []Task) ([]Task, []int) {
+	present := make(map[int64]bool, len(tasks))
+	for _, task := range tasks {
+		present[task.ID] = true
+	}
+	children := make(map[int64][]Task)
+	roots := make([]Task, 0, len(tasks))
+	for _, task := range tasks {
+		if task.ParentID != 0 && present[task.ParentID] && task.ParentID != task.ID {
+			children[task.ParentID] = append(children[task.ParentID], task)
+			continue
+		}
+		roots = append(roots, task)
+	}
+
+	out := make([]Task, 0, len(tasks))
+	depths := make([]int, 0, len(tasks))
+	visited := make(map[int64]bool, len(tasks))
+	var walk func(task Task, depth int)
+	walk = func(task Task, depth int) {
+		if visited[task.ID] {
+			return
+		}
+		visited[task.ID] = true
+		out = append(out, task)
+		depths = append(depths, depth)
+		for _, child := range children[task.ID] {
+			walk(child, depth+1)
+		}
+	}
+	for _, task := range roots {
+		walk(task, 0)
+	}
+	// Tasks caught in a parent cycle have no root; keep them flat.
+	for _, task := range tasks {
+		walk(task, 0)
+	}
+	return out, depths
+}
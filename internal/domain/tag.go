@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+func NormalizeTag(raw string) string {
+	text := strings.TrimSpace(raw)
+	text = strings.TrimLeft(text, "#@")
+	return strings.ToLower(strings.TrimSpace(text))
+}
+
+func IsValidTag(tag string) bool {
+	if tag == "" {
+		return false
+	}
+	for _, r := range tag {
+		if unicode.IsSpace(r) || r == ',' {
+			return false
+		}
+	}
+	return true
+}
+
+func NormalizeTags(raw []string) ([]string, error) {
+	seen := make(map[string]struct{}, len(raw))
+	out := make([]string, 0, len(raw))
+	for _, item := range raw {
+		tag := NormalizeTag(item)
+		if !IsValidTag(tag) {
+			return nil, ErrInvalidTag
+		}
+		if _, ok := seen[tag]; ok {
+			continue
+		}
+		seen[tag] = struct{}{}
+		out = append(out, tag)
+	}
+	sort.Strings(out)
+	return out, nil
+}
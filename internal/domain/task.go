@@ -3,14 +3,40 @@ package domain
 import "time"
 
 type Task struct {
-	ID        int64
-	Title     string
-	Notes     string
-	Status    Status
-	Project   string
-	Priority  string
-	DueAt     *time.Time
-	DoneAt    *time.Time
+	ID          int64
+	ParentID    int64
+	Title       string
+	Notes       string
+	Status      Status
+	Project     string
+	Priority    string
+	DueAt       *time.Time
+	StartAt     *time.Time // kept out of Inbox, Today and its project until then
+	DoneAt      *time.Time
+	Tags        []string
+	Recurrence  Recurrence
+	SnoozeCount int        // times the due time was pushed back with snooze
+	WaitingOn   string     // who or what a waiting task waits on
+	FollowUpAt  *time.Time // when a waiting task shows up in Today again
+	// Subtasks and BlockedBy are filled in by the repository when reading
+	// tasks; BlockedBy only lists blockers that are still open.
+	Subtasks  Progress
+	BlockedBy []int64
 	CreatedAt time.Time
 	UpdatedAt time.Time
 }
+
+func (t Task) IsBlocked() bool {
+	return len(t.BlockedBy) > 0
+}
+
+// NeedsFollowUp reports whether the task is waiting and its follow-up
+// time is before end.
+func (t Task) NeedsFollowUp(end time.Time) bool {
+	return t.Status == StatusWaiting && t.FollowUpAt != nil && t.FollowUpAt.Before(end)
+}
+
+// IsDeferred reports whether the task starts after now.
+func (t Task) IsDeferred(now time.Time) bool {
+	return t.StartAt != nil && t.StartAt.After(now)
+}
@@ -0,0 +1,260 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+)
+
+var statusNameRegexp = regexp.MustCompile(`^[a-z][a-z0-9_-]*$`)
+
+var builtinStatuses = []Status{StatusDoing, StatusTodo, StatusWaiting, StatusDone, StatusInbox, StatusDeleted}
+
+var builtinTransitions = map[Status][]Status{
+	StatusInbox:   {StatusTodo, StatusDoing, StatusWaiting, StatusDone, StatusDeleted},
+	StatusTodo:    {StatusDoing, StatusWaiting, StatusDone, StatusDeleted},
+	StatusDoing:   {StatusTodo, StatusWaiting, StatusDone, StatusDeleted},
+	StatusWaiting: {StatusTodo, StatusDoing, StatusDone, StatusDeleted},
+	StatusDone:    {StatusTodo, StatusDeleted},
+	StatusDeleted: {StatusTodo},
+}
+
+// customTransitions are the moves of a custom status that the workflow
+// does not list.
+var customTransitions = []Status{StatusTodo, StatusDoing, StatusDone, StatusDeleted}
+
+// Workflow is the set of statuses a task can be in and the moves between
+// them. Every status but deleted is either open or closed. The zero
+// Workflow is the built-in one.
+type Workflow struct {
+	statuses    []Status
+	transitions map[Status]map[Status]bool
+	open        map[Status]bool
+	closed      map[Status]bool
+}
+
+// WorkflowSpec describes the changes to the built-in workflow. Empty
+// fields keep the built-in behaviour.
+type WorkflowSpec struct {
+	// Statuses adds custom statuses.
+	Statuses []Status
+	// Transitions replaces the moves out of each listed status.
+	Transitions map[Status][]Status
+	// Open and Closed classify statuses; unlisted ones are open, except
+	// done which is always closed.
+	Open   []Status
+	Closed []Status
+	// Order lists statuses in the order td ls sorts them; unlisted ones
+	// follow in the built-in order.
+	Order []Status
+}
+
+var builtinWorkflow = mustWorkflow(WorkflowSpec{})
+
+func DefaultWorkflow() Workflow {
+	return builtinWorkflow
+}
+
+func mustWorkflow(spec WorkflowSpec) Workflow {
+	w, err := NewWorkflow(spec)
+	if err != nil {
+		panic(err)
+	}
+	return w
+}
+
+func NewWorkflow(spec WorkflowSpec) (Workflow, error) {
+	w := Workflow{
+		transitions: make(map[Status]map[Status]bool),
+		open:        make(map[Status]bool),
+		closed:      map[Status]bool{StatusDone: true},
+	}
+	known := make(map[Status]bool, len(builtinStatuses)+len(spec.Statuses))
+	for _, s := range builtinStatuses {
+		known[s] = true
+	}
+	custom := make([]Status, 0, len(spec.Statuses))
+	for _, s := range spec.Statuses {
+		if !statusNameRegexp.MatchString(string(s)) {
+			return Workflow{}, fmt.Errorf("workflow status %q: use lowercase letters, digits, - and _", s)
+		}
+		if known[s] {
+			return Workflow{}, fmt.Errorf("workflow status %q is already defined", s)
+		}
+		known[s] = true
+		custom = append(custom, s)
+	}
+	check := func(where string, s Status) error {
+		if !known[s] {
+			return fmt.Errorf("workflow %s: unknown status %q", where, s)
+		}
+		return nil
+	}
+
+	for from, tos := range builtinTransitions {
+		w.transitions[from] = statusSet(tos)
+	}
+	for _, s := range custom {
+		w.transitions[s] = statusSet(customTransitions)
+	}
+	for from, tos := range spec.Transitions {
+		if err := check("transitions", from); err != nil {
+			return Workflow{}, err
+		}
+		for _, to := range tos {
+			if err := check("transitions of "+string(from), to); err != nil {
+				return Workflow{}, err
+			}
+		}
+		w.transitions[from] = statusSet(tos)
+	}
+	for _, s := range custom {
+		if !w.reachable(s) {
+			return Workflow{}, fmt.Errorf("workflow status %q cannot be reached; list it in the transitions of another status", s)
+		}
+	}
+
+	for _, s := range spec.Closed {
+		if err := check("closed", s); err != nil {
+			return Workflow{}, err
+		}
+		w.closed[s] = true
+	}
+	for _, s := range spec.Open {
+		if err := check("open", s); err != nil {
+			return Workflow{}, err
+		}
+		if w.closed[s] {
+			return Workflow{}, fmt.Errorf("workflow status %q cannot be both open and closed", s)
+		}
+		w.open[s] = true
+	}
+	if w.open[StatusDeleted] || w.closed[StatusDeleted] {
+		return Workflow{}, fmt.Errorf("workflow status %q is neither open nor closed", StatusDeleted)
+	}
+
+	seen := make(map[Status]bool, len(known))
+	for _, s := range spec.Order {
+		if err := check("order", s); err != nil {
+			return Workflow{}, err
+		}
+		if !seen[s] {
+			seen[s] = true
+			w.statuses = append(w.statuses, s)
+		}
+	}
+	// Custom statuses default to just before done, next to the other open
+	// statuses.
+	defaults := make([]Status, 0, len(known))
+	for _, s := range builtinStatuses {
+		if s == StatusDone {
+			defaults = append(defaults, custom...)
+		}
+		defaults = append(defaults, s)
+	}
+	for _, s := range defaults {
+		if !seen[s] {
+			w.statuses = append(w.statuses, s)
+		}
+		if s != StatusDeleted && !w.closed[s] {
+			w.open[s] = true
+		}
+	}
+	return w, nil
+}
+
+func (w Workflow) orBuiltin() Workflow {
+	if w.transitions == nil {
+		return builtinWorkflow
+	}
+	return w
+}
+
+func (w Workflow) Has(s Status) bool {
+	_, ok := w.orBuiltin().transitions[s]
+	return ok
+}
+
+func (w Workflow) ParseStatus(raw string) (Status, error) {
+	s := Status(raw)
+	if !w.Has(s) {
+		return "", ErrInvalidStatus
+	}
+	return s, nil
+}
+
+// CanTransit reports whether a task can move from one status to another.
+// A task left in a status the workflow no longer has can move anywhere.
+func (w Workflow) CanTransit(from, to Status) bool {
+	w = w.orBuiltin()
+	if !w.Has(to) {
+		return false
+	}
+	if from == to || !w.Has(from) {
+		return true
+	}
+	return w.transitions[from][to]
+}
+
+// IsOpen reports whether tasks in s are still to be worked on.
+func (w Workflow) IsOpen(s Status) bool {
+	return w.orBuiltin().open[s]
+}
+
+// IsClosed reports whether tasks in s are finished; they get a done time
+// and show up in the Log view.
+func (w Workflow) IsClosed(s Status) bool {
+	return w.orBuiltin().closed[s]
+}
+
+// Statuses returns every status in sort order, deleted included.
+func (w Workflow) Statuses() []Status {
+	return append([]Status(nil), w.orBuiltin().statuses...)
+}
+
+func (w Workflow) OpenStatuses() []Status {
+	w = w.orBuiltin()
+	return w.filter(w.open)
+}
+
+func (w Workflow) ClosedStatuses() []Status {
+	w = w.orBuiltin()
+	return w.filter(w.closed)
+}
+
+// Rank orders statuses for listing; unknown statuses sort last.
+func (w Workflow) Rank(s Status) int {
+	w = w.orBuiltin()
+	for i, status := range w.statuses {
+		if status == s {
+			return i
+		}
+	}
+	return len(w.statuses)
+}
+
+func (w Workflow) reachable(s Status) bool {
+	for from, tos := range w.transitions {
+		if from != s && tos[s] {
+			return true
+		}
+	}
+	return false
+}
+
+func (w Workflow) filter(set map[Status]bool) []Status {
+	out := make([]Status, 0, len(set))
+	for _, s := range w.statuses {
+		if set[s] {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func statusSet(items []Status) map[Status]bool {
+	out := make(map[Status]bool, len(items))
+	for _, s := range items {
+		out[s] = true
+	}
+	return out
+}
@@ -0,0 +1,415 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"td/internal/domain"
+)
+
+// Error reports a problem with one term of a query.
+type Error struct {
+	Term string
+	Pos  int
+	Msg  string
+}
+
+func (e *Error) Error() string {
+	if e.Term == "" {
+		return "invalid query: " + e.Msg
+	}
+	return fmt.Sprintf("invalid query term %q at column %d: %s", e.Term, e.Pos+1, e.Msg)
+}
+
+type token struct {
+	text string
+	raw  string
+	pos  int
+	// quoted is the index in text where quoted content starts, or -1.
+	quoted int
+}
+
+type term struct {
+	token
+	negate bool
+	field  string
+	op     string
+	value  string
+}
+
+var weekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "sun": time.Sunday,
+	"monday": time.Monday, "mon": time.Monday,
+	"tuesday": time.Tuesday, "tue": time.Tuesday,
+	"wednesday": time.Wednesday, "wed": time.Wednesday,
+	"thursday": time.Thursday, "thu": time.Thursday,
+	"friday": time.Friday, "fri": time.Friday,
+	"saturday": time.Saturday, "sat": time.Saturday,
+}
+
+// Parse turns a filter expression into a Query. Relative dates resolve
+// against now, using calendar days in now's location, and statuses must
+// belong to workflow.
+//
+//	project:work status:todo,doing due<friday pri<=P2 -tag:later "report"
+func Parse(input string, now time.Time, workflow domain.Workflow) (Query, error) {
+	tokens, err := tokenize(input)
+	if err != nil {
+		return Query{}, err
+	}
+	q := Query{Workflow: workflow}
+	for _, tok := range tokens {
+		t, err := splitTerm(tok)
+		if err != nil {
+			return Query{}, err
+		}
+		if err := q.apply(t, now); err != nil {
+			return Query{}, err
+		}
+	}
+	if len(q.EffectiveStatuses()) == 0 {
+		return Query{}, &Error{Msg: "status filters exclude every status"}
+	}
+	if q.Priorities != nil && len(q.Priorities) == 0 {
+		return Query{}, &Error{Msg: "priority filters exclude every priority"}
+	}
+	return q, nil
+}
+
+func tokenize(input string) ([]token, error) {
+	runes := []rune(input)
+	out := make([]token, 0, 8)
+	for i := 0; i < len(runes); {
+		if unicode.IsSpace(runes[i]) {
+			i++
+			continue
+		}
+		start := i
+		var text strings.Builder
+		quoted := -1
+		for i < len(runes) && !unicode.IsSpace(runes[i]) {
+			if runes[i] != '"' {
+				text.WriteRune(runes[i])
+				i++
+				continue
+			}
+			if quoted < 0 {
+				quoted = len(text.String())
+			}
+			end := i + 1
+			for end < len(runes) && runes[end] != '"' {
+				end++
+			}
+			if end >= len(runes) {
+				return nil, &Error{Term: string(runes[start:]), Pos: start, Msg: "missing closing quote"}
+			}
+			text.WriteString(string(runes[i+1 : end]))
+			i = end + 1
+		}
+		out = append(out, token{
+			text:   text.String(),
+			raw:    string(runes[start:i]),
+			pos:    start,
+			quoted: quoted,
+		})
+	}
+	return out, nil
+}
+
+func splitTerm(tok token) (term, error) {
+	t := term{token: tok}
+	text := tok.text
+	if strings.HasPrefix(text, "-") && tok.quoted != 0 {
+		t.negate = true
+		text = text[1:]
+		if t.quoted > 0 {
+			t.quoted--
+		}
+	}
+	limit := len(text)
+	if t.quoted >= 0 {
+		limit = t.quoted
+	}
+	opAt := strings.IndexAny(text[:limit], ":<>=")
+	if opAt <= 0 {
+		if text == "" {
+			return term{}, &Error{Term: tok.raw, Pos: tok.pos, Msg: "empty term"}
+		}
+		t.value = text
+		return t, nil
+	}
+	t.field = strings.ToLower(text[:opAt])
+	t.op = text[opAt : opAt+1]
+	if (t.op == "<" || t.op == ">") && opAt+1 < len(text) && text[opAt+1] == '=' {
+		t.op += "="
+	}
+	t.value = text[opAt+len(t.op):]
+	if t.value == "" {
+		return term{}, &Error{Term: tok.raw, Pos: tok.pos, Msg: fmt.Sprintf("missing value after %s%s", t.field, t.op)}
+	}
+	return t, nil
+}
+
+func (q *Query) apply(t term, now time.Time) error {
+	fail := func(format string, args ...any) error {
+		return &Error{Term: t.raw, Pos: t.pos, Msg: fmt.Sprintf(format, args...)}
+	}
+	if t.field == "" {
+		if t.negate {
+			q.ExcludeText = append(q.ExcludeText, t.value)
+		} else {
+			q.Text = append(q.Text, t.value)
+		}
+		return nil
+	}
+
+	switch t.field {
+	case "project", "proj":
+		if t.op != ":" && t.op != "=" {
+			return fail("project only supports ':'")
+		}
+		values := splitValues(t.value)
+		if t.negate {
+			q.ExcludeProjects = append(q.ExcludeProjects, values...)
+		} else {
+			q.Projects = append(q.Projects, values...)
+		}
+	case "status", "is":
+		if t.op != ":" && t.op != "=" {
+			return fail("status only supports ':'")
+		}
+		for _, value := range splitValues(t.value) {
+			status, err := q.Workflow.ParseStatus(strings.ToLower(value))
+			if err != nil {
+				return fail("unknown status %q (use %s)", value, joinStatuses(q.Workflow.Statuses()))
+			}
+			if t.negate {
+				q.ExcludeStatuses = append(q.ExcludeStatuses, status)
+			} else {
+				q.Statuses = append(q.Statuses, status)
+			}
+		}
+	case "tag":
+		if t.op != ":" && t.op != "=" {
+			return fail("tag only supports ':'")
+		}
+		for _, value := range splitValues(t.value) {
+			tag := domain.NormalizeTag(value)
+			if !domain.IsValidTag(tag) {
+				return fail("invalid tag %q", value)
+			}
+			if t.negate {
+				q.ExcludeTags = append(q.ExcludeTags, tag)
+			} else {
+				q.Tags = append(q.Tags, tag)
+			}
+		}
+	case "pri", "priority":
+		allowed, err := priorityRange(t.op, t.value)
+		if err != nil {
+			return fail("%v", err)
+		}
+		if t.negate {
+			allowed = complementPriorities(allowed)
+		}
+		q.Priorities = intersectPriorities(q.Priorities, allowed)
+	case "due", "done":
+		if t.negate {
+			return fail("%s cannot be negated, use the opposite comparison", t.field)
+		}
+		target := &q.Due
+		if t.field == "done" {
+			target = &q.Done
+		}
+		if err := applyDate(target, t.op, t.value, now); err != nil {
+			return fail("%v", err)
+		}
+	default:
+		return fail("unknown field %q (use project, status, tag, pri, due or done; quote text that contains ':')", t.field)
+	}
+	return nil
+}
+
+func splitValues(raw string) []string {
+	parts := strings.Split(raw, ",")
+	out := make([]string, 0, len(parts))
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+var allPriorities = []string{"P1", "P2", "P3", "P4"}
+
+// priorityRange compares by rank, so P1 < P2: pri<=P2 selects P1 and P2.
+func priorityRange(op, raw string) ([]string, error) {
+	values := splitValues(raw)
+	if op != ":" && op != "=" && len(values) != 1 {
+		return nil, fmt.Errorf("%s takes a single priority", op)
+	}
+	out := make([]string, 0, len(allPriorities))
+	for _, value := range values {
+		priority := strings.ToUpper(value)
+		if !strings.HasPrefix(priority, "P") {
+			priority = "P" + priority
+		}
+		if !domain.IsValidPriority(priority) {
+			return nil, fmt.Errorf("unknown priority %q (use P1-P4)", value)
+		}
+		rank := domain.PriorityRank(priority)
+		for _, candidate := range allPriorities {
+			r := domain.PriorityRank(candidate)
+			var ok bool
+			switch op {
+			case ":", "=":
+				ok = r == rank
+			case "<":
+				ok = r < rank
+			case "<=":
+				ok = r <= rank
+			case ">":
+				ok = r > rank
+			case ">=":
+				ok = r >= rank
+			}
+			if ok && !containsString(out, candidate) {
+				out = append(out, candidate)
+			}
+		}
+	}
+	return out, nil
+}
+
+func complementPriorities(items []string) []string {
+	out := make([]string, 0, len(allPriorities))
+	for _, candidate := range allPriorities {
+		if !containsString(items, candidate) {
+			out = append(out, candidate)
+		}
+	}
+	return out
+}
+
+func intersectPriorities(current, allowed []string) []string {
+	if current == nil {
+		return allowed
+	}
+	out := make([]string, 0, len(current))
+	for _, item := range current {
+		if containsString(allowed, item) {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+func applyDate(r *DateRange, op, raw string, now time.Time) error {
+	switch strings.ToLower(raw) {
+	case "none":
+		if op != ":" && op != "=" {
+			return errors.New("none only supports ':'")
+		}
+		r.None = true
+		return nil
+	case "any":
+		if op != ":" && op != "=" {
+			return errors.New("any only supports ':'")
+		}
+		r.Any = true
+		return nil
+	}
+	day, err := ParseDate(raw, now)
+	if err != nil {
+		return err
+	}
+	next := day.AddDate(0, 0, 1)
+	switch op {
+	case ":", "=":
+		r.From = later(r.From, day)
+		r.To = earlier(r.To, next)
+	case "<":
+		r.To = earlier(r.To, day)
+	case "<=":
+		r.To = earlier(r.To, next)
+	case ">":
+		r.From = later(r.From, next)
+	case ">=":
+		r.From = later(r.From, day)
+	}
+	return nil
+}
+
+func later(current *time.Time, candidate time.Time) *time.Time {
+	if current != nil && current.After(candidate) {
+		return current
+	}
+	return &candidate
+}
+
+func earlier(current *time.Time, candidate time.Time) *time.Time {
+	if current != nil && current.Before(candidate) {
+		return current
+	}
+	return &candidate
+}
+
+// ParseDate resolves a day keyword to the start of that day in now's
+// location. It accepts today, tomorrow, yesterday, weekday names (the next
+// occurrence, today included), offsets such as +3d, -1w or 2d, and
+// YYYY-MM-DD.
+func ParseDate(raw string, now time.Time) (time.Time, error) {
+	value := strings.ToLower(strings.TrimSpace(raw))
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch value {
+	case "today":
+		return today, nil
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), nil
+	}
+	if weekday, ok := weekdays[value]; ok {
+		ahead := (int(weekday) - int(today.Weekday()) + 7) % 7
+		return today.AddDate(0, 0, ahead), nil
+	}
+	if days, ok := parseOffsetDays(value); ok {
+		return today.AddDate(0, 0, days), nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, now.Location()); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("unknown date %q (use today, tomorrow, a weekday, +3d, -1w or YYYY-MM-DD)", raw)
+}
+
+func parseOffsetDays(value string) (int, bool) {
+	if len(value) < 2 {
+		return 0, false
+	}
+	unit := value[len(value)-1]
+	if unit != 'd' && unit != 'w' {
+		return 0, false
+	}
+	n, err := strconv.Atoi(value[:len(value)-1])
+	if err != nil {
+		return 0, false
+	}
+	if unit == 'w' {
+		n *= 7
+	}
+	return n, true
+}
+
+func joinStatuses(statuses []domain.Status) string {
+	parts := make([]string, 0, len(statuses))
+	for _, status := range statuses {
+		parts = append(parts, string(status))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,135 @@
+package query
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"td/internal/domain"
+)
+
+func TestParseShouldBuildStructuredQuery(t *testing.T) {
+	now := time.Date(2026, 2, 23, 10, 0, 0, 0, time.UTC) // Monday
+	q, err := Parse(`project:work status:todo,doing due<friday pri<=P2 -tag:later "weekly report"`, now, domain.DefaultWorkflow())
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(q.Projects) != 1 || q.Projects[0] != "work" {
+		t.Fatalf("projects = %v", q.Projects)
+	}
+	if len(q.Statuses) != 2 || q.Statuses[0] != domain.StatusTodo || q.Statuses[1] != domain.StatusDoing {
+		t.Fatalf("statuses = %v", q.Statuses)
+	}
+	friday := time.Date(2026, 2, 27, 0, 0, 0, 0, time.UTC)
+	if q.Due.To == nil || !q.Due.To.Equal(friday) || q.Due.From != nil {
+		t.Fatalf("due = %+v, want before %v", q.Due, friday)
+	}
+	if strings.Join(q.Priorities, ",") != "P1,P2" {
+		t.Fatalf("priorities = %v", q.Priorities)
+	}
+	if len(q.ExcludeTags) != 1 || q.ExcludeTags[0] != "later" {
+		t.Fatalf("exclude tags = %v", q.ExcludeTags)
+	}
+	if len(q.Text) != 1 || q.Text[0] != "weekly report" {
+		t.Fatalf("text = %v", q.Text)
+	}
+
+	filter := q.Filter()
+	if filter.Project != "work" || len(filter.Statuses) != 2 || filter.DueTo == nil {
+		t.Fatalf("filter = %+v", filter)
+	}
+}
+
+func TestParseDateRangesShouldUseCalendarDays(t *testing.T) {
+	loc := time.FixedZone("CST", 8*3600)
+	now := time.Date(2026, 2, 23, 23, 30, 0, 0, loc)
+	q, err := Parse("due>=today due<=tomorrow", now, domain.DefaultWorkflow())
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	from := time.Date(2026, 2, 23, 0, 0, 0, 0, loc)
+	to := time.Date(2026, 2, 25, 0, 0, 0, 0, loc)
+	if !q.Due.From.Equal(from) || !q.Due.To.Equal(to) {
+		t.Fatalf("due = %v..%v, want %v..%v", q.Due.From, q.Due.To, from, to)
+	}
+
+	q, err = Parse("done:2026-02-20 due:none", now, domain.DefaultWorkflow())
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if !q.Due.None || q.Done.From == nil || q.Done.To.Sub(*q.Done.From) != 24*time.Hour {
+		t.Fatalf("query = %+v", q)
+	}
+}
+
+func TestQueryMatch(t *testing.T) {
+	now := time.Date(2026, 2, 23, 10, 0, 0, 0, time.UTC)
+	due := now.Add(2 * time.Hour)
+	task := domain.Task{
+		Title:    "Write weekly REPORT",
+		Status:   domain.StatusTodo,
+		Project:  "work",
+		Priority: "P2",
+		DueAt:    &due,
+		Tags:     []string{"urgent"},
+	}
+	cases := []struct {
+		expr string
+		want bool
+	}{
+		{"", true},
+		{"report", true},
+		{"-report", false},
+		{"project:work,home tag:urgent", true},
+		{"-project:work", false},
+		{"-tag:urgent", false},
+		{"pri<P2", false},
+		{"pri>=2", true},
+		{"due:today", true},
+		{"due:none", false},
+		{"due:any", true},
+		{"status:done", false},
+		{"-status:todo", false},
+	}
+	for _, tc := range cases {
+		q, err := Parse(tc.expr, now, domain.DefaultWorkflow())
+		if err != nil {
+			t.Fatalf("parse %q: %v", tc.expr, err)
+		}
+		if got := q.Match(task); got != tc.want {
+			t.Fatalf("match %q = %v, want %v", tc.expr, got, tc.want)
+		}
+	}
+
+	deleted := task
+	deleted.Status = domain.StatusDeleted
+	q, _ := Parse("report", now, domain.DefaultWorkflow())
+	if q.Match(deleted) {
+		t.Fatalf("default query should hide deleted tasks")
+	}
+}
+
+func TestParseShouldReportFriendlyErrors(t *testing.T) {
+	now := time.Date(2026, 2, 23, 10, 0, 0, 0, time.UTC)
+	cases := map[string]string{
+		`colour:red`:                            `unknown field "colour"`,
+		`status:later`:                          `unknown status "later"`,
+		`pri<=P9`:                               `unknown priority "P9"`,
+		`due<someday`:                           `unknown date "someday"`,
+		`project:`:                              `missing value after project:`,
+		`"report`:                               `missing closing quote`,
+		`-due<friday`:                           `cannot be negated`,
+		`-status:inbox,todo,doing,waiting,done`: `exclude every status`,
+	}
+	for expr, want := range cases {
+		_, err := Parse(expr, now, domain.DefaultWorkflow())
+		var qerr *Error
+		if !errors.As(err, &qerr) {
+			t.Fatalf("parse %q error = %v, want *Error", expr, err)
+		}
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("parse %q error = %q, want contains %q", expr, err.Error(), want)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package query
+
+import (
+	"strings"
+	"time"
+
+	"td/internal/domain"
+	"td/internal/repo"
+)
+
+// DefaultStatuses is used when a query does not name any status: every
+// open and closed status of the workflow, which leaves out deleted.
+func DefaultStatuses(workflow domain.Workflow) []domain.Status {
+	return append(workflow.OpenStatuses(), workflow.ClosedStatuses()...)
+}
+
+type DateRange struct {
+	From *time.Time
+	To   *time.Time
+	None bool
+	Any  bool
+}
+
+func (r DateRange) IsZero() bool {
+	return r.From == nil && r.To == nil && !r.None && !r.Any
+}
+
+func (r DateRange) match(value *time.Time) bool {
+	if r.None {
+		return value == nil
+	}
+	if r.IsZero() {
+		return true
+	}
+	if value == nil {
+		return false
+	}
+	if r.From != nil && value.Before(*r.From) {
+		return false
+	}
+	if r.To != nil && !value.Before(*r.To) {
+		return false
+	}
+	return true
+}
+
+type Query struct {
+	// Workflow is the one status names were checked against.
+	Workflow        domain.Workflow
+	Statuses        []domain.Status
+	ExcludeStatuses []domain.Status
+	Projects        []string
+	ExcludeProjects []string
+	Tags            []string
+	ExcludeTags     []string
+	Priorities      []string
+	Due             DateRange
+	Done            DateRange
+	Text            []string
+	ExcludeText     []string
+}
+
+// EffectiveStatuses returns the statuses the query selects after applying
+// defaults and exclusions.
+func (q Query) EffectiveStatuses() []domain.Status {
+	base := q.Statuses
+	if len(base) == 0 {
+		base = DefaultStatuses(q.Workflow)
+	}
+	out := make([]domain.Status, 0, len(base))
+	for _, status := range base {
+		if !containsStatus(q.ExcludeStatuses, status) {
+			out = append(out, status)
+		}
+	}
+	return out
+}
+
+// Filter returns the part of the query the repository can evaluate in SQL.
+// Callers still run Match on the result for the remaining predicates.
+func (q Query) Filter() repo.TaskListFilter {
+	filter := repo.TaskListFilter{Statuses: q.EffectiveStatuses()}
+	if len(q.Projects) == 1 {
+		filter.Project = q.Projects[0]
+	}
+	if len(q.Tags) > 0 {
+		filter.Tag = q.Tags[0]
+	}
+	if !q.Due.None {
+		filter.DueFrom = q.Due.From
+		filter.DueTo = q.Due.To
+	}
+	if !q.Done.None {
+		filter.DoneFrom = q.Done.From
+		filter.DoneTo = q.Done.To
+	}
+	return filter
+}
+
+func (q Query) Match(task domain.Task) bool {
+	if !containsStatus(q.EffectiveStatuses(), task.Status) {
+		return false
+	}
+	if len(q.Projects) > 0 && !containsString(q.Projects, task.Project) {
+		return false
+	}
+	if containsString(q.ExcludeProjects, task.Project) {
+		return false
+	}
+	for _, tag := range q.Tags {
+		if !containsString(task.Tags, tag) {
+			return false
+		}
+	}
+	for _, tag := range q.ExcludeTags {
+		if containsString(task.Tags, tag) {
+			return false
+		}
+	}
+	if q.Priorities != nil && !containsString(q.Priorities, domain.NormalizePriority(task.Priority)) {
+		return false
+	}
+	if !q.Due.match(task.DueAt) || !q.Done.match(task.DoneAt) {
+		return false
+	}
+	haystack := strings.ToLower(task.Title + "\n" + task.Notes)
+	for _, text := range q.Text {
+		if !strings.Contains(haystack, strings.ToLower(text)) {
+			return false
+		}
+	}
+	for _, text := range q.ExcludeText {
+		if strings.Contains(haystack, strings.ToLower(text)) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsStatus(items []domain.Status, status domain.Status) bool {
+	for _, item := range items {
+		if item == status {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(items []string, value string) bool {
+	for _, item := range items {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
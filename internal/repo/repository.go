@@ -9,8 +9,11 @@ import (
 
 type TaskRepository interface {
 	Create(ctx context.Context, task domain.Task) (int64, error)
+	CreateMany(ctx context.Context, tasks []domain.Task) ([]int64, error)
+	Upsert(ctx context.Context, tasks []domain.Task) ([]int64, error)
 	GetByID(ctx context.Context, id int64) (domain.Task, error)
 	List(ctx context.Context, filter TaskListFilter) ([]domain.Task, error)
+	Count(ctx context.Context, filter TaskListFilter) (int, error)
 	CreateProject(ctx context.Context, name string) error
 	ListProjects(ctx context.Context) ([]string, error)
 	RenameProject(ctx context.Context, oldName, newName string) error
@@ -18,17 +21,169 @@ type TaskRepository interface {
 	UpdateTitle(ctx context.Context, id int64, title string) error
 	UpdateProject(ctx context.Context, id int64, project string) error
 	UpdateDueAt(ctx context.Context, id int64, dueAt *time.Time) error
+	UpdateStartAt(ctx context.Context, id int64, startAt *time.Time) error
+	Snooze(ctx context.Context, dueAts map[int64]time.Time) error
 	UpdatePriority(ctx context.Context, id int64, priority string) error
+	UpdateRecurrence(ctx context.Context, id int64, recurrence domain.Recurrence) error
 	SetStatus(ctx context.Context, id int64, status domain.Status) error
+	Wait(ctx context.Context, id int64, on string, followUpAt *time.Time) error
 	MarkDone(ctx context.Context, ids []int64) error
+	MarkDoneWithSubtasks(ctx context.Context, ids []int64) error
 	MarkDoing(ctx context.Context, ids []int64) error
+	Transit(ctx context.Context, ids []int64, to domain.Status) error
 	Reopen(ctx context.Context, ids []int64) error
 	SoftDelete(ctx context.Context, ids []int64) error
 	Restore(ctx context.Context, ids []int64) error
 	Purge(ctx context.Context, ids []int64) error
+	AddTags(ctx context.Context, id int64, tags []string) error
+	RemoveTags(ctx context.Context, id int64, tags []string) error
+	ListTags(ctx context.Context) ([]string, error)
+	RenameTag(ctx context.Context, oldName, newName string) error
+	MergeTags(ctx context.Context, sources []string, target string) error
+	AddDependencies(ctx context.Context, taskID int64, blockerIDs []int64) error
+	RemoveDependencies(ctx context.Context, taskID int64, blockerIDs []int64) error
+	Undo(ctx context.Context) (domain.Operation, error)
+	Redo(ctx context.Context) (domain.Operation, error)
+	ListOperations(ctx context.Context, limit int) ([]domain.Operation, error)
+	Search(ctx context.Context, terms []string, filter TaskListFilter) ([]SearchHit, error)
 }
 
+// Snippet markers surround the matched text in SearchHit.Snippet.
+const (
+	SnippetStart = "\x02"
+	SnippetEnd   = "\x03"
+)
+
+// SearchHit is a task matching every search term, best match first.
+type SearchHit struct {
+	Task    domain.Task
+	Snippet string
+}
+
+type TaskSort string
+
+const (
+	SortByID          TaskSort = ""
+	SortByPriority    TaskSort = "priority"
+	SortByDue         TaskSort = "due"
+	SortByStart       TaskSort = "start"
+	SortByFollowUp    TaskSort = "followup"
+	SortByDoneDesc    TaskSort = "done_desc"
+	SortByUpdatedDesc TaskSort = "updated_desc"
+)
+
+// TaskListFilter narrows List and Count. Time ranges are half-open:
+// From is inclusive, To is exclusive, and a task without the field never
+// matches a range on it.
 type TaskListFilter struct {
-	Project string
-	Limit   int
+	Statuses  []domain.Status
+	Project   string
+	NoProject bool
+	Tag       string
+	ParentID  int64
+	// Unblocked keeps tasks without open blockers; BlockerID keeps tasks
+	// waiting on that task while it is open.
+	Unblocked bool
+	BlockerID int64
+	DueFrom   *time.Time
+	DueTo     *time.Time
+	StartFrom *time.Time
+	StartTo   *time.Time
+	// StartedBy keeps tasks that are not deferred past it: those without a
+	// start time or starting no later than it.
+	StartedBy *time.Time
+	// FollowUpTo keeps tasks with a follow-up time before it.
+	FollowUpTo  *time.Time
+	DoneFrom    *time.Time
+	DoneTo      *time.Time
+	UpdatedFrom *time.Time
+	UpdatedTo   *time.Time
+	Sort        TaskSort
+	Limit       int
+}
+
+// Match reports whether task satisfies the filter; it mirrors the SQL
+// translation and ignores Sort and Limit.
+func (f TaskListFilter) Match(task domain.Task) bool {
+	if len(f.Statuses) > 0 && !containsStatus(f.Statuses, task.Status) {
+		return false
+	}
+	if f.Project != "" && task.Project != f.Project {
+		return false
+	}
+	if f.NoProject && task.Project != "" {
+		return false
+	}
+	if tag := domain.NormalizeTag(f.Tag); tag != "" && !containsTag(task.Tags, tag) {
+		return false
+	}
+	if f.ParentID != 0 && task.ParentID != f.ParentID {
+		return false
+	}
+	if f.Unblocked && task.IsBlocked() {
+		return false
+	}
+	if f.BlockerID != 0 && !containsID(task.BlockedBy, f.BlockerID) {
+		return false
+	}
+	if !inRange(task.DueAt, f.DueFrom, f.DueTo) {
+		return false
+	}
+	if !inRange(task.StartAt, f.StartFrom, f.StartTo) {
+		return false
+	}
+	if f.StartedBy != nil && task.IsDeferred(*f.StartedBy) {
+		return false
+	}
+	if !inRange(task.FollowUpAt, nil, f.FollowUpTo) {
+		return false
+	}
+	if !inRange(task.DoneAt, f.DoneFrom, f.DoneTo) {
+		return false
+	}
+	updatedAt := task.UpdatedAt
+	return inRange(&updatedAt, f.UpdatedFrom, f.UpdatedTo)
+}
+
+func containsStatus(items []domain.Status, status domain.Status) bool {
+	for _, item := range items {
+		if item == status {
+			return true
+		}
+	}
+	return false
+}
+
+func containsID(items []int64, id int64) bool {
+	for _, item := range items {
+		if item == id {
+			return true
+		}
+	}
+	return false
+}
+
+func containsTag(items []string, tag string) bool {
+	for _, item := range items {
+		if item == tag {
+			return true
+		}
+	}
+	return false
+}
+
+func inRange(value, from, to *time.Time) bool {
+	if from == nil && to == nil {
+		return true
+	}
+	if value == nil {
+		return false
+	}
+	if from != nil && value.Before(*from) {
+		return false
+	}
+	if to != nil && !value.Before(*to) {
+		return false
+	}
+	return true
 }
@@ -0,0 +1,20 @@
+package repo
+
+import (
+	"context"
+
+	"td/internal/domain"
+)
+
+type sourceKey struct{}
+
+// WithSource tags changes made with ctx so task history can tell the CLI,
+// the TUI and AI parsing apart.
+func WithSource(ctx context.Context, source domain.EventSource) context.Context {
+	return context.WithValue(ctx, sourceKey{}, source)
+}
+
+func SourceFromContext(ctx context.Context) domain.EventSource {
+	source, _ := ctx.Value(sourceKey{}).(domain.EventSource)
+	return source
+}
@@ -0,0 +1,157 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"td/internal/ai"
+)
+
+const (
+	DefaultAICacheTTL        = 24 * time.Hour
+	DefaultAICacheMaxEntries = 500
+)
+
+// AICache keeps AI responses in the ai_cache table. Entries expire after
+// TTL, and beyond MaxEntries the least recently used ones are dropped.
+type AICache struct {
+	db         *sql.DB
+	TTL        time.Duration
+	MaxEntries int
+	now        func() time.Time
+}
+
+var _ ai.Cache = (*AICache)(nil)
+
+func NewAICache(db *sql.DB, ttl time.Duration, maxEntries int) *AICache {
+	if ttl <= 0 {
+		ttl = DefaultAICacheTTL
+	}
+	if maxEntries <= 0 {
+		maxEntries = DefaultAICacheMaxEntries
+	}
+	return &AICache{db: db, TTL: ttl, MaxEntries: maxEntries, now: time.Now}
+}
+
+// AICache returns the AI response cache stored next to the tasks.
+func (r *TaskRepository) AICache(ttl time.Duration, maxEntries int) *AICache {
+	return NewAICache(r.db, ttl, maxEntries)
+}
+
+type AICacheStats struct {
+	Entries int
+	Expired int
+	Bytes   int64
+	Hits    int64
+	Oldest  *time.Time
+	Newest  *time.Time
+}
+
+func (c *AICache) Get(ctx context.Context, key string) (string, bool) {
+	now := c.now()
+	var value string
+	err := c.db.QueryRowContext(
+		ctx,
+		`SELECT response_json FROM ai_cache WHERE cache_key = ? AND created_at >= ?`,
+		key, dbTime(now.Add(-c.TTL)),
+	).Scan(&value)
+	if err != nil {
+		return "", false
+	}
+	_, _ = c.db.ExecContext(
+		ctx,
+		`UPDATE ai_cache SET used_at = ?, hits = hits + 1 WHERE cache_key = ?`,
+		dbTime(now), key,
+	)
+	return value, true
+}
+
+func (c *AICache) Put(ctx context.Context, key, value string) {
+	now := dbTime(c.now())
+	if _, err := c.db.ExecContext(
+		ctx,
+		`INSERT INTO ai_cache(cache_key, response_json, created_at, used_at)
+		 VALUES(?, ?, ?, ?)
+		 ON CONFLICT(cache_key) DO UPDATE SET
+		     response_json = excluded.response_json,
+		     created_at = excluded.created_at,
+		     used_at = excluded.used_at`,
+		key, value, now, now,
+	); err != nil {
+		return
+	}
+	_ = c.prune(ctx)
+}
+
+// prune drops expired entries and then the least recently used ones over
+// MaxEntries.
+func (c *AICache) prune(ctx context.Context) error {
+	if _, err := c.db.ExecContext(
+		ctx,
+		`DELETE FROM ai_cache WHERE created_at < ?`,
+		dbTime(c.now().Add(-c.TTL)),
+	); err != nil {
+		return err
+	}
+	_, err := c.db.ExecContext(
+		ctx,
+		`DELETE FROM ai_cache
+		  WHERE cache_key NOT IN (
+		        SELECT cache_key FROM ai_cache
+		         ORDER BY COALESCE(used_at, created_at) DESC, created_at DESC
+		         LIMIT ?
+		  )`,
+		c.MaxEntries,
+	)
+	return err
+}
+
+func (c *AICache) Stats(ctx context.Context) (AICacheStats, error) {
+	var (
+		stats          AICacheStats
+		oldest, newest sql.NullString
+	)
+	err := c.db.QueryRowContext(
+		ctx,
+		`SELECT COUNT(*),
+		        COALESCE(SUM(created_at < ?), 0),
+		        COALESCE(SUM(length(CAST(response_json AS BLOB))), 0),
+		        COALESCE(SUM(hits), 0),
+		        MIN(created_at),
+		        MAX(created_at)
+		   FROM ai_cache`,
+		dbTime(c.now().Add(-c.TTL)),
+	).Scan(&stats.Entries, &stats.Expired, &stats.Bytes, &stats.Hits, &oldest, &newest)
+	if err != nil {
+		return AICacheStats{}, err
+	}
+	if stats.Oldest, err = parseCacheTime(oldest); err != nil {
+		return AICacheStats{}, err
+	}
+	if stats.Newest, err = parseCacheTime(newest); err != nil {
+		return AICacheStats{}, err
+	}
+	return stats, nil
+}
+
+// Clear removes every cached response and reports how many there were.
+func (c *AICache) Clear(ctx context.Context) (int64, error) {
+	res, err := c.db.ExecContext(ctx, `DELETE FROM ai_cache`)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func parseCacheTime(raw sql.NullString) (*time.Time, error) {
+	if !raw.Valid || raw.String == "" {
+		return nil, nil
+	}
+	t, err := time.ParseInLocation(dbTimeLayout, raw.String, time.UTC)
+	if err != nil {
+		return nil, errors.New("invalid ai_cache time " + raw.String)
+	}
+	return &t, nil
+}
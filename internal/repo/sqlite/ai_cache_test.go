@@ -0,0 +1,56 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestAICacheShouldExpireAndDropLeastRecentlyUsed(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+	if err := Migrate(db); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	ctx := context.Background()
+	now := time.Date(2026, 3, 4, 10, 0, 0, 0, time.UTC)
+	cache := NewAICache(db, time.Hour, 2)
+	cache.now = func() time.Time { return now }
+
+	cache.Put(ctx, "a", `{"title":"a"}`)
+	now = now.Add(time.Minute)
+	cache.Put(ctx, "b", `{"title":"b"}`)
+	now = now.Add(time.Minute)
+	if value, ok := cache.Get(ctx, "a"); !ok || value != `{"title":"a"}` {
+		t.Fatalf("get a = %q, %v", value, ok)
+	}
+	now = now.Add(time.Minute)
+	cache.Put(ctx, "c", `{"title":"c"}`)
+	if _, ok := cache.Get(ctx, "b"); ok {
+		t.Fatalf("b is least recently used and should be dropped")
+	}
+
+	stats, err := cache.Stats(ctx)
+	if err != nil {
+		t.Fatalf("stats: %v", err)
+	}
+	if stats.Entries != 2 || stats.Hits != 1 || stats.Bytes != 26 || stats.Oldest == nil || !stats.Oldest.Equal(now.Add(-3*time.Minute)) {
+		t.Fatalf("stats = %+v", stats)
+	}
+
+	now = now.Add(time.Hour - time.Minute)
+	if _, ok := cache.Get(ctx, "a"); ok {
+		t.Fatalf("a should expire after the ttl")
+	}
+	if _, ok := cache.Get(ctx, "c"); !ok {
+		t.Fatalf("c should still be cached")
+	}
+	if stats, err = cache.Stats(ctx); err != nil || stats.Expired != 1 {
+		t.Fatalf("stats = %+v, %v", stats, err)
+	}
+
+	n, err := cache.Clear(ctx)
+	if err != nil || n != 2 {
+		t.Fatalf("clear = %d, %v", n, err)
+	}
+}
@@ -7,15 +7,23 @@ import (
 )
 
 func Open(path string) (*sql.DB, error) {
-	db, err := sql.Open("sqlite", path)
+	db, err := Connect(path)
 	if err != nil {
 		return nil, err
 	}
-	if _, err = db.Exec(`PRAGMA foreign_keys = ON`); err != nil {
+	if err = Migrate(db); err != nil {
 		_ = db.Close()
 		return nil, err
 	}
-	if err = Migrate(db); err != nil {
+	return db, nil
+}
+
+func Connect(path string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = db.Exec(`PRAGMA foreign_keys = ON`); err != nil {
 		_ = db.Close()
 		return nil, err
 	}
@@ -0,0 +1,141 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"td/internal/domain"
+)
+
+// openBlockerSQL selects dependencies whose blocker is still open.
+func (r *TaskRepository) openBlockerSQL() string {
+	return `SELECT d.task_id, d.blocker_id
+	   FROM task_dependencies d
+	   JOIN tasks b ON b.id = d.blocker_id
+	  WHERE b.status IN (` + statusListSQL(r.workflow.OpenStatuses()) + `)`
+}
+
+// AddDependencies records that taskID cannot start before each blocker is
+// done. Dependencies that would close a cycle are rejected.
+func (r *TaskRepository) AddDependencies(ctx context.Context, taskID int64, blockerIDs []int64) error {
+	return r.updateTask(ctx, taskID, "block", func(tx *sql.Tx) error {
+		for _, blockerID := range blockerIDs {
+			if _, err := r.statusByID(ctx, tx, blockerID); err != nil {
+				return fmt.Errorf("blocker #%d: %w", blockerID, err)
+			}
+			cycle, err := dependencyReachesTx(ctx, tx, blockerID, taskID)
+			if err != nil {
+				return err
+			}
+			if blockerID == taskID || cycle {
+				return fmt.Errorf("#%d waiting on #%d: %w", taskID, blockerID, domain.ErrDependencyCycle)
+			}
+			if _, err := tx.ExecContext(
+				ctx,
+				`INSERT OR IGNORE INTO task_dependencies(task_id, blocker_id) VALUES (?, ?)`,
+				taskID, blockerID,
+			); err != nil {
+				return err
+			}
+		}
+		return touchTaskTx(ctx, tx, taskID)
+	})
+}
+
+// RemoveDependencies drops the given blockers of taskID, or all of them
+// when blockerIDs is empty.
+func (r *TaskRepository) RemoveDependencies(ctx context.Context, taskID int64, blockerIDs []int64) error {
+	return r.updateTask(ctx, taskID, "unblock", func(tx *sql.Tx) error {
+		if len(blockerIDs) == 0 {
+			if _, err := tx.ExecContext(ctx, `DELETE FROM task_dependencies WHERE task_id = ?`, taskID); err != nil {
+				return err
+			}
+		}
+		for _, blockerID := range blockerIDs {
+			if _, err := tx.ExecContext(
+				ctx,
+				`DELETE FROM task_dependencies WHERE task_id = ? AND blocker_id = ?`,
+				taskID, blockerID,
+			); err != nil {
+				return err
+			}
+		}
+		return touchTaskTx(ctx, tx, taskID)
+	})
+}
+
+// dependencyReachesTx reports whether from already waits on target,
+// directly or through other tasks.
+func dependencyReachesTx(ctx context.Context, tx *sql.Tx, from, target int64) (bool, error) {
+	var found int
+	err := tx.QueryRowContext(
+		ctx,
+		`WITH RECURSIVE chain(id) AS (
+		     SELECT blocker_id FROM task_dependencies WHERE task_id = ?
+		     UNION
+		     SELECT d.blocker_id FROM task_dependencies d JOIN chain ON d.task_id = chain.id
+		 )
+		 SELECT EXISTS(SELECT 1 FROM chain WHERE id = ?)`,
+		from, target,
+	).Scan(&found)
+	return found == 1, err
+}
+
+func (r *TaskRepository) openBlockersTx(ctx context.Context, tx *sql.Tx, taskID int64) ([]int64, error) {
+	tasks := []domain.Task{{ID: taskID}}
+	if err := r.loadTaskBlockers(ctx, tx, tasks); err != nil {
+		return nil, err
+	}
+	return tasks[0].BlockedBy, nil
+}
+
+func (r *TaskRepository) loadTaskBlockers(ctx context.Context, q queryer, tasks []domain.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+	index := make(map[int64]int, len(tasks))
+	for i := range tasks {
+		index[tasks[i].ID] = i
+	}
+	for start := 0; start < len(tasks); start += tagLoadChunkSize {
+		end := start + tagLoadChunkSize
+		if end > len(tasks) {
+			end = len(tasks)
+		}
+		placeholders := make([]string, 0, end-start)
+		args := make([]any, 0, end-start)
+		for _, task := range tasks[start:end] {
+			placeholders = append(placeholders, "?")
+			args = append(args, task.ID)
+		}
+		rows, err := q.QueryContext(
+			ctx,
+			r.openBlockerSQL()+` AND d.task_id IN (`+strings.Join(placeholders, ", ")+`)
+			  ORDER BY d.blocker_id`,
+			args...,
+		)
+		if err != nil {
+			return err
+		}
+		for rows.Next() {
+			var taskID, blockerID int64
+			if err := rows.Scan(&taskID, &blockerID); err != nil {
+				rows.Close()
+				return err
+			}
+			if i, ok := index[taskID]; ok {
+				tasks[i].BlockedBy = append(tasks[i].BlockedBy, blockerID)
+			}
+		}
+		if err := rows.Err(); err != nil {
+			rows.Close()
+			return err
+		}
+		if err := rows.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,215 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"td/internal/domain"
+	"td/internal/repo"
+)
+
+// trackedFields are compared by recordChangesTx, in the order their
+// events are written.
+var trackedFields = []string{
+	domain.EventTitle,
+	domain.EventNotes,
+	domain.EventStatus,
+	domain.EventProject,
+	domain.EventPriority,
+	domain.EventDue,
+	domain.EventStart,
+	domain.EventRecurrence,
+	domain.EventSnoozes,
+	domain.EventWaitingOn,
+	domain.EventFollowUp,
+	domain.EventParent,
+	domain.EventTags,
+	domain.EventBlockedBy,
+}
+
+// taskSnapshot is a task row with its tags and all of its dependencies,
+// open or not, which is what history and the undo journal compare.
+type taskSnapshot struct {
+	domain.Task
+	Blockers []int64
+}
+
+// ListEvents returns the history of a task oldest first. With a positive
+// limit only the latest events are returned.
+func (r *TaskRepository) ListEvents(ctx context.Context, taskID int64, limit int) ([]domain.TaskEvent, error) {
+	query := `SELECT id, task_id, field, old_value, new_value, source, created_at
+	            FROM task_events
+	           WHERE task_id = ?
+	           ORDER BY id DESC`
+	args := []any{taskID}
+	if limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, limit)
+	}
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := make([]domain.TaskEvent, 0, 16)
+	for rows.Next() {
+		var (
+			event  domain.TaskEvent
+			source string
+		)
+		if err := rows.Scan(&event.ID, &event.TaskID, &event.Field, &event.OldValue, &event.NewValue, &source, &event.CreatedAt); err != nil {
+			return nil, err
+		}
+		event.Source = domain.EventSource(source)
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for i, j := 0, len(events)-1; i < j; i, j = i+1, j-1 {
+		events[i], events[j] = events[j], events[i]
+	}
+	return events, nil
+}
+
+// updateTask runs update in one transaction and records the fields it
+// changed on task id as an undoable operation named label.
+func (r *TaskRepository) updateTask(ctx context.Context, id int64, label string, update func(tx *sql.Tx) error) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	ctx = beginJournal(ctx, label)
+
+	before, err := taskSnapshotTx(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	if err := update(tx); err != nil {
+		return err
+	}
+	if err := recordChangesTx(ctx, tx, before); err != nil {
+		return err
+	}
+	return commitTx(ctx, tx)
+}
+
+func taskSnapshotTx(ctx context.Context, tx *sql.Tx, id int64) (taskSnapshot, error) {
+	task, err := scanTask(tx.QueryRowContext(ctx, `SELECT `+taskColumns+` FROM tasks WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return taskSnapshot{}, domain.ErrTaskNotFound
+	}
+	if err != nil {
+		return taskSnapshot{}, err
+	}
+	tasks := []domain.Task{task}
+	if err := loadTaskTags(ctx, tx, tasks); err != nil {
+		return taskSnapshot{}, err
+	}
+	blockers, err := queryIDsTx(ctx, tx, `SELECT blocker_id FROM task_dependencies WHERE task_id = ? ORDER BY blocker_id`, id)
+	if err != nil {
+		return taskSnapshot{}, err
+	}
+	return taskSnapshot{Task: tasks[0], Blockers: blockers}, nil
+}
+
+// recordChangesTx compares before with the task as it is now, writes one
+// event per changed field and adds the task to the undo journal.
+func recordChangesTx(ctx context.Context, tx *sql.Tx, before taskSnapshot) error {
+	after, err := taskSnapshotTx(ctx, tx, before.ID)
+	if err != nil {
+		return err
+	}
+	oldValues := eventValues(before)
+	newValues := eventValues(after)
+	changed := false
+	for _, field := range trackedFields {
+		if oldValues[field] == newValues[field] {
+			continue
+		}
+		changed = true
+		if err := recordEventTx(ctx, tx, before.ID, field, oldValues[field], newValues[field]); err != nil {
+			return err
+		}
+	}
+	if changed {
+		journalFromContext(ctx).track(before.ID, &before, &after)
+	}
+	return nil
+}
+
+// recordCreatedTx records a task that did not exist before this change.
+func recordCreatedTx(ctx context.Context, tx *sql.Tx, id int64) error {
+	after, err := taskSnapshotTx(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	journalFromContext(ctx).track(id, nil, &after)
+	return recordEventTx(ctx, tx, id, domain.EventCreated, "", after.Title)
+}
+
+func recordEventTx(ctx context.Context, tx *sql.Tx, taskID int64, field, oldValue, newValue string) error {
+	_, err := tx.ExecContext(
+		ctx,
+		`INSERT INTO task_events(task_id, field, old_value, new_value, source)
+		 VALUES (?, ?, ?, ?, ?)`,
+		taskID, field, oldValue, newValue, string(repo.SourceFromContext(ctx)),
+	)
+	return err
+}
+
+func eventValues(task taskSnapshot) map[string]string {
+	values := map[string]string{
+		domain.EventTitle:      task.Title,
+		domain.EventNotes:      task.Notes,
+		domain.EventStatus:     string(task.Status),
+		domain.EventProject:    task.Project,
+		domain.EventPriority:   task.Priority,
+		domain.EventRecurrence: task.Recurrence.String(),
+		domain.EventSnoozes:    strconv.Itoa(task.SnoozeCount),
+		domain.EventWaitingOn:  task.WaitingOn,
+		domain.EventTags:       strings.Join(task.Tags, ","),
+	}
+	if task.DueAt != nil {
+		values[domain.EventDue] = task.DueAt.UTC().Format(time.RFC3339)
+	}
+	if task.StartAt != nil {
+		values[domain.EventStart] = task.StartAt.UTC().Format(time.RFC3339)
+	}
+	if task.FollowUpAt != nil {
+		values[domain.EventFollowUp] = task.FollowUpAt.UTC().Format(time.RFC3339)
+	}
+	if task.ParentID != 0 {
+		values[domain.EventParent] = strconv.FormatInt(task.ParentID, 10)
+	}
+	blockers := make([]string, 0, len(task.Blockers))
+	for _, id := range task.Blockers {
+		blockers = append(blockers, strconv.FormatInt(id, 10))
+	}
+	values[domain.EventBlockedBy] = strings.Join(blockers, ",")
+	return values
+}
+
+func queryIDsTx(ctx context.Context, tx *sql.Tx, query string, args ...any) ([]int64, error) {
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make([]int64, 0, 8)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
@@ -0,0 +1,410 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"td/internal/domain"
+	"td/internal/repo"
+)
+
+// maxOperations bounds the undo journal; older operations are forgotten.
+const maxOperations = 200
+
+type journalKey struct{}
+
+// journal collects what one repository call changes so the call can be
+// undone and redone as a whole.
+type journal struct {
+	label   string
+	changes journalChanges
+	index   map[int64]int
+}
+
+type journalChanges struct {
+	Tasks           []journalChange `json:"tasks"`
+	CreatedProjects []string        `json:"created_projects,omitempty"`
+	DeletedProjects []string        `json:"deleted_projects,omitempty"`
+}
+
+// journalChange holds a task before and after an operation. A nil side
+// means the task did not exist at that point.
+type journalChange struct {
+	ID     int64        `json:"id"`
+	Before *journalTask `json:"before"`
+	After  *journalTask `json:"after"`
+}
+
+type journalTask struct {
+	ParentID   int64      `json:"parent_id,omitempty"`
+	Title      string     `json:"title"`
+	Notes      string     `json:"notes,omitempty"`
+	Status     string     `json:"status"`
+	Project    string     `json:"project,omitempty"`
+	Priority   string     `json:"priority"`
+	DueAt      *time.Time `json:"due_at,omitempty"`
+	StartAt    *time.Time `json:"start_at,omitempty"`
+	DoneAt     *time.Time `json:"done_at,omitempty"`
+	Recurrence string     `json:"recurrence,omitempty"`
+	Snoozes    int        `json:"snoozes,omitempty"`
+	WaitingOn  string     `json:"waiting_on,omitempty"`
+	FollowUpAt *time.Time `json:"followup_at,omitempty"`
+	Tags       []string   `json:"tags,omitempty"`
+	Blockers   []int64    `json:"blockers,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func beginJournal(ctx context.Context, label string) context.Context {
+	return context.WithValue(ctx, journalKey{}, &journal{label: label, index: make(map[int64]int)})
+}
+
+// journalFromContext returns nil outside an operation, e.g. while undoing.
+// A nil journal ignores everything.
+func journalFromContext(ctx context.Context) *journal {
+	j, _ := ctx.Value(journalKey{}).(*journal)
+	return j
+}
+
+func (j *journal) track(id int64, before, after *taskSnapshot) {
+	if j == nil {
+		return
+	}
+	if i, ok := j.index[id]; ok {
+		j.changes.Tasks[i].After = newJournalTask(after)
+		return
+	}
+	j.index[id] = len(j.changes.Tasks)
+	j.changes.Tasks = append(j.changes.Tasks, journalChange{
+		ID:     id,
+		Before: newJournalTask(before),
+		After:  newJournalTask(after),
+	})
+}
+
+func (j *journal) projectCreated(name string) {
+	if j != nil {
+		j.changes.CreatedProjects = append(j.changes.CreatedProjects, name)
+	}
+}
+
+func (j *journal) projectDeleted(name string) {
+	if j != nil {
+		j.changes.DeletedProjects = append(j.changes.DeletedProjects, name)
+	}
+}
+
+func (j *journal) summary() string {
+	taskIDs := make([]int64, 0, len(j.changes.Tasks))
+	for _, change := range j.changes.Tasks {
+		taskIDs = append(taskIDs, change.ID)
+	}
+	sort.Slice(taskIDs, func(a, b int) bool { return taskIDs[a] < taskIDs[b] })
+	ids := make([]string, 0, len(taskIDs))
+	for _, id := range taskIDs {
+		ids = append(ids, fmt.Sprintf("#%d", id))
+	}
+	if len(ids) > 5 {
+		ids = append(ids[:5], fmt.Sprintf("+%d", len(ids)-5))
+	}
+	if len(ids) == 0 {
+		return j.label
+	}
+	return j.label + " " + strings.Join(ids, " ")
+}
+
+func newJournalTask(s *taskSnapshot) *journalTask {
+	if s == nil {
+		return nil
+	}
+	return &journalTask{
+		ParentID:   s.ParentID,
+		Title:      s.Title,
+		Notes:      s.Notes,
+		Status:     string(s.Status),
+		Project:    s.Project,
+		Priority:   s.Priority,
+		DueAt:      s.DueAt,
+		StartAt:    s.StartAt,
+		DoneAt:     s.DoneAt,
+		Recurrence: s.Recurrence.String(),
+		Snoozes:    s.SnoozeCount,
+		WaitingOn:  s.WaitingOn,
+		FollowUpAt: s.FollowUpAt,
+		Tags:       s.Tags,
+		Blockers:   s.Blockers,
+		CreatedAt:  s.CreatedAt,
+	}
+}
+
+// commitTx stores the operation collected in ctx, if it changed anything,
+// and commits. A new operation makes undone ones impossible to redo.
+func commitTx(ctx context.Context, tx *sql.Tx) error {
+	j := journalFromContext(ctx)
+	if j != nil && (len(j.changes.Tasks) > 0 || len(j.changes.CreatedProjects) > 0 || len(j.changes.DeletedProjects) > 0) {
+		raw, err := json.Marshal(j.changes)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `UPDATE operations SET state = 'dropped' WHERE state = 'undone'`); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(
+			ctx,
+			`INSERT INTO operations(summary, source, changes_json) VALUES (?, ?, ?)`,
+			j.summary(), string(repo.SourceFromContext(ctx)), string(raw),
+		); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(
+			ctx,
+			`DELETE FROM operations
+			  WHERE id <= (SELECT id FROM operations ORDER BY id DESC LIMIT 1 OFFSET ?)`,
+			maxOperations,
+		); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Undo reverts the latest operation that is not undone yet.
+func (r *TaskRepository) Undo(ctx context.Context) (domain.Operation, error) {
+	return r.replay(ctx, `SELECT id, summary, source, state, created_at, changes_json
+	                        FROM operations WHERE state = 'done' ORDER BY id DESC LIMIT 1`,
+		true, domain.ErrNothingToUndo)
+}
+
+// Redo reapplies the operation undone most recently, as long as nothing
+// was changed since.
+func (r *TaskRepository) Redo(ctx context.Context) (domain.Operation, error) {
+	return r.replay(ctx, `SELECT id, summary, source, state, created_at, changes_json
+	                        FROM operations WHERE state = 'undone' ORDER BY id ASC LIMIT 1`,
+		false, domain.ErrNothingToRedo)
+}
+
+// ListOperations returns the latest journaled operations, newest first,
+// leaving out ones that can no longer be redone.
+func (r *TaskRepository) ListOperations(ctx context.Context, limit int) ([]domain.Operation, error) {
+	query := `SELECT id, summary, source, state, created_at
+	            FROM operations
+	           WHERE state <> 'dropped'
+	           ORDER BY id DESC`
+	args := []any{}
+	if limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, limit)
+	}
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]domain.Operation, 0, 16)
+	for rows.Next() {
+		var (
+			op            domain.Operation
+			source, state string
+		)
+		if err := rows.Scan(&op.ID, &op.Summary, &source, &state, &op.CreatedAt); err != nil {
+			return nil, err
+		}
+		op.Source = domain.EventSource(source)
+		op.State = domain.OperationState(state)
+		out = append(out, op)
+	}
+	return out, rows.Err()
+}
+
+func (r *TaskRepository) replay(ctx context.Context, query string, undo bool, none error) (domain.Operation, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.Operation{}, err
+	}
+	defer tx.Rollback()
+
+	var (
+		op                 domain.Operation
+		source, state, raw string
+	)
+	err = tx.QueryRowContext(ctx, query).Scan(&op.ID, &op.Summary, &source, &state, &op.CreatedAt, &raw)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.Operation{}, none
+	}
+	if err != nil {
+		return domain.Operation{}, err
+	}
+	op.Source = domain.EventSource(source)
+	var changes journalChanges
+	if err := json.Unmarshal([]byte(raw), &changes); err != nil {
+		return domain.Operation{}, fmt.Errorf("decode operation %d: %w", op.ID, err)
+	}
+
+	targets := make([]journalChange, len(changes.Tasks))
+	for i, change := range changes.Tasks {
+		target := change.After
+		if undo {
+			target = change.Before
+		}
+		targets[i] = journalChange{ID: change.ID, After: target}
+	}
+	if undo {
+		for i, j := 0, len(targets)-1; i < j; i, j = i+1, j-1 {
+			targets[i], targets[j] = targets[j], targets[i]
+		}
+	}
+	createProjects, deleteProjects := changes.CreatedProjects, changes.DeletedProjects
+	if undo {
+		createProjects, deleteProjects = deleteProjects, createProjects
+	}
+	for _, name := range createProjects {
+		if err := ensureProjectTx(ctx, tx, name); err != nil {
+			return domain.Operation{}, err
+		}
+	}
+	if err := applyJournalTx(ctx, tx, targets); err != nil {
+		return domain.Operation{}, err
+	}
+	for _, name := range deleteProjects {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM projects WHERE name = ?`, name); err != nil {
+			return domain.Operation{}, err
+		}
+	}
+
+	op.State = domain.OperationUndone
+	undoneAt := any(dbTime(time.Now()))
+	if !undo {
+		op.State = domain.OperationDone
+		undoneAt = nil
+	}
+	if _, err := tx.ExecContext(
+		ctx,
+		`UPDATE operations SET state = ?, undone_at = ? WHERE id = ?`,
+		string(op.State), undoneAt, op.ID,
+	); err != nil {
+		return domain.Operation{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return domain.Operation{}, err
+	}
+	return op, nil
+}
+
+// applyJournalTx brings each task to the state in change.After, deleting
+// it when that is nil. Rows are written before dependencies so tasks can
+// wait on one another regardless of order.
+func applyJournalTx(ctx context.Context, tx *sql.Tx, targets []journalChange) error {
+	befores := make(map[int64]*taskSnapshot, len(targets))
+	for _, change := range targets {
+		before, err := taskSnapshotTx(ctx, tx, change.ID)
+		if errors.Is(err, domain.ErrTaskNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		befores[change.ID] = &before
+	}
+
+	for _, change := range targets {
+		task := change.After
+		if task == nil {
+			if err := deleteTaskRowTx(ctx, tx, change.ID); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := ensureProjectTx(ctx, tx, task.Project); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(
+			ctx,
+			`INSERT INTO tasks(id, parent_id, title, notes, status, project, priority, due_at, start_at, done_at, recurrence, snooze_count, waiting_on, followup_at, created_at)
+			 VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			 ON CONFLICT(id) DO UPDATE SET
+			     parent_id = excluded.parent_id,
+			     title = excluded.title,
+			     notes = excluded.notes,
+			     status = excluded.status,
+			     project = excluded.project,
+			     priority = excluded.priority,
+			     due_at = excluded.due_at,
+			     start_at = excluded.start_at,
+			     done_at = excluded.done_at,
+			     recurrence = excluded.recurrence,
+			     snooze_count = excluded.snooze_count,
+			     waiting_on = excluded.waiting_on,
+			     followup_at = excluded.followup_at,
+			     updated_at = CURRENT_TIMESTAMP`,
+			change.ID, nullableID(task.ParentID), task.Title, task.Notes, task.Status, task.Project, task.Priority,
+			dbTimePtr(task.DueAt), dbTimePtr(task.StartAt), dbTimePtr(task.DoneAt), task.Recurrence, task.Snoozes,
+			task.WaitingOn, dbTimePtr(task.FollowUpAt), dbTime(task.CreatedAt),
+		); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM task_tags WHERE task_id = ?`, change.ID); err != nil {
+			return err
+		}
+		if err := attachTagsTx(ctx, tx, change.ID, task.Tags); err != nil {
+			return err
+		}
+	}
+
+	for _, change := range targets {
+		if change.After == nil {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM task_dependencies WHERE task_id = ?`, change.ID); err != nil {
+			return err
+		}
+		for _, blockerID := range change.After.Blockers {
+			if _, err := tx.ExecContext(
+				ctx,
+				`INSERT OR IGNORE INTO task_dependencies(task_id, blocker_id)
+				 SELECT ?, id FROM tasks WHERE id = ?`,
+				change.ID, blockerID,
+			); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, change := range targets {
+		before, existed := befores[change.ID]
+		switch {
+		case change.After == nil && existed:
+			if err := recordEventTx(ctx, tx, change.ID, domain.EventPurged, before.Title, ""); err != nil {
+				return err
+			}
+		case change.After != nil && existed:
+			if err := recordChangesTx(ctx, tx, *before); err != nil {
+				return err
+			}
+		case change.After != nil:
+			if err := recordCreatedTx(ctx, tx, change.ID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func deleteTaskRowTx(ctx context.Context, tx *sql.Tx, id int64) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM task_tags WHERE task_id = ?`, id); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM task_dependencies WHERE task_id = ? OR blocker_id = ?`, id, id); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE tasks SET parent_id = NULL WHERE parent_id = ?`, id); err != nil {
+		return err
+	}
+	_, err := tx.ExecContext(ctx, `DELETE FROM tasks WHERE id = ?`, id)
+	return err
+}
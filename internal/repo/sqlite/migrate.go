@@ -1,19 +1,247 @@
 package sqlite
 
 import (
+	"context"
 	"database/sql"
-	_ "embed"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
 )
 
-//go:embed migrations/0001_init.sql
-var migration0001 string
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+var ErrSchemaTooNew = errors.New("database schema is newer than this td binary")
+
+var migrationNameRegexp = regexp.MustCompile(`^(\d+)_([A-Za-z0-9_]+)\.sql$`)
+
+type Migration struct {
+	Version int
+	Name    string
+	SQL     string
+}
+
+type MigrationStatus struct {
+	Version int
+	Name    string
+	Applied bool
+}
 
 func Migrate(db *sql.DB) error {
-	if _, err := db.Exec(migration0001); err != nil {
+	_, err := ApplyMigrations(context.Background(), db)
+	return err
+}
+
+func ApplyMigrations(ctx context.Context, db *sql.DB) ([]Migration, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+	return applyMigrations(ctx, db, migrations)
+}
+
+func Migrations() ([]Migration, error) {
+	return loadMigrations(migrationFiles, "migrations")
+}
+
+func MigrationStatuses(ctx context.Context, db *sql.DB) ([]MigrationStatus, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+	return migrationStatuses(ctx, db, migrations)
+}
+
+func loadMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Migration, 0, len(entries))
+	seen := make(map[int]string, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		matched := migrationNameRegexp.FindStringSubmatch(entry.Name())
+		if len(matched) != 3 {
+			return nil, fmt.Errorf("invalid migration file name %q", entry.Name())
+		}
+		version, err := strconv.Atoi(matched[1])
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("invalid migration version in %q", entry.Name())
+		}
+		if prev, ok := seen[version]; ok {
+			return nil, fmt.Errorf("duplicate migration version %d: %s and %s", version, prev, entry.Name())
+		}
+		seen[version] = entry.Name()
+		body, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, Migration{
+			Version: version,
+			Name:    matched[2],
+			SQL:     string(body),
+		})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Version < out[j].Version
+	})
+	return out, nil
+}
+
+func applyMigrations(ctx context.Context, db *sql.DB, migrations []Migration) ([]Migration, error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	applied, err := appliedVersions(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkSchemaNotNewer(applied, migrations); err != nil {
+		return nil, err
+	}
+	pending := make([]Migration, 0, len(migrations))
+	for _, m := range migrations {
+		if _, ok := applied[m.Version]; !ok {
+			pending = append(pending, m)
+		}
+	}
+	if len(pending) == 0 {
+		return nil, nil
+	}
+
+	// Table rebuilds must not trigger ON DELETE CASCADE, and SQLite ignores
+	// this pragma inside a transaction, so toggle it on the connection.
+	var foreignKeys int
+	if err := conn.QueryRowContext(ctx, `PRAGMA foreign_keys`).Scan(&foreignKeys); err != nil {
+		return nil, err
+	}
+	if foreignKeys == 1 {
+		if _, err := conn.ExecContext(ctx, `PRAGMA foreign_keys = OFF`); err != nil {
+			return nil, err
+		}
+		defer conn.ExecContext(context.Background(), `PRAGMA foreign_keys = ON`)
+	}
+
+	done := make([]Migration, 0, len(pending))
+	for _, m := range pending {
+		if err := applyMigration(ctx, conn, m); err != nil {
+			return done, fmt.Errorf("apply migration %04d_%s: %w", m.Version, m.Name, err)
+		}
+		done = append(done, m)
+	}
+	return done, nil
+}
+
+func applyMigration(ctx context.Context, conn *sql.Conn, m Migration) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, m.SQL); err != nil {
+		return err
+	}
+	if err := checkForeignKeys(ctx, tx); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations(version) VALUES (?)`, m.Version); err != nil {
 		return err
 	}
-	if _, err := db.Exec(`INSERT OR IGNORE INTO schema_migrations(version) VALUES (1)`); err != nil {
+	return tx.Commit()
+}
+
+func checkForeignKeys(ctx context.Context, tx *sql.Tx) error {
+	rows, err := tx.QueryContext(ctx, `PRAGMA foreign_key_check`)
+	if err != nil {
 		return err
 	}
+	defer rows.Close()
+	if rows.Next() {
+		return errors.New("foreign key check failed")
+	}
+	return rows.Err()
+}
+
+func migrationStatuses(ctx context.Context, db *sql.DB, migrations []Migration) ([]MigrationStatus, error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	applied, err := appliedVersions(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkSchemaNotNewer(applied, migrations); err != nil {
+		return nil, err
+	}
+	out := make([]MigrationStatus, 0, len(migrations))
+	for _, m := range migrations {
+		_, ok := applied[m.Version]
+		out = append(out, MigrationStatus{
+			Version: m.Version,
+			Name:    m.Name,
+			Applied: ok,
+		})
+	}
+	return out, nil
+}
+
+func appliedVersions(ctx context.Context, conn *sql.Conn) (map[int]struct{}, error) {
+	if _, err := conn.ExecContext(
+		ctx,
+		`CREATE TABLE IF NOT EXISTS schema_migrations (
+		    version INTEGER PRIMARY KEY
+		)`,
+	); err != nil {
+		return nil, err
+	}
+	rows, err := conn.QueryContext(ctx, `SELECT version FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make(map[int]struct{}, 8)
+	for rows.Next() {
+		var version int
+		if err := rows.Scan(&version); err != nil {
+			return nil, err
+		}
+		out[version] = struct{}{}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func checkSchemaNotNewer(applied map[int]struct{}, migrations []Migration) error {
+	latest := 0
+	if len(migrations) > 0 {
+		latest = migrations[len(migrations)-1].Version
+	}
+	current := 0
+	for version := range applied {
+		if version > current {
+			current = version
+		}
+	}
+	if current > latest {
+		return fmt.Errorf("%w: database version %d, supported %d; upgrade td", ErrSchemaTooNew, current, latest)
+	}
 	return nil
 }
@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS idx_task_tags_tag_id ON task_tags(tag_id);
@@ -0,0 +1,55 @@
+-- Rewrite due_at/done_at values stored in Go's time.String() layout
+-- ("2006-01-02 15:04:05.999 +0800 CST") as UTC text in CURRENT_TIMESTAMP
+-- layout, so range predicates can compare them directly and use indexes.
+WITH raw AS (
+    SELECT id,
+           due_at AS value,
+           substr(due_at, 20 + instr(substr(due_at, 20), ' '), 5) AS tz
+      FROM tasks
+     WHERE due_at IS NOT NULL
+       AND length(due_at) > 19
+       AND instr(substr(due_at, 20), ' ') > 0
+)
+UPDATE tasks
+   SET due_at = (
+       SELECT strftime(
+                  '%Y-%m-%d %H:%M:%S',
+                  substr(raw.value, 1, 19),
+                  printf('%+d minutes',
+                         (CASE substr(raw.tz, 1, 1) WHEN '-' THEN 1 ELSE -1 END)
+                         * (CAST(substr(raw.tz, 2, 2) AS INTEGER) * 60
+                            + CAST(substr(raw.tz, 4, 2) AS INTEGER)))
+              )
+         FROM raw
+        WHERE raw.id = tasks.id
+   )
+ WHERE id IN (SELECT id FROM raw);
+
+WITH raw AS (
+    SELECT id,
+           done_at AS value,
+           substr(done_at, 20 + instr(substr(done_at, 20), ' '), 5) AS tz
+      FROM tasks
+     WHERE done_at IS NOT NULL
+       AND length(done_at) > 19
+       AND instr(substr(done_at, 20), ' ') > 0
+)
+UPDATE tasks
+   SET done_at = (
+       SELECT strftime(
+                  '%Y-%m-%d %H:%M:%S',
+                  substr(raw.value, 1, 19),
+                  printf('%+d minutes',
+                         (CASE substr(raw.tz, 1, 1) WHEN '-' THEN 1 ELSE -1 END)
+                         * (CAST(substr(raw.tz, 2, 2) AS INTEGER) * 60
+                            + CAST(substr(raw.tz, 4, 2) AS INTEGER)))
+              )
+         FROM raw
+        WHERE raw.id = tasks.id
+   )
+ WHERE id IN (SELECT id FROM raw);
+
+CREATE INDEX IF NOT EXISTS idx_tasks_status_due_at ON tasks(status, due_at);
+CREATE INDEX IF NOT EXISTS idx_tasks_status_done_at ON tasks(status, done_at);
+CREATE INDEX IF NOT EXISTS idx_tasks_status_updated_at ON tasks(status, updated_at);
+CREATE INDEX IF NOT EXISTS idx_tasks_project_status ON tasks(project, status);
@@ -0,0 +1,3 @@
+ALTER TABLE tasks ADD COLUMN start_at DATETIME NULL;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_status_start_at ON tasks(status, start_at);
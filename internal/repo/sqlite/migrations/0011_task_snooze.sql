@@ -0,0 +1 @@
+ALTER TABLE tasks ADD COLUMN snooze_count INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,67 @@
+-- Statuses come from the configured workflow now, so rebuild tasks without
+-- the fixed status list and leave validation to td. As in 0012 the id
+-- sequence is kept, the indexes and search triggers are recreated and the
+-- search index is rebuilt.
+CREATE TABLE tasks_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    title TEXT NOT NULL,
+    notes TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL CHECK (status <> ''),
+    project TEXT NOT NULL DEFAULT '',
+    priority TEXT NOT NULL DEFAULT 'P2',
+    due_at DATETIME NULL,
+    done_at DATETIME NULL,
+    meta_json TEXT NOT NULL DEFAULT '{}',
+    created_at DATETIME NOT NULL DEFAULT (CURRENT_TIMESTAMP),
+    updated_at DATETIME NOT NULL DEFAULT (CURRENT_TIMESTAMP),
+    recurrence TEXT NOT NULL DEFAULT '',
+    parent_id INTEGER NULL,
+    start_at DATETIME NULL,
+    snooze_count INTEGER NOT NULL DEFAULT 0,
+    waiting_on TEXT NOT NULL DEFAULT '',
+    followup_at DATETIME NULL
+);
+
+INSERT INTO tasks_new (
+    id, title, notes, status, project, priority, due_at, done_at, meta_json,
+    created_at, updated_at, recurrence, parent_id, start_at, snooze_count,
+    waiting_on, followup_at
+)
+SELECT id, title, notes, status, project, priority, due_at, done_at, meta_json,
+       created_at, updated_at, recurrence, parent_id, start_at, snooze_count,
+       waiting_on, followup_at
+  FROM tasks;
+
+DELETE FROM sqlite_sequence WHERE name = 'tasks_new';
+UPDATE sqlite_sequence SET name = 'tasks_new' WHERE name = 'tasks';
+
+DROP TABLE tasks;
+ALTER TABLE tasks_new RENAME TO tasks;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_status ON tasks(status);
+CREATE INDEX IF NOT EXISTS idx_tasks_project ON tasks(project);
+CREATE INDEX IF NOT EXISTS idx_tasks_due_at ON tasks(due_at);
+CREATE INDEX IF NOT EXISTS idx_tasks_updated_at ON tasks(updated_at);
+CREATE INDEX IF NOT EXISTS idx_tasks_done_at ON tasks(done_at);
+CREATE INDEX IF NOT EXISTS idx_tasks_status_due_at ON tasks(status, due_at);
+CREATE INDEX IF NOT EXISTS idx_tasks_status_done_at ON tasks(status, done_at);
+CREATE INDEX IF NOT EXISTS idx_tasks_status_updated_at ON tasks(status, updated_at);
+CREATE INDEX IF NOT EXISTS idx_tasks_project_status ON tasks(project, status);
+CREATE INDEX IF NOT EXISTS idx_tasks_parent_id ON tasks(parent_id);
+CREATE INDEX IF NOT EXISTS idx_tasks_status_start_at ON tasks(status, start_at);
+CREATE INDEX IF NOT EXISTS idx_tasks_status_followup_at ON tasks(status, followup_at);
+
+CREATE TRIGGER IF NOT EXISTS tasks_fts_insert AFTER INSERT ON tasks BEGIN
+    INSERT INTO tasks_fts(rowid, title, notes) VALUES (new.id, new.title, new.notes);
+END;
+
+CREATE TRIGGER IF NOT EXISTS tasks_fts_delete AFTER DELETE ON tasks BEGIN
+    INSERT INTO tasks_fts(tasks_fts, rowid, title, notes) VALUES ('delete', old.id, old.title, old.notes);
+END;
+
+CREATE TRIGGER IF NOT EXISTS tasks_fts_update AFTER UPDATE OF title, notes ON tasks BEGIN
+    INSERT INTO tasks_fts(tasks_fts, rowid, title, notes) VALUES ('delete', old.id, old.title, old.notes);
+    INSERT INTO tasks_fts(rowid, title, notes) VALUES (new.id, new.title, new.notes);
+END;
+
+INSERT INTO tasks_fts(tasks_fts) VALUES ('rebuild');
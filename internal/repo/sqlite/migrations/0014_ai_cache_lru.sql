@@ -0,0 +1,8 @@
+-- Track when each cached AI response was last used and how often, so the
+-- cache can drop the least recently used entries and report hits.
+ALTER TABLE ai_cache ADD COLUMN used_at DATETIME;
+ALTER TABLE ai_cache ADD COLUMN hits INTEGER NOT NULL DEFAULT 0;
+
+UPDATE ai_cache SET used_at = created_at WHERE used_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_ai_cache_used_at ON ai_cache(used_at);
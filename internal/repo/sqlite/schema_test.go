@@ -1,8 +1,11 @@
 package sqlite
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"testing"
+	"testing/fstest"
 )
 
 func TestSchemaInit(t *testing.T) {
@@ -20,6 +23,213 @@ func TestSchemaInit(t *testing.T) {
 	}
 }
 
+func TestMigrateShouldBeIdempotentAndRecordVersions(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	if err := Migrate(db); err != nil {
+		t.Fatalf("first migrate: %v", err)
+	}
+	if err := Migrate(db); err != nil {
+		t.Fatalf("second migrate: %v", err)
+	}
+
+	migrations, err := Migrations()
+	if err != nil {
+		t.Fatalf("load migrations: %v", err)
+	}
+	var cnt int
+	if err := db.QueryRow(`SELECT COUNT(1) FROM schema_migrations`).Scan(&cnt); err != nil {
+		t.Fatalf("count versions: %v", err)
+	}
+	if cnt != len(migrations) {
+		t.Fatalf("recorded versions = %d, want %d", cnt, len(migrations))
+	}
+}
+
+func TestMigrateShouldApplyOnlyPendingMigrations(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+	ctx := context.Background()
+
+	first := []Migration{
+		{Version: 1, Name: "init", SQL: `CREATE TABLE items (id INTEGER PRIMARY KEY);`},
+	}
+	applied, err := applyMigrations(ctx, db, first)
+	if err != nil {
+		t.Fatalf("apply first: %v", err)
+	}
+	if len(applied) != 1 {
+		t.Fatalf("applied = %d, want 1", len(applied))
+	}
+
+	second := append(first, Migration{Version: 2, Name: "add_name", SQL: `ALTER TABLE items ADD COLUMN name TEXT NOT NULL DEFAULT '';`})
+	applied, err = applyMigrations(ctx, db, second)
+	if err != nil {
+		t.Fatalf("apply second: %v", err)
+	}
+	if len(applied) != 1 || applied[0].Version != 2 {
+		t.Fatalf("applied = %+v, want only version 2", applied)
+	}
+
+	statuses, err := migrationStatuses(ctx, db, second)
+	if err != nil {
+		t.Fatalf("statuses: %v", err)
+	}
+	for _, item := range statuses {
+		if !item.Applied {
+			t.Fatalf("migration %d should be applied", item.Version)
+		}
+	}
+}
+
+func TestMigrateShouldRollbackFailedMigration(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+	ctx := context.Background()
+
+	migrations := []Migration{
+		{Version: 1, Name: "init", SQL: `CREATE TABLE items (id INTEGER PRIMARY KEY);`},
+		{Version: 2, Name: "broken", SQL: `CREATE TABLE extra (id INTEGER); SELECT * FROM missing_table;`},
+	}
+	if _, err := applyMigrations(ctx, db, migrations); err == nil {
+		t.Fatalf("apply should fail on broken migration")
+	}
+	if tableExists(t, db, "extra") {
+		t.Fatalf("failed migration should be rolled back")
+	}
+	statuses, err := migrationStatuses(ctx, db, migrations)
+	if err != nil {
+		t.Fatalf("statuses: %v", err)
+	}
+	if !statuses[0].Applied || statuses[1].Applied {
+		t.Fatalf("statuses = %+v, want 1 applied and 2 pending", statuses)
+	}
+}
+
+func TestMigrateShouldNormalizeLegacyTaskTimes(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+	ctx := context.Background()
+
+	migrations, err := Migrations()
+	if err != nil {
+		t.Fatalf("load migrations: %v", err)
+	}
+	if _, err := applyMigrations(ctx, db, migrations[:2]); err != nil {
+		t.Fatalf("apply legacy migrations: %v", err)
+	}
+	if _, err := db.Exec(
+		`INSERT INTO tasks(title, status, due_at, done_at) VALUES (?, ?, ?, ?)`,
+		"legacy", "done", "2026-02-23 07:30:00 +0800 CST", "2026-02-22 21:15:00.123456 -0500 EST",
+	); err != nil {
+		t.Fatalf("insert legacy task: %v", err)
+	}
+	if _, err := applyMigrations(ctx, db, migrations); err != nil {
+		t.Fatalf("apply migrations: %v", err)
+	}
+
+	var dueAt, doneAt string
+	if err := db.QueryRow(`SELECT due_at || '', done_at || '' FROM tasks WHERE title = 'legacy'`).Scan(&dueAt, &doneAt); err != nil {
+		t.Fatalf("query legacy task: %v", err)
+	}
+	if dueAt != "2026-02-22 23:30:00" {
+		t.Fatalf("due_at = %q, want UTC text", dueAt)
+	}
+	if doneAt != "2026-02-23 02:15:00" {
+		t.Fatalf("done_at = %q, want UTC text", doneAt)
+	}
+	if !indexExists(t, db, "idx_tasks_status_due_at") {
+		t.Fatalf("status/due index should exist")
+	}
+}
+
+func TestMigrateShouldRebuildTasksForWaitingStatus(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+	ctx := context.Background()
+
+	migrations, err := Migrations()
+	if err != nil {
+		t.Fatalf("load migrations: %v", err)
+	}
+	if _, err := applyMigrations(ctx, db, migrations[:11]); err != nil {
+		t.Fatalf("apply earlier migrations: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO tasks(title, notes, status) VALUES ('call plumber', 'about the sink', 'todo'), ('gone', '', 'deleted')`); err != nil {
+		t.Fatalf("insert tasks: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO tags(name) VALUES ('home'); INSERT INTO task_tags(task_id, tag_id) VALUES (1, 1)`); err != nil {
+		t.Fatalf("insert tags: %v", err)
+	}
+	if _, err := db.Exec(`DELETE FROM tasks WHERE id = 2`); err != nil {
+		t.Fatalf("purge task: %v", err)
+	}
+	if _, err := db.Exec(`PRAGMA foreign_keys = ON`); err != nil {
+		t.Fatalf("enable foreign keys: %v", err)
+	}
+	if _, err := applyMigrations(ctx, db, migrations); err != nil {
+		t.Fatalf("apply migrations: %v", err)
+	}
+
+	if _, err := db.Exec(`UPDATE tasks SET status = 'waiting', waiting_on = 'alice' WHERE id = 1`); err != nil {
+		t.Fatalf("waiting status should be allowed: %v", err)
+	}
+	var tags int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM task_tags WHERE task_id = 1`).Scan(&tags); err != nil || tags != 1 {
+		t.Fatalf("task tags = %d, %v; rebuild must not cascade", tags, err)
+	}
+	var hit int64
+	if err := db.QueryRow(`SELECT rowid FROM tasks_fts WHERE tasks_fts MATCH 'sink'`).Scan(&hit); err != nil || hit != 1 {
+		t.Fatalf("search hit = %d, %v", hit, err)
+	}
+	res, err := db.Exec(`INSERT INTO tasks(title, status) VALUES ('new', 'inbox')`)
+	if err != nil {
+		t.Fatalf("insert after rebuild: %v", err)
+	}
+	if id, _ := res.LastInsertId(); id != 3 {
+		t.Fatalf("new id = %d, purged ids must not be reused", id)
+	}
+	if !indexExists(t, db, "idx_tasks_status_start_at") || !indexExists(t, db, "idx_tasks_status_followup_at") {
+		t.Fatalf("task indexes should be recreated")
+	}
+}
+
+func TestMigrateShouldRefuseNewerDatabase(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	if err := Migrate(db); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO schema_migrations(version) VALUES (9999)`); err != nil {
+		t.Fatalf("insert future version: %v", err)
+	}
+	err := Migrate(db)
+	if !errors.Is(err, ErrSchemaTooNew) {
+		t.Fatalf("migrate err = %v, want ErrSchemaTooNew", err)
+	}
+}
+
+func TestLoadMigrationsShouldSortAndRejectDuplicates(t *testing.T) {
+	fsys := fstest.MapFS{
+		"m/0002_second.sql": {Data: []byte("SELECT 2;")},
+		"m/0001_first.sql":  {Data: []byte("SELECT 1;")},
+	}
+	migrations, err := loadMigrations(fsys, "m")
+	if err != nil {
+		t.Fatalf("load migrations: %v", err)
+	}
+	if len(migrations) != 2 || migrations[0].Name != "first" || migrations[1].Version != 2 {
+		t.Fatalf("migrations = %+v, want sorted first/second", migrations)
+	}
+
+	fsys["m/02_dup.sql"] = &fstest.MapFile{Data: []byte("SELECT 3;")}
+	if _, err := loadMigrations(fsys, "m"); err == nil {
+		t.Fatalf("duplicate version should fail")
+	}
+}
+
 func openTestDB(t *testing.T) *sql.DB {
 	t.Helper()
 	db, err := sql.Open("sqlite", ":memory:")
@@ -46,3 +256,38 @@ func indexExists(t *testing.T, db *sql.DB, name string) bool {
 	}
 	return cnt > 0
 }
+
+func TestMigrateShouldAllowCustomStatuses(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+	ctx := context.Background()
+
+	migrations, err := Migrations()
+	if err != nil {
+		t.Fatalf("load migrations: %v", err)
+	}
+	if _, err := applyMigrations(ctx, db, migrations[:12]); err != nil {
+		t.Fatalf("apply earlier migrations: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO tasks(title, notes, status, waiting_on) VALUES ('get quote', 'for the roof', 'waiting', 'alice')`); err != nil {
+		t.Fatalf("insert task: %v", err)
+	}
+	if _, err := applyMigrations(ctx, db, migrations); err != nil {
+		t.Fatalf("apply migrations: %v", err)
+	}
+
+	if _, err := db.Exec(`UPDATE tasks SET status = 'review' WHERE id = 1`); err != nil {
+		t.Fatalf("custom status should be allowed: %v", err)
+	}
+	if _, err := db.Exec(`UPDATE tasks SET status = '' WHERE id = 1`); err == nil {
+		t.Fatalf("empty status should be rejected")
+	}
+	var waitingOn string
+	if err := db.QueryRow(`SELECT waiting_on FROM tasks WHERE id = 1`).Scan(&waitingOn); err != nil || waitingOn != "alice" {
+		t.Fatalf("waiting_on = %q, %v", waitingOn, err)
+	}
+	var hit int64
+	if err := db.QueryRow(`SELECT rowid FROM tasks_fts WHERE tasks_fts MATCH 'roof'`).Scan(&hit); err != nil || hit != 1 {
+		t.Fatalf("search hit = %d, %v", hit, err)
+	}
+}
@@ -0,0 +1,207 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"td/internal/domain"
+	"td/internal/repo"
+)
+
+// minTrigramTerm is the shortest term the trigram tokenizer can match;
+// shorter terms such as two-character Chinese words fall back to LIKE.
+const minTrigramTerm = 3
+
+const snippetContext = 16
+
+func (r *TaskRepository) Search(ctx context.Context, terms []string, filter repo.TaskListFilter) ([]repo.SearchHit, error) {
+	var phrases, short []string
+	for _, term := range terms {
+		term = strings.TrimSpace(term)
+		switch {
+		case term == "":
+		case utf8.RuneCountInString(term) >= minTrigramTerm:
+			phrases = append(phrases, `"`+strings.ReplaceAll(term, `"`, `""`)+`"`)
+		default:
+			short = append(short, term)
+		}
+	}
+	if len(phrases) == 0 && len(short) == 0 {
+		return nil, nil
+	}
+
+	where, filterArgs, err := r.taskFilterWhere(filter)
+	if err != nil {
+		return nil, err
+	}
+	clauses := make([]string, 0, len(short)+1)
+	if where != "" {
+		clauses = append(clauses, strings.TrimPrefix(where, " WHERE "))
+	}
+	var likeArgs []any
+	for _, term := range short {
+		clauses = append(clauses, `(title LIKE ? ESCAPE '\' OR notes LIKE ? ESCAPE '\')`)
+		pattern := "%" + escapeLike(term) + "%"
+		likeArgs = append(likeArgs, pattern, pattern)
+	}
+
+	var (
+		query string
+		args  []any
+	)
+	if len(phrases) > 0 {
+		query = `SELECT ` + taskColumns + `, hit_snippet
+		           FROM tasks
+		           JOIN (SELECT rowid AS hit_id,
+		                        bm25(tasks_fts, 2.0, 1.0) AS hit_rank,
+		                        snippet(tasks_fts, -1, ?, ?, '…', ?) AS hit_snippet
+		                   FROM tasks_fts
+		                  WHERE tasks_fts MATCH ?) ON hit_id = id`
+		args = append(args, repo.SnippetStart, repo.SnippetEnd, snippetContext, strings.Join(phrases, " "))
+	} else {
+		query = `SELECT ` + taskColumns + `, '' FROM tasks`
+	}
+	if len(clauses) > 0 {
+		query += " WHERE " + strings.Join(clauses, " AND ")
+	}
+	args = append(args, filterArgs...)
+	args = append(args, likeArgs...)
+	if len(phrases) > 0 {
+		query += " ORDER BY hit_rank, updated_at DESC, id DESC"
+	} else {
+		query += " ORDER BY updated_at DESC, id DESC"
+	}
+	if filter.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tasks := make([]domain.Task, 0, 16)
+	snippets := make([]string, 0, 16)
+	for rows.Next() {
+		var snippet string
+		task, err := scanTask(snippetScanner{rows: rows, snippet: &snippet})
+		if err != nil {
+			return nil, err
+		}
+		if snippet == "" {
+			snippet = highlightSnippet(task, short)
+		}
+		tasks = append(tasks, task)
+		snippets = append(snippets, snippet)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := loadTaskTags(ctx, r.db, tasks); err != nil {
+		return nil, err
+	}
+	if err := r.loadSubtaskProgress(ctx, r.db, tasks); err != nil {
+		return nil, err
+	}
+	if err := r.loadTaskBlockers(ctx, r.db, tasks); err != nil {
+		return nil, err
+	}
+	hits := make([]repo.SearchHit, 0, len(tasks))
+	for i, task := range tasks {
+		hits = append(hits, repo.SearchHit{Task: task, Snippet: snippets[i]})
+	}
+	return hits, nil
+}
+
+type snippetScanner struct {
+	rows    *sql.Rows
+	snippet *string
+}
+
+func (s snippetScanner) Scan(dest ...any) error {
+	return s.rows.Scan(append(dest, s.snippet)...)
+}
+
+func escapeLike(text string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return replacer.Replace(text)
+}
+
+// highlightSnippet mirrors the FTS snippet for searches made only of short
+// terms: it marks every term in the title, or in a window of the notes when
+// the title does not contain one.
+func highlightSnippet(task domain.Task, terms []string) string {
+	text := []rune(task.Title)
+	marks := termMarks(text, terms)
+	if len(marks) == 0 {
+		text = []rune(task.Notes)
+		marks = termMarks(text, terms)
+	}
+	if len(marks) == 0 {
+		return task.Title
+	}
+	start, end := 0, len(text)
+	first := 0
+	for !marks[first] {
+		first++
+	}
+	if start < first-snippetContext {
+		start = first - snippetContext
+	}
+	if end > first+3*snippetContext {
+		end = first + 3*snippetContext
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	for i := start; i < end; i++ {
+		if marks[i] && (i == start || !marks[i-1]) {
+			b.WriteString(repo.SnippetStart)
+		}
+		b.WriteRune(text[i])
+		if marks[i] && (i == end-1 || !marks[i+1]) {
+			b.WriteString(repo.SnippetEnd)
+		}
+	}
+	if end < len(text) {
+		b.WriteString("…")
+	}
+	return b.String()
+}
+
+// termMarks reports which runes of text fall inside a case-insensitive
+// match of any term.
+func termMarks(text []rune, terms []string) map[int]bool {
+	marks := make(map[int]bool)
+	for _, term := range terms {
+		needle := []rune(term)
+		if len(needle) == 0 {
+			continue
+		}
+		for i := 0; i+len(needle) <= len(text); i++ {
+			matched := true
+			for j, r := range needle {
+				if unicode.ToLower(text[i+j]) != unicode.ToLower(r) {
+					matched = false
+					break
+				}
+			}
+			if matched {
+				for j := range needle {
+					marks[i+j] = true
+				}
+			}
+		}
+	}
+	return marks
+}
@@ -0,0 +1,145 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"td/internal/domain"
+)
+
+// nullableID stores a zero parent as NULL.
+func nullableID(id int64) any {
+	if id == 0 {
+		return nil
+	}
+	return id
+}
+
+func checkParentTx(ctx context.Context, tx *sql.Tx, parentID int64) error {
+	if parentID == 0 {
+		return nil
+	}
+	var rawStatus string
+	err := tx.QueryRowContext(ctx, `SELECT status FROM tasks WHERE id = ?`, parentID).Scan(&rawStatus)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("parent #%d: %w", parentID, domain.ErrTaskNotFound)
+	}
+	if err != nil {
+		return err
+	}
+	if domain.Status(rawStatus) == domain.StatusDeleted {
+		return fmt.Errorf("parent #%d is deleted", parentID)
+	}
+	return nil
+}
+
+// subtaskIDsTx returns every descendant of id, depth first by ID.
+func subtaskIDsTx(ctx context.Context, tx *sql.Tx, id int64) ([]int64, error) {
+	rows, err := tx.QueryContext(
+		ctx,
+		`WITH RECURSIVE sub(id) AS (
+		     SELECT id FROM tasks WHERE parent_id = ?
+		     UNION
+		     SELECT t.id FROM tasks t JOIN sub ON t.parent_id = sub.id
+		 )
+		 SELECT id FROM sub WHERE id <> ? ORDER BY id`,
+		id, id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := make([]int64, 0, 4)
+	for rows.Next() {
+		var childID int64
+		if err := rows.Scan(&childID); err != nil {
+			return nil, err
+		}
+		out = append(out, childID)
+	}
+	return out, rows.Err()
+}
+
+// withSubtasksTx appends the descendants of ids after them, without
+// duplicates, so cascades touch each task once.
+func withSubtasksTx(ctx context.Context, tx *sql.Tx, ids []int64) ([]int64, error) {
+	seen := make(map[int64]bool, len(ids))
+	out := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			out = append(out, id)
+		}
+	}
+	for _, id := range ids {
+		children, err := subtaskIDsTx(ctx, tx, id)
+		if err != nil {
+			return nil, err
+		}
+		for _, child := range children {
+			if !seen[child] {
+				seen[child] = true
+				out = append(out, child)
+			}
+		}
+	}
+	return out, nil
+}
+
+func (r *TaskRepository) loadSubtaskProgress(ctx context.Context, q queryer, tasks []domain.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+	index := make(map[int64]int, len(tasks))
+	for i := range tasks {
+		index[tasks[i].ID] = i
+	}
+	for start := 0; start < len(tasks); start += tagLoadChunkSize {
+		end := start + tagLoadChunkSize
+		if end > len(tasks) {
+			end = len(tasks)
+		}
+		placeholders := make([]string, 0, end-start)
+		args := make([]any, 0, end-start)
+		for _, task := range tasks[start:end] {
+			placeholders = append(placeholders, "?")
+			args = append(args, task.ID)
+		}
+		rows, err := q.QueryContext(
+			ctx,
+			`SELECT parent_id, COUNT(*), COALESCE(SUM(status IN (`+statusListSQL(r.workflow.ClosedStatuses())+`)), 0)
+			   FROM tasks
+			  WHERE parent_id IN (`+strings.Join(placeholders, ", ")+`)
+			    AND status <> 'deleted'
+			  GROUP BY parent_id`,
+			args...,
+		)
+		if err != nil {
+			return err
+		}
+		for rows.Next() {
+			var (
+				parentID int64
+				progress domain.Progress
+			)
+			if err := rows.Scan(&parentID, &progress.Total, &progress.Done); err != nil {
+				rows.Close()
+				return err
+			}
+			if i, ok := index[parentID]; ok {
+				tasks[i].Subtasks = progress
+			}
+		}
+		if err := rows.Err(); err != nil {
+			rows.Close()
+			return err
+		}
+		if err := rows.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,295 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"td/internal/domain"
+)
+
+const tagLoadChunkSize = 500
+
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+func (r *TaskRepository) AddTags(ctx context.Context, id int64, tags []string) error {
+	normalized, err := domain.NormalizeTags(tags)
+	if err != nil {
+		return err
+	}
+	if len(normalized) == 0 {
+		return nil
+	}
+	return r.updateTask(ctx, id, "tag", func(tx *sql.Tx) error {
+		if err := attachTagsTx(ctx, tx, id, normalized); err != nil {
+			return err
+		}
+		return touchTaskTx(ctx, tx, id)
+	})
+}
+
+func (r *TaskRepository) RemoveTags(ctx context.Context, id int64, tags []string) error {
+	normalized, err := domain.NormalizeTags(tags)
+	if err != nil {
+		return err
+	}
+	if len(normalized) == 0 {
+		return nil
+	}
+	return r.updateTask(ctx, id, "untag", func(tx *sql.Tx) error {
+		for _, tag := range normalized {
+			if _, err := tx.ExecContext(
+				ctx,
+				`DELETE FROM task_tags
+				  WHERE task_id = ?
+				    AND tag_id = (SELECT id FROM tags WHERE name = ?)`,
+				id, tag,
+			); err != nil {
+				return err
+			}
+		}
+		return touchTaskTx(ctx, tx, id)
+	})
+}
+
+func (r *TaskRepository) ListTags(ctx context.Context) ([]string, error) {
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT name
+		   FROM tags
+		  WHERE EXISTS (SELECT 1 FROM task_tags WHERE tag_id = tags.id)
+		  ORDER BY name ASC`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]string, 0, 8)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		out = append(out, name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *TaskRepository) RenameTag(ctx context.Context, oldName, newName string) error {
+	oldName = domain.NormalizeTag(oldName)
+	newName = domain.NormalizeTag(newName)
+	if !domain.IsValidTag(oldName) || !domain.IsValidTag(newName) {
+		return domain.ErrInvalidTag
+	}
+	if oldName == newName {
+		return nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	ctx = beginJournal(ctx, "rename tag "+oldName)
+
+	oldID, err := tagIDByName(ctx, tx, oldName)
+	if err != nil {
+		return err
+	}
+	if _, err := tagIDByName(ctx, tx, newName); err == nil {
+		return fmt.Errorf("tag %s already exists, use merge", newName)
+	} else if !errors.Is(err, domain.ErrTagNotFound) {
+		return err
+	}
+	before, err := taggedTasksTx(ctx, tx, oldID)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE tags SET name = ? WHERE name = ?`, newName, oldName); err != nil {
+		return err
+	}
+	for _, task := range before {
+		if err := recordChangesTx(ctx, tx, task); err != nil {
+			return err
+		}
+	}
+	return commitTx(ctx, tx)
+}
+
+func (r *TaskRepository) MergeTags(ctx context.Context, sources []string, target string) error {
+	target = domain.NormalizeTag(target)
+	if !domain.IsValidTag(target) {
+		return domain.ErrInvalidTag
+	}
+	normalized, err := domain.NormalizeTags(sources)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	ctx = beginJournal(ctx, "merge tags into "+target)
+
+	targetID, err := ensureTagTx(ctx, tx, target)
+	if err != nil {
+		return err
+	}
+	for _, source := range normalized {
+		if source == target {
+			continue
+		}
+		sourceID, err := tagIDByName(ctx, tx, source)
+		if err != nil {
+			return err
+		}
+		before, err := taggedTasksTx(ctx, tx, sourceID)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(
+			ctx,
+			`INSERT OR IGNORE INTO task_tags(task_id, tag_id)
+			 SELECT task_id, ? FROM task_tags WHERE tag_id = ?`,
+			targetID, sourceID,
+		); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM task_tags WHERE tag_id = ?`, sourceID); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM tags WHERE id = ?`, sourceID); err != nil {
+			return err
+		}
+		for _, task := range before {
+			if err := recordChangesTx(ctx, tx, task); err != nil {
+				return err
+			}
+		}
+	}
+	return commitTx(ctx, tx)
+}
+
+// taggedTasksTx snapshots the tasks carrying tagID so tag renames and
+// merges can be recorded per task.
+func taggedTasksTx(ctx context.Context, tx *sql.Tx, tagID int64) ([]taskSnapshot, error) {
+	ids, err := queryIDsTx(ctx, tx, `SELECT task_id FROM task_tags WHERE tag_id = ? ORDER BY task_id`, tagID)
+	if err != nil {
+		return nil, err
+	}
+	tasks := make([]taskSnapshot, 0, len(ids))
+	for _, id := range ids {
+		task, err := taskSnapshotTx(ctx, tx, id)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, nil
+}
+
+func attachTagsTx(ctx context.Context, tx *sql.Tx, taskID int64, tags []string) error {
+	for _, tag := range tags {
+		tagID, err := ensureTagTx(ctx, tx, tag)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(
+			ctx,
+			`INSERT OR IGNORE INTO task_tags(task_id, tag_id) VALUES (?, ?)`,
+			taskID, tagID,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func ensureTagTx(ctx context.Context, tx *sql.Tx, name string) (int64, error) {
+	if _, err := tx.ExecContext(ctx, `INSERT OR IGNORE INTO tags(name) VALUES (?)`, name); err != nil {
+		return 0, err
+	}
+	return tagIDByName(ctx, tx, name)
+}
+
+func tagIDByName(ctx context.Context, tx *sql.Tx, name string) (int64, error) {
+	var id int64
+	err := tx.QueryRowContext(ctx, `SELECT id FROM tags WHERE name = ?`, name).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, domain.ErrTagNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+func touchTaskTx(ctx context.Context, tx *sql.Tx, id int64) error {
+	_, err := tx.ExecContext(ctx, `UPDATE tasks SET updated_at = CURRENT_TIMESTAMP WHERE id = ?`, id)
+	return err
+}
+
+func loadTaskTags(ctx context.Context, q queryer, tasks []domain.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+	index := make(map[int64]int, len(tasks))
+	for i := range tasks {
+		index[tasks[i].ID] = i
+	}
+	for start := 0; start < len(tasks); start += tagLoadChunkSize {
+		end := start + tagLoadChunkSize
+		if end > len(tasks) {
+			end = len(tasks)
+		}
+		placeholders := make([]string, 0, end-start)
+		args := make([]any, 0, end-start)
+		for _, task := range tasks[start:end] {
+			placeholders = append(placeholders, "?")
+			args = append(args, task.ID)
+		}
+		rows, err := q.QueryContext(
+			ctx,
+			`SELECT tt.task_id, t.name
+			   FROM task_tags tt
+			   JOIN tags t ON t.id = tt.tag_id
+			  WHERE tt.task_id IN (`+strings.Join(placeholders, ", ")+`)
+			  ORDER BY t.name ASC`,
+			args...,
+		)
+		if err != nil {
+			return err
+		}
+		for rows.Next() {
+			var (
+				taskID int64
+				name   string
+			)
+			if err := rows.Scan(&taskID, &name); err != nil {
+				rows.Close()
+				return err
+			}
+			if i, ok := index[taskID]; ok {
+				tasks[i].Tags = append(tasks[i].Tags, name)
+			}
+		}
+		if err := rows.Err(); err != nil {
+			rows.Close()
+			return err
+		}
+		if err := rows.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package sqlite
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"td/internal/domain"
+	"td/internal/repo"
+)
+
+// dbTimeLayout matches CURRENT_TIMESTAMP so stored values compare as text.
+const dbTimeLayout = "2006-01-02 15:04:05.999999999"
+
+func dbTime(t time.Time) string {
+	return t.UTC().Format(dbTimeLayout)
+}
+
+func dbTimePtr(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return dbTime(*t)
+}
+
+func dbTimeOrNil(t time.Time) any {
+	if t.IsZero() {
+		return nil
+	}
+	return dbTime(t)
+}
+
+func (r *TaskRepository) taskFilterWhere(filter repo.TaskListFilter) (string, []any, error) {
+	clauses := make([]string, 0, 4)
+	args := make([]any, 0, 4)
+	if len(filter.Statuses) > 0 {
+		placeholders := make([]string, 0, len(filter.Statuses))
+		for _, status := range filter.Statuses {
+			if !r.workflow.Has(status) {
+				return "", nil, domain.ErrInvalidStatus
+			}
+			placeholders = append(placeholders, "?")
+			args = append(args, string(status))
+		}
+		clauses = append(clauses, "status IN ("+strings.Join(placeholders, ", ")+")")
+	}
+	if filter.Project != "" {
+		clauses = append(clauses, "project = ?")
+		args = append(args, filter.Project)
+	}
+	if filter.NoProject {
+		clauses = append(clauses, "project = ''")
+	}
+	if tag := domain.NormalizeTag(filter.Tag); tag != "" {
+		clauses = append(clauses, `id IN (
+		    SELECT tt.task_id
+		      FROM task_tags tt
+		      JOIN tags t ON t.id = tt.tag_id
+		     WHERE t.name = ?
+		)`)
+		args = append(args, tag)
+	}
+	if filter.Unblocked {
+		clauses = append(clauses, "id NOT IN (SELECT task_id FROM ("+r.openBlockerSQL()+"))")
+	}
+	if filter.BlockerID != 0 {
+		clauses = append(clauses, "id IN (SELECT task_id FROM ("+r.openBlockerSQL()+" AND d.blocker_id = ?))")
+		args = append(args, filter.BlockerID)
+	}
+	if filter.ParentID != 0 {
+		clauses = append(clauses, "parent_id = ?")
+		args = append(args, filter.ParentID)
+	}
+	clauses, args = appendTimeRange(clauses, args, "due_at", filter.DueFrom, filter.DueTo)
+	clauses, args = appendTimeRange(clauses, args, "start_at", filter.StartFrom, filter.StartTo)
+	if filter.StartedBy != nil {
+		clauses = append(clauses, "(start_at IS NULL OR start_at <= ?)")
+		args = append(args, dbTime(*filter.StartedBy))
+	}
+	clauses, args = appendTimeRange(clauses, args, "followup_at", nil, filter.FollowUpTo)
+	clauses, args = appendTimeRange(clauses, args, "done_at", filter.DoneFrom, filter.DoneTo)
+	clauses, args = appendTimeRange(clauses, args, "updated_at", filter.UpdatedFrom, filter.UpdatedTo)
+	if len(clauses) == 0 {
+		return "", args, nil
+	}
+	return " WHERE " + strings.Join(clauses, " AND "), args, nil
+}
+
+// statusListSQL renders statuses as SQL string literals for IN lists.
+// Workflow status names are plain words, but quotes are escaped anyway.
+func statusListSQL(statuses []domain.Status) string {
+	if len(statuses) == 0 {
+		return "NULL"
+	}
+	parts := make([]string, 0, len(statuses))
+	for _, status := range statuses {
+		parts = append(parts, "'"+strings.ReplaceAll(string(status), "'", "''")+"'")
+	}
+	return strings.Join(parts, ", ")
+}
+
+func appendTimeRange(clauses []string, args []any, column string, from, to *time.Time) ([]string, []any) {
+	if from != nil {
+		clauses = append(clauses, column+" >= ?")
+		args = append(args, dbTime(*from))
+	}
+	if to != nil {
+		clauses = append(clauses, column+" < ?")
+		args = append(args, dbTime(*to))
+	}
+	return clauses, args
+}
+
+func taskOrderBy(sort repo.TaskSort) (string, error) {
+	switch sort {
+	case repo.SortByID:
+		return " ORDER BY id ASC", nil
+	case repo.SortByPriority:
+		return " ORDER BY priority ASC, due_at IS NULL, due_at ASC, id ASC", nil
+	case repo.SortByDue:
+		return " ORDER BY due_at IS NULL, due_at ASC, id ASC", nil
+	case repo.SortByStart:
+		return " ORDER BY start_at IS NULL, start_at ASC, id ASC", nil
+	case repo.SortByFollowUp:
+		return " ORDER BY followup_at IS NULL, followup_at ASC, id ASC", nil
+	case repo.SortByDoneDesc:
+		return " ORDER BY done_at IS NULL, done_at DESC, id DESC", nil
+	case repo.SortByUpdatedDesc:
+		return " ORDER BY updated_at DESC, id DESC", nil
+	default:
+		return "", fmt.Errorf("unsupported task sort %q", sort)
+	}
+}
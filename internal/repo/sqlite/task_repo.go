@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
@@ -12,37 +13,81 @@ import (
 	"td/internal/repo"
 )
 
+const taskColumns = `id, parent_id, title, notes, status, project, priority, due_at, start_at, done_at, recurrence, snooze_count, waiting_on, followup_at, created_at, updated_at`
+
 type TaskRepository struct {
-	db *sql.DB
+	db       *sql.DB
+	workflow domain.Workflow
 }
 
-func NewTaskRepository(db *sql.DB) *TaskRepository {
-	return &TaskRepository{db: db}
+// NewTaskRepository checks statuses and their moves against workflow.
+func NewTaskRepository(db *sql.DB, workflow domain.Workflow) *TaskRepository {
+	return &TaskRepository{db: db, workflow: workflow}
 }
 
 var _ repo.TaskRepository = (*TaskRepository)(nil)
 
 func (r *TaskRepository) Create(ctx context.Context, task domain.Task) (int64, error) {
+	ids, err := r.CreateMany(ctx, []domain.Task{task})
+	if err != nil {
+		return 0, err
+	}
+	return ids[0], nil
+}
+
+// CreateMany creates tasks in one transaction, so one undo removes them
+// all.
+func (r *TaskRepository) CreateMany(ctx context.Context, tasks []domain.Task) ([]int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	ctx = beginJournal(ctx, "add")
+
+	ids := make([]int64, 0, len(tasks))
+	for _, task := range tasks {
+		id, err := r.createTx(ctx, tx, task)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	if err := commitTx(ctx, tx); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func (r *TaskRepository) createTx(ctx context.Context, tx *sql.Tx, task domain.Task) (int64, error) {
 	status := task.Status
 	if status == "" {
 		status = domain.StatusInbox
 	}
-	if !domain.IsValidStatus(status) {
+	if !r.workflow.Has(status) {
 		return 0, domain.ErrInvalidStatus
 	}
 	priority := domain.NormalizePriority(task.Priority)
 	if !domain.IsValidPriority(priority) {
 		return 0, domain.ErrInvalidPriority
 	}
-	if err := r.ensureProject(ctx, task.Project); err != nil {
+	tags, err := domain.NormalizeTags(task.Tags)
+	if err != nil {
 		return 0, err
 	}
 
-	res, err := r.db.ExecContext(
+	if err := checkParentTx(ctx, tx, task.ParentID); err != nil {
+		return 0, err
+	}
+	if err := ensureProjectTx(ctx, tx, task.Project); err != nil {
+		return 0, err
+	}
+	res, err := tx.ExecContext(
 		ctx,
-		`INSERT INTO tasks(title, notes, status, project, priority, due_at)
-		 VALUES(?, ?, ?, ?, ?, ?)`,
-		task.Title, task.Notes, string(status), task.Project, priority, task.DueAt,
+		`INSERT INTO tasks(parent_id, title, notes, status, project, priority, due_at, start_at, recurrence, waiting_on, followup_at)
+		 VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		nullableID(task.ParentID), task.Title, task.Notes, string(status), task.Project, priority,
+		dbTimePtr(task.DueAt), dbTimePtr(task.StartAt), task.Recurrence.Anchored(task.DueAt).String(), task.WaitingOn, dbTimePtr(task.FollowUpAt),
 	)
 	if err != nil {
 		return 0, err
@@ -51,70 +96,214 @@ func (r *TaskRepository) Create(ctx context.Context, task domain.Task) (int64, e
 	if err != nil {
 		return 0, err
 	}
+	if err := attachTagsTx(ctx, tx, id, tags); err != nil {
+		return 0, err
+	}
+	if err := recordCreatedTx(ctx, tx, id); err != nil {
+		return 0, err
+	}
 	return id, nil
 }
 
+// Upsert writes full task rows in one transaction, keeping status, done
+// time and timestamps as given. Tasks with an ID replace that row or are
+// inserted under it; tasks without an ID get a new one. A negative ID also
+// gets a new one and stands for that task in the ParentID and BlockedBy
+// of the others, so a batch can hold new subtasks and blockers. Parents
+// and blockers are set once every row is written; parents must exist and
+// not be deleted, blockers must exist, and BlockedBy replaces the
+// blockers a task had.
+func (r *TaskRepository) Upsert(ctx context.Context, tasks []domain.Task) ([]int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	ctx = beginJournal(ctx, "import")
+
+	ids := make([]int64, 0, len(tasks))
+	befores := make([]*taskSnapshot, 0, len(tasks))
+	placeholders := make(map[int64]int64)
+	for _, task := range tasks {
+		if !r.workflow.Has(task.Status) {
+			return nil, domain.ErrInvalidStatus
+		}
+		priority := domain.NormalizePriority(task.Priority)
+		if !domain.IsValidPriority(priority) {
+			return nil, domain.ErrInvalidPriority
+		}
+		tags, err := domain.NormalizeTags(task.Tags)
+		if err != nil {
+			return nil, err
+		}
+		if err := ensureProjectTx(ctx, tx, task.Project); err != nil {
+			return nil, err
+		}
+		var (
+			id     any
+			before *taskSnapshot
+		)
+		if task.ID > 0 {
+			id = task.ID
+			snapshot, err := taskSnapshotTx(ctx, tx, task.ID)
+			if err != nil && !errors.Is(err, domain.ErrTaskNotFound) {
+				return nil, err
+			}
+			if err == nil {
+				before = &snapshot
+			}
+		}
+		var taskID int64
+		if err := tx.QueryRowContext(
+			ctx,
+			`INSERT INTO tasks(id, parent_id, title, notes, status, project, priority, due_at, start_at, done_at, recurrence, snooze_count, waiting_on, followup_at, created_at, updated_at)
+			 VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, COALESCE(?, CURRENT_TIMESTAMP), COALESCE(?, CURRENT_TIMESTAMP))
+			 ON CONFLICT(id) DO UPDATE SET
+			     parent_id = excluded.parent_id,
+			     title = excluded.title,
+			     notes = excluded.notes,
+			     status = excluded.status,
+			     project = excluded.project,
+			     priority = excluded.priority,
+			     due_at = excluded.due_at,
+			     start_at = excluded.start_at,
+			     done_at = excluded.done_at,
+			     recurrence = excluded.recurrence,
+			     snooze_count = excluded.snooze_count,
+			     waiting_on = excluded.waiting_on,
+			     followup_at = excluded.followup_at,
+			     created_at = excluded.created_at,
+			     updated_at = excluded.updated_at
+			 RETURNING id`,
+			id, nil, task.Title, task.Notes, string(task.Status), task.Project, priority,
+			dbTimePtr(task.DueAt), dbTimePtr(task.StartAt), dbTimePtr(task.DoneAt), task.Recurrence.String(), task.SnoozeCount,
+			task.WaitingOn, dbTimePtr(task.FollowUpAt), dbTimeOrNil(task.CreatedAt), dbTimeOrNil(task.UpdatedAt),
+		).Scan(&taskID); err != nil {
+			return nil, err
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM task_tags WHERE task_id = ?`, taskID); err != nil {
+			return nil, err
+		}
+		if err := attachTagsTx(ctx, tx, taskID, tags); err != nil {
+			return nil, err
+		}
+		if task.ID < 0 {
+			placeholders[task.ID] = taskID
+		}
+		ids = append(ids, taskID)
+		befores = append(befores, before)
+	}
+	resolve := func(id int64) (int64, error) {
+		if id >= 0 {
+			return id, nil
+		}
+		target, ok := placeholders[id]
+		if !ok {
+			return 0, fmt.Errorf("task %d: %w", id, domain.ErrTaskNotFound)
+		}
+		return target, nil
+	}
+	for i, task := range tasks {
+		parentID, err := resolve(task.ParentID)
+		if err != nil {
+			return nil, fmt.Errorf("#%d parent: %w", ids[i], err)
+		}
+		if parentID != 0 {
+			if err := checkParentTx(ctx, tx, parentID); err != nil {
+				return nil, fmt.Errorf("#%d: %w", ids[i], err)
+			}
+			if _, err := tx.ExecContext(ctx, `UPDATE tasks SET parent_id = ? WHERE id = ?`, parentID, ids[i]); err != nil {
+				return nil, err
+			}
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM task_dependencies WHERE task_id = ?`, ids[i]); err != nil {
+			return nil, err
+		}
+		for _, blockerID := range task.BlockedBy {
+			if blockerID, err = resolve(blockerID); err != nil {
+				return nil, fmt.Errorf("#%d blocker: %w", ids[i], err)
+			}
+			if _, err := r.statusByID(ctx, tx, blockerID); err != nil {
+				return nil, fmt.Errorf("#%d blocker #%d: %w", ids[i], blockerID, err)
+			}
+			if _, err := tx.ExecContext(
+				ctx,
+				`INSERT OR IGNORE INTO task_dependencies(task_id, blocker_id) VALUES (?, ?)`,
+				ids[i], blockerID,
+			); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, id := range ids {
+		blockers, err := queryIDsTx(ctx, tx, `SELECT blocker_id FROM task_dependencies WHERE task_id = ?`, id)
+		if err != nil {
+			return nil, err
+		}
+		for _, blockerID := range blockers {
+			cycle, err := dependencyReachesTx(ctx, tx, blockerID, id)
+			if err != nil {
+				return nil, err
+			}
+			if cycle {
+				return nil, fmt.Errorf("#%d waiting on #%d: %w", id, blockerID, domain.ErrDependencyCycle)
+			}
+		}
+	}
+	for i, id := range ids {
+		if befores[i] != nil {
+			err = recordChangesTx(ctx, tx, *befores[i])
+		} else {
+			err = recordCreatedTx(ctx, tx, id)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := commitTx(ctx, tx); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
 func (r *TaskRepository) GetByID(ctx context.Context, id int64) (domain.Task, error) {
-	var (
-		task      domain.Task
-		rawStatus string
-		dueAt     sql.NullTime
-		doneAt    sql.NullTime
-	)
-	err := r.db.QueryRowContext(
+	task, err := scanTask(r.db.QueryRowContext(
 		ctx,
-		`SELECT id, title, notes, status, project, priority, due_at, done_at, created_at, updated_at
+		`SELECT `+taskColumns+`
 		   FROM tasks
 		  WHERE id = ?`,
 		id,
-	).Scan(
-		&task.ID,
-		&task.Title,
-		&task.Notes,
-		&rawStatus,
-		&task.Project,
-		&task.Priority,
-		&dueAt,
-		&doneAt,
-		&task.CreatedAt,
-		&task.UpdatedAt,
-	)
+	))
 	if errors.Is(err, sql.ErrNoRows) {
 		return domain.Task{}, domain.ErrTaskNotFound
 	}
 	if err != nil {
 		return domain.Task{}, err
 	}
-
-	status, err := domain.ParseStatus(rawStatus)
-	if err != nil {
+	tasks := []domain.Task{task}
+	if err := loadTaskTags(ctx, r.db, tasks); err != nil {
 		return domain.Task{}, err
 	}
-	task.Status = status
-	if dueAt.Valid {
-		t := dueAt.Time.UTC()
-		task.DueAt = &t
+	if err := r.loadSubtaskProgress(ctx, r.db, tasks); err != nil {
+		return domain.Task{}, err
 	}
-	if doneAt.Valid {
-		t := doneAt.Time.UTC()
-		task.DoneAt = &t
+	if err := r.loadTaskBlockers(ctx, r.db, tasks); err != nil {
+		return domain.Task{}, err
 	}
-	return task, nil
+	return tasks[0], nil
 }
 
 func (r *TaskRepository) List(ctx context.Context, filter repo.TaskListFilter) ([]domain.Task, error) {
-	query := `SELECT id, title, notes, status, project, priority, due_at, done_at, created_at, updated_at
-	            FROM tasks`
-	args := make([]any, 0, 2)
-	clauses := make([]string, 0, 2)
-	if filter.Project != "" {
-		clauses = append(clauses, "project = ?")
-		args = append(args, filter.Project)
-	}
-	if len(clauses) > 0 {
-		query += " WHERE " + strings.Join(clauses, " AND ")
-	}
-	query += " ORDER BY id ASC"
+	where, args, err := r.taskFilterWhere(filter)
+	if err != nil {
+		return nil, err
+	}
+	orderBy, err := taskOrderBy(filter.Sort)
+	if err != nil {
+		return nil, err
+	}
+	query := `SELECT ` + taskColumns + `
+	            FROM tasks` + where + orderBy
 	if filter.Limit > 0 {
 		query += " LIMIT ?"
 		args = append(args, filter.Limit)
@@ -137,9 +326,33 @@ func (r *TaskRepository) List(ctx context.Context, filter repo.TaskListFilter) (
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := loadTaskTags(ctx, r.db, tasks); err != nil {
+		return nil, err
+	}
+	if err := r.loadSubtaskProgress(ctx, r.db, tasks); err != nil {
+		return nil, err
+	}
+	if err := r.loadTaskBlockers(ctx, r.db, tasks); err != nil {
+		return nil, err
+	}
 	return tasks, nil
 }
 
+func (r *TaskRepository) Count(ctx context.Context, filter repo.TaskListFilter) (int, error) {
+	where, args, err := r.taskFilterWhere(filter)
+	if err != nil {
+		return 0, err
+	}
+	var count int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM tasks`+where, args...).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
 func (r *TaskRepository) CreateProject(ctx context.Context, name string) error {
 	name = strings.TrimSpace(name)
 	if name == "" {
@@ -192,6 +405,7 @@ func (r *TaskRepository) RenameProject(ctx context.Context, oldName, newName str
 		return err
 	}
 	defer tx.Rollback()
+	ctx = beginJournal(ctx, "rename project "+oldName)
 
 	exists, err := projectExists(ctx, tx, oldName)
 	if err != nil {
@@ -203,6 +417,10 @@ func (r *TaskRepository) RenameProject(ctx context.Context, oldName, newName str
 	if err := ensureProjectTx(ctx, tx, newName); err != nil {
 		return err
 	}
+	before, err := projectTasksTx(ctx, tx, oldName)
+	if err != nil {
+		return err
+	}
 	if _, err := tx.ExecContext(
 		ctx,
 		`UPDATE tasks
@@ -212,10 +430,15 @@ func (r *TaskRepository) RenameProject(ctx context.Context, oldName, newName str
 	); err != nil {
 		return err
 	}
-	if _, err := tx.ExecContext(ctx, `DELETE FROM projects WHERE name = ?`, oldName); err != nil {
+	if err := deleteProjectRowTx(ctx, tx, oldName); err != nil {
 		return err
 	}
-	return tx.Commit()
+	for _, task := range before {
+		if err := recordChangesTx(ctx, tx, task); err != nil {
+			return err
+		}
+	}
+	return commitTx(ctx, tx)
 }
 
 func (r *TaskRepository) DeleteProject(ctx context.Context, name string) error {
@@ -229,6 +452,7 @@ func (r *TaskRepository) DeleteProject(ctx context.Context, name string) error {
 		return err
 	}
 	defer tx.Rollback()
+	ctx = beginJournal(ctx, "delete project "+name)
 
 	exists, err := projectExists(ctx, tx, name)
 	if err != nil {
@@ -237,6 +461,10 @@ func (r *TaskRepository) DeleteProject(ctx context.Context, name string) error {
 	if !exists {
 		return domain.ErrTaskNotFound
 	}
+	before, err := projectTasksTx(ctx, tx, name)
+	if err != nil {
+		return err
+	}
 	if _, err := tx.ExecContext(
 		ctx,
 		`UPDATE tasks
@@ -246,26 +474,47 @@ func (r *TaskRepository) DeleteProject(ctx context.Context, name string) error {
 	); err != nil {
 		return err
 	}
-	if _, err := tx.ExecContext(ctx, `DELETE FROM projects WHERE name = ?`, name); err != nil {
+	if err := deleteProjectRowTx(ctx, tx, name); err != nil {
 		return err
 	}
-	return tx.Commit()
+	for _, task := range before {
+		if err := recordChangesTx(ctx, tx, task); err != nil {
+			return err
+		}
+	}
+	return commitTx(ctx, tx)
 }
 
+// MarkDone completes only the given tasks; open subtasks stay open.
 func (r *TaskRepository) MarkDone(ctx context.Context, ids []int64) error {
-	return r.transit(ctx, ids, domain.StatusDone)
+	return r.transit(ctx, ids, domain.StatusDone, false)
+}
+
+// MarkDoneWithSubtasks also completes every open descendant.
+func (r *TaskRepository) MarkDoneWithSubtasks(ctx context.Context, ids []int64) error {
+	return r.transit(ctx, ids, domain.StatusDone, true)
 }
 
 func (r *TaskRepository) MarkDoing(ctx context.Context, ids []int64) error {
-	return r.transit(ctx, ids, domain.StatusDoing)
+	return r.transit(ctx, ids, domain.StatusDoing, false)
+}
+
+// Transit moves the tasks to any status the workflow allows, such as a
+// custom review status.
+func (r *TaskRepository) Transit(ctx context.Context, ids []int64, to domain.Status) error {
+	if !r.workflow.Has(to) {
+		return fmt.Errorf("%w %q", domain.ErrInvalidStatus, to)
+	}
+	return r.transit(ctx, ids, to, false)
 }
 
 func (r *TaskRepository) Reopen(ctx context.Context, ids []int64) error {
-	return r.transit(ctx, ids, domain.StatusTodo)
+	return r.transit(ctx, ids, domain.StatusTodo, false)
 }
 
+// SoftDelete moves the tasks and all their descendants to the trash.
 func (r *TaskRepository) SoftDelete(ctx context.Context, ids []int64) error {
-	return r.transit(ctx, ids, domain.StatusDeleted)
+	return r.transit(ctx, ids, domain.StatusDeleted, true)
 }
 
 func (r *TaskRepository) Restore(ctx context.Context, ids []int64) error {
@@ -277,25 +526,27 @@ func (r *TaskRepository) Restore(ctx context.Context, ids []int64) error {
 		return err
 	}
 	defer tx.Rollback()
+	ctx = beginJournal(ctx, "restore")
 
-	for _, id := range ids {
-		status, err := r.statusByID(ctx, tx, id)
+	all, err := withSubtasksTx(ctx, tx, ids)
+	if err != nil {
+		return err
+	}
+	for i, id := range all {
+		before, err := taskSnapshotTx(ctx, tx, id)
 		if err != nil {
 			return err
 		}
-		if !domain.CanTransit(status, domain.StatusTodo) {
+		status := before.Status
+		if i >= len(ids) && status != domain.StatusDeleted {
+			continue
+		}
+		if !r.workflow.CanTransit(status, domain.StatusTodo) {
 			return domain.NewInvalidTransitionError(status, domain.StatusTodo)
 		}
 
-		var project string
-		if err := tx.QueryRowContext(ctx, `SELECT project FROM tasks WHERE id = ?`, id).Scan(&project); err != nil {
-			if errors.Is(err, sql.ErrNoRows) {
-				return domain.ErrTaskNotFound
-			}
-			return err
-		}
 		target := domain.StatusTodo
-		if strings.TrimSpace(project) == "" {
+		if strings.TrimSpace(before.Project) == "" {
 			target = domain.StatusInbox
 		}
 		if _, err := tx.ExecContext(
@@ -307,8 +558,11 @@ func (r *TaskRepository) Restore(ctx context.Context, ids []int64) error {
 		); err != nil {
 			return err
 		}
+		if err := recordChangesTx(ctx, tx, before); err != nil {
+			return err
+		}
 	}
-	return tx.Commit()
+	return commitTx(ctx, tx)
 }
 
 func (r *TaskRepository) Purge(ctx context.Context, ids []int64) error {
@@ -320,70 +574,89 @@ func (r *TaskRepository) Purge(ctx context.Context, ids []int64) error {
 		return err
 	}
 	defer tx.Rollback()
+	ctx = beginJournal(ctx, "purge")
 
-	for _, id := range ids {
-		status, err := r.statusByID(ctx, tx, id)
+	all, err := withSubtasksTx(ctx, tx, ids)
+	if err != nil {
+		return err
+	}
+	for i, id := range all {
+		task, err := taskSnapshotTx(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if task.Status != domain.StatusDeleted {
+			if i >= len(ids) {
+				continue
+			}
+			return domain.NewInvalidTransitionError(task.Status, domain.StatusDeleted)
+		}
+		related, err := queryIDsTx(
+			ctx, tx,
+			`SELECT id FROM tasks WHERE parent_id = ?
+			 UNION
+			 SELECT task_id FROM task_dependencies WHERE blocker_id = ?`,
+			id, id,
+		)
 		if err != nil {
 			return err
 		}
-		if status != domain.StatusDeleted {
-			return domain.NewInvalidTransitionError(status, domain.StatusDeleted)
+		relatedBefore := make([]taskSnapshot, 0, len(related))
+		for _, relatedID := range related {
+			snapshot, err := taskSnapshotTx(ctx, tx, relatedID)
+			if err != nil {
+				return err
+			}
+			relatedBefore = append(relatedBefore, snapshot)
 		}
-		if _, err := tx.ExecContext(ctx, `DELETE FROM tasks WHERE id = ?`, id); err != nil {
+		journalFromContext(ctx).track(id, &task, nil)
+		if err := deleteTaskRowTx(ctx, tx, id); err != nil {
+			return err
+		}
+		for _, snapshot := range relatedBefore {
+			if err := recordChangesTx(ctx, tx, snapshot); err != nil {
+				return err
+			}
+		}
+		if err := recordEventTx(ctx, tx, id, domain.EventPurged, task.Title, ""); err != nil {
 			return err
 		}
 	}
-	return tx.Commit()
+	return commitTx(ctx, tx)
 }
 
 func (r *TaskRepository) UpdateTitle(ctx context.Context, id int64, title string) error {
-	result, err := r.db.ExecContext(
-		ctx,
-		`UPDATE tasks
-		    SET title = ?, updated_at = CURRENT_TIMESTAMP
-		  WHERE id = ?`,
-		title, id,
-	)
-	if err != nil {
-		return err
-	}
-	affected, err := result.RowsAffected()
-	if err != nil {
+	return r.updateTask(ctx, id, "edit", func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(
+			ctx,
+			`UPDATE tasks
+			    SET title = ?, updated_at = CURRENT_TIMESTAMP
+			  WHERE id = ?`,
+			title, id,
+		)
 		return err
-	}
-	if affected == 0 {
-		return domain.ErrTaskNotFound
-	}
-	return nil
+	})
 }
 
 func (r *TaskRepository) UpdateProject(ctx context.Context, id int64, project string) error {
-	if err := r.ensureProject(ctx, project); err != nil {
-		return err
-	}
-	result, err := r.db.ExecContext(
-		ctx,
-		`UPDATE tasks
-		    SET project = ?,
-		        status = CASE
-		                    WHEN status = 'inbox' AND ? <> '' THEN 'todo'
-		                    ELSE status
-		                 END,
-		        updated_at = CURRENT_TIMESTAMP
-		  WHERE id = ?`,
-		project, project, id,
-	)
-	if err != nil {
-		return err
-	}
-	affected, err := result.RowsAffected()
-	if err != nil {
+	return r.updateTask(ctx, id, "move", func(tx *sql.Tx) error {
+		if err := ensureProjectTx(ctx, tx, project); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(
+			ctx,
+			`UPDATE tasks
+			    SET project = ?,
+			        status = CASE
+			                    WHEN status = 'inbox' AND ? <> '' THEN 'todo'
+			                    ELSE status
+			                 END,
+			        updated_at = CURRENT_TIMESTAMP
+			  WHERE id = ?`,
+			project, project, id,
+		)
 		return err
-	}
-	if affected == 0 {
-		return domain.ErrTaskNotFound
-	}
-	return nil
+	})
 }
 
 func (r *TaskRepository) ensureProject(ctx context.Context, name string) error {
@@ -400,8 +673,46 @@ func ensureProjectTx(ctx context.Context, tx *sql.Tx, name string) error {
 	if name == "" {
 		return nil
 	}
-	_, err := tx.ExecContext(ctx, `INSERT OR IGNORE INTO projects(name) VALUES (?)`, name)
-	return err
+	res, err := tx.ExecContext(ctx, `INSERT OR IGNORE INTO projects(name) VALUES (?)`, name)
+	if err != nil {
+		return err
+	}
+	if created, err := res.RowsAffected(); err != nil {
+		return err
+	} else if created > 0 {
+		journalFromContext(ctx).projectCreated(name)
+	}
+	return nil
+}
+
+// projectTasksTx snapshots the tasks in a project before a bulk change.
+func projectTasksTx(ctx context.Context, tx *sql.Tx, name string) ([]taskSnapshot, error) {
+	ids, err := queryIDsTx(ctx, tx, `SELECT id FROM tasks WHERE project = ? ORDER BY id`, name)
+	if err != nil {
+		return nil, err
+	}
+	tasks := make([]taskSnapshot, 0, len(ids))
+	for _, id := range ids {
+		task, err := taskSnapshotTx(ctx, tx, id)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, nil
+}
+
+func deleteProjectRowTx(ctx context.Context, tx *sql.Tx, name string) error {
+	res, err := tx.ExecContext(ctx, `DELETE FROM projects WHERE name = ?`, name)
+	if err != nil {
+		return err
+	}
+	if deleted, err := res.RowsAffected(); err != nil {
+		return err
+	} else if deleted > 0 {
+		journalFromContext(ctx).projectDeleted(name)
+	}
+	return nil
 }
 
 func projectExists(ctx context.Context, tx *sql.Tx, name string) (bool, error) {
@@ -418,29 +729,122 @@ func projectExists(ctx context.Context, tx *sql.Tx, name string) (bool, error) {
 	return exists == 1, nil
 }
 
+// UpdateDueAt sets the due time. A monthly or yearly rule anchored to the
+// old due day moves to the new one.
 func (r *TaskRepository) UpdateDueAt(ctx context.Context, id int64, dueAt *time.Time) error {
-	var due any
-	if dueAt != nil {
-		due = dueAt.UTC()
-	}
-	result, err := r.db.ExecContext(
-		ctx,
-		`UPDATE tasks
-		    SET due_at = ?, updated_at = CURRENT_TIMESTAMP
-		  WHERE id = ?`,
-		due, id,
-	)
-	if err != nil {
+	return r.updateTask(ctx, id, "due", func(tx *sql.Tx) error {
+		task, err := scanTask(tx.QueryRowContext(ctx, `SELECT `+taskColumns+` FROM tasks WHERE id = ?`, id))
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(
+			ctx,
+			`UPDATE tasks
+			    SET due_at = ?, recurrence = ?, updated_at = CURRENT_TIMESTAMP
+			  WHERE id = ?`,
+			dbTimePtr(dueAt), task.Recurrence.Reanchored(dueAt).String(), id,
+		)
 		return err
+	})
+}
+
+// Snooze moves the due time of each task in dueAts and counts the snooze,
+// all as one operation. Only open tasks can be snoozed.
+func (r *TaskRepository) Snooze(ctx context.Context, dueAts map[int64]time.Time) error {
+	if len(dueAts) == 0 {
+		return nil
+	}
+	ids := make([]int64, 0, len(dueAts))
+	for id := range dueAts {
+		ids = append(ids, id)
 	}
-	affected, err := result.RowsAffected()
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
-	if affected == 0 {
-		return domain.ErrTaskNotFound
+	defer tx.Rollback()
+	ctx = beginJournal(ctx, "snooze")
+
+	for _, id := range ids {
+		before, err := taskSnapshotTx(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if !r.workflow.IsOpen(before.Status) {
+			return fmt.Errorf("cannot snooze #%d: task is %s", id, before.Status)
+		}
+		dueAt := dueAts[id]
+		if _, err := tx.ExecContext(
+			ctx,
+			`UPDATE tasks
+			    SET due_at = ?, snooze_count = snooze_count + 1, updated_at = CURRENT_TIMESTAMP
+			  WHERE id = ?`,
+			dbTime(dueAt), id,
+		); err != nil {
+			return err
+		}
+		if err := recordChangesTx(ctx, tx, before); err != nil {
+			return err
+		}
 	}
-	return nil
+	return commitTx(ctx, tx)
+}
+
+// Wait marks the task as waiting on someone or something until it is
+// reopened or completed; with a follow-up time it shows up in Today again
+// from then on.
+func (r *TaskRepository) Wait(ctx context.Context, id int64, on string, followUpAt *time.Time) error {
+	return r.updateTask(ctx, id, "wait", func(tx *sql.Tx) error {
+		var rawStatus string
+		if err := tx.QueryRowContext(ctx, `SELECT status FROM tasks WHERE id = ?`, id).Scan(&rawStatus); err != nil {
+			return err
+		}
+		from := domain.Status(rawStatus)
+		if !r.workflow.CanTransit(from, domain.StatusWaiting) {
+			return domain.NewInvalidTransitionError(from, domain.StatusWaiting)
+		}
+		_, err := tx.ExecContext(
+			ctx,
+			`UPDATE tasks
+			    SET status = ?, waiting_on = ?, followup_at = ?, updated_at = CURRENT_TIMESTAMP
+			  WHERE id = ?`,
+			string(domain.StatusWaiting), on, dbTimePtr(followUpAt), id,
+		)
+		return err
+	})
+}
+
+func (r *TaskRepository) UpdateStartAt(ctx context.Context, id int64, startAt *time.Time) error {
+	return r.updateTask(ctx, id, "defer", func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(
+			ctx,
+			`UPDATE tasks
+			    SET start_at = ?, updated_at = CURRENT_TIMESTAMP
+			  WHERE id = ?`,
+			dbTimePtr(startAt), id,
+		)
+		return err
+	})
+}
+
+func (r *TaskRepository) UpdateRecurrence(ctx context.Context, id int64, recurrence domain.Recurrence) error {
+	return r.updateTask(ctx, id, "repeat", func(tx *sql.Tx) error {
+		task, err := scanTask(tx.QueryRowContext(ctx, `SELECT `+taskColumns+` FROM tasks WHERE id = ?`, id))
+		if err != nil {
+			return err
+		}
+		recurrence = recurrence.Anchored(task.DueAt)
+		_, err = tx.ExecContext(
+			ctx,
+			`UPDATE tasks
+			    SET recurrence = ?, updated_at = CURRENT_TIMESTAMP
+			  WHERE id = ?`,
+			recurrence.String(), id,
+		)
+		return err
+	})
 }
 
 func (r *TaskRepository) UpdatePriority(ctx context.Context, id int64, priority string) error {
@@ -448,57 +852,56 @@ func (r *TaskRepository) UpdatePriority(ctx context.Context, id int64, priority
 	if !domain.IsValidPriority(priority) {
 		return domain.ErrInvalidPriority
 	}
-	result, err := r.db.ExecContext(
-		ctx,
-		`UPDATE tasks
-		    SET priority = ?, updated_at = CURRENT_TIMESTAMP
-		  WHERE id = ?`,
-		priority, id,
-	)
-	if err != nil {
-		return err
-	}
-	affected, err := result.RowsAffected()
-	if err != nil {
+	return r.updateTask(ctx, id, "priority", func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(
+			ctx,
+			`UPDATE tasks
+			    SET priority = ?, updated_at = CURRENT_TIMESTAMP
+			  WHERE id = ?`,
+			priority, id,
+		)
 		return err
-	}
-	if affected == 0 {
-		return domain.ErrTaskNotFound
-	}
-	return nil
+	})
 }
 
+// SetStatus moves one task to status without the workflow checks of
+// transit. Closing a recurring task still creates its next occurrence.
 func (r *TaskRepository) SetStatus(ctx context.Context, id int64, status domain.Status) error {
-	if !domain.IsValidStatus(status) {
+	if !r.workflow.Has(status) {
 		return domain.ErrInvalidStatus
 	}
-	var doneAt any
-	if status == domain.StatusDone {
-		doneAt = time.Now().UTC()
-	} else {
-		doneAt = nil
-	}
-	result, err := r.db.ExecContext(
-		ctx,
-		`UPDATE tasks
-		    SET status = ?, done_at = ?, updated_at = CURRENT_TIMESTAMP
-		  WHERE id = ?`,
-		string(status), doneAt, id,
-	)
-	if err != nil {
-		return err
-	}
-	affected, err := result.RowsAffected()
-	if err != nil {
-		return err
-	}
-	if affected == 0 {
-		return domain.ErrTaskNotFound
-	}
-	return nil
+	return r.updateTask(ctx, id, "status", func(tx *sql.Tx) error {
+		from, err := r.statusByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		var doneAt any
+		if r.workflow.IsClosed(status) {
+			doneAt = dbTime(now)
+		} else {
+			doneAt = nil
+		}
+		if _, err := tx.ExecContext(
+			ctx,
+			`UPDATE tasks
+			    SET status = ?, done_at = ?, updated_at = CURRENT_TIMESTAMP
+			  WHERE id = ?`,
+			string(status), doneAt, id,
+		); err != nil {
+			return err
+		}
+		if r.workflow.IsClosed(status) && !r.workflow.IsClosed(from) {
+			return createNextOccurrenceTx(ctx, tx, id, now)
+		}
+		return nil
+	})
 }
 
-func (r *TaskRepository) transit(ctx context.Context, ids []int64, to domain.Status) error {
+// transit moves ids to the target status. With cascade, descendants move
+// too; those already there or unable to get there are left alone instead
+// of failing the whole change.
+func (r *TaskRepository) transit(ctx context.Context, ids []int64, to domain.Status, cascade bool) error {
 	if len(ids) == 0 {
 		return nil
 	}
@@ -507,33 +910,128 @@ func (r *TaskRepository) transit(ctx context.Context, ids []int64, to domain.Sta
 		return err
 	}
 	defer tx.Rollback()
+	label, ok := transitLabels[to]
+	if !ok {
+		label = string(to)
+	}
+	ctx = beginJournal(ctx, label)
 
-	for _, id := range ids {
-		from, err := r.statusByID(ctx, tx, id)
+	all := ids
+	if cascade {
+		all, err = withSubtasksTx(ctx, tx, ids)
 		if err != nil {
 			return err
 		}
-		if !domain.CanTransit(from, to) {
+	}
+	for i, id := range all {
+		before, err := taskSnapshotTx(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		from := before.Status
+		if i >= len(ids) && (from == to || !r.workflow.CanTransit(from, to)) {
+			continue
+		}
+		if !r.workflow.CanTransit(from, to) {
 			return domain.NewInvalidTransitionError(from, to)
 		}
+		if to == domain.StatusDoing && from != domain.StatusDoing {
+			blockers, err := r.openBlockersTx(ctx, tx, id)
+			if err != nil {
+				return err
+			}
+			if len(blockers) > 0 {
+				return fmt.Errorf("#%d waits on %s: %w", id, formatIDs(blockers), domain.ErrTaskBlocked)
+			}
+		}
+		now := time.Now()
 		var doneAt any
-		if to == domain.StatusDone {
-			doneAt = time.Now().UTC()
+		if r.workflow.IsClosed(to) {
+			doneAt = dbTime(now)
 		} else {
 			doneAt = nil
 		}
 		if _, err := tx.ExecContext(
 			ctx,
 			`UPDATE tasks
-			    SET status = ?, done_at = ?, updated_at = CURRENT_TIMESTAMP
+			    SET status = ?, done_at = ?, waiting_on = '', followup_at = NULL, updated_at = CURRENT_TIMESTAMP
 			  WHERE id = ?`,
 			string(to), doneAt, id,
 		); err != nil {
 			return err
 		}
+		if r.workflow.IsClosed(to) && !r.workflow.IsClosed(from) {
+			if err := createNextOccurrenceTx(ctx, tx, id, now); err != nil {
+				return err
+			}
+		}
+		if err := recordChangesTx(ctx, tx, before); err != nil {
+			return err
+		}
+	}
+
+	return commitTx(ctx, tx)
+}
+
+var transitLabels = map[domain.Status]string{
+	domain.StatusDone:    "done",
+	domain.StatusDoing:   "start",
+	domain.StatusTodo:    "reopen",
+	domain.StatusDeleted: "delete",
+}
+
+// createNextOccurrenceTx copies a recurring task that was just completed
+// into a fresh open task due at the next occurrence. The rule moves to the
+// copy, so reopening and completing the old task does not repeat it twice.
+func createNextOccurrenceTx(ctx context.Context, tx *sql.Tx, id int64, doneAt time.Time) error {
+	task, err := scanTask(tx.QueryRowContext(ctx, `SELECT `+taskColumns+` FROM tasks WHERE id = ?`, id))
+	if err != nil {
+		return err
+	}
+	if task.Recurrence.IsZero() {
+		return nil
+	}
+	tasks := []domain.Task{task}
+	if err := loadTaskTags(ctx, tx, tasks); err != nil {
+		return err
 	}
+	task = tasks[0]
 
-	return tx.Commit()
+	status := domain.StatusInbox
+	if strings.TrimSpace(task.Project) != "" {
+		status = domain.StatusTodo
+	}
+	// Rules stored before anchoring existed are anchored on the way.
+	recurrence := task.Recurrence.Anchored(task.DueAt)
+	next := recurrence.Next(task.DueAt, doneAt.In(time.Local))
+	// A deferred occurrence keeps the same lead time before its due date.
+	var startAt *time.Time
+	if task.StartAt != nil && task.DueAt != nil {
+		start := next.Add(task.StartAt.Sub(*task.DueAt))
+		startAt = &start
+	}
+	res, err := tx.ExecContext(
+		ctx,
+		`INSERT INTO tasks(parent_id, title, notes, status, project, priority, due_at, start_at, recurrence)
+		 VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		nullableID(task.ParentID), task.Title, task.Notes, string(status), task.Project, task.Priority,
+		dbTime(next), dbTimePtr(startAt), recurrence.String(),
+	)
+	if err != nil {
+		return err
+	}
+	nextID, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	if err := attachTagsTx(ctx, tx, nextID, task.Tags); err != nil {
+		return err
+	}
+	if err := recordCreatedTx(ctx, tx, nextID); err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `UPDATE tasks SET recurrence = '' WHERE id = ?`, id)
+	return err
 }
 
 func (r *TaskRepository) statusByID(ctx context.Context, tx *sql.Tx, id int64) (domain.Status, error) {
@@ -549,45 +1047,117 @@ func (r *TaskRepository) statusByID(ctx context.Context, tx *sql.Tx, id int64) (
 	if err != nil {
 		return "", err
 	}
-	return domain.ParseStatus(rawStatus)
+	return storedStatus(rawStatus)
+}
+
+// UnknownStatuses lists the tasks whose stored status the workflow does not
+// define, grouped by status.
+func (r *TaskRepository) UnknownStatuses(ctx context.Context) ([]domain.UnknownStatusError, error) {
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT status, id FROM tasks
+		  WHERE status NOT IN (`+statusListSQL(r.workflow.Statuses())+`)
+		  ORDER BY status, id`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []domain.UnknownStatusError
+	for rows.Next() {
+		var (
+			status domain.Status
+			id     int64
+		)
+		if err := rows.Scan(&status, &id); err != nil {
+			return nil, err
+		}
+		if n := len(out); n > 0 && out[n-1].Status == status {
+			out[n-1].IDs = append(out[n-1].IDs, id)
+			continue
+		}
+		out = append(out, domain.UnknownStatusError{Status: status, IDs: []int64{id}})
+	}
+	return out, rows.Err()
+}
+
+// storedStatus keeps statuses that config.toml no longer lists, so tasks
+// left in them can still be read and moved on; UnknownStatuses reports them.
+func storedStatus(raw string) (domain.Status, error) {
+	if raw == "" {
+		return "", domain.ErrInvalidStatus
+	}
+	return domain.Status(raw), nil
 }
 
 func scanTask(scanner interface {
 	Scan(dest ...any) error
 }) (domain.Task, error) {
 	var (
-		task      domain.Task
-		rawStatus string
-		dueAt     sql.NullTime
-		doneAt    sql.NullTime
+		task          domain.Task
+		parentID      sql.NullInt64
+		rawStatus     string
+		dueAt         sql.NullTime
+		startAt       sql.NullTime
+		doneAt        sql.NullTime
+		followUpAt    sql.NullTime
+		rawRecurrence string
 	)
 	if err := scanner.Scan(
 		&task.ID,
+		&parentID,
 		&task.Title,
 		&task.Notes,
 		&rawStatus,
 		&task.Project,
 		&task.Priority,
 		&dueAt,
+		&startAt,
 		&doneAt,
+		&rawRecurrence,
+		&task.SnoozeCount,
+		&task.WaitingOn,
+		&followUpAt,
 		&task.CreatedAt,
 		&task.UpdatedAt,
 	); err != nil {
 		return domain.Task{}, err
 	}
 
-	status, err := domain.ParseStatus(rawStatus)
+	status, err := storedStatus(rawStatus)
 	if err != nil {
 		return domain.Task{}, fmt.Errorf("parse status %q: %w", rawStatus, err)
 	}
 	task.Status = status
+	task.ParentID = parentID.Int64
+	recurrence, err := domain.ParseRecurrence(rawRecurrence)
+	if err != nil {
+		return domain.Task{}, fmt.Errorf("parse recurrence of task %d: %w", task.ID, err)
+	}
+	task.Recurrence = recurrence
 	if dueAt.Valid {
 		t := dueAt.Time.UTC()
 		task.DueAt = &t
 	}
+	if startAt.Valid {
+		t := startAt.Time.UTC()
+		task.StartAt = &t
+	}
 	if doneAt.Valid {
 		t := doneAt.Time.UTC()
 		task.DoneAt = &t
 	}
+	if followUpAt.Valid {
+		t := followUpAt.Time.UTC()
+		task.FollowUpAt = &t
+	}
 	return task, nil
 }
+
+func formatIDs(ids []int64) string {
+	parts := make([]string, 0, len(ids))
+	for _, id := range ids {
+		parts = append(parts, fmt.Sprintf("#%d", id))
+	}
+	return strings.Join(parts, ", ")
+}
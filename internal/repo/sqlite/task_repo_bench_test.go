@@ -0,0 +1,131 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"td/internal/app/usecase"
+	"td/internal/domain"
+	taskrepo "td/internal/repo"
+)
+
+const benchTaskCount = 100000
+
+var benchNow = time.Date(2026, 2, 23, 10, 0, 0, 0, time.UTC)
+
+func BenchmarkListByViewToday(b *testing.B) {
+	benchmarkListByView(b, domain.ViewToday)
+}
+
+func BenchmarkListByViewLog(b *testing.B) {
+	benchmarkListByView(b, domain.ViewLog)
+}
+
+func BenchmarkListByViewProject(b *testing.B) {
+	benchmarkListByView(b, domain.ViewProject)
+}
+
+func BenchmarkListAllThenFilterToday(b *testing.B) {
+	repo := NewTaskRepository(openBenchDB(b), domain.DefaultWorkflow())
+	ctx := context.Background()
+	dayEnd := benchNow.Truncate(24 * time.Hour).Add(24 * time.Hour)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tasks, err := repo.List(ctx, taskrepo.TaskListFilter{})
+		if err != nil {
+			b.Fatalf("list: %v", err)
+		}
+		n := 0
+		for _, task := range tasks {
+			if task.Status == domain.StatusDoing || (task.Status == domain.StatusTodo && task.DueAt != nil && task.DueAt.Before(dayEnd)) {
+				n++
+			}
+		}
+		_ = n
+	}
+}
+
+func BenchmarkCountOverdue(b *testing.B) {
+	repo := NewTaskRepository(openBenchDB(b), domain.DefaultWorkflow())
+	ctx := context.Background()
+	filter := taskrepo.TaskListFilter{
+		Statuses: []domain.Status{domain.StatusInbox, domain.StatusTodo, domain.StatusDoing},
+		DueTo:    &benchNow,
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := repo.Count(ctx, filter); err != nil {
+			b.Fatalf("count: %v", err)
+		}
+	}
+}
+
+func benchmarkListByView(b *testing.B, view domain.View) {
+	uc := usecase.NewNavQueryUseCase(NewTaskRepository(openBenchDB(b), domain.DefaultWorkflow()), domain.DefaultWorkflow())
+	ctx := context.Background()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := uc.ListByView(ctx, view, benchNow, "project-7", false); err != nil {
+			b.Fatalf("list %s: %v", view, err)
+		}
+	}
+}
+
+// openBenchDB seeds benchTaskCount tasks: mostly done history spread over
+// two years, plus a small open set so views select a realistic slice.
+func openBenchDB(b *testing.B) *sql.DB {
+	b.Helper()
+	db, err := Open(filepath.Join(b.TempDir(), "bench.db"))
+	if err != nil {
+		b.Fatalf("open: %v", err)
+	}
+	b.Cleanup(func() { _ = db.Close() })
+
+	tx, err := db.Begin()
+	if err != nil {
+		b.Fatalf("begin: %v", err)
+	}
+	stmt, err := tx.Prepare(`INSERT INTO tasks(title, status, project, priority, due_at, done_at) VALUES (?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		b.Fatalf("prepare: %v", err)
+	}
+	for i := 0; i < benchTaskCount; i++ {
+		status := domain.StatusDone
+		switch i % 50 {
+		case 0:
+			status = domain.StatusDoing
+		case 1, 2, 3:
+			status = domain.StatusTodo
+		case 4:
+			status = domain.StatusInbox
+		case 5:
+			status = domain.StatusDeleted
+		}
+		due := dbTime(benchNow.Add(time.Duration(i%720-360) * time.Hour))
+		var doneAt any
+		if status == domain.StatusDone {
+			doneAt = dbTime(benchNow.Add(-time.Duration(i%17520) * time.Hour))
+		}
+		if _, err := stmt.Exec(
+			fmt.Sprintf("task %d", i),
+			string(status),
+			fmt.Sprintf("project-%d", i%20),
+			fmt.Sprintf("P%d", i%4+1),
+			due,
+			doneAt,
+		); err != nil {
+			b.Fatalf("insert: %v", err)
+		}
+	}
+	if err := stmt.Close(); err != nil {
+		b.Fatalf("close stmt: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		b.Fatalf("commit: %v", err)
+	}
+	return db
+}
@@ -2,10 +2,14 @@ package sqlite
 
 import (
 	"context"
+	"errors"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	"td/internal/domain"
+	taskrepo "td/internal/repo"
 )
 
 func TestTaskLifecycle(t *testing.T) {
@@ -15,7 +19,7 @@ func TestTaskLifecycle(t *testing.T) {
 		t.Fatalf("migrate: %v", err)
 	}
 
-	repo := NewTaskRepository(db)
+	repo := NewTaskRepository(db, domain.DefaultWorkflow())
 	ctx := context.Background()
 
 	id, err := repo.Create(ctx, domain.Task{
@@ -56,7 +60,7 @@ func TestTaskMarkDoing(t *testing.T) {
 		t.Fatalf("migrate: %v", err)
 	}
 
-	repo := NewTaskRepository(db)
+	repo := NewTaskRepository(db, domain.DefaultWorkflow())
 	ctx := context.Background()
 
 	id, err := repo.Create(ctx, domain.Task{
@@ -86,7 +90,7 @@ func TestTaskUpdateProjectAndDueAt(t *testing.T) {
 		t.Fatalf("migrate: %v", err)
 	}
 
-	repo := NewTaskRepository(db)
+	repo := NewTaskRepository(db, domain.DefaultWorkflow())
 	ctx := context.Background()
 
 	id, err := repo.Create(ctx, domain.Task{
@@ -127,7 +131,7 @@ func TestProjectCRUDAndTaskDetachOnDelete(t *testing.T) {
 		t.Fatalf("migrate: %v", err)
 	}
 
-	repo := NewTaskRepository(db)
+	repo := NewTaskRepository(db, domain.DefaultWorkflow())
 	ctx := context.Background()
 
 	if err := repo.CreateProject(ctx, "work"); err != nil {
@@ -183,7 +187,7 @@ func TestUpdateProjectShouldMoveInboxToTodo(t *testing.T) {
 		t.Fatalf("migrate: %v", err)
 	}
 
-	repo := NewTaskRepository(db)
+	repo := NewTaskRepository(db, domain.DefaultWorkflow())
 	ctx := context.Background()
 
 	id, err := repo.Create(ctx, domain.Task{
@@ -216,7 +220,7 @@ func TestRestoreShouldMoveTaskWithoutProjectToInbox(t *testing.T) {
 		t.Fatalf("migrate: %v", err)
 	}
 
-	repo := NewTaskRepository(db)
+	repo := NewTaskRepository(db, domain.DefaultWorkflow())
 	ctx := context.Background()
 
 	id, err := repo.Create(ctx, domain.Task{
@@ -249,7 +253,7 @@ func TestSetStatusShouldUpdateStatusAndDoneAt(t *testing.T) {
 		t.Fatalf("migrate: %v", err)
 	}
 
-	repo := NewTaskRepository(db)
+	repo := NewTaskRepository(db, domain.DefaultWorkflow())
 	ctx := context.Background()
 
 	id, err := repo.Create(ctx, domain.Task{
@@ -287,4 +291,1012 @@ func TestSetStatusShouldUpdateStatusAndDoneAt(t *testing.T) {
 	if task.DoneAt != nil {
 		t.Fatalf("done_at should be nil when status is not done")
 	}
+
+	dueAt := time.Now().AddDate(0, 0, 1).UTC()
+	recurring, err := repo.Create(ctx, domain.Task{
+		Title:      "water plants",
+		Status:     domain.StatusTodo,
+		DueAt:      &dueAt,
+		Recurrence: domain.Recurrence{Freq: domain.FreqWeekly, Interval: 1},
+	})
+	if err != nil {
+		t.Fatalf("create recurring task: %v", err)
+	}
+	if err := repo.SetStatus(ctx, recurring, domain.StatusDone); err != nil {
+		t.Fatalf("set recurring status done: %v", err)
+	}
+	open, err := repo.List(ctx, taskrepo.TaskListFilter{Statuses: []domain.Status{domain.StatusInbox}})
+	if err != nil || len(open) != 1 || open[0].Title != "water plants" || open[0].Recurrence.IsZero() {
+		t.Fatalf("next occurrence = %+v, %v", open, err)
+	}
+}
+
+func TestTaskTagsLifecycle(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+	if err := Migrate(db); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	repo := NewTaskRepository(db, domain.DefaultWorkflow())
+	ctx := context.Background()
+
+	id, err := repo.Create(ctx, domain.Task{
+		Title:  "write report",
+		Status: domain.StatusTodo,
+		Tags:   []string{"Work", "#urgent", "work"},
+	})
+	if err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+	task, err := repo.GetByID(ctx, id)
+	if err != nil {
+		t.Fatalf("get task: %v", err)
+	}
+	if strings.Join(task.Tags, ",") != "urgent,work" {
+		t.Fatalf("tags = %v, want [urgent work]", task.Tags)
+	}
+
+	if err := repo.AddTags(ctx, id, []string{"later"}); err != nil {
+		t.Fatalf("add tags: %v", err)
+	}
+	if err := repo.RemoveTags(ctx, id, []string{"urgent"}); err != nil {
+		t.Fatalf("remove tags: %v", err)
+	}
+	tags, err := repo.ListTags(ctx)
+	if err != nil {
+		t.Fatalf("list tags: %v", err)
+	}
+	if strings.Join(tags, ",") != "later,work" {
+		t.Fatalf("list tags = %v, want [later work]", tags)
+	}
+
+	if err := repo.RenameTag(ctx, "later", "someday"); err != nil {
+		t.Fatalf("rename tag: %v", err)
+	}
+	if err := repo.RenameTag(ctx, "someday", "work"); err == nil {
+		t.Fatalf("rename onto existing tag should fail")
+	}
+	if err := repo.MergeTags(ctx, []string{"someday"}, "work"); err != nil {
+		t.Fatalf("merge tags: %v", err)
+	}
+	task, err = repo.GetByID(ctx, id)
+	if err != nil {
+		t.Fatalf("get task after merge: %v", err)
+	}
+	if strings.Join(task.Tags, ",") != "work" {
+		t.Fatalf("tags after merge = %v, want [work]", task.Tags)
+	}
+
+	otherID, err := repo.Create(ctx, domain.Task{Title: "untagged", Status: domain.StatusTodo})
+	if err != nil {
+		t.Fatalf("create untagged: %v", err)
+	}
+	tasks, err := repo.List(ctx, taskrepo.TaskListFilter{Tag: "work"})
+	if err != nil {
+		t.Fatalf("list by tag: %v", err)
+	}
+	if len(tasks) != 1 || tasks[0].ID != id {
+		t.Fatalf("list by tag = %+v, want only #%d (not #%d)", tasks, id, otherID)
+	}
+
+	if err := repo.SoftDelete(ctx, []int64{id}); err != nil {
+		t.Fatalf("soft delete: %v", err)
+	}
+	if err := repo.Purge(ctx, []int64{id}); err != nil {
+		t.Fatalf("purge: %v", err)
+	}
+	tags, err = repo.ListTags(ctx)
+	if err != nil {
+		t.Fatalf("list tags after purge: %v", err)
+	}
+	if len(tags) != 0 {
+		t.Fatalf("tags after purge = %v, want empty", tags)
+	}
+}
+
+func TestListShouldPushDownFilters(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+	if err := Migrate(db); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	repo := NewTaskRepository(db, domain.DefaultWorkflow())
+	ctx := context.Background()
+	cst := time.FixedZone("CST", 8*3600)
+	dueEarly := time.Date(2026, 2, 23, 7, 0, 0, 0, cst)
+	dueLate := time.Date(2026, 2, 23, 9, 0, 0, 0, time.UTC)
+	dueNextWeek := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+
+	lateID, err := repo.Create(ctx, domain.Task{Title: "late", Status: domain.StatusTodo, Project: "work", DueAt: &dueLate})
+	if err != nil {
+		t.Fatalf("create late: %v", err)
+	}
+	earlyID, err := repo.Create(ctx, domain.Task{Title: "early", Status: domain.StatusTodo, DueAt: &dueEarly})
+	if err != nil {
+		t.Fatalf("create early: %v", err)
+	}
+	if _, err := repo.Create(ctx, domain.Task{Title: "next week", Status: domain.StatusTodo, Project: "work", DueAt: &dueNextWeek}); err != nil {
+		t.Fatalf("create next week: %v", err)
+	}
+	doneID, err := repo.Create(ctx, domain.Task{Title: "done", Status: domain.StatusTodo, Project: "work"})
+	if err != nil {
+		t.Fatalf("create done: %v", err)
+	}
+	if err := repo.MarkDone(ctx, []int64{doneID}); err != nil {
+		t.Fatalf("mark done: %v", err)
+	}
+
+	dueTo := time.Date(2026, 2, 24, 0, 0, 0, 0, time.UTC)
+	tasks, err := repo.List(ctx, taskrepo.TaskListFilter{
+		Statuses: []domain.Status{domain.StatusTodo},
+		DueTo:    &dueTo,
+		Sort:     taskrepo.SortByDue,
+	})
+	if err != nil {
+		t.Fatalf("list due range: %v", err)
+	}
+	if len(tasks) != 2 || tasks[0].ID != earlyID || tasks[1].ID != lateID {
+		t.Fatalf("due range = %+v, want #%d then #%d", tasks, earlyID, lateID)
+	}
+	if !tasks[0].DueAt.Equal(dueEarly) {
+		t.Fatalf("due = %v, want %v", tasks[0].DueAt, dueEarly)
+	}
+
+	tasks, err = repo.List(ctx, taskrepo.TaskListFilter{
+		Statuses:  []domain.Status{domain.StatusTodo},
+		NoProject: true,
+	})
+	if err != nil {
+		t.Fatalf("list without project: %v", err)
+	}
+	if len(tasks) != 1 || tasks[0].ID != earlyID {
+		t.Fatalf("without project = %+v, want only #%d", tasks, earlyID)
+	}
+
+	doneFrom := time.Now().Add(-time.Hour)
+	count, err := repo.Count(ctx, taskrepo.TaskListFilter{
+		Statuses: []domain.Status{domain.StatusDone},
+		Project:  "work",
+		DoneFrom: &doneFrom,
+	})
+	if err != nil {
+		t.Fatalf("count done: %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("done count = %d, want 1", count)
+	}
+
+	if _, err := repo.List(ctx, taskrepo.TaskListFilter{Statuses: []domain.Status{"later"}}); err == nil {
+		t.Fatalf("invalid status should fail")
+	}
+}
+
+func TestMarkDoneShouldCreateNextOccurrence(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+	if err := Migrate(db); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	repo := NewTaskRepository(db, domain.DefaultWorkflow())
+	ctx := context.Background()
+
+	now := time.Now()
+	soon := time.Date(now.Year(), now.Month(), now.Day(), 9, 30, 0, 0, time.Local).AddDate(0, 0, 3)
+	overdue := soon.AddDate(0, 0, -13)
+	weekly := domain.Recurrence{Freq: domain.FreqWeekly, Interval: 1}
+	daily := domain.Recurrence{Freq: domain.FreqDaily, Interval: 1, AfterCompletion: true}
+
+	cases := []struct {
+		name       string
+		dueAt      time.Time
+		recurrence domain.Recurrence
+		want       time.Time
+	}{
+		{name: "fixed", dueAt: soon, recurrence: weekly, want: soon.AddDate(0, 0, 7)},
+		{name: "fixed skips missed", dueAt: overdue, recurrence: weekly, want: overdue.AddDate(0, 0, 14)},
+		{name: "after completion", dueAt: overdue, recurrence: daily,
+			want: time.Date(now.Year(), now.Month(), now.Day(), 9, 30, 0, 0, time.Local).AddDate(0, 0, 1)},
+	}
+	for _, tc := range cases {
+		dueAt := tc.dueAt.UTC()
+		id, err := repo.Create(ctx, domain.Task{
+			Title:      tc.name,
+			Status:     domain.StatusDoing,
+			Project:    "home",
+			Priority:   "P1",
+			DueAt:      &dueAt,
+			Tags:       []string{"chore"},
+			Recurrence: tc.recurrence,
+		})
+		if err != nil {
+			t.Fatalf("%s: create: %v", tc.name, err)
+		}
+		if err := repo.MarkDone(ctx, []int64{id}); err != nil {
+			t.Fatalf("%s: mark done: %v", tc.name, err)
+		}
+
+		done, err := repo.GetByID(ctx, id)
+		if err != nil {
+			t.Fatalf("%s: get done: %v", tc.name, err)
+		}
+		if done.Status != domain.StatusDone || !done.Recurrence.IsZero() {
+			t.Fatalf("%s: completed task = %+v, want done without recurrence", tc.name, done)
+		}
+		open, err := repo.List(ctx, taskrepo.TaskListFilter{
+			Statuses: []domain.Status{domain.StatusTodo},
+			Project:  "home",
+		})
+		if err != nil {
+			t.Fatalf("%s: list: %v", tc.name, err)
+		}
+		if len(open) != 1 {
+			t.Fatalf("%s: open occurrences = %d, want 1", tc.name, len(open))
+		}
+		next := open[0]
+		if next.Title != tc.name || next.Priority != "P1" || strings.Join(next.Tags, ",") != "chore" {
+			t.Fatalf("%s: next occurrence = %+v", tc.name, next)
+		}
+		if next.Recurrence.String() != tc.recurrence.String() {
+			t.Fatalf("%s: next recurrence = %q, want %q", tc.name, next.Recurrence, tc.recurrence)
+		}
+		if next.DueAt == nil || !next.DueAt.Equal(tc.want) {
+			t.Fatalf("%s: next due = %v, want %v", tc.name, next.DueAt, tc.want.UTC())
+		}
+		if _, err := db.ExecContext(ctx, `UPDATE tasks SET status = 'deleted' WHERE project = 'home'`); err != nil {
+			t.Fatalf("%s: reset: %v", tc.name, err)
+		}
+	}
+}
+
+func TestMonthlyRepeatShouldKeepItsDayAfterClamping(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+	if err := Migrate(db); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	repo := NewTaskRepository(db, domain.DefaultWorkflow())
+	ctx := context.Background()
+
+	year := time.Now().Year() + 1
+	dueAt := time.Date(year, 1, 31, 9, 0, 0, 0, time.Local)
+	id, err := repo.Create(ctx, domain.Task{
+		Title:      "pay rent",
+		Status:     domain.StatusTodo,
+		DueAt:      &dueAt,
+		Recurrence: domain.Recurrence{Freq: domain.FreqMonthly, Interval: 1},
+	})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	for _, want := range []time.Time{
+		time.Date(year, 2, 28, 9, 0, 0, 0, time.Local),
+		time.Date(year, 3, 31, 9, 0, 0, 0, time.Local),
+	} {
+		if err := repo.MarkDone(ctx, []int64{id}); err != nil {
+			t.Fatalf("mark done: %v", err)
+		}
+		open, err := repo.List(ctx, taskrepo.TaskListFilter{Statuses: []domain.Status{domain.StatusInbox}})
+		if err != nil || len(open) != 1 {
+			t.Fatalf("open occurrences = %+v, %v", open, err)
+		}
+		id = open[0].ID
+		if open[0].DueAt == nil || !open[0].DueAt.Equal(want) {
+			t.Fatalf("next due = %v, want %v", open[0].DueAt, want)
+		}
+		if got := open[0].Recurrence.String(); got != "FREQ=MONTHLY;BYMONTHDAY=31;X-TD-ANCHOR=DUE" {
+			t.Fatalf("next recurrence = %q", got)
+		}
+	}
+
+	moved := time.Date(year, 4, 15, 9, 0, 0, 0, time.Local)
+	if err := repo.UpdateDueAt(ctx, id, &moved); err != nil {
+		t.Fatalf("update due: %v", err)
+	}
+	task, _ := repo.GetByID(ctx, id)
+	if got := task.Recurrence.String(); got != "FREQ=MONTHLY;BYMONTHDAY=15;X-TD-ANCHOR=DUE" {
+		t.Fatalf("recurrence after moving due = %q", got)
+	}
+
+	explicit, _ := domain.ParseRecurrence("FREQ=MONTHLY;BYMONTHDAY=15")
+	if err := repo.UpdateRecurrence(ctx, id, explicit); err != nil {
+		t.Fatalf("update recurrence: %v", err)
+	}
+	moved = time.Date(year, 4, 20, 9, 0, 0, 0, time.Local)
+	if err := repo.UpdateDueAt(ctx, id, &moved); err != nil {
+		t.Fatalf("update due: %v", err)
+	}
+	task, _ = repo.GetByID(ctx, id)
+	if got := task.Recurrence.String(); got != "FREQ=MONTHLY;BYMONTHDAY=15" {
+		t.Fatalf("explicit recurrence after moving due = %q", got)
+	}
+}
+
+func TestSubtasksShouldCascadeAndReportProgress(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+	if err := Migrate(db); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	repo := NewTaskRepository(db, domain.DefaultWorkflow())
+	ctx := context.Background()
+
+	create := func(title string, parentID int64) int64 {
+		t.Helper()
+		id, err := repo.Create(ctx, domain.Task{Title: title, Status: domain.StatusTodo, Project: "trip", ParentID: parentID})
+		if err != nil {
+			t.Fatalf("create %s: %v", title, err)
+		}
+		return id
+	}
+	statusOf := func(id int64) domain.Status {
+		t.Helper()
+		task, err := repo.GetByID(ctx, id)
+		if err != nil {
+			t.Fatalf("get #%d: %v", id, err)
+		}
+		return task.Status
+	}
+
+	parent := create("plan trip", 0)
+	flights := create("book flights", parent)
+	hotel := create("book hotel", parent)
+	deposit := create("pay deposit", hotel)
+	if _, err := repo.Create(ctx, domain.Task{Title: "orphan", Status: domain.StatusTodo, ParentID: 999}); err == nil {
+		t.Fatalf("create with missing parent should fail")
+	}
+
+	if err := repo.MarkDone(ctx, []int64{flights}); err != nil {
+		t.Fatalf("mark done: %v", err)
+	}
+	task, err := repo.GetByID(ctx, parent)
+	if err != nil {
+		t.Fatalf("get parent: %v", err)
+	}
+	if task.Subtasks != (domain.Progress{Done: 1, Total: 2}) {
+		t.Fatalf("progress = %+v, want 1/2", task.Subtasks)
+	}
+	children, err := repo.List(ctx, taskrepo.TaskListFilter{ParentID: parent})
+	if err != nil || len(children) != 2 || children[1].Subtasks.Total != 1 {
+		t.Fatalf("children = %+v, err = %v", children, err)
+	}
+
+	if err := repo.SoftDelete(ctx, []int64{parent}); err != nil {
+		t.Fatalf("soft delete: %v", err)
+	}
+	for _, id := range []int64{parent, flights, hotel, deposit} {
+		if got := statusOf(id); got != domain.StatusDeleted {
+			t.Fatalf("#%d status after delete = %s, want deleted", id, got)
+		}
+	}
+	if err := repo.Restore(ctx, []int64{parent}); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if statusOf(deposit) != domain.StatusTodo || statusOf(flights) != domain.StatusTodo {
+		t.Fatalf("restore should bring subtasks back")
+	}
+
+	if err := repo.MarkDoneWithSubtasks(ctx, []int64{parent}); err != nil {
+		t.Fatalf("mark done with subtasks: %v", err)
+	}
+	for _, id := range []int64{parent, flights, hotel, deposit} {
+		if got := statusOf(id); got != domain.StatusDone {
+			t.Fatalf("#%d status after cascade = %s, want done", id, got)
+		}
+	}
+
+	if err := repo.SoftDelete(ctx, []int64{hotel}); err != nil {
+		t.Fatalf("soft delete hotel: %v", err)
+	}
+	if err := repo.Purge(ctx, []int64{hotel}); err != nil {
+		t.Fatalf("purge: %v", err)
+	}
+	if _, err := repo.GetByID(ctx, deposit); err != domain.ErrTaskNotFound {
+		t.Fatalf("purge should remove deleted subtasks, err = %v", err)
+	}
+	task, err = repo.GetByID(ctx, parent)
+	if err != nil || task.Subtasks != (domain.Progress{Done: 1, Total: 1}) {
+		t.Fatalf("progress after purge = %+v, err = %v", task.Subtasks, err)
+	}
+}
+
+func TestDependenciesShouldRejectCyclesAndReleaseOnDone(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+	if err := Migrate(db); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	repo := NewTaskRepository(db, domain.DefaultWorkflow())
+	ctx := context.Background()
+
+	ids := make([]int64, 0, 3)
+	for _, title := range []string{"design", "build", "ship"} {
+		id, err := repo.Create(ctx, domain.Task{Title: title, Status: domain.StatusTodo})
+		if err != nil {
+			t.Fatalf("create %s: %v", title, err)
+		}
+		ids = append(ids, id)
+	}
+	design, build, ship := ids[0], ids[1], ids[2]
+	if err := repo.AddDependencies(ctx, build, []int64{design}); err != nil {
+		t.Fatalf("block build: %v", err)
+	}
+	if err := repo.AddDependencies(ctx, ship, []int64{build}); err != nil {
+		t.Fatalf("block ship: %v", err)
+	}
+	if err := repo.AddDependencies(ctx, design, []int64{ship}); !errors.Is(err, domain.ErrDependencyCycle) {
+		t.Fatalf("cycle err = %v", err)
+	}
+	if err := repo.AddDependencies(ctx, design, []int64{design}); !errors.Is(err, domain.ErrDependencyCycle) {
+		t.Fatalf("self dependency err = %v", err)
+	}
+	if err := repo.AddDependencies(ctx, design, []int64{999}); !errors.Is(err, domain.ErrTaskNotFound) {
+		t.Fatalf("missing blocker err = %v", err)
+	}
+	if err := repo.MarkDoing(ctx, []int64{build}); !errors.Is(err, domain.ErrTaskBlocked) {
+		t.Fatalf("start blocked err = %v", err)
+	}
+
+	unblocked, err := repo.List(ctx, taskrepo.TaskListFilter{Statuses: []domain.Status{domain.StatusTodo}, Unblocked: true})
+	if err != nil {
+		t.Fatalf("list unblocked: %v", err)
+	}
+	if len(unblocked) != 1 || unblocked[0].ID != design {
+		t.Fatalf("unblocked = %+v", unblocked)
+	}
+	waiting, err := repo.List(ctx, taskrepo.TaskListFilter{BlockerID: design})
+	if err != nil {
+		t.Fatalf("list dependents: %v", err)
+	}
+	if len(waiting) != 1 || waiting[0].ID != build || len(waiting[0].BlockedBy) != 1 {
+		t.Fatalf("dependents = %+v", waiting)
+	}
+
+	if err := repo.MarkDone(ctx, []int64{design}); err != nil {
+		t.Fatalf("done design: %v", err)
+	}
+	got, err := repo.GetByID(ctx, build)
+	if err != nil {
+		t.Fatalf("get build: %v", err)
+	}
+	if got.IsBlocked() {
+		t.Fatalf("build should be released once design is done: %+v", got.BlockedBy)
+	}
+	if err := repo.MarkDoing(ctx, []int64{build}); err != nil {
+		t.Fatalf("start build: %v", err)
+	}
+
+	if err := repo.RemoveDependencies(ctx, ship, nil); err != nil {
+		t.Fatalf("unblock ship: %v", err)
+	}
+	got, err = repo.GetByID(ctx, ship)
+	if err != nil {
+		t.Fatalf("get ship: %v", err)
+	}
+	if got.IsBlocked() {
+		t.Fatalf("ship should have no blockers: %+v", got.BlockedBy)
+	}
+}
+
+func TestTaskEventsShouldRecordChangedFieldsWithSource(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+	if err := Migrate(db); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	repo := NewTaskRepository(db, domain.DefaultWorkflow())
+	ctx := taskrepo.WithSource(context.Background(), domain.SourceCLI)
+
+	id, err := repo.Create(ctx, domain.Task{Title: "draft", Status: domain.StatusInbox})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if err := repo.UpdateTitle(ctx, id, "draft report"); err != nil {
+		t.Fatalf("title: %v", err)
+	}
+	if err := repo.UpdateTitle(ctx, id, "draft report"); err != nil {
+		t.Fatalf("same title: %v", err)
+	}
+	if err := repo.UpdateProject(ctx, id, "work"); err != nil {
+		t.Fatalf("project: %v", err)
+	}
+	due := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	if err := repo.UpdateDueAt(ctx, id, &due); err != nil {
+		t.Fatalf("due: %v", err)
+	}
+	if err := repo.AddTags(ctx, id, []string{"q1"}); err != nil {
+		t.Fatalf("tags: %v", err)
+	}
+	tuiCtx := taskrepo.WithSource(context.Background(), domain.SourceTUI)
+	if err := repo.MarkDone(tuiCtx, []int64{id}); err != nil {
+		t.Fatalf("done: %v", err)
+	}
+	if err := repo.UpdateTitle(ctx, 999, "missing"); !errors.Is(err, domain.ErrTaskNotFound) {
+		t.Fatalf("missing task err = %v", err)
+	}
+
+	events, err := repo.ListEvents(context.Background(), id, 0)
+	if err != nil {
+		t.Fatalf("list events: %v", err)
+	}
+	got := make([]string, 0, len(events))
+	for _, event := range events {
+		got = append(got, string(event.Source)+" "+event.Field+" "+event.OldValue+">"+event.NewValue)
+	}
+	want := []string{
+		"cli created >draft",
+		"cli title draft>draft report",
+		"cli status inbox>todo",
+		"cli project >work",
+		"cli due >2026-03-01T09:00:00Z",
+		"cli tags >q1",
+		"tui status todo>done",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("events =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	latest, err := repo.ListEvents(context.Background(), id, 2)
+	if err != nil {
+		t.Fatalf("list latest: %v", err)
+	}
+	if len(latest) != 2 || latest[1].Field != domain.EventStatus || latest[0].Field != domain.EventTags {
+		t.Fatalf("latest events = %+v", latest)
+	}
+}
+
+func TestUndoRedoShouldReplayOperations(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+	if err := Migrate(db); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	repo := NewTaskRepository(db, domain.DefaultWorkflow())
+	ctx := taskrepo.WithSource(context.Background(), domain.SourceCLI)
+
+	if _, err := repo.Undo(ctx); !errors.Is(err, domain.ErrNothingToUndo) {
+		t.Fatalf("undo on empty journal err = %v", err)
+	}
+	due := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	id, err := repo.Create(ctx, domain.Task{
+		Title:      "standup",
+		Status:     domain.StatusTodo,
+		Project:    "work",
+		DueAt:      &due,
+		Recurrence: domain.Recurrence{Freq: domain.FreqDaily, Interval: 1},
+	})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if err := repo.MarkDone(ctx, []int64{id}); err != nil {
+		t.Fatalf("done: %v", err)
+	}
+	if n, _ := repo.Count(ctx, taskrepo.TaskListFilter{Project: "work"}); n != 2 {
+		t.Fatalf("tasks after done = %d, want 2", n)
+	}
+
+	op, err := repo.Undo(ctx)
+	if err != nil {
+		t.Fatalf("undo done: %v", err)
+	}
+	if op.Summary != "done #"+strconv.FormatInt(id, 10)+" #"+strconv.FormatInt(id+1, 10) {
+		t.Fatalf("undo summary = %q", op.Summary)
+	}
+	task, err := repo.GetByID(ctx, id)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if task.Status != domain.StatusTodo || task.DoneAt != nil || task.Recurrence.IsZero() {
+		t.Fatalf("task after undo = %+v", task)
+	}
+	if _, err := repo.GetByID(ctx, id+1); !errors.Is(err, domain.ErrTaskNotFound) {
+		t.Fatalf("spawned occurrence should be removed, err = %v", err)
+	}
+
+	if _, err := repo.Redo(ctx); err != nil {
+		t.Fatalf("redo done: %v", err)
+	}
+	if task, _ := repo.GetByID(ctx, id); task.Status != domain.StatusDone {
+		t.Fatalf("status after redo = %s, want done", task.Status)
+	}
+	if _, err := repo.GetByID(ctx, id+1); err != nil {
+		t.Fatalf("redo should recreate occurrence: %v", err)
+	}
+
+	if err := repo.DeleteProject(ctx, "work"); err != nil {
+		t.Fatalf("delete project: %v", err)
+	}
+	if _, err := repo.Undo(ctx); err != nil {
+		t.Fatalf("undo delete project: %v", err)
+	}
+	projects, err := repo.ListProjects(ctx)
+	if err != nil {
+		t.Fatalf("list projects: %v", err)
+	}
+	if strings.Join(projects, ",") != "work" {
+		t.Fatalf("projects after undo = %v", projects)
+	}
+	if task, _ := repo.GetByID(ctx, id+1); task.Project != "work" {
+		t.Fatalf("project after undo = %q, want work", task.Project)
+	}
+
+	if err := repo.SoftDelete(ctx, []int64{id + 1}); err != nil {
+		t.Fatalf("soft delete: %v", err)
+	}
+	if err := repo.Purge(ctx, []int64{id + 1}); err != nil {
+		t.Fatalf("purge: %v", err)
+	}
+	if _, err := repo.Undo(ctx); err != nil {
+		t.Fatalf("undo purge: %v", err)
+	}
+	if task, err := repo.GetByID(ctx, id+1); err != nil || task.Status != domain.StatusDeleted {
+		t.Fatalf("task after undo purge = %+v, %v", task, err)
+	}
+
+	// A new change drops the redo tail.
+	if err := repo.UpdateTitle(ctx, id, "daily standup"); err != nil {
+		t.Fatalf("title: %v", err)
+	}
+	if _, err := repo.Redo(ctx); !errors.Is(err, domain.ErrNothingToRedo) {
+		t.Fatalf("redo after new change err = %v", err)
+	}
+	ops, err := repo.ListOperations(ctx, 2)
+	if err != nil {
+		t.Fatalf("list operations: %v", err)
+	}
+	if len(ops) != 2 || ops[0].Summary != "edit #"+strconv.FormatInt(id, 10) || ops[0].Source != domain.SourceCLI || ops[1].State != domain.OperationDone {
+		t.Fatalf("operations = %+v", ops)
+	}
+}
+
+func TestSearchShouldRankAndHighlightMatches(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+	if err := Migrate(db); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	repo := NewTaskRepository(db, domain.DefaultWorkflow())
+	ctx := context.Background()
+
+	notesID, err := repo.Create(ctx, domain.Task{Title: "周五例会", Notes: "会前整理周报模板", Status: domain.StatusTodo})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	titleID, err := repo.Create(ctx, domain.Task{Title: "整理周报模板", Status: domain.StatusTodo})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	otherID, err := repo.Create(ctx, domain.Task{Title: "Write Report", Status: domain.StatusInbox})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	open := taskrepo.TaskListFilter{Statuses: []domain.Status{domain.StatusInbox, domain.StatusTodo}}
+	hits, err := repo.Search(ctx, []string{"周报模板"}, open)
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if len(hits) != 2 || hits[0].Task.ID != titleID || hits[1].Task.ID != notesID {
+		t.Fatalf("hits = %+v, want title match before notes match", hits)
+	}
+	if want := "整理" + taskrepo.SnippetStart + "周报模板" + taskrepo.SnippetEnd; hits[0].Snippet != want {
+		t.Fatalf("snippet = %q, want %q", hits[0].Snippet, want)
+	}
+
+	hits, err = repo.Search(ctx, []string{"周报"}, open)
+	if err != nil {
+		t.Fatalf("short search: %v", err)
+	}
+	if len(hits) != 2 || !strings.Contains(hits[0].Snippet, taskrepo.SnippetStart+"周报"+taskrepo.SnippetEnd) {
+		t.Fatalf("short term hits = %+v", hits)
+	}
+
+	hits, err = repo.Search(ctx, []string{"report"}, open)
+	if err != nil {
+		t.Fatalf("search report: %v", err)
+	}
+	if len(hits) != 1 || hits[0].Task.ID != otherID {
+		t.Fatalf("case-insensitive hits = %+v", hits)
+	}
+
+	if err := repo.UpdateTitle(ctx, otherID, "Write summary"); err != nil {
+		t.Fatalf("title: %v", err)
+	}
+	if err := repo.SoftDelete(ctx, []int64{titleID}); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if hits, _ := repo.Search(ctx, []string{"report"}, open); len(hits) != 0 {
+		t.Fatalf("renamed task should not match old title: %+v", hits)
+	}
+	if hits, _ := repo.Search(ctx, []string{"周报模板"}, open); len(hits) != 1 || hits[0].Task.ID != notesID {
+		t.Fatalf("deleted task should be filtered out: %+v", hits)
+	}
+	if err := repo.Purge(ctx, []int64{titleID}); err != nil {
+		t.Fatalf("purge: %v", err)
+	}
+	if hits, _ := repo.Search(ctx, []string{"周报模板"}, taskrepo.TaskListFilter{}); len(hits) != 1 {
+		t.Fatalf("purged task should leave the index: %+v", hits)
+	}
+}
+
+func TestSnoozeShouldCountAndUndoAsOneOperation(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+	if err := Migrate(db); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	repo := NewTaskRepository(db, domain.DefaultWorkflow())
+	ctx := context.Background()
+
+	due := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	a, _ := repo.Create(ctx, domain.Task{Title: "a", Status: domain.StatusTodo, DueAt: &due})
+	b, _ := repo.Create(ctx, domain.Task{Title: "b", Status: domain.StatusTodo})
+	later := due.AddDate(0, 0, 1)
+	if err := repo.Snooze(ctx, map[int64]time.Time{a: later, b: later}); err != nil {
+		t.Fatalf("snooze: %v", err)
+	}
+	if err := repo.Snooze(ctx, map[int64]time.Time{a: later.AddDate(0, 0, 1)}); err != nil {
+		t.Fatalf("snooze again: %v", err)
+	}
+	task, _ := repo.GetByID(ctx, a)
+	if task.SnoozeCount != 2 || !task.DueAt.Equal(later.AddDate(0, 0, 1)) {
+		t.Fatalf("task a = count %d due %v", task.SnoozeCount, task.DueAt)
+	}
+
+	if _, err := repo.Undo(ctx); err != nil {
+		t.Fatalf("undo: %v", err)
+	}
+	op, err := repo.Undo(ctx)
+	if err != nil || op.Summary != "snooze #"+strconv.FormatInt(a, 10)+" #"+strconv.FormatInt(b, 10) {
+		t.Fatalf("undo = %+v, %v", op, err)
+	}
+	for _, id := range []int64{a, b} {
+		task, _ := repo.GetByID(ctx, id)
+		if task.SnoozeCount != 0 {
+			t.Fatalf("#%d snooze count after undo = %d", id, task.SnoozeCount)
+		}
+	}
+	task, _ = repo.GetByID(ctx, a)
+	if !task.DueAt.Equal(due) {
+		t.Fatalf("due after undo = %v, want %s", task.DueAt, due)
+	}
+
+	if err := repo.MarkDone(ctx, []int64{b}); err != nil {
+		t.Fatalf("done: %v", err)
+	}
+	if err := repo.Snooze(ctx, map[int64]time.Time{a: later, b: later}); err == nil {
+		t.Fatalf("snoozing a done task should fail")
+	}
+	task, _ = repo.GetByID(ctx, a)
+	if task.SnoozeCount != 0 {
+		t.Fatalf("failed snooze should not touch other tasks, count = %d", task.SnoozeCount)
+	}
+}
+
+func TestCreateManyShouldUndoAsOneOperation(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+	if err := Migrate(db); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	repo := NewTaskRepository(db, domain.DefaultWorkflow())
+	ctx := context.Background()
+
+	if _, err := repo.CreateMany(ctx, []domain.Task{{Title: "ok", Status: domain.StatusTodo}, {Title: "bad", Priority: "P9"}}); err == nil {
+		t.Fatalf("create many with an invalid task should fail")
+	}
+	if count, _ := repo.Count(ctx, taskrepo.TaskListFilter{}); count != 0 {
+		t.Fatalf("failed create many left %d tasks", count)
+	}
+
+	ids, err := repo.CreateMany(ctx, []domain.Task{
+		{Title: "write minutes", Project: "weekly"},
+		{Title: "book room", Project: "weekly"},
+	})
+	if err != nil || len(ids) != 2 {
+		t.Fatalf("create many = %v, %v", ids, err)
+	}
+	op, err := repo.Undo(ctx)
+	if err != nil {
+		t.Fatalf("undo: %v", err)
+	}
+	if !strings.HasPrefix(op.Summary, "add") {
+		t.Fatalf("undo summary = %q", op.Summary)
+	}
+	for _, id := range ids {
+		if _, err := repo.GetByID(ctx, id); err == nil {
+			t.Fatalf("#%d should be gone after one undo", id)
+		}
+	}
+}
+
+func TestWaitShouldTrackWhoAndClearOnReopen(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+	if err := Migrate(db); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	repo := NewTaskRepository(db, domain.DefaultWorkflow())
+	ctx := context.Background()
+
+	id, err := repo.Create(ctx, domain.Task{Title: "contract", Status: domain.StatusTodo})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	followUp := time.Date(2026, 3, 6, 0, 0, 0, 0, time.UTC)
+	if err := repo.Wait(ctx, id, "alice", &followUp); err != nil {
+		t.Fatalf("wait: %v", err)
+	}
+	task, _ := repo.GetByID(ctx, id)
+	if task.Status != domain.StatusWaiting || task.WaitingOn != "alice" || task.FollowUpAt == nil || !task.FollowUpAt.Equal(followUp) {
+		t.Fatalf("task = %+v", task)
+	}
+	events, err := repo.ListEvents(ctx, id, 0)
+	if err != nil {
+		t.Fatalf("events: %v", err)
+	}
+	fields := make([]string, 0, len(events))
+	for _, event := range events {
+		fields = append(fields, event.Field)
+	}
+	if got := strings.Join(fields, ","); !strings.Contains(got, "status,waiting_on,followup") {
+		t.Fatalf("event fields = %s", got)
+	}
+
+	before := followUp.Add(time.Second)
+	if n, _ := repo.Count(ctx, taskrepo.TaskListFilter{Statuses: []domain.Status{domain.StatusWaiting}, FollowUpTo: &followUp}); n != 0 {
+		t.Fatalf("follow-up range should be exclusive, got %d", n)
+	}
+	if n, _ := repo.Count(ctx, taskrepo.TaskListFilter{Statuses: []domain.Status{domain.StatusWaiting}, FollowUpTo: &before}); n != 1 {
+		t.Fatalf("follow-up count = %d, want 1", n)
+	}
+
+	if err := repo.Reopen(ctx, []int64{id}); err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	task, _ = repo.GetByID(ctx, id)
+	if task.Status != domain.StatusTodo || task.WaitingOn != "" || task.FollowUpAt != nil {
+		t.Fatalf("reopened task = %+v, want wait cleared", task)
+	}
+	if _, err := repo.Undo(ctx); err != nil {
+		t.Fatalf("undo: %v", err)
+	}
+	task, _ = repo.GetByID(ctx, id)
+	if task.Status != domain.StatusWaiting || task.WaitingOn != "alice" {
+		t.Fatalf("undo should restore the wait, got %+v", task)
+	}
+
+	if err := repo.MarkDone(ctx, []int64{id}); err != nil {
+		t.Fatalf("done from waiting: %v", err)
+	}
+	var transitionErr domain.InvalidTransitionError
+	if err := repo.Wait(ctx, id, "bob", nil); !errors.As(err, &transitionErr) {
+		t.Fatalf("waiting on a done task err = %v", err)
+	}
+}
+
+func TestTransitShouldFollowTheRepositoryWorkflow(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+	if err := Migrate(db); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	workflow, err := domain.NewWorkflow(domain.WorkflowSpec{
+		Statuses: []domain.Status{"review"},
+		Transitions: map[domain.Status][]domain.Status{
+			domain.StatusDoing: {"review", domain.StatusDone},
+		},
+	})
+	if err != nil {
+		t.Fatalf("workflow: %v", err)
+	}
+	custom := NewTaskRepository(db, workflow)
+	builtin := NewTaskRepository(db, domain.DefaultWorkflow())
+	ctx := context.Background()
+
+	id, err := custom.Create(ctx, domain.Task{Title: "write spec", Status: domain.StatusDoing})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if err := builtin.Transit(ctx, []int64{id}, "review"); !errors.Is(err, domain.ErrInvalidStatus) {
+		t.Fatalf("built-in transit to review err = %v", err)
+	}
+	if err := custom.Transit(ctx, []int64{id}, "review"); err != nil {
+		t.Fatalf("custom transit to review: %v", err)
+	}
+	var transitionErr domain.InvalidTransitionError
+	if err := custom.Transit(ctx, []int64{id}, domain.StatusWaiting); !errors.As(err, &transitionErr) {
+		t.Fatalf("review -> waiting err = %v, want invalid transition", err)
+	}
+	if n, err := builtin.Count(ctx, taskrepo.TaskListFilter{Statuses: []domain.Status{"review"}}); !errors.Is(err, domain.ErrInvalidStatus) {
+		t.Fatalf("built-in count of review = %d, %v", n, err)
+	}
+	if n, err := custom.Count(ctx, taskrepo.TaskListFilter{Statuses: []domain.Status{"review"}}); err != nil || n != 1 {
+		t.Fatalf("custom count of review = %d, %v", n, err)
+	}
+}
+
+func TestClosingThroughCustomStatusShouldCreateNextOccurrence(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+	if err := Migrate(db); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	workflow, err := domain.NewWorkflow(domain.WorkflowSpec{
+		Statuses: []domain.Status{"skipped"},
+		Closed:   []domain.Status{"skipped"},
+		Transitions: map[domain.Status][]domain.Status{
+			domain.StatusTodo: {domain.StatusDoing, "skipped", domain.StatusDone, domain.StatusDeleted},
+		},
+	})
+	if err != nil {
+		t.Fatalf("workflow: %v", err)
+	}
+	repo := NewTaskRepository(db, workflow)
+	ctx := context.Background()
+
+	dueAt := time.Date(time.Now().Year()+1, 3, 2, 9, 0, 0, 0, time.Local)
+	id, err := repo.Create(ctx, domain.Task{
+		Title:      "water plants",
+		Status:     domain.StatusTodo,
+		Project:    "home",
+		DueAt:      &dueAt,
+		Recurrence: domain.Recurrence{Freq: domain.FreqWeekly, Interval: 1},
+	})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if err := repo.Transit(ctx, []int64{id}, "skipped"); err != nil {
+		t.Fatalf("skip: %v", err)
+	}
+	open, err := repo.List(ctx, taskrepo.TaskListFilter{Statuses: []domain.Status{domain.StatusTodo}})
+	if err != nil || len(open) != 1 || open[0].Recurrence.IsZero() {
+		t.Fatalf("next occurrence = %+v, %v", open, err)
+	}
+	if want := dueAt.AddDate(0, 0, 7); open[0].DueAt == nil || !open[0].DueAt.Equal(want) {
+		t.Fatalf("next due = %v, want %v", open[0].DueAt, want)
+	}
+}
+
+func TestUnknownStatusesShouldListTasksOutsideTheWorkflow(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+	if err := Migrate(db); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	workflow, err := domain.NewWorkflow(domain.WorkflowSpec{
+		Statuses: []domain.Status{"review"},
+		Transitions: map[domain.Status][]domain.Status{
+			domain.StatusDoing: {"review", domain.StatusDone},
+		},
+	})
+	if err != nil {
+		t.Fatalf("workflow: %v", err)
+	}
+	custom := NewTaskRepository(db, workflow)
+	builtin := NewTaskRepository(db, domain.DefaultWorkflow())
+	ctx := context.Background()
+
+	first, _ := custom.Create(ctx, domain.Task{Title: "write spec", Status: "review"})
+	_, _ = custom.Create(ctx, domain.Task{Title: "plan", Status: domain.StatusTodo})
+	second, _ := custom.Create(ctx, domain.Task{Title: "ship", Status: "review"})
+
+	unknown, err := custom.UnknownStatuses(ctx)
+	if err != nil || len(unknown) != 0 {
+		t.Fatalf("custom unknown statuses = %v, %v", unknown, err)
+	}
+	unknown, err = builtin.UnknownStatuses(ctx)
+	if err != nil {
+		t.Fatalf("built-in unknown statuses: %v", err)
+	}
+	if len(unknown) != 1 || unknown[0].Status != "review" || len(unknown[0].IDs) != 2 || unknown[0].IDs[0] != first || unknown[0].IDs[1] != second {
+		t.Fatalf("built-in unknown statuses = %+v", unknown)
+	}
+	if err := builtin.Transit(ctx, []int64{first}, domain.StatusTodo); err != nil {
+		t.Fatalf("move out of unknown status: %v", err)
+	}
+	if unknown, _ := builtin.UnknownStatuses(ctx); len(unknown) != 1 || len(unknown[0].IDs) != 1 {
+		t.Fatalf("unknown statuses after move = %+v", unknown)
+	}
 }
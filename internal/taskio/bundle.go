@@ -0,0 +1,174 @@
+package taskio
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"td/internal/domain"
+)
+
+const (
+	BundleFormat  = "td-export"
+	BundleVersion = 2
+)
+
+// Bundle is the versioned envelope written by `td export --format json`.
+// Readers reject bundles with a newer Version. Version 2 added blocked_by.
+type Bundle struct {
+	Format     string   `json:"format"`
+	Version    int      `json:"version"`
+	ExportedAt string   `json:"exported_at"`
+	TDVersion  string   `json:"td_version"`
+	Projects   []string `json:"projects"`
+	Tags       []string `json:"tags"`
+	Tasks      []Task   `json:"tasks"`
+}
+
+func NewBundle(tasks []domain.Task, projects, tags []string, exportedAt time.Time, tdVersion string) Bundle {
+	items := make([]Task, 0, len(tasks))
+	for _, task := range tasks {
+		items = append(items, FromDomain(task))
+	}
+	if projects == nil {
+		projects = []string{}
+	}
+	if tags == nil {
+		tags = []string{}
+	}
+	return Bundle{
+		Format:     BundleFormat,
+		Version:    BundleVersion,
+		ExportedAt: FormatTime(exportedAt),
+		TDVersion:  tdVersion,
+		Projects:   projects,
+		Tags:       tags,
+		Tasks:      items,
+	}
+}
+
+func EncodeBundle(w io.Writer, bundle Bundle) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(bundle)
+}
+
+func DecodeBundle(r io.Reader) (Bundle, error) {
+	var bundle Bundle
+	if err := json.NewDecoder(r).Decode(&bundle); err != nil {
+		return Bundle{}, fmt.Errorf("decode bundle: %w", err)
+	}
+	if bundle.Format != BundleFormat {
+		return Bundle{}, fmt.Errorf("not a td export bundle (format %q)", bundle.Format)
+	}
+	if bundle.Version <= 0 {
+		return Bundle{}, errors.New("bundle version is missing")
+	}
+	if bundle.Version > BundleVersion {
+		return Bundle{}, fmt.Errorf("bundle version %d is newer than supported %d; upgrade td", bundle.Version, BundleVersion)
+	}
+	return bundle, nil
+}
+
+// DomainTasks converts the bundle tasks, reporting the first invalid one.
+// Statuses must belong to workflow.
+func (b Bundle) DomainTasks(workflow domain.Workflow) ([]domain.Task, error) {
+	out := make([]domain.Task, 0, len(b.Tasks))
+	for i, item := range b.Tasks {
+		task, err := item.ToDomain(workflow)
+		if err != nil {
+			return nil, fmt.Errorf("task %d (id %d): %w", i+1, item.ID, err)
+		}
+		out = append(out, task)
+	}
+	return out, nil
+}
+
+func (t Task) ToDomain(workflow domain.Workflow) (domain.Task, error) {
+	title := strings.TrimSpace(t.Title)
+	if title == "" {
+		return domain.Task{}, errors.New("title is empty")
+	}
+	status, err := workflow.ParseStatus(t.Status)
+	if err != nil {
+		return domain.Task{}, fmt.Errorf("status %q: %w", t.Status, err)
+	}
+	priority := domain.NormalizePriority(t.Priority)
+	if !domain.IsValidPriority(priority) {
+		return domain.Task{}, fmt.Errorf("priority %q: %w", t.Priority, domain.ErrInvalidPriority)
+	}
+	tags, err := domain.NormalizeTags(t.Tags)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	recurrence, err := domain.ParseRecurrence(t.Recurrence)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	task := domain.Task{
+		ID:          t.ID,
+		ParentID:    derefID(t.ParentID),
+		BlockedBy:   t.BlockedBy,
+		Title:       title,
+		Notes:       t.Notes,
+		Status:      status,
+		Project:     strings.TrimSpace(t.Project),
+		Priority:    priority,
+		Tags:        tags,
+		Recurrence:  recurrence,
+		SnoozeCount: t.Snoozes,
+		WaitingOn:   strings.TrimSpace(t.WaitingOn),
+	}
+	if task.DueAt, err = parseTimePtr("due_at", t.DueAt); err != nil {
+		return domain.Task{}, err
+	}
+	if task.StartAt, err = parseTimePtr("start_at", t.StartAt); err != nil {
+		return domain.Task{}, err
+	}
+	if task.FollowUpAt, err = parseTimePtr("followup_at", t.FollowUpAt); err != nil {
+		return domain.Task{}, err
+	}
+	if task.DoneAt, err = parseTimePtr("done_at", t.DoneAt); err != nil {
+		return domain.Task{}, err
+	}
+	if task.CreatedAt, err = parseOptionalTime("created_at", t.CreatedAt); err != nil {
+		return domain.Task{}, err
+	}
+	if task.UpdatedAt, err = parseOptionalTime("updated_at", t.UpdatedAt); err != nil {
+		return domain.Task{}, err
+	}
+	return task, nil
+}
+
+func parseTimePtr(field string, raw *string) (*time.Time, error) {
+	if raw == nil || strings.TrimSpace(*raw) == "" {
+		return nil, nil
+	}
+	t, err := parseOptionalTime(field, *raw)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func parseOptionalTime(field, raw string) (time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s %q is not RFC3339", field, raw)
+	}
+	return t.UTC(), nil
+}
+
+func derefID(id *int64) int64 {
+	if id == nil {
+		return 0
+	}
+	return *id
+}
@@ -0,0 +1,69 @@
+package taskio
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"td/internal/domain"
+)
+
+func TestBundleRoundTrip(t *testing.T) {
+	due := time.Date(2026, 2, 24, 8, 0, 0, 0, time.UTC)
+	created := time.Date(2026, 2, 23, 1, 2, 3, 0, time.UTC)
+	tasks := []domain.Task{{
+		ID:        3,
+		Title:     "write report",
+		Status:    domain.StatusDoing,
+		Project:   "work",
+		Priority:  "P1",
+		DueAt:     &due,
+		Tags:      []string{"urgent"},
+		BlockedBy: []int64{1, 2},
+		CreatedAt: created,
+		UpdatedAt: created,
+	}}
+
+	var buf bytes.Buffer
+	bundle := NewBundle(tasks, []string{"work", "empty"}, []string{"urgent"}, created, "v1.2.3")
+	if err := EncodeBundle(&buf, bundle); err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	decoded, err := DecodeBundle(&buf)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if decoded.Version != BundleVersion || decoded.TDVersion != "v1.2.3" || len(decoded.Projects) != 2 {
+		t.Fatalf("decoded envelope = %+v", decoded)
+	}
+	got, err := decoded.DomainTasks(domain.DefaultWorkflow())
+	if err != nil {
+		t.Fatalf("domain tasks: %v", err)
+	}
+	if len(got) != 1 || got[0].ID != 3 || got[0].Status != domain.StatusDoing || !got[0].DueAt.Equal(due) || !got[0].CreatedAt.Equal(created) {
+		t.Fatalf("round trip task = %+v", got)
+	}
+	if len(got[0].BlockedBy) != 2 || got[0].BlockedBy[0] != 1 || got[0].BlockedBy[1] != 2 {
+		t.Fatalf("round trip blockers = %v", got[0].BlockedBy)
+	}
+}
+
+func TestDecodeBundleShouldRejectUnknownEnvelopes(t *testing.T) {
+	cases := map[string]string{
+		`{"format":"other","version":1}`:     "not a td export bundle",
+		`{"format":"td-export","version":9}`: "newer than supported",
+		`{"format":"td-export"}`:             "version is missing",
+	}
+	for input, want := range cases {
+		_, err := DecodeBundle(strings.NewReader(input))
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("decode %s error = %v, want %q", input, err, want)
+		}
+	}
+
+	bundle := Bundle{Format: BundleFormat, Version: 1, Tasks: []Task{{ID: 1, Title: "x", Status: "later"}}}
+	if _, err := bundle.DomainTasks(domain.DefaultWorkflow()); err == nil || !strings.Contains(err.Error(), "task 1 (id 1)") {
+		t.Fatalf("invalid status error = %v", err)
+	}
+}
@@ -0,0 +1,185 @@
+package taskio
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"td/internal/domain"
+)
+
+const icsTimeLayout = "20060102T150405Z"
+
+type ICSOptions struct {
+	// Events emits a VEVENT at each due time instead of VTODOs; tasks
+	// without a due time are left out.
+	Events        bool
+	EventDuration time.Duration
+	TDVersion     string
+	// Workflow tells which statuses count as completed.
+	Workflow domain.Workflow
+}
+
+// EncodeICS writes an RFC 5545 calendar. UIDs derive from task IDs and
+// DTSTAMP from UpdatedAt, so unchanged tasks encode identically.
+func EncodeICS(w io.Writer, tasks []domain.Task, opts ICSOptions) error {
+	duration := opts.EventDuration
+	if duration <= 0 {
+		duration = 30 * time.Minute
+	}
+	version := opts.TDVersion
+	if version == "" {
+		version = "dev"
+	}
+
+	bw := bufio.NewWriter(w)
+	write := func(name, value string) {
+		writeICSLine(bw, name+":"+value)
+	}
+	write("BEGIN", "VCALENDAR")
+	write("VERSION", "2.0")
+	write("PRODID", "-//td//td "+escapeICSText(version)+"//EN")
+	write("CALSCALE", "GREGORIAN")
+	write("X-WR-CALNAME", "td")
+	for _, task := range tasks {
+		if opts.Events && task.DueAt == nil {
+			continue
+		}
+		component := "VTODO"
+		if opts.Events {
+			component = "VEVENT"
+		}
+		write("BEGIN", component)
+		write("UID", fmt.Sprintf("task-%d@td", task.ID))
+		write("DTSTAMP", formatICSTime(stampTime(task)))
+		if !task.CreatedAt.IsZero() {
+			write("CREATED", formatICSTime(task.CreatedAt))
+		}
+		if !task.UpdatedAt.IsZero() {
+			write("LAST-MODIFIED", formatICSTime(task.UpdatedAt))
+		}
+		write("SUMMARY", escapeICSText(task.Title))
+		if task.Notes != "" {
+			write("DESCRIPTION", escapeICSText(task.Notes))
+		}
+		if categories := icsCategories(task); categories != "" {
+			write("CATEGORIES", categories)
+		}
+		write("PRIORITY", icsPriority(task.Priority))
+		if opts.Events {
+			write("DTSTART", formatICSTime(*task.DueAt))
+			write("DTEND", formatICSTime(task.DueAt.Add(duration)))
+			write("STATUS", icsEventStatus(task.Status))
+			write("TRANSP", "TRANSPARENT")
+		} else {
+			if task.StartAt != nil {
+				write("DTSTART", formatICSTime(*task.StartAt))
+			}
+			if task.DueAt != nil {
+				write("DUE", formatICSTime(*task.DueAt))
+			}
+			write("STATUS", icsTodoStatus(task.Status, opts.Workflow))
+			if opts.Workflow.IsClosed(task.Status) && task.DoneAt != nil {
+				write("COMPLETED", formatICSTime(*task.DoneAt))
+				write("PERCENT-COMPLETE", "100")
+			}
+		}
+		write("END", component)
+	}
+	write("END", "VCALENDAR")
+	return bw.Flush()
+}
+
+func stampTime(task domain.Task) time.Time {
+	if !task.UpdatedAt.IsZero() {
+		return task.UpdatedAt
+	}
+	return task.CreatedAt
+}
+
+func formatICSTime(t time.Time) string {
+	return t.UTC().Format(icsTimeLayout)
+}
+
+// icsPriority maps P1-P4 onto the RFC 5545 high (1), medium (5) and low
+// (7, 9) bands.
+func icsPriority(priority string) string {
+	switch domain.NormalizePriority(priority) {
+	case "P1":
+		return "1"
+	case "P3":
+		return "7"
+	case "P4":
+		return "9"
+	default:
+		return "5"
+	}
+}
+
+func icsTodoStatus(status domain.Status, workflow domain.Workflow) string {
+	switch status {
+	case domain.StatusDoing, domain.StatusWaiting:
+		return "IN-PROCESS"
+	case domain.StatusDeleted:
+		return "CANCELLED"
+	}
+	if workflow.IsClosed(status) {
+		return "COMPLETED"
+	}
+	return "NEEDS-ACTION"
+}
+
+func icsEventStatus(status domain.Status) string {
+	if status == domain.StatusDeleted {
+		return "CANCELLED"
+	}
+	return "CONFIRMED"
+}
+
+func icsCategories(task domain.Task) string {
+	items := make([]string, 0, len(task.Tags)+1)
+	if project := strings.TrimSpace(task.Project); project != "" {
+		items = append(items, escapeICSText(project))
+	}
+	for _, tag := range task.Tags {
+		items = append(items, escapeICSText(tag))
+	}
+	return strings.Join(items, ",")
+}
+
+func escapeICSText(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	replacer := strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\n", `\n`,
+		"\r", `\n`,
+	)
+	return replacer.Replace(text)
+}
+
+// writeICSLine folds content lines at 75 octets without splitting UTF-8
+// sequences; continuation lines start with a space, which counts toward
+// their length. Every line ends with CRLF.
+func writeICSLine(w *bufio.Writer, line string) {
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !isUTF8Start(line[cut]) {
+			cut--
+		}
+		w.WriteString(line[:cut])
+		w.WriteString("\r\n ")
+		line = line[cut:]
+		limit = 74
+	}
+	w.WriteString(line)
+	w.WriteString("\r\n")
+}
+
+func isUTF8Start(b byte) bool {
+	return b&0xC0 != 0x80
+}
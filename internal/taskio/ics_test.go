@@ -0,0 +1,95 @@
+package taskio
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"td/internal/domain"
+)
+
+func TestEncodeICSShouldWriteVTODOs(t *testing.T) {
+	due := time.Date(2026, 2, 25, 10, 0, 0, 0, time.UTC)
+	doneAt := time.Date(2026, 2, 22, 8, 30, 0, 0, time.UTC)
+	updated := time.Date(2026, 2, 23, 1, 0, 0, 0, time.UTC)
+	tasks := []domain.Task{
+		{ID: 7, Title: "write report, draft; v2", Status: domain.StatusDoing, Project: "work", Priority: "P1", Tags: []string{"urgent"}, DueAt: &due, Notes: "line1\nline2", UpdatedAt: updated},
+		{ID: 8, Title: "file taxes", Status: domain.StatusDone, Priority: "P4", DoneAt: &doneAt, UpdatedAt: updated},
+	}
+
+	var buf bytes.Buffer
+	if err := EncodeICS(&buf, tasks, ICSOptions{TDVersion: "v1"}); err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:-//td//td v1//EN\r\n",
+		"BEGIN:VTODO\r\nUID:task-7@td\r\nDTSTAMP:20260223T010000Z\r\n",
+		"SUMMARY:write report\\, draft\\; v2\r\n",
+		"DESCRIPTION:line1\\nline2\r\n",
+		"CATEGORIES:work,urgent\r\n",
+		"PRIORITY:1\r\n",
+		"DUE:20260225T100000Z\r\nSTATUS:IN-PROCESS\r\n",
+		"UID:task-8@td\r\n",
+		"PRIORITY:9\r\n",
+		"STATUS:COMPLETED\r\nCOMPLETED:20260222T083000Z\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("ics missing %q:\n%s", want, out)
+		}
+	}
+
+	var again bytes.Buffer
+	_ = EncodeICS(&again, tasks, ICSOptions{TDVersion: "v1"})
+	if again.String() != out {
+		t.Fatalf("ics output should be stable")
+	}
+}
+
+func TestEncodeICSEventsShouldUseDueTimes(t *testing.T) {
+	due := time.Date(2026, 2, 25, 10, 0, 0, 0, time.UTC)
+	tasks := []domain.Task{
+		{ID: 1, Title: "standup", Status: domain.StatusTodo, DueAt: &due},
+		{ID: 2, Title: "someday", Status: domain.StatusTodo},
+	}
+	var buf bytes.Buffer
+	if err := EncodeICS(&buf, tasks, ICSOptions{Events: true, EventDuration: time.Hour}); err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	out := buf.String()
+	if strings.Contains(out, "VTODO") || strings.Contains(out, "someday") {
+		t.Fatalf("events output should only contain VEVENTs with due times:\n%s", out)
+	}
+	if !strings.Contains(out, "DTSTART:20260225T100000Z\r\nDTEND:20260225T110000Z\r\n") {
+		t.Fatalf("event times missing:\n%s", out)
+	}
+}
+
+func TestWriteICSLineShouldFoldLongLines(t *testing.T) {
+	tasks := []domain.Task{{ID: 1, Title: strings.Repeat("写周报", 30), Status: domain.StatusTodo}}
+	var buf bytes.Buffer
+	if err := EncodeICS(&buf, tasks, ICSOptions{}); err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	var summary strings.Builder
+	inSummary := false
+	for _, line := range strings.Split(buf.String(), "\r\n") {
+		if len(line) > 75 {
+			t.Fatalf("line longer than 75 octets: %q", line)
+		}
+		switch {
+		case strings.HasPrefix(line, "SUMMARY:"):
+			inSummary = true
+			summary.WriteString(strings.TrimPrefix(line, "SUMMARY:"))
+		case inSummary && strings.HasPrefix(line, " "):
+			summary.WriteString(line[1:])
+		default:
+			inSummary = false
+		}
+	}
+	if summary.String() != tasks[0].Title {
+		t.Fatalf("unfolded summary = %q", summary.String())
+	}
+}
@@ -0,0 +1,155 @@
+package taskio
+
+import (
+	"time"
+
+	"td/internal/domain"
+)
+
+// Task is the stable JSON representation of domain.Task shared by
+// `--output json` and export. Timestamps are RFC3339 in UTC, optional ones
+// are null, and tags and blocked_by, the open blockers, are always arrays.
+type Task struct {
+	ID         int64    `json:"id"`
+	ParentID   *int64   `json:"parent_id"`
+	BlockedBy  []int64  `json:"blocked_by"`
+	Title      string   `json:"title"`
+	Status     string   `json:"status"`
+	Project    string   `json:"project"`
+	Priority   string   `json:"priority"`
+	Tags       []string `json:"tags"`
+	DueAt      *string  `json:"due_at"`
+	StartAt    *string  `json:"start_at"`
+	DoneAt     *string  `json:"done_at"`
+	Recurrence string   `json:"recurrence"`
+	Snoozes    int      `json:"snooze_count"`
+	WaitingOn  string   `json:"waiting_on"`
+	FollowUpAt *string  `json:"followup_at"`
+	Notes      string   `json:"notes"`
+	CreatedAt  string   `json:"created_at"`
+	UpdatedAt  string   `json:"updated_at"`
+}
+
+// TaskFields lists the JSON field names of Task in output order.
+var TaskFields = []string{
+	"id",
+	"parent_id",
+	"blocked_by",
+	"title",
+	"status",
+	"project",
+	"priority",
+	"tags",
+	"due_at",
+	"start_at",
+	"done_at",
+	"recurrence",
+	"snooze_count",
+	"waiting_on",
+	"followup_at",
+	"notes",
+	"created_at",
+	"updated_at",
+}
+
+func FromDomain(task domain.Task) Task {
+	tags := task.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	blockedBy := task.BlockedBy
+	if blockedBy == nil {
+		blockedBy = []int64{}
+	}
+	var parentID *int64
+	if task.ParentID != 0 {
+		id := task.ParentID
+		parentID = &id
+	}
+	return Task{
+		ID:         task.ID,
+		ParentID:   parentID,
+		BlockedBy:  blockedBy,
+		Title:      task.Title,
+		Status:     string(task.Status),
+		Project:    task.Project,
+		Priority:   domain.NormalizePriority(task.Priority),
+		Tags:       tags,
+		DueAt:      formatTimePtr(task.DueAt),
+		StartAt:    formatTimePtr(task.StartAt),
+		DoneAt:     formatTimePtr(task.DoneAt),
+		Recurrence: task.Recurrence.String(),
+		Snoozes:    task.SnoozeCount,
+		WaitingOn:  task.WaitingOn,
+		FollowUpAt: formatTimePtr(task.FollowUpAt),
+		Notes:      task.Notes,
+		CreatedAt:  FormatTime(task.CreatedAt),
+		UpdatedAt:  FormatTime(task.UpdatedAt),
+	}
+}
+
+// Field returns the JSON value of a field named in TaskFields.
+func (t Task) Field(name string) (any, bool) {
+	switch name {
+	case "id":
+		return t.ID, true
+	case "parent_id":
+		if t.ParentID == nil {
+			return nil, true
+		}
+		return *t.ParentID, true
+	case "blocked_by":
+		return t.BlockedBy, true
+	case "title":
+		return t.Title, true
+	case "status":
+		return t.Status, true
+	case "project":
+		return t.Project, true
+	case "priority":
+		return t.Priority, true
+	case "tags":
+		return t.Tags, true
+	case "due_at":
+		return derefTime(t.DueAt), true
+	case "start_at":
+		return derefTime(t.StartAt), true
+	case "done_at":
+		return derefTime(t.DoneAt), true
+	case "recurrence":
+		return t.Recurrence, true
+	case "snooze_count":
+		return t.Snoozes, true
+	case "waiting_on":
+		return t.WaitingOn, true
+	case "followup_at":
+		return derefTime(t.FollowUpAt), true
+	case "notes":
+		return t.Notes, true
+	case "created_at":
+		return t.CreatedAt, true
+	case "updated_at":
+		return t.UpdatedAt, true
+	default:
+		return nil, false
+	}
+}
+
+func FormatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+func formatTimePtr(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	value := FormatTime(*t)
+	return &value
+}
+
+func derefTime(value *string) any {
+	if value == nil {
+		return nil
+	}
+	return *value
+}
@@ -0,0 +1,47 @@
+package taskio
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"td/internal/domain"
+)
+
+func TestFromDomainShouldUseStableSchema(t *testing.T) {
+	loc := time.FixedZone("CST", 8*3600)
+	due := time.Date(2026, 2, 24, 8, 0, 0, 0, loc)
+	created := time.Date(2026, 2, 23, 1, 2, 3, 456, time.UTC)
+	data, err := json.Marshal(FromDomain(domain.Task{
+		ID:         7,
+		Title:      "write report",
+		Status:     domain.StatusTodo,
+		Project:    "work",
+		DueAt:      &due,
+		Recurrence: domain.Recurrence{Freq: domain.FreqWeekly, Interval: 2, AfterCompletion: true},
+		CreatedAt:  created,
+		UpdatedAt:  created,
+	}))
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	want := `{"id":7,"parent_id":null,"blocked_by":[],"title":"write report","status":"todo","project":"work","priority":"P2","tags":[],` +
+		`"due_at":"2026-02-24T00:00:00Z","start_at":null,"done_at":null,"recurrence":"FREQ=WEEKLY;INTERVAL=2;X-TD-FROM=DONE","snooze_count":0,"waiting_on":"","followup_at":null,"notes":"",` +
+		`"created_at":"2026-02-23T01:02:03Z","updated_at":"2026-02-23T01:02:03Z"}`
+	if string(data) != want {
+		t.Fatalf("json = %s\nwant %s", data, want)
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if len(fields) != len(TaskFields) {
+		t.Fatalf("json has %d fields, TaskFields lists %d", len(fields), len(TaskFields))
+	}
+	for _, name := range TaskFields {
+		if _, ok := fields[name]; !ok {
+			t.Fatalf("TaskFields entry %q missing from json", name)
+		}
+	}
+}
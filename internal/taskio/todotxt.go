@@ -0,0 +1,229 @@
+package taskio
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	"td/internal/domain"
+)
+
+const todoTxtDate = "2006-01-02"
+
+var todoTxtPriorityRegexp = regexp.MustCompile(`^\(([A-Z])\)$`)
+
+// EncodeTodoTxt writes one todo.txt line per task, skipping deleted tasks.
+// Dates are calendar days in loc; tasks in a closed status of workflow are
+// completed.
+func EncodeTodoTxt(w io.Writer, tasks []domain.Task, loc *time.Location, workflow domain.Workflow) error {
+	bw := bufio.NewWriter(w)
+	for _, task := range tasks {
+		if task.Status == domain.StatusDeleted {
+			continue
+		}
+		if _, err := bw.WriteString(FormatTodoTxtLine(task, loc, workflow) + "\n"); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// DecodeTodoTxt reads todo.txt lines, ignoring blank ones.
+func DecodeTodoTxt(r io.Reader, loc *time.Location) ([]domain.Task, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	out := make([]domain.Task, 0, 16)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		task, err := ParseTodoTxtLine(line, loc)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		out = append(out, task)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// FormatTodoTxtLine maps P1-P4 to (A)-(D), the project to +project, tags
+// to @context and due/done to their dates. Completed tasks keep their
+// priority as pri:X since todo.txt drops (X) on completion.
+func FormatTodoTxtLine(task domain.Task, loc *time.Location, workflow domain.Workflow) string {
+	if loc == nil {
+		loc = time.Local
+	}
+	parts := make([]string, 0, 8)
+	letter := string(rune('A' + domain.PriorityRank(task.Priority)))
+	done := workflow.IsClosed(task.Status)
+	if done {
+		parts = append(parts, "x")
+		if task.DoneAt != nil {
+			parts = append(parts, task.DoneAt.In(loc).Format(todoTxtDate))
+		}
+	} else {
+		parts = append(parts, "("+letter+")")
+	}
+	if !task.CreatedAt.IsZero() && (!done || task.DoneAt != nil) {
+		parts = append(parts, task.CreatedAt.In(loc).Format(todoTxtDate))
+	}
+	parts = append(parts, strings.Join(strings.Fields(task.Title), " "))
+	if project := strings.TrimSpace(task.Project); project != "" {
+		parts = append(parts, "+"+encodeTodoTxtProject(project))
+	}
+	for _, tag := range task.Tags {
+		parts = append(parts, "@"+tag)
+	}
+	if task.DueAt != nil {
+		parts = append(parts, "due:"+task.DueAt.In(loc).Format(todoTxtDate))
+	}
+	if task.StartAt != nil {
+		parts = append(parts, "t:"+task.StartAt.In(loc).Format(todoTxtDate))
+	}
+	if done {
+		parts = append(parts, "pri:"+letter)
+	}
+	return strings.Join(parts, " ")
+}
+
+// ParseTodoTxtLine is the inverse of FormatTodoTxtLine. Priorities after
+// (D) map to P4, a date-only due becomes 23:59 that day like `td due`, the
+// t: threshold date is the start of that day, and tasks without a project
+// land in the inbox.
+func ParseTodoTxtLine(line string, loc *time.Location) (domain.Task, error) {
+	if loc == nil {
+		loc = time.Local
+	}
+	fields := strings.Fields(line)
+	task := domain.Task{Priority: domain.DefaultPriority}
+	i := 0
+	if len(fields) > 0 && fields[0] == "x" {
+		task.Status = domain.StatusDone
+		i++
+		if d, ok := parseTodoTxtDate(fields, i, loc); ok {
+			task.DoneAt = &d
+			i++
+			if created, ok := parseTodoTxtDate(fields, i, loc); ok {
+				task.CreatedAt = created
+				i++
+			}
+		}
+	} else {
+		if i < len(fields) {
+			if matched := todoTxtPriorityRegexp.FindStringSubmatch(fields[i]); len(matched) == 2 {
+				task.Priority = todoTxtPriority(matched[1])
+				i++
+			}
+		}
+		if created, ok := parseTodoTxtDate(fields, i, loc); ok {
+			task.CreatedAt = created
+			i++
+		}
+	}
+
+	title := make([]string, 0, len(fields)-i)
+	tags := make([]string, 0, 2)
+	for _, field := range fields[i:] {
+		switch {
+		case len(field) > 1 && field[0] == '+' && task.Project == "":
+			task.Project = decodeTodoTxtProject(field[1:])
+		case len(field) > 1 && field[0] == '@':
+			tags = append(tags, field[1:])
+		case strings.HasPrefix(field, "due:"):
+			day, err := time.ParseInLocation(todoTxtDate, strings.TrimPrefix(field, "due:"), loc)
+			if err != nil {
+				return domain.Task{}, fmt.Errorf("invalid %s, expect due:YYYY-MM-DD", field)
+			}
+			due := time.Date(day.Year(), day.Month(), day.Day(), 23, 59, 0, 0, loc).UTC()
+			task.DueAt = &due
+		case strings.HasPrefix(field, "t:"):
+			day, err := time.ParseInLocation(todoTxtDate, strings.TrimPrefix(field, "t:"), loc)
+			if err != nil {
+				return domain.Task{}, fmt.Errorf("invalid %s, expect t:YYYY-MM-DD", field)
+			}
+			start := day.UTC()
+			task.StartAt = &start
+		case strings.HasPrefix(field, "pri:") && len(field) == 5 && field[4] >= 'A' && field[4] <= 'Z':
+			task.Priority = todoTxtPriority(field[4:])
+		default:
+			title = append(title, field)
+		}
+	}
+	task.Title = strings.Join(title, " ")
+	if task.Title == "" {
+		return domain.Task{}, fmt.Errorf("empty title in %q", line)
+	}
+	normalized, err := domain.NormalizeTags(tags)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	task.Tags = normalized
+	if task.Status == "" {
+		task.Status = domain.StatusInbox
+		if task.Project != "" {
+			task.Status = domain.StatusTodo
+		}
+	}
+	return task, nil
+}
+
+// encodeTodoTxtProject keeps a project in one todo.txt word by escaping
+// whitespace and % as in URLs: "Home Office" becomes Home%20Office.
+func encodeTodoTxtProject(project string) string {
+	var b strings.Builder
+	for _, r := range project {
+		if r != '%' && !unicode.IsSpace(r) {
+			b.WriteRune(r)
+			continue
+		}
+		for _, c := range []byte(string(r)) {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// decodeTodoTxtProject undoes encodeTodoTxtProject. Words that are not
+// valid escapes, as other tools may write, are kept as they are.
+func decodeTodoTxtProject(raw string) string {
+	project, err := url.PathUnescape(raw)
+	if err != nil {
+		return raw
+	}
+	return project
+}
+
+func parseTodoTxtDate(fields []string, i int, loc *time.Location) (time.Time, bool) {
+	if i >= len(fields) {
+		return time.Time{}, false
+	}
+	t, err := time.ParseInLocation(todoTxtDate, fields[i], loc)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t.UTC(), true
+}
+
+func todoTxtPriority(letter string) string {
+	switch letter {
+	case "A":
+		return "P1"
+	case "B":
+		return "P2"
+	case "C":
+		return "P3"
+	default:
+		return "P4"
+	}
+}
@@ -0,0 +1,115 @@
+package taskio
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"td/internal/domain"
+)
+
+func TestParseTodoTxtLine(t *testing.T) {
+	loc := time.FixedZone("CST", 8*3600)
+	task, err := ParseTodoTxtLine("(A) 2026-02-20 Call mom +Family @phone @Home due:2026-02-25 url:x", loc)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if task.Title != "Call mom url:x" || task.Project != "Family" || task.Priority != "P1" || task.Status != domain.StatusTodo {
+		t.Fatalf("task = %+v", task)
+	}
+	if strings.Join(task.Tags, ",") != "home,phone" {
+		t.Fatalf("tags = %v", task.Tags)
+	}
+	wantDue := time.Date(2026, 2, 25, 23, 59, 0, 0, loc)
+	if task.DueAt == nil || !task.DueAt.Equal(wantDue) {
+		t.Fatalf("due = %v, want %v", task.DueAt, wantDue)
+	}
+	if !task.CreatedAt.Equal(time.Date(2026, 2, 20, 0, 0, 0, 0, loc)) {
+		t.Fatalf("created = %v", task.CreatedAt)
+	}
+
+	done, err := ParseTodoTxtLine("x 2026-02-22 2026-02-20 File taxes pri:C", loc)
+	if err != nil {
+		t.Fatalf("parse done: %v", err)
+	}
+	if done.Status != domain.StatusDone || done.DoneAt == nil || done.Priority != "P3" || done.Title != "File taxes" {
+		t.Fatalf("done task = %+v", done)
+	}
+
+	inbox, err := ParseTodoTxtLine("(F) someday", loc)
+	if err != nil {
+		t.Fatalf("parse inbox: %v", err)
+	}
+	if inbox.Status != domain.StatusInbox || inbox.Priority != "P4" {
+		t.Fatalf("inbox task = %+v", inbox)
+	}
+
+	for line, want := range map[string]string{
+		"plan desks +Home_Office":  "Home_Office",
+		"sale +50%off":             "50%off",
+		"paint walls +Home%20Room": "Home Room",
+	} {
+		if task, err := ParseTodoTxtLine(line, loc); err != nil || task.Project != want {
+			t.Fatalf("project of %q = %q, %v, want %q", line, task.Project, err, want)
+		}
+	}
+
+	if _, err := ParseTodoTxtLine("(A) +work due:friday", loc); err == nil {
+		t.Fatalf("invalid due should fail")
+	}
+}
+
+func TestTodoTxtRoundTripShouldKeepSharedFields(t *testing.T) {
+	loc := time.FixedZone("CST", 8*3600)
+	due := time.Date(2026, 2, 25, 23, 59, 0, 0, loc).UTC()
+	start := time.Date(2026, 2, 23, 0, 0, 0, 0, loc).UTC()
+	doneAt := time.Date(2026, 2, 22, 0, 0, 0, 0, loc).UTC()
+	created := time.Date(2026, 2, 20, 0, 0, 0, 0, loc).UTC()
+	tasks := []domain.Task{
+		{Title: "write report", Status: domain.StatusTodo, Project: "work", Priority: "P1", Tags: []string{"office", "urgent"}, DueAt: &due, StartAt: &start, CreatedAt: created},
+		{Title: "file taxes", Status: domain.StatusDone, Project: "home", Priority: "P3", DoneAt: &doneAt, CreatedAt: created},
+		{Title: "idea", Status: domain.StatusInbox, Priority: "P4"},
+		{Title: "paint walls", Status: domain.StatusTodo, Project: "Home Office 50%", Priority: "P2"},
+		{Title: "gone", Status: domain.StatusDeleted, Priority: "P2"},
+	}
+
+	var buf bytes.Buffer
+	if err := EncodeTodoTxt(&buf, tasks, loc, domain.DefaultWorkflow()); err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	want := "(A) 2026-02-20 write report +work @office @urgent due:2026-02-25 t:2026-02-23\n" +
+		"x 2026-02-22 2026-02-20 file taxes +home pri:C\n" +
+		"(D) idea\n" +
+		"(B) paint walls +Home%20Office%2050%25\n"
+	if buf.String() != want {
+		t.Fatalf("encoded =\n%s\nwant\n%s", buf.String(), want)
+	}
+
+	decoded, err := DecodeTodoTxt(&buf, loc)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(decoded) != 4 {
+		t.Fatalf("decoded %d tasks, want 4", len(decoded))
+	}
+	for i, got := range decoded {
+		src := tasks[i]
+		if got.Title != src.Title || got.Status != src.Status || got.Project != src.Project || got.Priority != src.Priority {
+			t.Fatalf("task %d = %+v, want %+v", i, got, src)
+		}
+		if strings.Join(got.Tags, ",") != strings.Join(src.Tags, ",") {
+			t.Fatalf("task %d tags = %v, want %v", i, got.Tags, src.Tags)
+		}
+		if !sameTime(got.DueAt, src.DueAt) || !sameTime(got.StartAt, src.StartAt) || !sameTime(got.DoneAt, src.DoneAt) || !got.CreatedAt.Equal(src.CreatedAt) {
+			t.Fatalf("task %d times = %+v, want %+v", i, got, src)
+		}
+	}
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Equal(*b)
+}
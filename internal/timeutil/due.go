@@ -0,0 +1,637 @@
+package timeutil
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ClockFunc adapts a plain function to Clock.
+type ClockFunc func() time.Time
+
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+// A due day given without a time ends at 23:59.
+const (
+	endOfDayHour   = 23
+	endOfDayMinute = 59
+)
+
+// ParseDue resolves a due date typed by a person, relative to clk and in
+// loc, and returns it in UTC. Besides YYYY-MM-DD [HH:MM], YYYYMMDDHHMM and
+// RFC3339 it understands, without any network call:
+//
+//	today 18:00, tomorrow, in 3 days, next monday, fri 9am, eod, eow
+//	明天下午3点, 下周一, 后天, 周五上午10点半, 3天后
+//
+// A bare weekday is its next occurrence, today included; "next monday" and
+// 下周一 are the Monday of next week, weeks starting on Monday. A time
+// without a day is today, or tomorrow once it has passed.
+func ParseDue(raw string, clk Clock, loc *time.Location) (time.Time, error) {
+	return parseWhen("due", raw, clk, loc, endOfDay)
+}
+
+// ParseStart reads a start or defer time like ParseDue, except that a day
+// given without a time begins at 00:00 instead of ending at 23:59.
+func ParseStart(raw string, clk Clock, loc *time.Location) (time.Time, error) {
+	return parseWhen("start", raw, clk, loc, startOfDay)
+}
+
+// parseWhen resolves raw, placing a day without a time with bareDay; kind
+// names the value in errors.
+func parseWhen(kind, raw string, clk Clock, loc *time.Location, bareDay func(time.Time) time.Time) (time.Time, error) {
+	text := strings.TrimSpace(raw)
+	if text == "" {
+		return time.Time{}, fmt.Errorf("%s datetime is empty", kind)
+	}
+	if clk == nil {
+		clk = SystemClock{}
+	}
+	if loc == nil {
+		loc = time.Local
+	}
+	if t, ok := parseAbsoluteDue(text, loc, bareDay); ok {
+		return t.UTC(), nil
+	}
+	now := clk.Now().In(loc)
+	if parts, ok := parseNaturalDue(text, now); ok {
+		if t, ok := parts.resolve(now, bareDay); ok {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid %s datetime %q, expect YYYY-MM-DD [HH:MM] or a phrase like tomorrow 9am, next monday, in 3 days, 明天下午3点", kind, raw)
+}
+
+// FindDue looks for a due phrase inside free text such as a clipboard line
+// and returns the time with the phrase as written. Only explicit phrases
+// count: a lone day or period word such as today, evening, fri or 明天, or
+// a bare date, is too likely to be part of the sentence unless a time, an
+// offset or a marker like "by" comes with it.
+func FindDue(text string, clk Clock, loc *time.Location) (time.Time, string, bool) {
+	if clk == nil {
+		clk = SystemClock{}
+	}
+	if loc == nil {
+		loc = time.Local
+	}
+	now := clk.Now().In(loc)
+	fields := strings.Fields(text)
+	for start := range fields {
+		for end := min(len(fields), start+4); end > start; end-- {
+			phrase := strings.Join(fields[start:end], " ")
+			if t, ok := parseAbsoluteDue(phrase, loc, endOfDay); ok && !isBareDate(phrase, loc) {
+				return t.UTC(), phrase, true
+			}
+			if parts, ok := parseNaturalDue(phrase, now); ok && parts.explicit {
+				if t, ok := parts.resolve(now, endOfDay); ok {
+					return t.UTC(), phrase, true
+				}
+			}
+		}
+		if t, phrase, ok := findChineseDue(fields[start], now); ok {
+			return t.UTC(), phrase, true
+		}
+	}
+	return time.Time{}, "", false
+}
+
+func isBareDate(text string, loc *time.Location) bool {
+	_, err := time.ParseInLocation("2006-01-02", text, loc)
+	return err == nil
+}
+
+// findChineseDue scans a field for the longest Chinese phrase that starts
+// earliest. Phrases need a day, period or digit so that words such as 一点
+// in 快一点 are not read as a time.
+func findChineseDue(field string, now time.Time) (time.Time, string, bool) {
+	runes := []rune(field)
+	for start := range runes {
+		if !unicode.Is(unicode.Han, runes[start]) && !unicode.IsDigit(runes[start]) {
+			continue
+		}
+		for end := min(len(runes), start+16); end > start+1; end-- {
+			phrase := string(runes[start:end])
+			if !containsHan(phrase) || !strings.ContainsAny(phrase, "0123456789天日晚早周星期礼拜午后") {
+				continue
+			}
+			if parts, ok := parseChineseDue(phrase, now); ok && parts.explicit {
+				if t, ok := parts.resolve(now, endOfDay); ok {
+					return t, phrase, true
+				}
+			}
+		}
+	}
+	return time.Time{}, "", false
+}
+
+func parseAbsoluteDue(text string, loc *time.Location, bareDay func(time.Time) time.Time) (time.Time, bool) {
+	if t, err := time.Parse(time.RFC3339, text); err == nil {
+		return t, true
+	}
+	for _, layout := range []string{"2006-01-02 15:04", "2006-01-02T15:04", "200601021504"} {
+		if t, err := time.ParseInLocation(layout, text, loc); err == nil {
+			return t, true
+		}
+	}
+	if t, err := time.ParseInLocation("2006-01-02", text, loc); err == nil {
+		return bareDay(t), true
+	}
+	return time.Time{}, false
+}
+
+func parseNaturalDue(text string, now time.Time) (dueParts, bool) {
+	text = strings.ToLower(strings.TrimSpace(text))
+	if containsHan(text) {
+		return parseChineseDue(strings.Join(strings.Fields(text), ""), now)
+	}
+	return parseEnglishDue(strings.Fields(text), now)
+}
+
+// dueParts collects what a phrase said before the parts are combined.
+type dueParts struct {
+	day     *time.Time
+	hour    int
+	minute  int
+	hasTime bool
+	// nextDay puts the time after midnight of day, as in 今晚12点.
+	nextDay bool
+	exact   *time.Time
+	// explicit marks a clock time, an offset, a next week day or a marker
+	// such as "by", which FindDue needs before it trusts a phrase.
+	explicit bool
+}
+
+func (p *dueParts) setDay(day time.Time) bool {
+	if p.day != nil {
+		return false
+	}
+	p.day = &day
+	return true
+}
+
+func (p *dueParts) setTime(hour, minute int) bool {
+	if p.hasTime || hour < 0 || hour > 23 || minute < 0 || minute > 59 {
+		return false
+	}
+	p.hour, p.minute, p.hasTime = hour, minute, true
+	return true
+}
+
+func (p dueParts) resolve(now time.Time, bareDay func(time.Time) time.Time) (time.Time, bool) {
+	if p.exact != nil {
+		if p.day != nil || p.hasTime {
+			return time.Time{}, false
+		}
+		return *p.exact, true
+	}
+	switch {
+	case p.day != nil && p.hasTime:
+		day := *p.day
+		if p.nextDay {
+			day = day.AddDate(0, 0, 1)
+		}
+		return atTime(day, p.hour, p.minute), true
+	case p.day != nil:
+		return bareDay(*p.day), true
+	case p.hasTime:
+		t := atTime(startOfDay(now), p.hour, p.minute)
+		if t.Before(now) {
+			t = t.AddDate(0, 0, 1)
+		}
+		return t, true
+	}
+	return time.Time{}, false
+}
+
+var englishWeekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "sun": time.Sunday,
+	"monday": time.Monday, "mon": time.Monday,
+	"tuesday": time.Tuesday, "tue": time.Tuesday, "tues": time.Tuesday,
+	"wednesday": time.Wednesday, "wed": time.Wednesday,
+	"thursday": time.Thursday, "thu": time.Thursday, "thurs": time.Thursday,
+	"friday": time.Friday, "fri": time.Friday,
+	"saturday": time.Saturday, "sat": time.Saturday,
+}
+
+var englishPeriods = map[string]int{
+	"morning":   9,
+	"noon":      12,
+	"afternoon": 14,
+	"evening":   18,
+	"tonight":   20,
+}
+
+var (
+	clockRegexp    = regexp.MustCompile(`^(\d{1,2}):(\d{2})$`)
+	meridiemRegexp = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?(am|pm)$`)
+	offsetRegexp   = regexp.MustCompile(`^(\d+)([a-z]+)$`)
+)
+
+func parseEnglishDue(tokens []string, now time.Time) (dueParts, bool) {
+	if len(tokens) == 0 {
+		return dueParts{}, false
+	}
+	today := startOfDay(now)
+	var parts dueParts
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+		ok := true
+		switch token {
+		case "at", "on":
+			continue
+		case "by", "due":
+			parts.explicit = true
+			continue
+		case "today":
+			ok = parts.setDay(today)
+		case "eod":
+			parts.explicit = true
+			ok = parts.setDay(today)
+		case "tomorrow", "tmr", "tmrw":
+			ok = parts.setDay(today.AddDate(0, 0, 1))
+		case "eow":
+			parts.explicit = true
+			ok = parts.setDay(weekStart(today).AddDate(0, 0, 6))
+		case "eom":
+			parts.explicit = true
+			ok = parts.setDay(time.Date(today.Year(), today.Month()+1, 0, 0, 0, 0, 0, today.Location()))
+		case "tonight":
+			ok = parts.setDay(today) && parts.setTime(englishPeriods[token], 0)
+		case "morning", "noon", "afternoon", "evening":
+			ok = parts.setTime(englishPeriods[token], 0)
+		case "next":
+			if i+1 >= len(tokens) {
+				return dueParts{}, false
+			}
+			parts.explicit = true
+			i++
+			if tokens[i] == "week" {
+				ok = parts.setDay(weekStart(today).AddDate(0, 0, 7))
+				break
+			}
+			weekday, found := englishWeekdays[tokens[i]]
+			if !found {
+				return dueParts{}, false
+			}
+			ok = parts.setDay(weekStart(today).AddDate(0, 0, 7+weekdayOffset(weekday)))
+		case "in":
+			amount, unit, used, found := englishOffset(tokens[i+1:])
+			if !found {
+				return dueParts{}, false
+			}
+			parts.explicit = true
+			i += used
+			ok = applyOffset(&parts, now, amount, unit)
+		default:
+			if weekday, found := englishWeekdays[token]; found {
+				ok = parts.setDay(today.AddDate(0, 0, (int(weekday)-int(today.Weekday())+7)%7))
+				break
+			}
+			if day, err := time.ParseInLocation("2006-01-02", token, now.Location()); err == nil {
+				ok = parts.setDay(day)
+				break
+			}
+			hour, minute, used, found := englishClock(tokens[i:])
+			if !found {
+				return dueParts{}, false
+			}
+			parts.explicit = true
+			i += used - 1
+			ok = parts.setTime(hour, minute)
+		}
+		if !ok {
+			return dueParts{}, false
+		}
+	}
+	return parts, true
+}
+
+// englishClock reads 18:00, 9am, 9:30pm or "9 pm" and reports how many
+// tokens it used.
+func englishClock(tokens []string) (int, int, int, bool) {
+	token := tokens[0]
+	used := 1
+	if len(tokens) > 1 && (tokens[1] == "am" || tokens[1] == "pm") {
+		token += tokens[1]
+		used = 2
+	}
+	if m := clockRegexp.FindStringSubmatch(token); m != nil {
+		hour, _ := strconv.Atoi(m[1])
+		minute, _ := strconv.Atoi(m[2])
+		return hour, minute, 1, true
+	}
+	m := meridiemRegexp.FindStringSubmatch(token)
+	if m == nil {
+		return 0, 0, 0, false
+	}
+	hour, _ := strconv.Atoi(m[1])
+	minute := 0
+	if m[2] != "" {
+		minute, _ = strconv.Atoi(m[2])
+	}
+	if hour < 1 || hour > 12 {
+		return 0, 0, 0, false
+	}
+	hour %= 12
+	if m[3] == "pm" {
+		hour += 12
+	}
+	return hour, minute, used, true
+}
+
+// englishOffset reads "3 days", "a week" or "2h" after "in".
+func englishOffset(tokens []string) (int, string, int, bool) {
+	if len(tokens) == 0 {
+		return 0, "", 0, false
+	}
+	if m := offsetRegexp.FindStringSubmatch(tokens[0]); m != nil {
+		amount, _ := strconv.Atoi(m[1])
+		return amount, m[2], 1, true
+	}
+	if len(tokens) < 2 {
+		return 0, "", 0, false
+	}
+	amount, err := strconv.Atoi(tokens[0])
+	if tokens[0] == "a" || tokens[0] == "an" {
+		amount, err = 1, nil
+	}
+	if err != nil {
+		return 0, "", 0, false
+	}
+	return amount, tokens[1], 2, true
+}
+
+func applyOffset(parts *dueParts, now time.Time, amount int, unit string) bool {
+	if amount <= 0 {
+		return false
+	}
+	today := startOfDay(now)
+	switch unit {
+	case "m", "min", "mins", "minute", "minutes", "分钟":
+		t := now.Add(time.Duration(amount) * time.Minute).Truncate(time.Minute)
+		parts.exact = &t
+		return true
+	case "h", "hr", "hrs", "hour", "hours", "小时":
+		t := now.Add(time.Duration(amount) * time.Hour).Truncate(time.Minute)
+		parts.exact = &t
+		return true
+	case "d", "day", "days", "天":
+		return parts.setDay(today.AddDate(0, 0, amount))
+	case "w", "week", "weeks", "周", "星期":
+		return parts.setDay(today.AddDate(0, 0, 7*amount))
+	case "month", "months", "月":
+		return parts.setDay(today.AddDate(0, amount, 0))
+	}
+	return false
+}
+
+var (
+	cnRelativeDays = []struct {
+		word string
+		days int
+	}{
+		{"大后天", 3}, {"后天", 2}, {"明天", 1}, {"明日", 1}, {"今天", 0}, {"今日", 0},
+	}
+	cnShortDays = []struct {
+		word   string
+		days   int
+		period string
+	}{
+		{"今晚", 0, "晚上"}, {"明晚", 1, "晚上"}, {"明早", 1, "早上"},
+	}
+	cnPeriods = map[string]int{
+		"凌晨": 0, "早上": 9, "早晨": 9, "上午": 9, "中午": 12, "下午": 14, "傍晚": 18, "晚上": 20, "夜里": 22,
+	}
+	cnWeekRegexp   = regexp.MustCompile(`^(下下|下|本|这)?个?(?:周|星期|礼拜)([一二三四五六日天])`)
+	cnOffsetRegexp = regexp.MustCompile(`^([0-9]+|[一二两三四五六七八九十]+)个?(天|周|星期|月|小时|分钟)(?:后|以后|之后)`)
+	cnClockRegexp  = regexp.MustCompile(`^([0-9]{1,2}|[零一二两三四五六七八九十]+)(?:点|时)(半|一刻|三刻|([0-9]{1,2}|[零一二三四五六七八九十]+)分?)?`)
+	cnColonRegexp  = regexp.MustCompile(`^([0-9]{1,2}):([0-9]{2})`)
+)
+
+var cnWeekdays = map[string]int{"一": 0, "二": 1, "三": 2, "四": 3, "五": 4, "六": 5, "日": 6, "天": 6}
+
+func parseChineseDue(text string, now time.Time) (dueParts, bool) {
+	today := startOfDay(now)
+	var parts dueParts
+	var period string
+	rest := text
+	for rest != "" {
+		matched := false
+		for _, item := range cnRelativeDays {
+			if strings.HasPrefix(rest, item.word) {
+				if !parts.setDay(today.AddDate(0, 0, item.days)) {
+					return dueParts{}, false
+				}
+				rest, matched = rest[len(item.word):], true
+				break
+			}
+		}
+		for _, item := range cnShortDays {
+			if !matched && strings.HasPrefix(rest, item.word) {
+				if !parts.setDay(today.AddDate(0, 0, item.days)) {
+					return dueParts{}, false
+				}
+				period = item.period
+				rest, matched = rest[len(item.word):], true
+			}
+		}
+		if matched {
+			continue
+		}
+		if m := cnWeekRegexp.FindStringSubmatch(rest); m != nil {
+			var day time.Time
+			offset := cnWeekdays[m[2]]
+			if m[1] != "" {
+				parts.explicit = true
+			}
+			switch m[1] {
+			case "下":
+				day = weekStart(today).AddDate(0, 0, 7+offset)
+			case "下下":
+				day = weekStart(today).AddDate(0, 0, 14+offset)
+			case "本", "这":
+				day = weekStart(today).AddDate(0, 0, offset)
+			default:
+				day = today.AddDate(0, 0, (offset-weekdayOffset(today.Weekday())+7)%7)
+			}
+			if !parts.setDay(day) {
+				return dueParts{}, false
+			}
+			rest = rest[len(m[0]):]
+			continue
+		}
+		if m := cnOffsetRegexp.FindStringSubmatch(rest); m != nil {
+			amount, ok := chineseNumber(m[1])
+			if !ok || !applyOffset(&parts, now, amount, m[2]) {
+				return dueParts{}, false
+			}
+			parts.explicit = true
+			rest = rest[len(m[0]):]
+			continue
+		}
+		if word := periodPrefix(rest); word != "" {
+			if period != "" {
+				return dueParts{}, false
+			}
+			period = word
+			rest = rest[len(word):]
+			continue
+		}
+		if m := cnColonRegexp.FindStringSubmatch(rest); m != nil {
+			hour, _ := strconv.Atoi(m[1])
+			minute, _ := strconv.Atoi(m[2])
+			if !parts.setChineseTime(hour, minute, period) {
+				return dueParts{}, false
+			}
+			parts.explicit = true
+			rest = rest[len(m[0]):]
+			continue
+		}
+		if m := cnClockRegexp.FindStringSubmatch(rest); m != nil {
+			hour, ok := chineseNumber(m[1])
+			if !ok {
+				return dueParts{}, false
+			}
+			minute := 0
+			switch m[2] {
+			case "":
+			case "半":
+				minute = 30
+			case "一刻":
+				minute = 15
+			case "三刻":
+				minute = 45
+			default:
+				if minute, ok = chineseNumber(m[3]); !ok {
+					return dueParts{}, false
+				}
+			}
+			if !parts.setChineseTime(hour, minute, period) {
+				return dueParts{}, false
+			}
+			parts.explicit = true
+			rest = rest[len(m[0]):]
+			continue
+		}
+		for _, suffix := range []string{"之前", "以前", "前"} {
+			if rest == suffix {
+				rest, matched = "", true
+				parts.explicit = true
+				break
+			}
+		}
+		if !matched {
+			return dueParts{}, false
+		}
+	}
+	if period != "" && !parts.hasTime {
+		if !parts.setTime(cnPeriods[period], 0) {
+			return dueParts{}, false
+		}
+	}
+	return parts, true
+}
+
+func periodPrefix(text string) string {
+	for word := range cnPeriods {
+		if strings.HasPrefix(text, word) {
+			return word
+		}
+	}
+	return ""
+}
+
+// setChineseTime sets the time of day, moving 下午3点 to 15:00, 中午1点
+// to 13:00 and 晚上12点 to midnight at the end of the day.
+func (p *dueParts) setChineseTime(hour, minute int, period string) bool {
+	switch period {
+	case "下午", "傍晚", "晚上", "夜里":
+		if hour < 12 {
+			hour += 12
+		} else if hour == 12 && (period == "晚上" || period == "夜里") {
+			hour = 0
+			p.nextDay = true
+		}
+	case "中午":
+		if hour < 6 {
+			hour += 12
+		}
+	}
+	return p.setTime(hour, minute)
+}
+
+// chineseNumber parses digits or Chinese numerals up to 99.
+func chineseNumber(text string) (int, bool) {
+	if n, err := strconv.Atoi(text); err == nil {
+		return n, true
+	}
+	digits := map[rune]int{'零': 0, '一': 1, '二': 2, '两': 2, '三': 3, '四': 4, '五': 5, '六': 6, '七': 7, '八': 8, '九': 9}
+	runes := []rune(text)
+	if len(runes) == 0 {
+		return 0, false
+	}
+	tens := strings.IndexRune(text, '十')
+	if tens < 0 {
+		if len(runes) != 1 {
+			return 0, false
+		}
+		n, ok := digits[runes[0]]
+		return n, ok
+	}
+	high, low := 1, 0
+	before, after := []rune(text[:tens]), []rune(text[tens+len("十"):])
+	if len(before) > 1 || len(after) > 1 {
+		return 0, false
+	}
+	if len(before) == 1 {
+		n, ok := digits[before[0]]
+		if !ok {
+			return 0, false
+		}
+		high = n
+	}
+	if len(after) == 1 {
+		n, ok := digits[after[0]]
+		if !ok {
+			return 0, false
+		}
+		low = n
+	}
+	return high*10 + low, true
+}
+
+func containsHan(text string) bool {
+	for _, r := range text {
+		if unicode.Is(unicode.Han, r) {
+			return true
+		}
+	}
+	return false
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+func endOfDay(t time.Time) time.Time {
+	return atTime(t, endOfDayHour, endOfDayMinute)
+}
+
+func atTime(day time.Time, hour, minute int) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, day.Location())
+}
+
+// weekStart returns the Monday of day's week.
+func weekStart(day time.Time) time.Time {
+	return day.AddDate(0, 0, -weekdayOffset(day.Weekday()))
+}
+
+// weekdayOffset counts days from Monday.
+func weekdayOffset(weekday time.Weekday) int {
+	return (int(weekday) + 6) % 7
+}
@@ -0,0 +1,121 @@
+package timeutil
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDueShouldResolvePhrasesAgainstClock(t *testing.T) {
+	loc := time.FixedZone("CST", 8*3600)
+	// Wednesday.
+	now := time.Date(2026, 3, 4, 10, 15, 0, 0, loc)
+	clk := ClockFunc(func() time.Time { return now })
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, 3, day, hour, minute, 0, 0, loc)
+	}
+
+	cases := []struct {
+		in   string
+		want time.Time
+	}{
+		{"2026-03-10", at(10, 23, 59)},
+		{"2026-03-10 08:30", at(10, 8, 30)},
+		{"today 18:00", at(4, 18, 0)},
+		{"Tomorrow", at(5, 23, 59)},
+		{"in 3 days", at(7, 23, 59)},
+		{"in 2h", at(4, 12, 15)},
+		{"next monday", at(9, 23, 59)},
+		{"monday", at(9, 23, 59)},
+		{"wed", at(4, 23, 59)},
+		{"fri 9am", at(6, 9, 0)},
+		{"friday at 9:30 pm", at(6, 21, 30)},
+		{"9am", at(5, 9, 0)},
+		{"eod", at(4, 23, 59)},
+		{"eow", at(8, 23, 59)},
+		{"明天下午3点", at(5, 15, 0)},
+		{"下周一", at(9, 23, 59)},
+		{"后天", at(6, 23, 59)},
+		{"周五上午十点半", at(6, 10, 30)},
+		{"本周一", at(2, 23, 59)},
+		{"今晚", at(4, 20, 0)},
+		{"今晚12点", at(5, 0, 0)},
+		{"晚上12点", at(5, 0, 0)},
+		{"明天晚上12点半", at(6, 0, 30)},
+		{"夜里11点", at(4, 23, 0)},
+		{"中午12点", at(4, 12, 0)},
+		{"3天后", at(7, 23, 59)},
+		{"明天 18:30", at(5, 18, 30)},
+	}
+	for _, tc := range cases {
+		got, err := ParseDue(tc.in, clk, loc)
+		if err != nil {
+			t.Fatalf("ParseDue(%q): %v", tc.in, err)
+		}
+		if !got.Equal(tc.want) {
+			t.Fatalf("ParseDue(%q) = %s, want %s", tc.in, got.In(loc), tc.want)
+		}
+	}
+
+	for _, in := range []string{"", "someday", "next", "in 3", "13pm", "明天后天", "快一点", "in -1 days", "in -2h", "in 0 days", "0天后"} {
+		if _, err := ParseDue(in, clk, loc); err == nil {
+			t.Fatalf("ParseDue(%q) should fail", in)
+		}
+	}
+}
+
+func TestParseStartShouldBeginBareDaysAtMidnight(t *testing.T) {
+	loc := time.FixedZone("CST", 8*3600)
+	clk := ClockFunc(func() time.Time { return time.Date(2026, 3, 4, 10, 15, 0, 0, loc) })
+
+	cases := []struct {
+		in   string
+		want time.Time
+	}{
+		{"2026-03-10", time.Date(2026, 3, 10, 0, 0, 0, 0, loc)},
+		{"next monday", time.Date(2026, 3, 9, 0, 0, 0, 0, loc)},
+		{"tomorrow 9am", time.Date(2026, 3, 5, 9, 0, 0, 0, loc)},
+		{"下周一", time.Date(2026, 3, 9, 0, 0, 0, 0, loc)},
+	}
+	for _, tc := range cases {
+		got, err := ParseStart(tc.in, clk, loc)
+		if err != nil {
+			t.Fatalf("ParseStart(%q): %v", tc.in, err)
+		}
+		if !got.Equal(tc.want) {
+			t.Fatalf("ParseStart(%q) = %s, want %s", tc.in, got.In(loc), tc.want)
+		}
+	}
+}
+
+func TestFindDueShouldLocatePhraseInText(t *testing.T) {
+	loc := time.FixedZone("CST", 8*3600)
+	clk := ClockFunc(func() time.Time { return time.Date(2026, 3, 4, 10, 15, 0, 0, loc) })
+
+	cases := []struct {
+		in     string
+		phrase string
+		want   time.Time
+	}{
+		{"明天下午3点交周报", "明天下午3点", time.Date(2026, 3, 5, 15, 0, 0, 0, loc)},
+		{"send invoice fri 9am please", "fri 9am", time.Date(2026, 3, 6, 9, 0, 0, 0, loc)},
+		{"review PR by tomorrow", "by tomorrow", time.Date(2026, 3, 5, 23, 59, 0, 0, loc)},
+		{"call Bob tomorrow at 19:30", "tomorrow at 19:30", time.Date(2026, 3, 5, 19, 30, 0, 0, loc)},
+		{"renew passport in 3 days", "in 3 days", time.Date(2026, 3, 7, 23, 59, 0, 0, loc)},
+		{"交周报 周五之前", "周五之前", time.Date(2026, 3, 6, 23, 59, 0, 0, loc)},
+	}
+	for _, tc := range cases {
+		got, phrase, ok := FindDue(tc.in, clk, loc)
+		if !ok || phrase != tc.phrase || !got.Equal(tc.want) {
+			t.Fatalf("FindDue(%q) = %s %q %v, want %s %q", tc.in, got.In(loc), phrase, ok, tc.want, tc.phrase)
+		}
+	}
+	for _, in := range []string{
+		"快一点处理", "sat exam notes", "buy milk",
+		"Review today PR", "noon walk", "evening run", "plan sat morning",
+		"notes from 2026-03-01 standup", "明天交周报", "周五例会",
+	} {
+		if _, phrase, ok := FindDue(in, clk, loc); ok {
+			t.Fatalf("FindDue(%q) should not match, got %q", in, phrase)
+		}
+	}
+}
@@ -11,6 +11,11 @@ const (
 	KeyProject     = "P"
 	KeyToday       = "t"
 	KeyDue         = "d"
+	KeyDefer       = "s"
+	KeySnooze      = "S"
+	KeySnoozeAll   = "O"
+	KeyWait        = "w"
+	KeyMove        = "m"
 	KeyPriority    = "y"
 	KeyComplete    = "c"
 	KeyRestore     = "r"
@@ -25,4 +30,6 @@ const (
 	KeyQuit        = "q"
 	KeyHelp        = "?"
 	KeyUndo        = "z"
+	KeyRedo        = "Z"
+	KeySearch      = "/"
 )
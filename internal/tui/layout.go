@@ -182,7 +182,7 @@ func renderHeader(done, total int, view domain.View, count int, doing, todo, don
 	return renderBox(headerBoxStyle, content, width, 0)
 }
 
-func renderFooter(statusMsg string, focused focusArea, width int, view domain.View) string {
+func renderFooter(statusMsg string, focused focusArea, width int, view domain.View, filter string) string {
 	status := statusMsg
 	if status == "" {
 		status = "ready"
@@ -191,7 +191,11 @@ func renderFooter(statusMsg string, focused focusArea, width int, view domain.Vi
 	if focused == focusList {
 		focusLabel = "list"
 	}
-	content := "{status} " + status + "  {focus} " + focusLabel + "  {help} ?  {quit} q"
+	content := "{status} " + status + "  {focus} " + focusLabel
+	if filter != "" {
+		content += "  {filter} " + filter
+	}
+	content += "  {help} ?  {quit} q"
 	if view == domain.ViewTrash {
 		content += "  {restore} r  {purge} X"
 	}
@@ -206,7 +210,8 @@ func renderFooter(statusMsg string, focused focusArea, width int, view domain.Vi
 	content = strings.ReplaceAll(content, "{quit}", "quit")
 	content = strings.ReplaceAll(content, "{restore}", "restore")
 	content = strings.ReplaceAll(content, "{purge}", "purge")
-	content = highlightFooterLabels(content, "status", "focus", "help", "quit", "restore", "purge")
+	content = strings.ReplaceAll(content, "{filter}", "filter")
+	content = highlightFooterLabels(content, "status", "focus", "filter", "help", "quit", "restore", "purge")
 	return renderBox(footerBoxStyle, content, width, 0)
 }
 
@@ -242,18 +247,18 @@ func renderHelpModal(width int) string {
 		renderHelpLine("j/k, up/down", "move cursor"),
 		renderHelpLine("Tab", "switch focus nav/list"),
 		renderHelpLine("Enter", "select current view/project"),
+		renderHelpLine("/", "filter list live (Enter keep, Esc clear)"),
 		"",
 		helpSectionStyle.Render("Task"),
 		renderHelpLine("a", "add task"),
 		renderHelpLine("e", "edit title"),
 		renderHelpLine("x", "delete task / project"),
 		renderHelpLine("c", "mark done"),
-		renderHelpLine("z", "undo last action"),
+		renderHelpLine("z / Z", "undo / redo last action"),
 		renderHelpLine("P", "set project"),
-		renderHelpLine("t", "mark today"),
-		renderHelpLine("d", "set due"),
-		renderHelpLine("y", "set priority"),
-		renderHelpLine("h", "toggle done in project"),
+		renderHelpLine("t / d / s / y", "today / due / defer until / priority"),
+		renderHelpLine("S / O / w / m", "snooze / snooze overdue / wait on (alice ^fri) / move"),
+		renderHelpLine("h", "toggle done in project / tag"),
 		renderHelpLine("r / X", "restore selected in trash / purge all in trash"),
 		renderHelpLine("Space", "ai input + preview"),
 		renderHelpLine("p / Ctrl+a", "ai parse clipboard"),
@@ -263,7 +268,7 @@ func renderHelpModal(width int) string {
 	return renderBox(helpModalBoxStyle, joinLines(lines), modalWidth, 0)
 }
 
-func renderAIInputModal(width int, input string, cursor int) string {
+func renderAIInputModal(width int, input string, cursor int, multi bool) string {
 	modalWidth := width - 12
 	if modalWidth > 90 {
 		modalWidth = 90
@@ -276,17 +281,24 @@ func renderAIInputModal(width int, input string, cursor int) string {
 	}
 
 	line := renderCursorAt(input, cursor)
+	mode := "one task"
+	if multi {
+		mode = "every task in the text"
+	}
 	lines := []string{
 		helpTitleStyle.Render("AI QUICK INPUT"),
 		"",
 		renderHelpLine("text", truncateLineForPane(line, modalWidth-8)),
+		renderHelpLine("mode", mode),
 		"",
-		helpHintStyle.Render("Enter preview  esc cancel"),
+		helpHintStyle.Render("Enter preview  Tab one/multi  esc cancel"),
 	}
 	return renderBox(helpModalBoxStyle, joinLines(lines), modalWidth, 0)
 }
 
-func renderAIPreviewModal(width int, parsed clipboard.ParsedTask, source string) string {
+// renderAIPreviewModal shows one parsed task in full, or several as a
+// list with the accepted ones checked and the cursor on one of them.
+func renderAIPreviewModal(width int, parsed []clipboard.ParsedTask, accepted []bool, cursor int, source string) string {
 	modalWidth := width - 12
 	if modalWidth > 92 {
 		modalWidth = 92
@@ -302,34 +314,66 @@ func renderAIPreviewModal(width int, parsed clipboard.ParsedTask, source string)
 	if strings.TrimSpace(strings.ToLower(source)) == "ai" {
 		sourceLabel = "AI"
 	}
-	title := strings.TrimSpace(parsed.Title)
+	lines := []string{
+		helpTitleStyle.Render("AI PREVIEW"),
+		"",
+		renderHelpLine("source", sourceLabel),
+	}
+	if len(parsed) != 1 {
+		count := 0
+		for _, ok := range accepted {
+			if ok {
+				count++
+			}
+		}
+		lines = append(lines, renderHelpLine("tasks", fmt.Sprintf("%d of %d selected", count, len(parsed))), "")
+		for i, task := range parsed {
+			mark := "[ ]"
+			if i < len(accepted) && accepted[i] {
+				mark = "[x]"
+			}
+			fields := []string{strings.TrimSpace(task.Title)}
+			for _, field := range []string{task.Project, task.Due, task.Priority} {
+				if field = strings.TrimSpace(field); field != "" {
+					fields = append(fields, field)
+				}
+			}
+			line := truncateLineForPane(mark+" "+strings.Join(fields, "  "), modalWidth-8)
+			if i == cursor {
+				lines = append(lines, listSelectedStyle.Render("> "+line))
+			} else {
+				lines = append(lines, "  "+line)
+			}
+		}
+		lines = append(lines, "", helpHintStyle.Render("Enter create selected  space toggle  j/k move  e edit  esc cancel"))
+		return renderBox(helpModalBoxStyle, joinLines(lines), modalWidth, 0)
+	}
+
+	task := parsed[0]
+	title := strings.TrimSpace(task.Title)
 	if title == "" {
 		title = "-"
 	}
-	project := strings.TrimSpace(parsed.Project)
+	project := strings.TrimSpace(task.Project)
 	if project == "" {
 		project = "-"
 	}
-	due := strings.TrimSpace(parsed.Due)
+	due := strings.TrimSpace(task.Due)
 	if due == "" {
 		due = "-"
 	}
-	priority := strings.TrimSpace(parsed.Priority)
+	priority := strings.TrimSpace(task.Priority)
 	if priority == "" {
 		priority = "P2"
 	}
-
-	lines := []string{
-		helpTitleStyle.Render("AI PREVIEW"),
-		"",
-		renderHelpLine("source", sourceLabel),
+	lines = append(lines,
 		renderHelpLine("todo", truncateLineForPane(title, modalWidth-10)),
 		renderHelpLine("project", truncateLineForPane(project, modalWidth-10)),
 		renderHelpLine("due", due),
 		renderHelpLine("priority", priority),
 		"",
 		helpHintStyle.Render("Enter confirm  e edit  esc cancel"),
-	}
+	)
 	return renderBox(helpModalBoxStyle, joinLines(lines), modalWidth, 0)
 }
 
@@ -470,10 +514,16 @@ func viewLabel(v domain.View) string {
 		return "Today"
 	case domain.ViewInbox:
 		return "Inbox"
+	case domain.ViewUpcoming:
+		return "Upcoming"
+	case domain.ViewWaiting:
+		return "Waiting"
 	case domain.ViewLog:
 		return "Log"
 	case domain.ViewProject:
 		return "Project"
+	case domain.ViewTag:
+		return "Tags"
 	case domain.ViewTrash:
 		return "Trash"
 	default:
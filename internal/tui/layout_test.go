@@ -74,7 +74,7 @@ func TestFooterShouldNotContainMidResetBetweenSegments(t *testing.T) {
 		lipgloss.SetColorProfile(oldProfile)
 	})
 
-	footer := renderFooter("ready", focusNav, 130, domain.ViewInbox)
+	footer := renderFooter("ready", focusNav, 130, domain.ViewInbox, "")
 	if strings.Contains(footer, "\x1b[0m  \x1b[38;") {
 		t.Fatalf("footer contains mid-reset artifacts: %q", footer)
 	}
@@ -88,7 +88,7 @@ func TestFooterShouldContainHighlightStyle(t *testing.T) {
 		lipgloss.SetColorProfile(oldProfile)
 	})
 
-	footer := renderFooter("ready", focusNav, 130, domain.ViewInbox)
+	footer := renderFooter("ready", focusNav, 130, domain.ViewInbox, "")
 	if !containsANSIColor(footer, "52;211;153") {
 		t.Fatalf("footer should contain ready highlight color, footer=%q", footer)
 	}
@@ -102,7 +102,7 @@ func TestFooterShouldRestoreMutedBaseColorAfterTokenHighlight(t *testing.T) {
 		lipgloss.SetColorProfile(oldProfile)
 	})
 
-	footer := renderFooter("ready", focusNav, 130, domain.ViewInbox)
+	footer := renderFooter("ready", focusNav, 130, domain.ViewInbox, "")
 	if !strings.Contains(footer, "38;2;147;161;176m") {
 		t.Fatalf("footer should contain muted base color marker, footer=%q", footer)
 	}
@@ -150,7 +150,7 @@ func TestListPaneShouldNotContainMidResetArtifacts(t *testing.T) {
 		lipgloss.SetColorProfile(oldProfile)
 	})
 
-	lines := renderList(nil, 0, false, 60, 12, domain.ViewInbox, time.Local)
+	lines := renderList(nil, 0, false, 60, 12, domain.ViewInbox, domain.DefaultWorkflow(), time.Now())
 	block := strings.Join(lines, "\n")
 	if strings.Contains(block, "\x1b[1;38;2;217;226;236mTasks") {
 		t.Fatalf("list title should not use nested line style render, block=%q", block)
@@ -174,7 +174,7 @@ func TestSelectedTaskLineShouldNotUseDifferentBackgroundBlock(t *testing.T) {
 		{ID: 1, Title: "selected-row", Status: domain.StatusTodo, Priority: "P1", DueAt: &due},
 		{ID: 2, Title: "normal-row", Status: domain.StatusTodo, Priority: "P2"},
 	}
-	lines := renderList(tasks, 0, true, 90, 12, domain.ViewInbox, loc)
+	lines := renderList(tasks, 0, true, 90, 12, domain.ViewInbox, domain.DefaultWorkflow(), time.Now().In(loc))
 	block := strings.Join(lines, "\n")
 	if strings.Contains(block, "31;49;66") {
 		t.Fatalf("selected line should not introduce dark background block, block=%q", block)
@@ -199,7 +199,7 @@ func TestTaskRowShouldAvoidInlineResetArtifacts(t *testing.T) {
 		Priority: "P1",
 		DueAt:    &due,
 	}
-	row := renderTaskLine("> ", renderStatusLabel(task.Status), task, domain.ViewToday, loc, 100)
+	row := renderTaskLine("> ", renderStatusLabel(task.Status, domain.DefaultWorkflow()), task, domain.ViewToday, time.Now().In(loc), 100)
 	if strings.Contains(row, "\x1b[0m") {
 		t.Fatalf("task row should avoid inline reset artifact, row=%q", row)
 	}
@@ -306,7 +306,7 @@ func TestHelpModalShouldContainTrashActions(t *testing.T) {
 
 func TestHelpModalShouldContainPriorityAction(t *testing.T) {
 	modal := ansi.Strip(renderHelpModal(100))
-	if !strings.Contains(modal, "y") || !strings.Contains(modal, "set priority") {
+	if !strings.Contains(modal, "y") || !strings.Contains(modal, "priority") {
 		t.Fatalf("help modal should contain priority action, modal=%q", modal)
 	}
 }
@@ -317,8 +317,8 @@ func TestRenderTaskLineShouldAlignMetaColumnByStatusWidth(t *testing.T) {
 	todoTask := domain.Task{Title: "todo-item", Status: domain.StatusTodo, Priority: "P1", DueAt: &due}
 	doingTask := domain.Task{Title: "doing-item", Status: domain.StatusDoing, Priority: "P2", DueAt: &due}
 
-	lineTodo := renderTaskLine("  ", renderStatusLabel(todoTask.Status), todoTask, domain.ViewInbox, loc, 120)
-	lineDoing := renderTaskLine("  ", renderStatusLabel(doingTask.Status), doingTask, domain.ViewInbox, loc, 120)
+	lineTodo := renderTaskLine("  ", renderStatusLabel(todoTask.Status, domain.DefaultWorkflow()), todoTask, domain.ViewInbox, time.Now().In(loc), 120)
+	lineDoing := renderTaskLine("  ", renderStatusLabel(doingTask.Status, domain.DefaultWorkflow()), doingTask, domain.ViewInbox, time.Now().In(loc), 120)
 
 	idxTodo := strings.Index(lineTodo, "2026-02-24 09:30")
 	idxDoing := strings.Index(lineDoing, "2026-02-24 09:30")
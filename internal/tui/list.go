@@ -16,6 +16,7 @@ const (
 	listStatusInbox  = "\x1b[38;2;203;213;225m"
 	listStatusTodo   = "\x1b[38;2;96;165;250m"
 	listStatusDoing  = "\x1b[38;2;251;191;36m"
+	listStatusWait   = "\x1b[38;2;196;181;253m"
 	listStatusDone   = "\x1b[38;2;52;211;153m"
 	listStatusDelete = "\x1b[38;2;248;113;113m"
 	listMetaProject  = "\x1b[38;2;150;185;216m"
@@ -23,13 +24,14 @@ const (
 	listMetaDueWarn  = "\x1b[1;38;2;251;191;36m"
 	listMetaDone     = "\x1b[38;2;147;197;253m"
 	listMetaMuted    = "\x1b[38;2;147;161;176m"
+	listMetaTag      = "\x1b[38;2;196;181;253m"
 	listPriP1        = "\x1b[1;38;2;251;113;133m"
 	listPriP2        = "\x1b[1;38;2;251;191;36m"
 	listPriP3        = "\x1b[38;2;96;165;250m"
 	listPriP4        = listMetaMuted
 )
 
-func renderList(tasks []domain.Task, cursor int, focused bool, width, height int, view domain.View, loc *time.Location) []string {
+func renderList(tasks []domain.Task, cursor int, focused bool, width, height int, view domain.View, workflow domain.Workflow, now time.Time) []string {
 	contentWidth := paneContentWidth(width)
 	contentHeight := paneContentHeight(height)
 	lines := []string{truncateLineForPane("Tasks", contentWidth)}
@@ -39,11 +41,13 @@ func renderList(tasks []domain.Task, cursor int, focused bool, width, height int
 		} else {
 			visible := contentHeight - 1
 			start, end := viewportWindow(len(tasks), cursor, visible)
+			_, depths := domain.NestSubtasks(tasks)
 			for idx := start; idx < end; idx++ {
 				task := tasks[idx]
+				task.Title = strings.Repeat("  ", depths[idx]) + task.Title
 				prefix := renderListPrefix(idx == cursor)
-				status := renderStatusLabel(task.Status)
-				line := renderTaskLine(prefix, status, task, view, loc, contentWidth)
+				status := renderStatusLabel(task.Status, workflow)
+				line := renderTaskLine(prefix, status, task, view, now, contentWidth)
 				lines = append(lines, line)
 			}
 		}
@@ -55,7 +59,7 @@ func renderList(tasks []domain.Task, cursor int, focused bool, width, height int
 	return splitRendered(renderBox(style, joinLines(lines), width, height))
 }
 
-func renderStatusLabel(status domain.Status) string {
+func renderStatusLabel(status domain.Status, workflow domain.Workflow) string {
 	label := "[" + string(status) + "]"
 	switch status {
 	case domain.StatusInbox:
@@ -64,13 +68,17 @@ func renderStatusLabel(status domain.Status) string {
 		return paintList(label, listStatusTodo)
 	case domain.StatusDoing:
 		return paintList(label, listStatusDoing)
+	case domain.StatusWaiting:
+		return paintList(label, listStatusWait)
 	case domain.StatusDone:
 		return paintList(label, listStatusDone)
 	case domain.StatusDeleted:
 		return paintList(label, listStatusDelete)
-	default:
-		return label
 	}
+	if workflow.IsClosed(status) {
+		return paintList(label, listStatusDone)
+	}
+	return label
 }
 
 func formatDue(dueAt *time.Time, loc *time.Location) string {
@@ -93,19 +101,17 @@ func formatDoneAt(doneAt *time.Time, loc *time.Location) string {
 	return doneAt.In(loc).Format("2006-01-02 15:04")
 }
 
-func renderTaskLine(prefix, status string, task domain.Task, view domain.View, loc *time.Location, width int) string {
+func renderTaskLine(prefix, status string, task domain.Task, view domain.View, now time.Time, width int) string {
 	if width <= 0 {
 		width = 1
 	}
-	if loc == nil {
-		loc = time.Local
-	}
 	statusField := padFixed(status, 9)
-	meta := renderTaskMeta(task, view, loc)
+	meta := renderTaskMeta(task, view, now)
 	return composeTaskLine(prefix, statusField, task.Title, meta, width)
 }
 
-func renderTaskMeta(task domain.Task, view domain.View, loc *time.Location) string {
+func renderTaskMeta(task domain.Task, view domain.View, now time.Time) string {
+	loc := now.Location()
 	segments := make([]string, 0, 3)
 
 	switch view {
@@ -118,15 +124,66 @@ func renderTaskMeta(task domain.Task, view domain.View, loc *time.Location) stri
 		segments = append(segments, renderPriorityMeta(task.Priority))
 	case domain.ViewToday:
 		segments = append(segments, renderProjectMeta(task.Project))
-		segments = append(segments, renderDueMeta(task.DueAt, loc))
+		segments = append(segments, renderDueMeta(task.DueAt, now))
+		segments = append(segments, renderPriorityMeta(task.Priority))
+	case domain.ViewUpcoming:
+		segments = append(segments, renderProjectMeta(task.Project))
+		segments = append(segments, renderStartMeta(task.StartAt, loc))
+		segments = append(segments, renderDueMeta(task.DueAt, now))
+		segments = append(segments, renderPriorityMeta(task.Priority))
+	case domain.ViewWaiting:
+		segments = append(segments, renderWaitingMeta(task, now))
+		segments = append(segments, renderProjectMeta(task.Project))
 		segments = append(segments, renderPriorityMeta(task.Priority))
 	default:
-		segments = append(segments, renderDueMeta(task.DueAt, loc))
+		segments = append(segments, renderDueMeta(task.DueAt, now))
 		segments = append(segments, renderPriorityMeta(task.Priority))
 	}
+	if task.Subtasks.Total > 0 && view != domain.ViewTrash {
+		segments = append(segments, renderProgressMeta(task.Subtasks))
+	}
+	if task.IsBlocked() && view != domain.ViewLog && view != domain.ViewTrash {
+		segments = append(segments, renderBlockedMeta(task.BlockedBy))
+	}
+	if !task.Recurrence.IsZero() && view != domain.ViewLog && view != domain.ViewTrash {
+		segments = append(segments, paintList("↻ "+task.Recurrence.Short(), listMetaDue))
+	}
+	if task.Status == domain.StatusWaiting && view != domain.ViewWaiting {
+		segments = append(segments, renderWaitingMeta(task, now))
+	}
+	if task.IsDeferred(now) && view != domain.ViewUpcoming && view != domain.ViewLog && view != domain.ViewTrash {
+		segments = append(segments, renderStartMeta(task.StartAt, loc))
+	}
+	if len(task.Tags) > 0 && view != domain.ViewTrash {
+		segments = append(segments, renderTagsMeta(task.Tags))
+	}
 	return strings.Join(segments, "  ")
 }
 
+func renderProgressMeta(progress domain.Progress) string {
+	label := fmt.Sprintf("%d/%d", progress.Done, progress.Total)
+	if progress.Open() == 0 {
+		return paintList(label, listMetaDone)
+	}
+	return paintList(label, listMetaMuted)
+}
+
+func renderBlockedMeta(blockers []int64) string {
+	parts := make([]string, 0, len(blockers))
+	for _, id := range blockers {
+		parts = append(parts, fmt.Sprintf("#%d", id))
+	}
+	return paintList("blocked "+strings.Join(parts, ","), listMetaDueWarn)
+}
+
+func renderTagsMeta(tags []string) string {
+	parts := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		parts = append(parts, "#"+tag)
+	}
+	return paintList(strings.Join(parts, " "), listMetaTag)
+}
+
 func renderProjectMeta(project string) string {
 	project = strings.TrimSpace(project)
 	if project == "" {
@@ -135,21 +192,46 @@ func renderProjectMeta(project string) string {
 	return paintList(project, listMetaProject)
 }
 
-func renderDueMeta(dueAt *time.Time, loc *time.Location) string {
+func renderDueMeta(dueAt *time.Time, now time.Time) string {
 	if dueAt == nil {
 		return paintList("-", listMetaMuted)
 	}
-	if loc == nil {
-		loc = time.Local
-	}
-	dueLocal := dueAt.In(loc)
+	dueLocal := dueAt.In(now.Location())
 	label := dueLocal.Format("2006-01-02 15:04")
-	if dueLocal.Before(time.Now().In(loc)) {
+	if dueLocal.Before(now) {
 		return paintList(label, listMetaDueWarn)
 	}
 	return paintList(label, listMetaDue)
 }
 
+func renderStartMeta(startAt *time.Time, loc *time.Location) string {
+	if startAt == nil {
+		return paintList("-", listMetaMuted)
+	}
+	if loc == nil {
+		loc = time.Local
+	}
+	return paintList("starts "+startAt.In(loc).Format("2006-01-02 15:04"), listMetaMuted)
+}
+
+func renderWaitingMeta(task domain.Task, now time.Time) string {
+	parts := make([]string, 0, 2)
+	if task.WaitingOn != "" {
+		parts = append(parts, "waiting on "+task.WaitingOn)
+	}
+	if task.FollowUpAt != nil {
+		parts = append(parts, "follow up "+formatDue(task.FollowUpAt, now.Location()))
+	}
+	if len(parts) == 0 {
+		parts = append(parts, "waiting")
+	}
+	color := listMetaMuted
+	if task.NeedsFollowUp(now) {
+		color = listMetaDueWarn
+	}
+	return paintList(strings.Join(parts, ", "), color)
+}
+
 func renderDoneMeta(doneAt *time.Time, loc *time.Location) string {
 	if doneAt == nil {
 		return paintList("-", listMetaMuted)
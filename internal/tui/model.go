@@ -2,7 +2,9 @@ package tui
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -12,7 +14,9 @@ import (
 	"td/internal/app/usecase"
 	"td/internal/clipboard"
 	"td/internal/domain"
+	"td/internal/query"
 	"td/internal/repo"
+	"td/internal/timeutil"
 )
 
 type focusArea int
@@ -30,37 +34,23 @@ const (
 	inputEdit
 	inputTaskProject
 	inputDue
+	inputDefer
+	inputSnooze
+	inputSnoozeOverdue
+	inputWait
+	inputMove
 	inputPriority
 	inputProjectCreate
 	inputProjectRename
+	inputSearch
 )
 
-type undoKind int
-
-const (
-	undoTaskDelete undoKind = iota
-	undoProjectDelete
-	undoTaskStatus
-)
-
-type taskStatusChange struct {
-	taskID int64
-	from   domain.Status
-	to     domain.Status
-}
-
-type undoAction struct {
-	kind          undoKind
-	taskIDs       []int64
-	project       string
-	statusChanges []taskStatusChange
-}
-
 type Model struct {
 	navItems           []navItem
 	navIndex           int
 	activeView         domain.View
 	project            string
+	tag                string
 	focus              focusArea
 	listCursor         int
 	width              int
@@ -69,6 +59,8 @@ type Model struct {
 	clipUseCase        usecase.AddFromClipboardUseCase
 	now                func() time.Time
 	tasks              []domain.Task
+	filter             string
+	filterErr          error
 	statusMsg          string
 	todayDone          int
 	todayTotal         int
@@ -84,16 +76,21 @@ type Model struct {
 	projectOptions     []string
 	projectSelectIndex int
 	projects           []string
+	tags               []string
 	showDone           bool
 	showHelp           bool
 	showAIInput        bool
 	showAIPreview      bool
 	aiInputValue       string
 	aiInputCursor      int
-	aiPreview          clipboard.ParsedTask
+	aiMulti            bool
+	aiPreview          []clipboard.ParsedTask
+	aiAccepted         []bool
+	aiPreviewIndex     int
 	aiPreviewRaw       string
 	aiSource           string
-	undoStack          []undoAction
+	confirmTask        domain.Task
+	confirmSubtasks    []domain.Task
 }
 
 const projectNoneOption = "[none]"
@@ -102,8 +99,8 @@ func NewModel() Model {
 	return NewModelWithQuery(usecase.NavQueryUseCase{})
 }
 
-func NewModelWithRepo(r repo.TaskRepository) Model {
-	m := NewModelWithQuery(usecase.NewNavQueryUseCase(r))
+func NewModelWithRepo(r repo.TaskRepository, workflow domain.Workflow) Model {
+	m := NewModelWithQuery(usecase.NewNavQueryUseCase(r, workflow))
 	m.clipUseCase = usecase.AddFromClipboardUseCase{
 		Repo:     r,
 		AIParser: &usecase.AIParseTaskUseCase{},
@@ -111,6 +108,15 @@ func NewModelWithRepo(r repo.TaskRepository) Model {
 	return m
 }
 
+// WithClock makes the model read the time from clk and reloads the views
+// that depend on it.
+func (m Model) WithClock(clk timeutil.Clock) Model {
+	m.now = func() time.Time { return clk.Now().Local() }
+	m.clipUseCase.Clock = clk
+	m.reload()
+	return m
+}
+
 func (m Model) WithAIParser(parser *usecase.AIParseTaskUseCase) Model {
 	m.clipUseCase.AIParser = parser
 	return m
@@ -131,6 +137,11 @@ func NewModelWithQuery(uc usecase.NavQueryUseCase) Model {
 	return m
 }
 
+// workflow is the one the nav views list statuses from.
+func (m Model) workflow() domain.Workflow {
+	return m.queryUseCase.Workflow
+}
+
 func (m Model) Init() tea.Cmd {
 	return nil
 }
@@ -156,6 +167,10 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.handleAIInputKey(msg)
 			return m, nil
 		}
+		if m.confirmSubtasks != nil {
+			m.handleSubtaskConfirmKey(msg)
+			return m, nil
+		}
 		if m.inputMode != inputNone {
 			m.handleInputKey(msg)
 			return m, nil
@@ -163,6 +178,12 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		switch msg.String() {
 		case KeyHelp:
 			m.showHelp = true
+		case KeySearch:
+			m.beginInput(inputSearch, m.filter, "")
+		case KeyEsc:
+			if m.filter != "" {
+				m.clearFilter()
+			}
 		case KeyAISpace, " ":
 			m.beginAIInput()
 		case KeyQuit:
@@ -196,31 +217,40 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				if !ok {
 					return m, nil
 				}
-				if row.Kind == navRowProject {
+				switch row.Kind {
+				case navRowProject:
 					m.activeView = domain.ViewProject
 					m.project = row.Project
-				} else {
+				case navRowTag:
+					m.activeView = domain.ViewTag
+					m.tag = row.Tag
+				default:
 					m.activeView = row.View
 					if row.View == domain.ViewProject && m.project == "" && len(m.projects) > 0 {
 						m.project = m.projects[0]
 					}
+					if row.View == domain.ViewTag && m.tag == "" && len(m.tags) > 0 {
+						m.tag = m.tags[0]
+					}
 				}
 				m.listCursor = 0
+				m.filter = ""
 				m.reload()
 			}
 		case KeyToggleDone:
-			if m.activeView == domain.ViewProject {
+			if m.activeView == domain.ViewProject || m.activeView == domain.ViewTag {
 				m.showDone = !m.showDone
+				scope := string(m.activeView)
 				if m.showDone {
-					m.statusMsg = "project: showing done tasks"
+					m.statusMsg = scope + ": showing done tasks"
 				} else {
-					m.statusMsg = "project: hiding done tasks"
+					m.statusMsg = scope + ": hiding done tasks"
 				}
 				m.reload()
 			}
 		case KeyClipAdd:
 			if m.clipUseCase.Repo != nil {
-				_, err := m.clipUseCase.AddFromClipboard(context.Background(), "", true)
+				_, err := m.clipUseCase.AddFromClipboard(tuiContext(), "", true)
 				if err != nil {
 					m.statusMsg = fmt.Sprintf("ai parse failed: %v", err)
 				} else {
@@ -230,7 +260,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		case KeyClipAddAI:
 			if m.clipUseCase.Repo != nil {
-				_, err := m.clipUseCase.AddFromClipboard(context.Background(), "", true)
+				_, err := m.clipUseCase.AddFromClipboard(tuiContext(), "", true)
 				if err != nil {
 					m.statusMsg = fmt.Sprintf("ai parse failed: %v", err)
 				} else {
@@ -262,6 +292,32 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 				m.beginInput(inputDue, initial, "")
 			}
+		case KeyDefer:
+			if task, ok := m.currentTaskForAction(); ok {
+				initial := ""
+				if task.StartAt != nil {
+					initial = formatDue(task.StartAt, m.now().Location())
+				}
+				m.beginInput(inputDefer, initial, "")
+			}
+		case KeyWait:
+			if task, ok := m.currentTaskForAction(); ok {
+				m.beginInput(inputWait, formatWaitInput(task, m.now().Location()), "")
+			}
+		case KeyMove:
+			if task, ok := m.currentTaskForAction(); ok {
+				m.beginInput(inputMove, "", strings.Join(statusTargets(m.workflow(), task.Status), " / "))
+			}
+		case KeySnooze:
+			if _, ok := m.currentTaskForAction(); ok {
+				m.beginInput(inputSnooze, usecase.DefaultSnooze, "")
+			}
+		case KeySnoozeAll:
+			if ids := m.overdueTaskIDs(); len(ids) > 0 {
+				m.beginInput(inputSnoozeOverdue, usecase.DefaultSnooze, strconv.Itoa(len(ids)))
+			} else {
+				m.statusMsg = "no overdue task in this view"
+			}
 		case KeyPriority:
 			if task, ok := m.currentTaskForAction(); ok {
 				initial := domain.NormalizePriority(task.Priority)
@@ -273,7 +329,9 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			m.removeCurrentTask()
 		case KeyUndo:
-			m.undoLastDelete()
+			m.undoLastOperation()
+		case KeyRedo:
+			m.redoLastOperation()
 		case KeyComplete:
 			if m.focus == focusNav {
 				if m.tryCompleteProjectFromNav() {
@@ -314,7 +372,7 @@ func (m Model) View() string {
 		m.now(),
 		m.width,
 	)
-	footer := renderFooter(statusLine, m.focus, m.width, m.activeView)
+	footer := renderFooter(statusLine, m.focus, m.width, m.activeView, m.filter)
 	if m.inputMode != inputNone {
 		footer = renderInputFooter(statusLine, m.width)
 	}
@@ -326,8 +384,8 @@ func (m Model) View() string {
 	navRows := m.navRows()
 	m.clampNavIndex()
 	navWidth, listWidth, gap := bodyPaneWidths(m.width)
-	left := renderNav(navRows, m.navIndex, m.activeView, m.project, m.focus == focusNav, navWidth, bodyHeight)
-	right := renderList(m.tasks, m.listCursor, m.focus == focusList, listWidth, bodyHeight, m.activeView, m.now().Location())
+	left := renderNav(navRows, m.navIndex, m.activeView, m.project, m.tag, m.focus == focusNav, navWidth, bodyHeight)
+	right := renderList(m.tasks, m.listCursor, m.focus == focusList, listWidth, bodyHeight, m.activeView, m.workflow(), m.now())
 	left = fitPaneHeight(left, bodyHeight)
 	right = fitPaneHeight(right, bodyHeight)
 	body := joinColumns(left, right, navWidth, listWidth, gap)
@@ -340,12 +398,12 @@ func (m Model) View() string {
 	}
 	if m.showAIInput {
 		dimmed := renderDimmedPage(page, m.width, m.height)
-		modal := renderAIInputModal(m.width, m.aiInputValue, m.aiInputCursor)
+		modal := renderAIInputModal(m.width, m.aiInputValue, m.aiInputCursor, m.aiMulti)
 		return overlayCentered(dimmed, modal, m.width, m.height)
 	}
 	if m.showAIPreview {
 		dimmed := renderDimmedPage(page, m.width, m.height)
-		modal := renderAIPreviewModal(m.width, m.aiPreview, m.aiSource)
+		modal := renderAIPreviewModal(m.width, m.aiPreview, m.aiAccepted, m.aiPreviewIndex, m.aiSource)
 		return overlayCentered(dimmed, modal, m.width, m.height)
 	}
 	return page
@@ -360,13 +418,22 @@ func (m *Model) reload() {
 		return
 	}
 	m.refreshProjects()
-	tasks, err := m.queryUseCase.ListByView(
-		context.Background(),
-		m.activeView,
-		m.now(),
-		m.project,
-		m.activeView == domain.ViewProject && m.showDone,
+	m.refreshTags()
+	var (
+		tasks []domain.Task
+		err   error
 	)
+	if m.activeView == domain.ViewTag {
+		tasks, err = m.queryUseCase.ListByTag(tuiContext(), m.tag, m.showDone)
+	} else {
+		tasks, err = m.queryUseCase.ListByView(
+			tuiContext(),
+			m.activeView,
+			m.now(),
+			m.project,
+			m.activeView == domain.ViewProject && m.showDone,
+		)
+	}
 	if err != nil {
 		m.tasks = nil
 		m.todayDone = 0
@@ -374,7 +441,7 @@ func (m *Model) reload() {
 		m.statusMsg = fmt.Sprintf("load failed: %v", err)
 		return
 	}
-	m.tasks = tasks
+	m.tasks, _ = domain.NestSubtasks(m.applyFilter(tasks))
 	if len(m.tasks) == 0 {
 		m.listCursor = 0
 	} else {
@@ -388,91 +455,110 @@ func (m *Model) reload() {
 	m.refreshTodayProgress()
 }
 
+// applyFilter keeps the tasks matching the / filter, which uses the td ls
+// query syntax. Statuses default to every status so the view decides them.
+func (m *Model) applyFilter(tasks []domain.Task) []domain.Task {
+	if strings.TrimSpace(m.filter) == "" {
+		return tasks
+	}
+	q, err := query.Parse(m.filter, m.now(), m.workflow())
+	if err != nil {
+		return tasks
+	}
+	if len(q.Statuses) == 0 {
+		q.Statuses = m.workflow().Statuses()
+	}
+	out := make([]domain.Task, 0, len(tasks))
+	for _, task := range tasks {
+		if q.Match(task) {
+			out = append(out, task)
+		}
+	}
+	return out
+}
+
+func (m *Model) clearFilter() {
+	m.filter = ""
+	m.filterErr = nil
+	m.statusMsg = "filter cleared"
+	m.reload()
+}
+
 func (m *Model) refreshTodayProgress() {
+	m.todayDone = 0
+	m.todayTotal = 0
+	m.metricTodo = 0
+	m.metricDoing = 0
+	m.metricDone = 0
+	m.metricOver = 0
 	if m.queryUseCase.Repo == nil {
-		m.todayDone = 0
-		m.todayTotal = 0
-		m.metricTodo = 0
-		m.metricDoing = 0
-		m.metricDone = 0
-		m.metricOver = 0
 		return
 	}
-	all, err := m.queryUseCase.Repo.List(context.Background(), repo.TaskListFilter{})
+	metrics, err := loadTaskMetrics(tuiContext(), m.queryUseCase.Repo, m.workflow(), m.now())
 	if err != nil {
-		m.todayDone = 0
-		m.todayTotal = 0
-		m.metricTodo = 0
-		m.metricDoing = 0
-		m.metricDone = 0
-		m.metricOver = 0
 		return
 	}
-	now := m.now()
-	loc := now.Location()
-	done := 0
-	total := 0
-	todoCount := 0
-	doingCount := 0
-	doneCount := 0
-	overdueCount := 0
-	for _, task := range all {
-		switch task.Status {
-		case domain.StatusTodo:
-			todoCount++
-		case domain.StatusDoing:
-			doingCount++
-		case domain.StatusDone:
-			doneCount++
-		}
-		if task.DueAt != nil {
-			due := task.DueAt.In(loc)
-			if due.Before(now) && (task.Status == domain.StatusInbox || task.Status == domain.StatusTodo || task.Status == domain.StatusDoing) {
-				overdueCount++
-			}
-		}
-		if !isTodayProgressTask(task, now) {
-			continue
-		}
-		total++
-		if task.Status == domain.StatusDone {
-			done++
-		}
-	}
-	m.todayDone = done
-	m.todayTotal = total
-	m.metricTodo = todoCount
-	m.metricDoing = doingCount
-	m.metricDone = doneCount
-	m.metricOver = overdueCount
+	m.todayDone = metrics.todayDone
+	m.todayTotal = metrics.todayTotal
+	m.metricTodo = metrics.todo
+	m.metricDoing = metrics.doing
+	m.metricDone = metrics.done
+	m.metricOver = metrics.overdue
+}
+
+type taskMetrics struct {
+	todo       int
+	doing      int
+	done       int
+	overdue    int
+	todayDone  int
+	todayTotal int
 }
 
-func isTodayProgressTask(task domain.Task, now time.Time) bool {
-	loc := now.Location()
+// loadTaskMetrics counts header metrics in SQL. Today progress covers
+// unblocked doing tasks, unblocked tasks in the other open statuses of
+// Today due before the end of the local day and tasks finished during the
+// local day.
+func loadTaskMetrics(ctx context.Context, r repo.TaskRepository, workflow domain.Workflow, now time.Time) (taskMetrics, error) {
 	dayStart := startOfDay(now)
 	dayEnd := dayStart.Add(24 * time.Hour)
-
-	switch task.Status {
-	case domain.StatusDoing:
-		return true
-	case domain.StatusDone:
-		if task.DoneAt == nil {
-			return false
-		}
-		done := task.DoneAt.In(loc)
-		return !done.Before(dayStart) && done.Before(dayEnd)
-	case domain.StatusTodo:
-		if task.DueAt == nil {
-			return false
-		}
-		due := task.DueAt.In(loc)
-		if due.Before(dayStart) {
-			return true
+	var out taskMetrics
+	var todayDoing, todayTodo int
+	counts := []struct {
+		dst    *int
+		filter repo.TaskListFilter
+	}{
+		{&out.todo, repo.TaskListFilter{Statuses: []domain.Status{domain.StatusTodo}}},
+		{&out.doing, repo.TaskListFilter{Statuses: []domain.Status{domain.StatusDoing}}},
+		{&out.done, repo.TaskListFilter{Statuses: workflow.ClosedStatuses()}},
+		{&out.overdue, repo.TaskListFilter{
+			Statuses: workflow.OpenStatuses(),
+			DueTo:    &now,
+		}},
+		{&out.todayDone, repo.TaskListFilter{
+			Statuses: workflow.ClosedStatuses(),
+			DoneFrom: &dayStart,
+			DoneTo:   &dayEnd,
+		}},
+		{&todayDoing, repo.TaskListFilter{
+			Statuses:  []domain.Status{domain.StatusDoing},
+			Unblocked: true,
+		}},
+		{&todayTodo, repo.TaskListFilter{
+			Statuses:  usecase.TodayDueStatuses(workflow),
+			DueTo:     &dayEnd,
+			Unblocked: true,
+		}},
+	}
+	for _, item := range counts {
+		n, err := r.Count(ctx, item.filter)
+		if err != nil {
+			return taskMetrics{}, err
 		}
-		return !due.Before(dayStart) && due.Before(dayEnd)
-	default:
-		return false
+		*item.dst = n
 	}
+	out.todayTotal = todayDoing + out.todayDone + todayTodo
+	return out, nil
 }
 
 func startOfDay(t time.Time) time.Time {
@@ -485,14 +571,14 @@ func (m *Model) refreshProjects() {
 		return
 	}
 	uc := usecase.ProjectUseCase{Repo: m.queryUseCase.Repo}
-	projects, err := uc.List(context.Background())
+	projects, err := uc.List(tuiContext())
 	if err != nil {
 		m.projects = nil
 		m.statusMsg = fmt.Sprintf("load projects failed: %v", err)
 		return
 	}
 	m.projects = projects
-	if m.project != "" && !containsProjectName(projects, m.project) {
+	if m.project != "" && !containsName(projects, m.project) {
 		m.project = ""
 	}
 	if m.activeView == domain.ViewProject && m.project == "" && len(projects) > 0 {
@@ -501,8 +587,30 @@ func (m *Model) refreshProjects() {
 	m.clampNavIndex()
 }
 
+func (m *Model) refreshTags() {
+	if m.queryUseCase.Repo == nil {
+		m.tags = nil
+		return
+	}
+	uc := usecase.TagUseCase{Repo: m.queryUseCase.Repo}
+	tags, err := uc.List(tuiContext())
+	if err != nil {
+		m.tags = nil
+		m.statusMsg = fmt.Sprintf("load tags failed: %v", err)
+		return
+	}
+	m.tags = tags
+	if m.tag != "" && !containsName(tags, m.tag) {
+		m.tag = ""
+	}
+	if m.activeView == domain.ViewTag && m.tag == "" && len(tags) > 0 {
+		m.tag = tags[0]
+	}
+	m.clampNavIndex()
+}
+
 func (m Model) navRows() []navRow {
-	return buildNavRows(m.navItems, m.projects)
+	return buildNavRows(m.navItems, m.projects, m.tags)
 }
 
 func (m *Model) clampNavIndex() {
@@ -565,25 +673,11 @@ func (m *Model) tryDeleteProjectFromNav() bool {
 		m.statusMsg = "select a project"
 		return true
 	}
-	projectTasks, err := m.queryUseCase.Repo.List(context.Background(), repo.TaskListFilter{Project: row.Project})
-	if err != nil {
-		m.statusMsg = fmt.Sprintf("load project tasks failed: %v", err)
-		return true
-	}
-	ids := make([]int64, 0, len(projectTasks))
-	for _, task := range projectTasks {
-		ids = append(ids, task.ID)
-	}
 	uc := usecase.ProjectUseCase{Repo: m.queryUseCase.Repo}
-	if err := uc.Delete(context.Background(), row.Project); err != nil {
+	if err := uc.Delete(tuiContext(), row.Project); err != nil {
 		m.statusMsg = fmt.Sprintf("delete project failed: %v", err)
 		return true
 	}
-	m.pushUndo(undoAction{
-		kind:    undoProjectDelete,
-		project: row.Project,
-		taskIDs: ids,
-	})
 	if m.project == row.Project {
 		m.project = ""
 	}
@@ -604,39 +698,30 @@ func (m *Model) tryCompleteProjectFromNav() bool {
 		m.statusMsg = "select a project"
 		return true
 	}
-	tasks, err := m.queryUseCase.Repo.List(context.Background(), repo.TaskListFilter{Project: row.Project})
+	tasks, err := m.queryUseCase.Repo.List(tuiContext(), repo.TaskListFilter{
+		Project:  row.Project,
+		Statuses: m.workflow().OpenStatuses(),
+	})
 	if err != nil {
 		m.statusMsg = fmt.Sprintf("load project tasks failed: %v", err)
 		return true
 	}
 	ids := make([]int64, 0, len(tasks))
-	changes := make([]taskStatusChange, 0, len(tasks))
 	for _, task := range tasks {
-		if task.Status == domain.StatusInbox || task.Status == domain.StatusTodo || task.Status == domain.StatusDoing {
-			ids = append(ids, task.ID)
-			changes = append(changes, taskStatusChange{
-				taskID: task.ID,
-				from:   task.Status,
-				to:     domain.StatusDone,
-			})
-		}
+		ids = append(ids, task.ID)
 	}
 	if len(ids) == 0 {
 		m.statusMsg = fmt.Sprintf("project %s has no open task", row.Project)
 		return true
 	}
 
-	uc := usecase.UpdateTaskUseCase{Repo: m.queryUseCase.Repo}
-	err = uc.MarkDone(context.Background(), ids)
+	uc := usecase.UpdateTaskUseCase{Repo: m.queryUseCase.Repo, Workflow: m.workflow()}
+	unblocked, err := uc.MarkDone(tuiContext(), ids)
 	if err != nil {
 		m.statusMsg = fmt.Sprintf("project done failed: %v", err)
 		return true
 	}
-	m.pushUndo(undoAction{
-		kind:          undoTaskStatus,
-		statusChanges: changes,
-	})
-	m.statusMsg = fmt.Sprintf("done %d task(s) in %s (z undo)", len(ids), row.Project)
+	m.statusMsg = fmt.Sprintf("done %d task(s) in %s (z undo)", len(ids), row.Project) + unblockedNotice(unblocked)
 	m.reload()
 	return true
 }
@@ -654,8 +739,8 @@ func (m *Model) focusProjectRow(name string) {
 	}
 }
 
-func containsProjectName(projects []string, name string) bool {
-	for _, item := range projects {
+func containsName(items []string, name string) bool {
+	for _, item := range items {
 		if item == name {
 			return true
 		}
@@ -663,6 +748,18 @@ func containsProjectName(projects []string, name string) bool {
 	return false
 }
 
+// overdueTaskIDs lists the open tasks in the view whose due time has passed.
+func (m Model) overdueTaskIDs() []int64 {
+	now := m.now()
+	var ids []int64
+	for _, task := range m.tasks {
+		if m.workflow().IsOpen(task.Status) && task.DueAt != nil && task.DueAt.Before(now) {
+			ids = append(ids, task.ID)
+		}
+	}
+	return ids
+}
+
 func (m *Model) beginInput(mode inputMode, initial, target string) {
 	m.inputMode = mode
 	m.inputValue = initial
@@ -702,6 +799,10 @@ func (m *Model) handleInputKey(msg tea.KeyMsg) {
 	if m.inputMode == inputTaskProject && m.handleTaskProjectInputKey(msg) {
 		return
 	}
+	if m.inputMode == inputSearch {
+		m.handleSearchInputKey(msg)
+		return
+	}
 
 	switch msg.Type {
 	case tea.KeyEsc:
@@ -740,6 +841,53 @@ func (m *Model) handleInputKey(msg tea.KeyMsg) {
 	}
 }
 
+// handleSearchInputKey edits the / filter and re-filters the list after
+// every key; an unfinished expression keeps the last result. Enter keeps
+// the filter, Esc clears it.
+func (m *Model) handleSearchInputKey(msg tea.KeyMsg) {
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.endInput()
+		m.clearFilter()
+		return
+	case tea.KeyEnter:
+		m.endInput()
+		switch {
+		case m.filterErr != nil:
+			m.statusMsg = m.filterErr.Error()
+			m.filterErr = nil
+		case m.filter != "":
+			m.statusMsg = fmt.Sprintf("%d match(es)", len(m.tasks))
+		}
+		return
+	case tea.KeyLeft, tea.KeyCtrlB:
+		m.moveInputCursor(-1)
+		return
+	case tea.KeyRight, tea.KeyCtrlF:
+		m.moveInputCursor(1)
+		return
+	case tea.KeyBackspace, tea.KeyCtrlH:
+		m.deleteInputRuneBeforeCursor()
+	case tea.KeySpace:
+		m.insertInputText(" ")
+	case tea.KeyRunes:
+		if len(msg.Runes) == 0 {
+			return
+		}
+		m.insertInputText(string(msg.Runes))
+	default:
+		return
+	}
+	m.filterErr = nil
+	if _, err := query.Parse(m.inputValue, m.now(), m.workflow()); err != nil {
+		m.filterErr = err
+		return
+	}
+	m.filter = strings.TrimSpace(m.inputValue)
+	m.listCursor = 0
+	m.reload()
+}
+
 func (m *Model) handleTaskProjectInputKey(msg tea.KeyMsg) bool {
 	if !m.projectSelectMode {
 		switch msg.Type {
@@ -823,20 +971,28 @@ func (m *Model) submitInput() {
 			m.endInput()
 			return
 		}
-		uc := usecase.AddTaskUseCase{Repo: repo}
-		in := usecase.AddTaskInput{Title: text}
-		if m.activeView == domain.ViewProject && m.project != "" {
+		in, err := usecase.ParseQuickAdd(text, timeutil.ClockFunc(m.now), m.now().Location())
+		if err != nil {
+			m.statusMsg = fmt.Sprintf("add failed: %v", err)
+			m.endInput()
+			return
+		}
+		if m.activeView == domain.ViewProject && m.project != "" && in.Project == "" {
 			in.Project = m.project
 		}
-		task, err := uc.Execute(context.Background(), in)
+		if m.activeView == domain.ViewTag && m.tag != "" {
+			in.Tags = append(in.Tags, m.tag)
+		}
+		uc := usecase.AddTaskUseCase{Repo: repo}
+		task, err := uc.Execute(tuiContext(), in)
 		if err != nil {
 			m.statusMsg = fmt.Sprintf("add failed: %v", err)
 			m.endInput()
 			return
 		}
 		if m.activeView == domain.ViewToday {
-			uu := usecase.UpdateTaskUseCase{Repo: repo}
-			if err := uu.MarkToday(context.Background(), []int64{task.ID}); err != nil {
+			uu := usecase.UpdateTaskUseCase{Repo: repo, Workflow: m.workflow()}
+			if err := uu.MarkToday(tuiContext(), []int64{task.ID}); err != nil {
 				m.statusMsg = fmt.Sprintf("set today failed: %v", err)
 				m.endInput()
 				return
@@ -854,8 +1010,8 @@ func (m *Model) submitInput() {
 			m.endInput()
 			return
 		}
-		uc := usecase.UpdateTaskUseCase{Repo: repo}
-		if err := uc.EditTitle(context.Background(), task.ID, text); err != nil {
+		uc := usecase.UpdateTaskUseCase{Repo: repo, Workflow: m.workflow()}
+		if err := uc.EditTitle(tuiContext(), task.ID, text); err != nil {
 			m.statusMsg = fmt.Sprintf("edit failed: %v", err)
 			m.endInput()
 			return
@@ -876,8 +1032,8 @@ func (m *Model) submitInput() {
 				projectText = selected
 			}
 		}
-		uc := usecase.UpdateTaskUseCase{Repo: repo}
-		if err := uc.SetProject(context.Background(), task.ID, projectText); err != nil {
+		uc := usecase.UpdateTaskUseCase{Repo: repo, Workflow: m.workflow()}
+		if err := uc.SetProject(tuiContext(), task.ID, projectText); err != nil {
 			m.statusMsg = fmt.Sprintf("set project failed: %v", err)
 			m.endInput()
 			return
@@ -896,15 +1052,15 @@ func (m *Model) submitInput() {
 		}
 		var dueAt *time.Time
 		if text != "" {
-			due, err := parseDueInputTUI(text, m.now().Location())
+			due, err := timeutil.ParseDue(text, timeutil.ClockFunc(m.now), m.now().Location())
 			if err != nil {
 				m.statusMsg = err.Error()
 				return
 			}
 			dueAt = &due
 		}
-		uc := usecase.UpdateTaskUseCase{Repo: repo}
-		if err := uc.SetDueAt(context.Background(), task.ID, dueAt); err != nil {
+		uc := usecase.UpdateTaskUseCase{Repo: repo, Workflow: m.workflow()}
+		if err := uc.SetDueAt(tuiContext(), task.ID, dueAt); err != nil {
 			m.statusMsg = fmt.Sprintf("set due failed: %v", err)
 			m.endInput()
 			return
@@ -914,6 +1070,109 @@ func (m *Model) submitInput() {
 		} else {
 			m.statusMsg = fmt.Sprintf("due #%d updated", task.ID)
 		}
+	case inputDefer:
+		task, ok := m.currentTaskForAction()
+		if !ok {
+			m.endInput()
+			return
+		}
+		var startAt *time.Time
+		if text != "" {
+			start, err := timeutil.ParseStart(text, timeutil.ClockFunc(m.now), m.now().Location())
+			if err != nil {
+				m.statusMsg = err.Error()
+				return
+			}
+			startAt = &start
+		}
+		uc := usecase.UpdateTaskUseCase{Repo: repo, Workflow: m.workflow()}
+		if err := uc.SetStartAt(tuiContext(), task.ID, startAt); err != nil {
+			m.statusMsg = fmt.Sprintf("defer failed: %v", err)
+			m.endInput()
+			return
+		}
+		if startAt == nil {
+			m.statusMsg = fmt.Sprintf("cleared start #%d", task.ID)
+		} else {
+			m.statusMsg = fmt.Sprintf("deferred #%d until %s", task.ID, formatDue(startAt, m.now().Location()))
+		}
+	case inputWait:
+		task, ok := m.currentTaskForAction()
+		if !ok {
+			m.endInput()
+			return
+		}
+		on, followUpAt, err := parseWaitInput(text, timeutil.ClockFunc(m.now), m.now().Location())
+		if err != nil {
+			m.statusMsg = err.Error()
+			return
+		}
+		uc := usecase.UpdateTaskUseCase{Repo: repo, Workflow: m.workflow()}
+		if err := uc.Wait(tuiContext(), task.ID, on, followUpAt); err != nil {
+			m.statusMsg = fmt.Sprintf("wait failed: %v", err)
+			m.endInput()
+			return
+		}
+		m.statusMsg = fmt.Sprintf("waiting #%d", task.ID)
+		if on != "" {
+			m.statusMsg += " on " + on
+		}
+	case inputMove:
+		task, ok := m.currentTaskForAction()
+		if !ok {
+			m.endInput()
+			return
+		}
+		status, err := m.workflow().ParseStatus(strings.ToLower(text))
+		if err != nil {
+			m.statusMsg = fmt.Sprintf("unknown status %q, use %s", text, m.inputTarget)
+			return
+		}
+		uc := usecase.UpdateTaskUseCase{Repo: repo, Workflow: m.workflow()}
+		if err := uc.Transit(tuiContext(), []int64{task.ID}, status); err != nil {
+			m.statusMsg = fmt.Sprintf("move failed: %v", err)
+			m.endInput()
+			return
+		}
+		m.statusMsg = fmt.Sprintf("%s #%d", status, task.ID)
+	case inputSnooze:
+		task, ok := m.currentTaskForAction()
+		if !ok {
+			m.endInput()
+			return
+		}
+		snooze, err := usecase.ParseSnooze(text, timeutil.ClockFunc(m.now), m.now().Location())
+		if err != nil {
+			m.statusMsg = err.Error()
+			return
+		}
+		uc := usecase.UpdateTaskUseCase{Repo: repo, Workflow: m.workflow()}
+		tasks, err := uc.Snooze(tuiContext(), []int64{task.ID}, snooze, m.now())
+		if err != nil {
+			m.statusMsg = fmt.Sprintf("snooze failed: %v", err)
+			m.endInput()
+			return
+		}
+		m.statusMsg = fmt.Sprintf("snoozed #%d to %s", task.ID, formatDue(tasks[0].DueAt, m.now().Location()))
+	case inputSnoozeOverdue:
+		ids := m.overdueTaskIDs()
+		if len(ids) == 0 {
+			m.statusMsg = "no overdue task in this view"
+			m.endInput()
+			return
+		}
+		snooze, err := usecase.ParseSnooze(text, timeutil.ClockFunc(m.now), m.now().Location())
+		if err != nil {
+			m.statusMsg = err.Error()
+			return
+		}
+		uc := usecase.UpdateTaskUseCase{Repo: repo, Workflow: m.workflow()}
+		if _, err := uc.Snooze(tuiContext(), ids, snooze, m.now()); err != nil {
+			m.statusMsg = fmt.Sprintf("snooze failed: %v", err)
+			m.endInput()
+			return
+		}
+		m.statusMsg = fmt.Sprintf("snoozed %d overdue task(s)", len(ids))
 	case inputPriority:
 		task, ok := m.currentTaskForAction()
 		if !ok {
@@ -925,8 +1184,8 @@ func (m *Model) submitInput() {
 			m.statusMsg = "invalid priority"
 			return
 		}
-		uc := usecase.UpdateTaskUseCase{Repo: repo}
-		if err := uc.SetPriority(context.Background(), task.ID, priority); err != nil {
+		uc := usecase.UpdateTaskUseCase{Repo: repo, Workflow: m.workflow()}
+		if err := uc.SetPriority(tuiContext(), task.ID, priority); err != nil {
 			m.statusMsg = fmt.Sprintf("set priority failed: %v", err)
 			m.endInput()
 			return
@@ -939,7 +1198,7 @@ func (m *Model) submitInput() {
 			return
 		}
 		uc := usecase.ProjectUseCase{Repo: repo}
-		if err := uc.Add(context.Background(), text); err != nil {
+		if err := uc.Add(tuiContext(), text); err != nil {
 			m.statusMsg = fmt.Sprintf("create project failed: %v", err)
 			m.endInput()
 			return
@@ -961,7 +1220,7 @@ func (m *Model) submitInput() {
 			return
 		}
 		uc := usecase.ProjectUseCase{Repo: repo}
-		if err := uc.Rename(context.Background(), oldName, text); err != nil {
+		if err := uc.Rename(tuiContext(), oldName, text); err != nil {
 			m.statusMsg = fmt.Sprintf("rename project failed: %v", err)
 			m.endInput()
 			return
@@ -994,15 +1253,11 @@ func (m *Model) removeCurrentTask() {
 	if !ok {
 		return
 	}
-	uc := usecase.UpdateTaskUseCase{Repo: m.queryUseCase.Repo}
-	if err := uc.Remove(context.Background(), []int64{task.ID}); err != nil {
+	uc := usecase.UpdateTaskUseCase{Repo: m.queryUseCase.Repo, Workflow: m.workflow()}
+	if err := uc.Remove(tuiContext(), []int64{task.ID}); err != nil {
 		m.statusMsg = fmt.Sprintf("delete failed: %v", err)
 		return
 	}
-	m.pushUndo(undoAction{
-		kind:    undoTaskDelete,
-		taskIDs: []int64{task.ID},
-	})
 	m.statusMsg = fmt.Sprintf("deleted #%d (z undo)", task.ID)
 	m.reload()
 	if m.listCursor >= len(m.tasks) && m.listCursor > 0 {
@@ -1015,8 +1270,8 @@ func (m *Model) restoreCurrentTrashTask() {
 	if !ok {
 		return
 	}
-	uc := usecase.UpdateTaskUseCase{Repo: m.queryUseCase.Repo}
-	if err := uc.Restore(context.Background(), []int64{task.ID}); err != nil {
+	uc := usecase.UpdateTaskUseCase{Repo: m.queryUseCase.Repo, Workflow: m.workflow()}
+	if err := uc.Restore(tuiContext(), []int64{task.ID}); err != nil {
 		m.statusMsg = fmt.Sprintf("restore failed: %v", err)
 		return
 	}
@@ -1036,8 +1291,8 @@ func (m *Model) purgeTrashAll() {
 	for _, task := range m.tasks {
 		ids = append(ids, task.ID)
 	}
-	uc := usecase.UpdateTaskUseCase{Repo: m.queryUseCase.Repo}
-	if err := uc.Purge(context.Background(), ids); err != nil {
+	uc := usecase.UpdateTaskUseCase{Repo: m.queryUseCase.Repo, Workflow: m.workflow()}
+	if err := uc.Purge(tuiContext(), ids); err != nil {
 		m.statusMsg = fmt.Sprintf("purge failed: %v", err)
 		return
 	}
@@ -1045,16 +1300,15 @@ func (m *Model) purgeTrashAll() {
 	m.reload()
 }
 
-func (m *Model) pushUndo(action undoAction) {
-	m.undoStack = append(m.undoStack, action)
-}
-
 func (m *Model) beginAIInput() {
 	m.showAIInput = true
 	m.showAIPreview = false
 	m.aiInputValue = ""
 	m.aiInputCursor = 0
-	m.aiPreview = clipboard.ParsedTask{}
+	m.aiMulti = false
+	m.aiPreview = nil
+	m.aiAccepted = nil
+	m.aiPreviewIndex = 0
 	m.aiPreviewRaw = ""
 	m.aiSource = ""
 }
@@ -1079,6 +1333,8 @@ func (m *Model) handleAIInputKey(msg tea.KeyMsg) {
 		m.closeAIInput("ai input cancelled")
 	case tea.KeyEnter:
 		m.submitAIInputPreview()
+	case tea.KeyTab:
+		m.aiMulti = !m.aiMulti
 	case tea.KeyLeft, tea.KeyCtrlB:
 		m.moveAIInputCursor(-1)
 	case tea.KeyRight, tea.KeyCtrlF:
@@ -1119,7 +1375,19 @@ func (m *Model) submitAIInputPreview() {
 		m.statusMsg = "ai text is empty"
 		return
 	}
-	parsed, source, err := m.clipUseCase.ParseInput(context.Background(), text, true)
+	// One task unless multi is toggled on, like td add --multi.
+	var (
+		parsed []clipboard.ParsedTask
+		source string
+		err    error
+	)
+	if m.aiMulti {
+		parsed, source, err = m.clipUseCase.ParseInputs(tuiContext(), text, true)
+	} else {
+		var task clipboard.ParsedTask
+		task, source, err = m.clipUseCase.ParseInput(tuiContext(), text, true)
+		parsed = []clipboard.ParsedTask{task}
+	}
 	if err != nil {
 		m.closeAIInput(fmt.Sprintf("ai parse failed: %v", err))
 		return
@@ -1127,6 +1395,11 @@ func (m *Model) submitAIInputPreview() {
 	m.showAIInput = false
 	m.showAIPreview = true
 	m.aiPreview = parsed
+	m.aiAccepted = make([]bool, len(parsed))
+	for i := range m.aiAccepted {
+		m.aiAccepted[i] = true
+	}
+	m.aiPreviewIndex = 0
 	m.aiPreviewRaw = text
 	m.aiSource = source
 }
@@ -1142,6 +1415,18 @@ func (m *Model) handleAIPreviewKey(msg tea.KeyMsg) {
 		m.aiInputCursor = len([]rune(m.aiInputValue))
 	case KeySelect:
 		m.confirmAIPreviewCreate()
+	case KeyDown, "down":
+		if m.aiPreviewIndex < len(m.aiPreview)-1 {
+			m.aiPreviewIndex++
+		}
+	case KeyUp, "up":
+		if m.aiPreviewIndex > 0 {
+			m.aiPreviewIndex--
+		}
+	case " ", "space", KeyDelete:
+		if len(m.aiPreview) > 1 {
+			m.aiAccepted[m.aiPreviewIndex] = !m.aiAccepted[m.aiPreviewIndex]
+		}
 	}
 }
 
@@ -1150,77 +1435,125 @@ func (m *Model) confirmAIPreviewCreate() {
 		m.closeAIPreview("repo not ready")
 		return
 	}
-	task, err := m.clipUseCase.CreateFromParsed(context.Background(), m.aiPreview)
+	var accepted []clipboard.ParsedTask
+	for i, task := range m.aiPreview {
+		if m.aiAccepted[i] {
+			accepted = append(accepted, task)
+		}
+	}
+	if len(accepted) == 0 {
+		m.statusMsg = "no task selected"
+		return
+	}
+	ctx := tuiContext()
+	if m.aiSource == "ai" {
+		ctx = repo.WithSource(ctx, domain.SourceAI)
+	}
+	tasks, err := m.clipUseCase.CreateManyFromParsed(ctx, accepted)
 	if err != nil {
 		m.statusMsg = fmt.Sprintf("create failed: %v", err)
 		return
 	}
 	m.showAIPreview = false
+	parse := "fallback parse"
 	if m.aiSource == "ai" {
-		m.statusMsg = fmt.Sprintf("created #%d from ai parse", task.ID)
+		parse = "ai parse"
+	}
+	if len(tasks) == 1 {
+		m.statusMsg = fmt.Sprintf("created #%d from %s", tasks[0].ID, parse)
 	} else {
-		m.statusMsg = fmt.Sprintf("created #%d from fallback parse", task.ID)
+		m.statusMsg = fmt.Sprintf("created %d tasks from %s", len(tasks), parse)
 	}
 	m.reload()
 }
 
-func (m *Model) undoLastDelete() {
+func (m *Model) undoLastOperation() {
 	if m.queryUseCase.Repo == nil {
 		m.statusMsg = "repo not ready"
 		return
 	}
-	if len(m.undoStack) == 0 {
+	uc := usecase.UpdateTaskUseCase{Repo: m.queryUseCase.Repo, Workflow: m.workflow()}
+	op, err := uc.Undo(tuiContext())
+	if errors.Is(err, domain.ErrNothingToUndo) {
 		m.statusMsg = "nothing to undo"
 		return
 	}
-	idx := len(m.undoStack) - 1
-	action := m.undoStack[idx]
-	ctx := context.Background()
+	if err != nil {
+		m.statusMsg = fmt.Sprintf("undo failed: %v", err)
+		return
+	}
+	m.statusMsg = fmt.Sprintf("undid %s (Z redo)", op.Summary)
+	m.reloadAfterJournal()
+}
 
-	switch action.kind {
-	case undoTaskDelete:
-		uc := usecase.UpdateTaskUseCase{Repo: m.queryUseCase.Repo}
-		if err := uc.Restore(ctx, action.taskIDs); err != nil {
-			m.statusMsg = fmt.Sprintf("undo failed: %v", err)
-			return
-		}
-		m.undoStack = m.undoStack[:idx]
-		m.statusMsg = fmt.Sprintf("undid delete of %d task(s)", len(action.taskIDs))
-	case undoProjectDelete:
-		projectUC := usecase.ProjectUseCase{Repo: m.queryUseCase.Repo}
-		if err := projectUC.Add(ctx, action.project); err != nil {
-			m.statusMsg = fmt.Sprintf("undo failed: %v", err)
-			return
-		}
-		taskUC := usecase.UpdateTaskUseCase{Repo: m.queryUseCase.Repo}
-		for _, id := range action.taskIDs {
-			if err := taskUC.SetProject(ctx, id, action.project); err != nil {
-				m.statusMsg = fmt.Sprintf("undo failed: %v", err)
-				return
-			}
-		}
-		m.undoStack = m.undoStack[:idx]
-		m.statusMsg = fmt.Sprintf("undid delete project %s", action.project)
-	case undoTaskStatus:
-		uc := usecase.UpdateTaskUseCase{Repo: m.queryUseCase.Repo}
-		for i := len(action.statusChanges) - 1; i >= 0; i-- {
-			change := action.statusChanges[i]
-			if err := uc.SetStatus(ctx, change.taskID, change.from); err != nil {
-				m.statusMsg = fmt.Sprintf("undo failed: %v", err)
-				return
-			}
-		}
-		m.undoStack = m.undoStack[:idx]
-		m.statusMsg = fmt.Sprintf("undid status change of %d task(s)", len(action.statusChanges))
-	default:
-		m.undoStack = m.undoStack[:idx]
-		m.statusMsg = "nothing to undo"
+func (m *Model) redoLastOperation() {
+	if m.queryUseCase.Repo == nil {
+		m.statusMsg = "repo not ready"
+		return
+	}
+	uc := usecase.UpdateTaskUseCase{Repo: m.queryUseCase.Repo, Workflow: m.workflow()}
+	op, err := uc.Redo(tuiContext())
+	if errors.Is(err, domain.ErrNothingToRedo) {
+		m.statusMsg = "nothing to redo"
+		return
+	}
+	if err != nil {
+		m.statusMsg = fmt.Sprintf("redo failed: %v", err)
+		return
 	}
+	m.statusMsg = fmt.Sprintf("redid %s", op.Summary)
+	m.reloadAfterJournal()
+}
 
+// reloadAfterJournal refreshes the screen after undo or redo, keeping the
+// selected project row when the project still exists.
+func (m *Model) reloadAfterJournal() {
+	project := m.project
 	m.reload()
-	if action.kind == undoProjectDelete {
-		m.focusProjectRow(action.project)
+	if project != "" {
+		m.focusProjectRow(project)
+	}
+	if m.listCursor >= len(m.tasks) && m.listCursor > 0 {
+		m.listCursor = len(m.tasks) - 1
+	}
+}
+
+// statusTargets lists the statuses a task in from can move to, in sort
+// order.
+func statusTargets(workflow domain.Workflow, from domain.Status) []string {
+	out := make([]string, 0, 8)
+	for _, status := range workflow.Statuses() {
+		if status != from && workflow.CanTransit(from, status) {
+			out = append(out, string(status))
+		}
 	}
+	return out
+}
+
+// parseWaitInput splits "alice ^fri" into who the task waits on and the
+// follow-up time after ^, which starts bare days at 00:00 like defer.
+func parseWaitInput(text string, clk timeutil.Clock, loc *time.Location) (string, *time.Time, error) {
+	on, followUp, ok := strings.Cut(text, "^")
+	on = strings.TrimSpace(on)
+	if !ok || strings.TrimSpace(followUp) == "" {
+		return on, nil, nil
+	}
+	followUpAt, err := timeutil.ParseStart(followUp, clk, loc)
+	if err != nil {
+		return "", nil, err
+	}
+	return on, &followUpAt, nil
+}
+
+func formatWaitInput(task domain.Task, loc *time.Location) string {
+	if task.Status != domain.StatusWaiting {
+		return ""
+	}
+	out := task.WaitingOn
+	if task.FollowUpAt != nil {
+		out = strings.TrimSpace(out + " ^" + formatDue(task.FollowUpAt, loc))
+	}
+	return out
 }
 
 func (m *Model) markCurrentTaskToday() {
@@ -1228,32 +1561,20 @@ func (m *Model) markCurrentTaskToday() {
 	if !ok {
 		return
 	}
-	uc := usecase.UpdateTaskUseCase{Repo: m.queryUseCase.Repo}
-	target := domain.StatusDoing
+	uc := usecase.UpdateTaskUseCase{Repo: m.queryUseCase.Repo, Workflow: m.workflow()}
 	if task.Status == domain.StatusDoing {
-		target = domain.StatusTodo
-		if err := uc.Reopen(context.Background(), []int64{task.ID}); err != nil {
+		if err := uc.Reopen(tuiContext(), []int64{task.ID}); err != nil {
 			m.statusMsg = fmt.Sprintf("set todo failed: %v", err)
 			return
 		}
 		m.statusMsg = fmt.Sprintf("todo #%d", task.ID)
 	} else {
-		if err := uc.MarkToday(context.Background(), []int64{task.ID}); err != nil {
+		if err := uc.MarkToday(tuiContext(), []int64{task.ID}); err != nil {
 			m.statusMsg = fmt.Sprintf("set today failed: %v", err)
 			return
 		}
 		m.statusMsg = fmt.Sprintf("today #%d", task.ID)
 	}
-	if task.Status != target {
-		m.pushUndo(undoAction{
-			kind: undoTaskStatus,
-			statusChanges: []taskStatusChange{{
-				taskID: task.ID,
-				from:   task.Status,
-				to:     target,
-			}},
-		})
-	}
 	m.reload()
 }
 
@@ -1266,26 +1587,82 @@ func (m *Model) completeCurrentTask() {
 		m.statusMsg = fmt.Sprintf("already done #%d", task.ID)
 		return
 	}
-	uc := usecase.UpdateTaskUseCase{Repo: m.queryUseCase.Repo}
-	if err := uc.MarkDone(context.Background(), []int64{task.ID}); err != nil {
+	uc := usecase.UpdateTaskUseCase{Repo: m.queryUseCase.Repo, Workflow: m.workflow()}
+	if task.Subtasks.Total > 0 {
+		open, err := uc.OpenSubtasks(tuiContext(), task.ID)
+		if err != nil {
+			m.statusMsg = fmt.Sprintf("set done failed: %v", err)
+			return
+		}
+		if len(open) > 0 {
+			m.confirmTask = task
+			m.confirmSubtasks = open
+			m.statusMsg = fmt.Sprintf("#%d has %d open subtask(s), complete them too? y yes  n only this  esc cancel", task.ID, len(open))
+			return
+		}
+	}
+	m.finishCompleteTask(task, nil)
+}
+
+func (m *Model) handleSubtaskConfirmKey(msg tea.KeyMsg) {
+	task, subtasks := m.confirmTask, m.confirmSubtasks
+	m.confirmTask = domain.Task{}
+	m.confirmSubtasks = nil
+	switch msg.String() {
+	case "y", "Y":
+		m.finishCompleteTask(task, subtasks)
+	case "n", "N":
+		m.finishCompleteTask(task, nil)
+	default:
+		m.statusMsg = "done cancelled"
+	}
+}
+
+// finishCompleteTask marks task done together with subtasks, which must be
+// its open descendants, so a single undo reopens all of them.
+func (m *Model) finishCompleteTask(task domain.Task, subtasks []domain.Task) {
+	uc := usecase.UpdateTaskUseCase{Repo: m.queryUseCase.Repo, Workflow: m.workflow()}
+	var (
+		unblocked []domain.Task
+		err       error
+	)
+	if len(subtasks) > 0 {
+		unblocked, err = uc.MarkDoneWithSubtasks(tuiContext(), []int64{task.ID})
+	} else {
+		unblocked, err = uc.MarkDone(tuiContext(), []int64{task.ID})
+	}
+	if err != nil {
 		m.statusMsg = fmt.Sprintf("set done failed: %v", err)
 		return
 	}
-	m.pushUndo(undoAction{
-		kind: undoTaskStatus,
-		statusChanges: []taskStatusChange{{
-			taskID: task.ID,
-			from:   task.Status,
-			to:     domain.StatusDone,
-		}},
-	})
-	m.statusMsg = fmt.Sprintf("done #%d (z undo)", task.ID)
+	if len(subtasks) > 0 {
+		m.statusMsg = fmt.Sprintf("done #%d and %d subtask(s) (z undo)", task.ID, len(subtasks))
+	} else {
+		m.statusMsg = fmt.Sprintf("done #%d (z undo)", task.ID)
+	}
+	m.statusMsg += unblockedNotice(unblocked)
 	m.reload()
 	if m.listCursor >= len(m.tasks) && m.listCursor > 0 {
 		m.listCursor--
 	}
 }
 
+// tuiContext tags repository changes made from the TUI in task history.
+func tuiContext() context.Context {
+	return repo.WithSource(context.Background(), domain.SourceTUI)
+}
+
+func unblockedNotice(tasks []domain.Task) string {
+	if len(tasks) == 0 {
+		return ""
+	}
+	parts := make([]string, 0, len(tasks))
+	for _, task := range tasks {
+		parts = append(parts, fmt.Sprintf("#%d", task.ID))
+	}
+	return ", unblocked " + strings.Join(parts, " ")
+}
+
 func (m Model) inputPrompt() string {
 	switch m.inputMode {
 	case inputAdd:
@@ -1298,13 +1675,30 @@ func (m Model) inputPrompt() string {
 		}
 		return "project> " + renderCursorAt(m.inputValue, m.inputCursor)
 	case inputDue:
-		return "due(YYYY-MM-DD HH:MM)> " + renderCursorAt(m.inputValue, m.inputCursor)
+		return "due(YYYY-MM-DD HH:MM / tomorrow 9am)> " + renderCursorAt(m.inputValue, m.inputCursor)
+	case inputDefer:
+		return "defer until(YYYY-MM-DD / next monday, empty clears)> " + renderCursorAt(m.inputValue, m.inputCursor)
+	case inputMove:
+		return "move to(" + m.inputTarget + ")> " + renderCursorAt(m.inputValue, m.inputCursor)
+	case inputWait:
+		return "wait on(alice ^fri)> " + renderCursorAt(m.inputValue, m.inputCursor)
+	case inputSnooze:
+		return "snooze(2h / 1d / 2w / next week)> " + renderCursorAt(m.inputValue, m.inputCursor)
+	case inputSnoozeOverdue:
+		return "snooze " + m.inputTarget + " overdue(2h / 1d / 2w / next week)> " + renderCursorAt(m.inputValue, m.inputCursor)
 	case inputPriority:
 		return "priority(P1-P4)> " + renderCursorAt(m.inputValue, m.inputCursor)
 	case inputProjectCreate:
 		return "project add> " + renderCursorAt(m.inputValue, m.inputCursor)
 	case inputProjectRename:
 		return "project rename> " + renderCursorAt(m.inputValue, m.inputCursor)
+	case inputSearch:
+		prompt := "/" + renderCursorAt(m.inputValue, m.inputCursor)
+		var queryErr *query.Error
+		if errors.As(m.filterErr, &queryErr) {
+			prompt += "  (" + queryErr.Msg + ")"
+		}
+		return prompt
 	default:
 		return m.statusMsg
 	}
@@ -1432,30 +1826,3 @@ func deleteRuneBeforeCursor(base string, cursor int) (string, int) {
 	out = append(out, baseRunes[cursor:]...)
 	return string(out), cursor - 1
 }
-
-func parseDueInputTUI(raw string, loc *time.Location) (time.Time, error) {
-	text := strings.TrimSpace(raw)
-	if text == "" {
-		return time.Time{}, fmt.Errorf("due datetime is empty")
-	}
-	if loc == nil {
-		loc = time.Local
-	}
-	if t, err := time.Parse(time.RFC3339, text); err == nil {
-		return t.UTC(), nil
-	}
-	if t, err := time.ParseInLocation("2006-01-02 15:04", text, loc); err == nil {
-		return t.UTC(), nil
-	}
-	if t, err := time.ParseInLocation("2006-01-02T15:04", text, loc); err == nil {
-		return t.UTC(), nil
-	}
-	if t, err := time.ParseInLocation("200601021504", text, loc); err == nil {
-		return t.UTC(), nil
-	}
-	if t, err := time.ParseInLocation("2006-01-02", text, loc); err == nil {
-		dayEnd := time.Date(t.Year(), t.Month(), t.Day(), 23, 59, 0, 0, loc)
-		return dayEnd.UTC(), nil
-	}
-	return time.Time{}, fmt.Errorf("invalid due datetime")
-}
@@ -2,6 +2,7 @@ package tui
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"regexp"
 	"sort"
@@ -65,7 +66,7 @@ func TestTodayProgressUsesCurrentRuleScope(t *testing.T) {
 		},
 	}
 
-	m := NewModelWithRepo(r)
+	m := NewModelWithRepo(r, domain.DefaultWorkflow())
 	m.activeView = domain.ViewToday
 	m.now = func() time.Time { return now }
 	m.reload()
@@ -90,7 +91,11 @@ func TestTodayProgressShouldUseLocalDayBoundary(t *testing.T) {
 		DueAt:  &dueTomorrowLocal,
 	}
 
-	if isTodayProgressTask(task, now) {
+	metrics, err := loadTaskMetrics(context.Background(), &fakeTaskRepo{tasks: []domain.Task{task}}, domain.DefaultWorkflow(), now)
+	if err != nil {
+		t.Fatalf("load metrics: %v", err)
+	}
+	if metrics.todayTotal != 0 {
 		t.Fatalf("task due tomorrow local should not be counted in today's progress")
 	}
 }
@@ -106,7 +111,7 @@ func TestTodayProgressShouldCountDoneAtTodayWithoutDue(t *testing.T) {
 		},
 	}
 
-	m := NewModelWithRepo(r)
+	m := NewModelWithRepo(r, domain.DefaultWorkflow())
 	m.now = func() time.Time { return now }
 	m.reload()
 
@@ -134,7 +139,7 @@ func TestTodayProgressShouldNotCountDoneTaskFromPreviousDay(t *testing.T) {
 		},
 	}
 
-	m := NewModelWithRepo(r)
+	m := NewModelWithRepo(r, domain.DefaultWorkflow())
 	m.now = func() time.Time { return now }
 	m.reload()
 
@@ -158,7 +163,7 @@ func TestTodayViewShouldSortByPriority(t *testing.T) {
 		},
 	}
 
-	m := NewModelWithRepo(r)
+	m := NewModelWithRepo(r, domain.DefaultWorkflow())
 	m.now = func() time.Time { return now }
 	m.activeView = domain.ViewToday
 	m.reload()
@@ -192,7 +197,7 @@ func TestHeaderShouldShowBalancedMetrics(t *testing.T) {
 		},
 	}
 
-	m := NewModelWithRepo(r)
+	m := NewModelWithRepo(r, domain.DefaultWorkflow())
 	m.now = func() time.Time { return now }
 	m.activeView = domain.ViewInbox
 	updated, _ := m.Update(tea.WindowSizeMsg{Width: 140, Height: 24})
@@ -229,7 +234,7 @@ func TestInboxViewShouldShowTodoWithoutProject(t *testing.T) {
 		},
 	}
 
-	m := NewModelWithRepo(r)
+	m := NewModelWithRepo(r, domain.DefaultWorkflow())
 	m.activeView = domain.ViewInbox
 	m.reload()
 
@@ -244,7 +249,7 @@ func TestInboxViewShouldShowTodoWithoutProject(t *testing.T) {
 
 func TestPressPShouldUseAIParseForClipboard(t *testing.T) {
 	r := &fakeTaskRepo{}
-	m := NewModelWithRepo(r)
+	m := NewModelWithRepo(r, domain.DefaultWorkflow())
 	m.clipUseCase.ReadClipboard = func() (string, error) {
 		return "原始剪贴板内容", nil
 	}
@@ -268,7 +273,7 @@ func TestPressPShouldUseAIParseForClipboard(t *testing.T) {
 
 func TestSpaceShouldOpenAIPreviewAndConfirmCreate(t *testing.T) {
 	r := &fakeTaskRepo{}
-	m := NewModelWithRepo(r)
+	m := NewModelWithRepo(r, domain.DefaultWorkflow())
 	m.clipUseCase.AIParser = &usecase.AIParseTaskUseCase{
 		Provider: fakeParseProvider{
 			raw: `{"title":"在线推理功能","project":"PP Polaris","priority":"P1","due":"2026-02-25 13:00"}`,
@@ -318,9 +323,61 @@ func TestSpaceShouldOpenAIPreviewAndConfirmCreate(t *testing.T) {
 	}
 }
 
+func TestSpaceShouldPreviewSeveralTasksAndCreateAccepted(t *testing.T) {
+	r := &fakeTaskRepo{}
+	m := NewModelWithRepo(r, domain.DefaultWorkflow())
+	m.clipUseCase.AIParser = &usecase.AIParseTaskUseCase{
+		Provider: fakeMultiParseProvider{
+			raw: `{"project":"weekly","due":"2026-03-06 17:00","tasks":[{"title":"write minutes","priority":"P1"},{"title":"book room"},{"title":"send slides"}]}`,
+		},
+	}
+
+	m = sendRunes(m, ' ')
+	m = sendText(m, "周会纪要")
+	m = sendEnter(m)
+	view := ansi.Strip(m.View())
+	if strings.Contains(view, "selected") || !strings.Contains(view, "周会纪要") {
+		t.Fatalf("ai input should parse one task by default, view=%q", view)
+	}
+
+	m = sendRunes(m, 'e')
+	m = sendTab(m)
+	if view := ansi.Strip(m.View()); !strings.Contains(view, "every task in the text") {
+		t.Fatalf("tab should switch to several tasks, view=%q", view)
+	}
+	m = sendEnter(m)
+	view = ansi.Strip(m.View())
+	if !strings.Contains(view, "3 of 3 selected") || !strings.Contains(view, "book room") || !strings.Contains(view, "weekly") {
+		t.Fatalf("preview should list every task, view=%q", view)
+	}
+
+	m = sendRunes(m, 'j')
+	m = sendRunes(m, ' ')
+	view = ansi.Strip(m.View())
+	if !strings.Contains(view, "2 of 3 selected") || !strings.Contains(view, "[ ] book room") {
+		t.Fatalf("space should reject the task under the cursor, view=%q", view)
+	}
+
+	m = sendEnter(m)
+	if len(r.tasks) != 2 || r.tasks[0].Title != "write minutes" || r.tasks[1].Title != "send slides" {
+		t.Fatalf("tasks = %+v, want the accepted ones", r.tasks)
+	}
+	if r.tasks[1].Project != "weekly" || r.tasks[1].DueAt == nil {
+		t.Fatalf("shared project and due should be inherited, task = %+v", r.tasks[1])
+	}
+	if m.statusMsg != "created 2 tasks from ai parse" {
+		t.Fatalf("status message = %q", m.statusMsg)
+	}
+
+	m = sendRunes(m, 'z')
+	if len(r.tasks) != 0 {
+		t.Fatalf("one undo should remove the batch, tasks = %+v", r.tasks)
+	}
+}
+
 func TestFooterInputShouldShowCursor(t *testing.T) {
 	r := &fakeTaskRepo{}
-	m := NewModelWithRepo(r)
+	m := NewModelWithRepo(r, domain.DefaultWorkflow())
 	m = sendRunes(m, 'a')
 	m = sendText(m, "abc")
 
@@ -330,9 +387,27 @@ func TestFooterInputShouldShowCursor(t *testing.T) {
 	}
 }
 
+func TestAddInputShouldParseQuickAddTokens(t *testing.T) {
+	r := &fakeTaskRepo{}
+	m := NewModelWithRepo(r, domain.DefaultWorkflow())
+	m.now = func() time.Time { return time.Date(2026, 3, 4, 10, 0, 0, 0, time.Local) }
+	m = sendRunes(m, 'a')
+	m = sendText(m, "pay rent +home !1 @bills ^friday")
+	m = sendEnter(m)
+
+	if len(r.tasks) != 1 {
+		t.Fatalf("task count = %d, status %q", len(r.tasks), m.statusMsg)
+	}
+	task := r.tasks[0]
+	want := time.Date(2026, 3, 6, 23, 59, 0, 0, time.Local)
+	if task.Title != "pay rent" || task.Project != "home" || task.Priority != "P1" || strings.Join(task.Tags, ",") != "bills" || task.DueAt == nil || !task.DueAt.Equal(want) {
+		t.Fatalf("task = %+v", task)
+	}
+}
+
 func TestAISpaceInputModalShouldShowCursor(t *testing.T) {
 	r := &fakeTaskRepo{}
-	m := NewModelWithRepo(r)
+	m := NewModelWithRepo(r, domain.DefaultWorkflow())
 	m = sendRunes(m, ' ')
 	m = sendText(m, "abc")
 
@@ -344,7 +419,7 @@ func TestAISpaceInputModalShouldShowCursor(t *testing.T) {
 
 func TestFooterInputShouldSupportLeftRightMoveAndInsert(t *testing.T) {
 	r := &fakeTaskRepo{}
-	m := NewModelWithRepo(r)
+	m := NewModelWithRepo(r, domain.DefaultWorkflow())
 	m = sendRunes(m, 'a')
 	m = sendText(m, "abc")
 	m = sendLeft(m)
@@ -358,7 +433,7 @@ func TestFooterInputShouldSupportLeftRightMoveAndInsert(t *testing.T) {
 
 func TestAISpaceInputModalShouldSupportLeftRightMoveAndInsert(t *testing.T) {
 	r := &fakeTaskRepo{}
-	m := NewModelWithRepo(r)
+	m := NewModelWithRepo(r, domain.DefaultWorkflow())
 	m = sendRunes(m, ' ')
 	m = sendText(m, "abc")
 	m = sendLeft(m)
@@ -372,7 +447,7 @@ func TestAISpaceInputModalShouldSupportLeftRightMoveAndInsert(t *testing.T) {
 
 func TestFooterInputShouldSupportCtrlBFCursorMove(t *testing.T) {
 	r := &fakeTaskRepo{}
-	m := NewModelWithRepo(r)
+	m := NewModelWithRepo(r, domain.DefaultWorkflow())
 	m = sendRunes(m, 'a')
 	m = sendText(m, "abc")
 	m = sendCtrlB(m)
@@ -388,7 +463,7 @@ func TestFooterInputShouldSupportCtrlBFCursorMove(t *testing.T) {
 
 func TestAISpaceInputModalShouldSupportCtrlBFCursorMove(t *testing.T) {
 	r := &fakeTaskRepo{}
-	m := NewModelWithRepo(r)
+	m := NewModelWithRepo(r, domain.DefaultWorkflow())
 	m = sendRunes(m, ' ')
 	m = sendText(m, "abc")
 	m = sendCtrlB(m)
@@ -554,7 +629,7 @@ func TestFooterShouldOnlyShowInputPromptInInputMode(t *testing.T) {
 
 func TestFooterShouldRestoreShortcutsAfterInputEnd(t *testing.T) {
 	r := &fakeTaskRepo{}
-	m := NewModelWithRepo(r)
+	m := NewModelWithRepo(r, domain.DefaultWorkflow())
 
 	m = sendRunes(m, 'a')
 	m = sendText(m, "one")
@@ -575,7 +650,7 @@ func TestTrashFooterShouldShowRestoreAndPurgeHints(t *testing.T) {
 			{ID: 1, Title: "trash a", Status: domain.StatusDeleted},
 		},
 	}
-	m := NewModelWithRepo(r)
+	m := NewModelWithRepo(r, domain.DefaultWorkflow())
 	m.activeView = domain.ViewTrash
 	m.reload()
 
@@ -628,7 +703,7 @@ func TestHelpModalShouldCloseByQWithoutQuit(t *testing.T) {
 
 func TestUIAddTaskByInputMode(t *testing.T) {
 	r := &fakeTaskRepo{}
-	m := NewModelWithRepo(r)
+	m := NewModelWithRepo(r, domain.DefaultWorkflow())
 
 	m = sendRunes(m, 'a')
 	m = sendText(m, "write report")
@@ -644,7 +719,7 @@ func TestUIAddTaskByInputMode(t *testing.T) {
 
 func TestUIAddTaskInTodayShouldBeDoing(t *testing.T) {
 	r := &fakeTaskRepo{}
-	m := NewModelWithRepo(r)
+	m := NewModelWithRepo(r, domain.DefaultWorkflow())
 	m.activeView = domain.ViewToday
 	m.reload()
 
@@ -666,7 +741,7 @@ func TestUIEditProjectTodayDueAndDelete(t *testing.T) {
 			{ID: 1, Title: "task a", Status: domain.StatusInbox},
 		},
 	}
-	m := NewModelWithRepo(r)
+	m := NewModelWithRepo(r, domain.DefaultWorkflow())
 	m = setInboxView(m)
 
 	m = sendTab(m)
@@ -707,7 +782,7 @@ func TestEditInputShouldPrefillCurrentTaskTitle(t *testing.T) {
 			{ID: 1, Title: "task a", Status: domain.StatusInbox},
 		},
 	}
-	m := NewModelWithRepo(r)
+	m := NewModelWithRepo(r, domain.DefaultWorkflow())
 	m = setInboxView(m)
 	m = sendTab(m)
 	m = sendRunes(m, 'e')
@@ -729,7 +804,7 @@ func TestProjectInputShouldPrefillCurrentTaskProject(t *testing.T) {
 			{ID: 1, Title: "task a", Status: domain.StatusInbox, Project: "work"},
 		},
 	}
-	m := NewModelWithRepo(r)
+	m := NewModelWithRepo(r, domain.DefaultWorkflow())
 	m = setInboxView(m)
 	m = sendTab(m)
 	m = sendRunes(m, 'P')
@@ -752,7 +827,7 @@ func TestProjectInputShouldSelectExistingProjectByJKAndEnter(t *testing.T) {
 			{ID: 1, Title: "task a", Status: domain.StatusInbox},
 		},
 	}
-	m := NewModelWithRepo(r)
+	m := NewModelWithRepo(r, domain.DefaultWorkflow())
 	m = setInboxView(m)
 	m = sendTab(m)
 	m = sendRunes(m, 'P')
@@ -771,7 +846,7 @@ func TestProjectInputShouldCreateNewProjectInInputMode(t *testing.T) {
 			{ID: 1, Title: "task a", Status: domain.StatusInbox},
 		},
 	}
-	m := NewModelWithRepo(r)
+	m := NewModelWithRepo(r, domain.DefaultWorkflow())
 	m = setInboxView(m)
 	m = sendTab(m)
 	m = sendRunes(m, 'P')
@@ -794,7 +869,7 @@ func TestProjectInputShouldClearProjectBySelectingNone(t *testing.T) {
 			{ID: 1, Title: "task a", Status: domain.StatusTodo, Project: "alpha"},
 		},
 	}
-	m := NewModelWithRepo(r)
+	m := NewModelWithRepo(r, domain.DefaultWorkflow())
 	m.activeView = domain.ViewProject
 	m.project = "alpha"
 	m.reload()
@@ -821,7 +896,7 @@ func TestDueInputShouldPrefillCurrentTaskDue(t *testing.T) {
 			{ID: 1, Title: "task a", Status: domain.StatusTodo, DueAt: &due},
 		},
 	}
-	m := NewModelWithRepo(r)
+	m := NewModelWithRepo(r, domain.DefaultWorkflow())
 	m = setInboxView(m)
 	m = sendTab(m)
 	m = sendRunes(m, 'd')
@@ -844,7 +919,7 @@ func TestPriorityInputShouldPrefillCurrentTaskPriority(t *testing.T) {
 			{ID: 1, Title: "task a", Status: domain.StatusTodo, Priority: "P3"},
 		},
 	}
-	m := NewModelWithRepo(r)
+	m := NewModelWithRepo(r, domain.DefaultWorkflow())
 	m = setInboxView(m)
 	m = sendTab(m)
 	m = sendRunes(m, 'y')
@@ -863,7 +938,7 @@ func TestUIPriorityShouldUpdateByY(t *testing.T) {
 			{ID: 1, Title: "task a", Status: domain.StatusTodo, Priority: "P3"},
 		},
 	}
-	m := NewModelWithRepo(r)
+	m := NewModelWithRepo(r, domain.DefaultWorkflow())
 	m = setInboxView(m)
 	m = sendTab(m)
 	m = sendRunes(m, 'y')
@@ -881,8 +956,8 @@ func TestProjectRenameShouldPrefillSelectedProjectName(t *testing.T) {
 	r := &fakeTaskRepo{
 		projects: []string{"work"},
 	}
-	m := NewModelWithRepo(r)
-	m.navIndex = 1
+	m := NewModelWithRepo(r, domain.DefaultWorkflow())
+	m.navIndex = 3
 	m = sendRunes(m, 'j')
 	m = sendRunes(m, 'j')
 	m = sendRunes(m, 'j')
@@ -905,7 +980,7 @@ func TestInputShouldAcceptSpaceKeyForDue(t *testing.T) {
 			{ID: 1, Title: "task a", Status: domain.StatusInbox},
 		},
 	}
-	m := NewModelWithRepo(r)
+	m := NewModelWithRepo(r, domain.DefaultWorkflow())
 	m = setInboxView(m)
 	m = sendTab(m)
 	m = sendRunes(m, 'd')
@@ -926,7 +1001,7 @@ func TestInputShouldAcceptCompactDateTimeForDue(t *testing.T) {
 			{ID: 1, Title: "task a", Status: domain.StatusInbox},
 		},
 	}
-	m := NewModelWithRepo(r)
+	m := NewModelWithRepo(r, domain.DefaultWorkflow())
 	m = setInboxView(m)
 	m = sendTab(m)
 	m = sendRunes(m, 'd')
@@ -942,13 +1017,210 @@ func TestInputShouldAcceptCompactDateTimeForDue(t *testing.T) {
 	}
 }
 
+func TestInputShouldAcceptNaturalLanguageDue(t *testing.T) {
+	r := &fakeTaskRepo{
+		tasks: []domain.Task{
+			{ID: 1, Title: "task a", Status: domain.StatusInbox},
+		},
+	}
+	m := NewModelWithRepo(r, domain.DefaultWorkflow())
+	m.now = func() time.Time { return time.Date(2026, 3, 4, 10, 0, 0, 0, time.Local) }
+	m = setInboxView(m)
+	m = sendTab(m)
+	m = sendRunes(m, 'd')
+	m = sendText(m, "明天下午3点")
+	m = sendEnter(m)
+
+	want := time.Date(2026, 3, 5, 15, 0, 0, 0, time.Local)
+	if r.tasks[0].DueAt == nil || !r.tasks[0].DueAt.Equal(want) {
+		t.Fatalf("due_at = %v, want %s (status %q)", r.tasks[0].DueAt, want, m.statusMsg)
+	}
+}
+
+func TestDeferKeyShouldHideTaskUntilStart(t *testing.T) {
+	r := &fakeTaskRepo{
+		tasks: []domain.Task{
+			{ID: 1, Title: "task a", Status: domain.StatusInbox},
+			{ID: 2, Title: "task b", Status: domain.StatusInbox},
+		},
+	}
+	m := NewModelWithRepo(r, domain.DefaultWorkflow())
+	now := time.Date(2026, 3, 4, 10, 0, 0, 0, time.Local)
+	m.now = func() time.Time { return now }
+	m = setInboxView(m)
+	m = sendTab(m)
+	m = sendRunes(m, 's')
+	m = sendText(m, "tomorrow")
+	m = sendEnter(m)
+
+	want := time.Date(2026, 3, 5, 0, 0, 0, 0, time.Local)
+	if r.tasks[0].StartAt == nil || !r.tasks[0].StartAt.Equal(want) {
+		t.Fatalf("start_at = %v, want %s (status %q)", r.tasks[0].StartAt, want, m.statusMsg)
+	}
+	if len(m.tasks) != 1 || m.tasks[0].ID != 2 {
+		t.Fatalf("inbox should hide deferred task, got %+v", m.tasks)
+	}
+
+	m.activeView = domain.ViewUpcoming
+	m.reload()
+	if len(m.tasks) != 1 || m.tasks[0].ID != 1 {
+		t.Fatalf("upcoming = %+v, want deferred task", m.tasks)
+	}
+	if view := ansi.Strip(m.View()); !strings.Contains(view, "starts 2026-03-05 00:00") {
+		t.Fatalf("upcoming should show start time, view=%q", view)
+	}
+}
+
+func TestSnoozeKeyShouldPushDueByOffset(t *testing.T) {
+	now := time.Date(2026, 3, 4, 10, 0, 0, 0, time.Local)
+	due := time.Date(2026, 3, 3, 18, 0, 0, 0, time.Local)
+	r := &fakeTaskRepo{
+		tasks: []domain.Task{
+			{ID: 1, Title: "task a", Status: domain.StatusInbox, DueAt: &due},
+		},
+	}
+	m := NewModelWithRepo(r, domain.DefaultWorkflow())
+	m.now = func() time.Time { return now }
+	m = setInboxView(m)
+	m = sendTab(m)
+	m = sendRunes(m, 'S')
+	if m.inputValue != "1d" {
+		t.Fatalf("snooze input should default to 1d, got %q", m.inputValue)
+	}
+	m = sendEnter(m)
+
+	want := time.Date(2026, 3, 5, 18, 0, 0, 0, time.Local)
+	if r.tasks[0].DueAt == nil || !r.tasks[0].DueAt.Equal(want) || r.tasks[0].SnoozeCount != 1 {
+		t.Fatalf("task = due %v count %d, want %s (status %q)", r.tasks[0].DueAt, r.tasks[0].SnoozeCount, want, m.statusMsg)
+	}
+	if !strings.Contains(m.statusMsg, "snoozed #1 to 2026-03-05 18:00") {
+		t.Fatalf("status = %q", m.statusMsg)
+	}
+}
+
+func TestSnoozeOverdueKeyShouldPushEveryOverdueTaskInView(t *testing.T) {
+	now := time.Date(2026, 3, 4, 10, 0, 0, 0, time.Local)
+	past := time.Date(2026, 3, 3, 18, 0, 0, 0, time.Local)
+	future := time.Date(2026, 3, 6, 18, 0, 0, 0, time.Local)
+	r := &fakeTaskRepo{
+		tasks: []domain.Task{
+			{ID: 1, Title: "task a", Status: domain.StatusInbox, DueAt: &past},
+			{ID: 2, Title: "task b", Status: domain.StatusInbox, DueAt: &future},
+			{ID: 3, Title: "task c", Status: domain.StatusInbox, DueAt: &past},
+		},
+	}
+	m := NewModelWithRepo(r, domain.DefaultWorkflow())
+	m.now = func() time.Time { return now }
+	m = setInboxView(m)
+	m = sendRunes(m, 'O')
+	if !strings.Contains(m.View(), "snooze 2 overdue") {
+		t.Fatalf("prompt should count overdue tasks, view=%q", m.View())
+	}
+	m = sendEnter(m)
+
+	want := time.Date(2026, 3, 5, 18, 0, 0, 0, time.Local)
+	for _, i := range []int{0, 2} {
+		if r.tasks[i].DueAt == nil || !r.tasks[i].DueAt.Equal(want) || r.tasks[i].SnoozeCount != 1 {
+			t.Fatalf("task #%d = due %v count %d, want %s (status %q)", r.tasks[i].ID, r.tasks[i].DueAt, r.tasks[i].SnoozeCount, want, m.statusMsg)
+		}
+	}
+	if !r.tasks[1].DueAt.Equal(future) || r.tasks[1].SnoozeCount != 0 {
+		t.Fatalf("future task should stay, got %v", r.tasks[1].DueAt)
+	}
+	if m.statusMsg != "snoozed 2 overdue task(s)" {
+		t.Fatalf("status = %q", m.statusMsg)
+	}
+
+	m = sendRunes(m, 'O')
+	if m.inputMode != inputNone || m.statusMsg != "no overdue task in this view" {
+		t.Fatalf("mode = %v status = %q", m.inputMode, m.statusMsg)
+	}
+}
+
+func TestWaitKeyShouldMoveTaskToWaitingView(t *testing.T) {
+	r := &fakeTaskRepo{
+		tasks: []domain.Task{
+			{ID: 1, Title: "task a", Status: domain.StatusInbox},
+		},
+	}
+	m := NewModelWithRepo(r, domain.DefaultWorkflow())
+	now := time.Date(2026, 3, 4, 10, 0, 0, 0, time.Local)
+	m.now = func() time.Time { return now }
+	m = setInboxView(m)
+	m = sendTab(m)
+	m = sendRunes(m, 'w')
+	m = sendText(m, "alice ^tomorrow")
+	m = sendEnter(m)
+
+	want := time.Date(2026, 3, 5, 0, 0, 0, 0, time.Local)
+	task := r.tasks[0]
+	if task.Status != domain.StatusWaiting || task.WaitingOn != "alice" || task.FollowUpAt == nil || !task.FollowUpAt.Equal(want) {
+		t.Fatalf("task = %+v (status %q)", task, m.statusMsg)
+	}
+	if len(m.tasks) != 0 {
+		t.Fatalf("inbox should drop waiting task, got %+v", m.tasks)
+	}
+
+	m.activeView = domain.ViewWaiting
+	m.reload()
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 140, Height: 24})
+	m = updated.(Model)
+	if len(m.tasks) != 1 {
+		t.Fatalf("waiting view = %+v", m.tasks)
+	}
+	if view := ansi.Strip(m.View()); !strings.Contains(view, "waiting on alice, follow up 2026-03-05 00:00") {
+		t.Fatalf("waiting view should show who and follow-up, view=%q", view)
+	}
+	m = sendRunes(m, 'w')
+	if m.inputValue != "alice ^2026-03-05 00:00" {
+		t.Fatalf("wait input should prefill, got %q", m.inputValue)
+	}
+}
+
+func TestMoveKeyShouldTransitToCustomStatus(t *testing.T) {
+	workflow, err := domain.NewWorkflow(domain.WorkflowSpec{
+		Statuses: []domain.Status{"review"},
+		Transitions: map[domain.Status][]domain.Status{
+			domain.StatusInbox: {domain.StatusTodo, "review", domain.StatusDeleted},
+		},
+	})
+	if err != nil {
+		t.Fatalf("workflow: %v", err)
+	}
+	r := &fakeTaskRepo{
+		tasks: []domain.Task{
+			{ID: 1, Title: "task a", Status: domain.StatusInbox},
+		},
+		workflow: workflow,
+	}
+	m := NewModelWithRepo(r, workflow)
+	m = setInboxView(m)
+	m = sendTab(m)
+	m = sendRunes(m, 'm')
+	if m.inputTarget != "todo / review / deleted" {
+		t.Fatalf("move targets = %q", m.inputTarget)
+	}
+	m = sendText(m, "done")
+	m = sendEnter(m)
+	if r.tasks[0].Status != domain.StatusInbox {
+		t.Fatalf("inbox -> done should be refused, status %q", r.tasks[0].Status)
+	}
+
+	m = sendRunes(m, 'm')
+	m = sendText(m, "review")
+	m = sendEnter(m)
+	if r.tasks[0].Status != "review" || m.statusMsg != "review #1" {
+		t.Fatalf("status = %q, msg = %q", r.tasks[0].Status, m.statusMsg)
+	}
+}
+
 func TestUIDeleteTask(t *testing.T) {
 	r := &fakeTaskRepo{
 		tasks: []domain.Task{
 			{ID: 1, Title: "task a", Status: domain.StatusInbox},
 		},
 	}
-	m := NewModelWithRepo(r)
+	m := NewModelWithRepo(r, domain.DefaultWorkflow())
 	m = setInboxView(m)
 	m = sendTab(m)
 	m = sendRunes(m, 'x')
@@ -966,7 +1238,7 @@ func TestUIDeleteTaskShouldUndoByZ(t *testing.T) {
 			{ID: 1, Title: "task a", Status: domain.StatusInbox},
 		},
 	}
-	m := NewModelWithRepo(r)
+	m := NewModelWithRepo(r, domain.DefaultWorkflow())
 	m = setInboxView(m)
 	m = sendTab(m)
 	m = sendRunes(m, 'x')
@@ -975,8 +1247,56 @@ func TestUIDeleteTaskShouldUndoByZ(t *testing.T) {
 	}
 
 	m = sendRunes(m, 'z')
-	if r.tasks[0].Status != domain.StatusTodo {
-		t.Fatalf("status after undo = %s, want %s", r.tasks[0].Status, domain.StatusTodo)
+	if r.tasks[0].Status != domain.StatusInbox {
+		t.Fatalf("status after undo = %s, want %s", r.tasks[0].Status, domain.StatusInbox)
+	}
+
+	m = sendRunes(m, 'Z')
+	if r.tasks[0].Status != domain.StatusDeleted {
+		t.Fatalf("status after redo = %s, want %s", r.tasks[0].Status, domain.StatusDeleted)
+	}
+	if !strings.Contains(m.statusMsg, "redid delete") {
+		t.Fatalf("status message = %q, want redo notice", m.statusMsg)
+	}
+	m = sendRunes(m, 'Z')
+	if m.statusMsg != "nothing to redo" {
+		t.Fatalf("status message = %q, want nothing to redo", m.statusMsg)
+	}
+}
+
+func TestSlashFilterShouldNarrowListWhileTyping(t *testing.T) {
+	r := &fakeTaskRepo{
+		tasks: []domain.Task{
+			{ID: 1, Title: "整理周报", Status: domain.StatusInbox},
+			{ID: 2, Title: "buy milk", Status: domain.StatusInbox, Priority: "P1"},
+			{ID: 3, Title: "周报发给老板", Status: domain.StatusInbox, Priority: "P1"},
+		},
+	}
+	m := NewModelWithRepo(r, domain.DefaultWorkflow())
+	m = setInboxView(m)
+
+	m = sendRunes(m, '/')
+	m = sendText(m, "周报")
+	if len(m.tasks) != 2 {
+		t.Fatalf("tasks while typing = %d, want 2", len(m.tasks))
+	}
+	m = sendText(m, " pri:")
+	if !strings.Contains(m.View(), "missing value") {
+		t.Fatalf("unfinished term should show the query error")
+	}
+	m = sendText(m, "P1")
+	m = sendEnter(m)
+	if m.inputMode != inputNone || len(m.tasks) != 1 || m.tasks[0].ID != 3 {
+		t.Fatalf("filtered tasks = %+v, want only #3", m.tasks)
+	}
+	if !strings.Contains(ansi.Strip(m.View()), "filter 周报 pri:P1") {
+		t.Fatalf("footer should show the active filter")
+	}
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = updated.(Model)
+	if m.filter != "" || len(m.tasks) != 3 {
+		t.Fatalf("esc should clear filter, tasks = %d", len(m.tasks))
 	}
 }
 
@@ -986,7 +1306,7 @@ func TestTrashViewShouldShowProjectInTaskRow(t *testing.T) {
 			{ID: 1, Title: "trash a", Status: domain.StatusDeleted, Project: "work"},
 		},
 	}
-	m := NewModelWithRepo(r)
+	m := NewModelWithRepo(r, domain.DefaultWorkflow())
 	m.activeView = domain.ViewTrash
 	m.reload()
 	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 24})
@@ -1007,7 +1327,7 @@ func TestTrashShouldRestoreSelectedByR(t *testing.T) {
 			{ID: 1, Title: "trash a", Status: domain.StatusDeleted},
 		},
 	}
-	m := NewModelWithRepo(r)
+	m := NewModelWithRepo(r, domain.DefaultWorkflow())
 	m.activeView = domain.ViewTrash
 	m.reload()
 	m = sendTab(m)
@@ -1026,7 +1346,7 @@ func TestTrashShouldPurgeAllByUpperX(t *testing.T) {
 			{ID: 3, Title: "todo a", Status: domain.StatusTodo},
 		},
 	}
-	m := NewModelWithRepo(r)
+	m := NewModelWithRepo(r, domain.DefaultWorkflow())
 	m.activeView = domain.ViewTrash
 	m.reload()
 	m = sendRunes(m, 'X')
@@ -1048,7 +1368,7 @@ func TestProjectViewToggleDoneByH(t *testing.T) {
 			{ID: 2, Title: "done in work", Status: domain.StatusDone, Project: "work", DoneAt: &now},
 		},
 	}
-	m := NewModelWithRepo(r)
+	m := NewModelWithRepo(r, domain.DefaultWorkflow())
 	m.activeView = domain.ViewProject
 	m.project = "work"
 	m.reload()
@@ -1070,7 +1390,7 @@ func TestListShouldShowDueInTaskRow(t *testing.T) {
 			{ID: 1, Title: "with due", Status: domain.StatusInbox, Priority: "P2", DueAt: &due},
 		},
 	}
-	m := NewModelWithRepo(r)
+	m := NewModelWithRepo(r, domain.DefaultWorkflow())
 	m = setInboxView(m)
 	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 24})
 	m = updated.(Model)
@@ -1097,7 +1417,7 @@ func TestTodayViewTaskRowShouldShowProjectAndDue(t *testing.T) {
 			{ID: 1, Title: "today task", Status: domain.StatusDoing, Project: "work", Priority: "P1", DueAt: &due},
 		},
 	}
-	m := NewModelWithRepo(r)
+	m := NewModelWithRepo(r, domain.DefaultWorkflow())
 	m.activeView = domain.ViewToday
 	m.reload()
 	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 24})
@@ -1126,8 +1446,8 @@ func TestProjectAddKeyInNavShouldCreateProject(t *testing.T) {
 	r := &fakeTaskRepo{
 		projects: []string{"work"},
 	}
-	m := NewModelWithRepo(r)
-	m.navIndex = 1
+	m := NewModelWithRepo(r, domain.DefaultWorkflow())
+	m.navIndex = 3
 	m = sendRunes(m, 'j')
 	m = sendRunes(m, 'j')
 	m = sendRunes(m, 'a')
@@ -1145,8 +1465,8 @@ func TestProjectRenameAndDeleteByNavKeys(t *testing.T) {
 			{ID: 1, Title: "task a", Status: domain.StatusTodo, Project: "work"},
 		},
 	}
-	m := NewModelWithRepo(r)
-	m.navIndex = 1
+	m := NewModelWithRepo(r, domain.DefaultWorkflow())
+	m.navIndex = 3
 	m = sendRunes(m, 'j')
 	m = sendRunes(m, 'j')
 	m = sendRunes(m, 'j')
@@ -1182,8 +1502,8 @@ func TestProjectDeleteShouldUndoByZ(t *testing.T) {
 			{ID: 1, Title: "task a", Status: domain.StatusTodo, Project: "work"},
 		},
 	}
-	m := NewModelWithRepo(r)
-	m.navIndex = 1
+	m := NewModelWithRepo(r, domain.DefaultWorkflow())
+	m.navIndex = 3
 	m = sendRunes(m, 'j')
 	m = sendRunes(m, 'j')
 	m = sendRunes(m, 'j')
@@ -1211,7 +1531,7 @@ func TestUndoShouldSupportMultipleStepsForTaskDelete(t *testing.T) {
 			{ID: 2, Title: "task b", Status: domain.StatusInbox},
 		},
 	}
-	m := NewModelWithRepo(r)
+	m := NewModelWithRepo(r, domain.DefaultWorkflow())
 	m = setInboxView(m)
 	m = sendTab(m)
 
@@ -1222,13 +1542,13 @@ func TestUndoShouldSupportMultipleStepsForTaskDelete(t *testing.T) {
 	}
 
 	m = sendRunes(m, 'z')
-	if r.tasks[0].Status != domain.StatusDeleted || r.tasks[1].Status != domain.StatusTodo {
-		t.Fatalf("after first undo statuses = %s/%s, want deleted/todo", r.tasks[0].Status, r.tasks[1].Status)
+	if r.tasks[0].Status != domain.StatusDeleted || r.tasks[1].Status != domain.StatusInbox {
+		t.Fatalf("after first undo statuses = %s/%s, want deleted/inbox", r.tasks[0].Status, r.tasks[1].Status)
 	}
 
 	m = sendRunes(m, 'z')
-	if r.tasks[0].Status != domain.StatusTodo || r.tasks[1].Status != domain.StatusTodo {
-		t.Fatalf("after second undo statuses = %s/%s, want todo/todo", r.tasks[0].Status, r.tasks[1].Status)
+	if r.tasks[0].Status != domain.StatusInbox || r.tasks[1].Status != domain.StatusInbox {
+		t.Fatalf("after second undo statuses = %s/%s, want inbox/inbox", r.tasks[0].Status, r.tasks[1].Status)
 	}
 }
 
@@ -1240,7 +1560,7 @@ func TestLogViewShouldShowProjectAndDoneAt(t *testing.T) {
 			{ID: 1, Title: "测试用例", Status: domain.StatusDone, Project: "xxx", Priority: "P2", DoneAt: &doneAt},
 		},
 	}
-	m := NewModelWithRepo(r)
+	m := NewModelWithRepo(r, domain.DefaultWorkflow())
 	m.now = func() time.Time { return now }
 	m.activeView = domain.ViewLog
 	m.reload()
@@ -1268,7 +1588,7 @@ func TestTaskShouldMarkDoneByCInListFocus(t *testing.T) {
 			{ID: 1, Title: "task a", Status: domain.StatusInbox},
 		},
 	}
-	m := NewModelWithRepo(r)
+	m := NewModelWithRepo(r, domain.DefaultWorkflow())
 	m = setInboxView(m)
 	m = sendTab(m)
 	m = sendRunes(m, 'c')
@@ -1277,6 +1597,79 @@ func TestTaskShouldMarkDoneByCInListFocus(t *testing.T) {
 	}
 }
 
+func TestBlockedTasksShouldStayOutOfTodayUntilReleased(t *testing.T) {
+	r := &fakeTaskRepo{
+		tasks: []domain.Task{
+			{ID: 1, Title: "order parts", Status: domain.StatusDoing},
+			{ID: 2, Title: "assemble shelf", Status: domain.StatusInbox},
+		},
+		blockers: map[int64][]int64{2: {1}},
+	}
+	m := NewModelWithRepo(r, domain.DefaultWorkflow())
+	m = setInboxView(m)
+	if view := m.View(); !strings.Contains(view, "blocked #1") {
+		t.Fatalf("inbox should mark blocked task:\n%s", view)
+	}
+	r.tasks[1].Status = domain.StatusDoing
+	m.activeView = domain.ViewToday
+	m.reload()
+	if len(m.tasks) != 1 || m.tasks[0].ID != 1 {
+		t.Fatalf("today should hide blocked task, got %+v", m.tasks)
+	}
+
+	m = sendTab(m)
+	m = sendRunes(m, 'c')
+	if r.tasks[0].Status != domain.StatusDone || !strings.Contains(m.statusMsg, "unblocked #2") {
+		t.Fatalf("completing blocker should report release, msg = %q", m.statusMsg)
+	}
+}
+
+func TestCompletingParentShouldAskAboutOpenSubtasks(t *testing.T) {
+	r := &fakeTaskRepo{
+		tasks: []domain.Task{
+			{ID: 1, Title: "plan trip", Status: domain.StatusInbox},
+			{ID: 2, Title: "book hotel", Status: domain.StatusInbox, ParentID: 1},
+			{ID: 3, Title: "pack", Status: domain.StatusInbox},
+			{ID: 4, Title: "book flights", Status: domain.StatusInbox, ParentID: 1},
+		},
+	}
+	m := NewModelWithRepo(r, domain.DefaultWorkflow())
+	m = setInboxView(m)
+	gotOrder := []int64{}
+	for _, task := range m.tasks {
+		gotOrder = append(gotOrder, task.ID)
+	}
+	if fmt.Sprint(gotOrder) != "[1 2 4 3]" {
+		t.Fatalf("nested order = %v, want [1 2 4 3]", gotOrder)
+	}
+	view := m.View()
+	if !strings.Contains(view, "0/2") || !strings.Contains(view, "  book hotel") {
+		t.Fatalf("view should show subtask progress and indentation:\n%s", view)
+	}
+
+	m = sendTab(m)
+	m = sendRunes(m, 'c')
+	if r.tasks[0].Status == domain.StatusDone || !strings.Contains(m.statusMsg, "2 open subtask(s)") {
+		t.Fatalf("completing a parent should ask first, status = %s msg = %q", r.tasks[0].Status, m.statusMsg)
+	}
+	m = sendRunes(m, 'y')
+	for _, i := range []int{0, 1, 3} {
+		if r.tasks[i].Status != domain.StatusDone {
+			t.Fatalf("task #%d status = %s, want done", r.tasks[i].ID, r.tasks[i].Status)
+		}
+	}
+	if r.tasks[2].Status != domain.StatusInbox {
+		t.Fatalf("unrelated task should stay open")
+	}
+
+	m = sendRunes(m, 'z')
+	for _, i := range []int{0, 1, 3} {
+		if r.tasks[i].Status != domain.StatusInbox {
+			t.Fatalf("undo should reopen #%d, status = %s", r.tasks[i].ID, r.tasks[i].Status)
+		}
+	}
+}
+
 func TestTodayToggleByTShouldSwitchDoingAndTodo(t *testing.T) {
 	r := &fakeTaskRepo{
 		projects: []string{"work"},
@@ -1285,7 +1678,7 @@ func TestTodayToggleByTShouldSwitchDoingAndTodo(t *testing.T) {
 			{ID: 2, Title: "todo task", Status: domain.StatusTodo, Project: "work"},
 		},
 	}
-	m := NewModelWithRepo(r)
+	m := NewModelWithRepo(r, domain.DefaultWorkflow())
 	m.activeView = domain.ViewProject
 	m.project = "work"
 	m.reload()
@@ -1315,8 +1708,8 @@ func TestProjectShouldMarkAllOpenTasksDoneByCInNav(t *testing.T) {
 			{ID: 5, Title: "e", Status: domain.StatusTodo, Project: "home"},
 		},
 	}
-	m := NewModelWithRepo(r)
-	m.navIndex = 1
+	m := NewModelWithRepo(r, domain.DefaultWorkflow())
+	m.navIndex = 3
 	m = sendRunes(m, 'j')
 	m = sendRunes(m, 'j')
 	m = sendRunes(m, 'j')
@@ -1340,7 +1733,7 @@ func TestTaskStatusShouldUndoTodoDoingDoneChainByZ(t *testing.T) {
 			{ID: 1, Title: "task a", Status: domain.StatusTodo, Project: "work"},
 		},
 	}
-	m := NewModelWithRepo(r)
+	m := NewModelWithRepo(r, domain.DefaultWorkflow())
 	m.activeView = domain.ViewProject
 	m.project = "work"
 	m.reload()
@@ -1373,7 +1766,7 @@ func TestTaskStatusUndoShouldRestoreInboxFromDoneByZ(t *testing.T) {
 			{ID: 1, Title: "task a", Status: domain.StatusInbox},
 		},
 	}
-	m := NewModelWithRepo(r)
+	m := NewModelWithRepo(r, domain.DefaultWorkflow())
 	m = setInboxView(m)
 	m = sendTab(m)
 
@@ -1400,8 +1793,8 @@ func TestProjectDoneByCInNavShouldUndoByZ(t *testing.T) {
 			{ID: 5, Title: "e", Status: domain.StatusTodo, Project: "home"},
 		},
 	}
-	m := NewModelWithRepo(r)
-	m.navIndex = 1
+	m := NewModelWithRepo(r, domain.DefaultWorkflow())
+	m.navIndex = 3
 	m = sendRunes(m, 'j')
 	m = sendRunes(m, 'j')
 	m = sendRunes(m, 'j')
@@ -1434,8 +1827,8 @@ func TestUndoShouldUseGlobalLatestAcrossProjectStatusAndDelete(t *testing.T) {
 			{ID: 2, Title: "b", Status: domain.StatusDoing, Project: "work"},
 		},
 	}
-	m := NewModelWithRepo(r)
-	m.navIndex = 1
+	m := NewModelWithRepo(r, domain.DefaultWorkflow())
+	m.navIndex = 3
 	m = sendRunes(m, 'j')
 	m = sendRunes(m, 'j')
 	m = sendRunes(m, 'j')
@@ -1470,6 +1863,52 @@ func TestUndoShouldUseGlobalLatestAcrossProjectStatusAndDelete(t *testing.T) {
 	}
 }
 
+func TestTagNavShouldFilterListByTag(t *testing.T) {
+	r := &fakeTaskRepo{
+		tasks: []domain.Task{
+			{ID: 1, Title: "tagged work", Status: domain.StatusTodo, Tags: []string{"work"}},
+			{ID: 2, Title: "tagged home", Status: domain.StatusTodo, Tags: []string{"home"}},
+			{ID: 3, Title: "untagged", Status: domain.StatusTodo},
+		},
+	}
+	m := NewModelWithRepo(r, domain.DefaultWorkflow())
+	rows := m.navRows()
+	tagRow := -1
+	for idx, row := range rows {
+		if row.Kind == navRowTag && row.Tag == "work" {
+			tagRow = idx
+		}
+	}
+	if tagRow < 0 {
+		t.Fatalf("nav rows should contain #work, rows=%+v", rows)
+	}
+	m.navIndex = tagRow
+	m = sendEnter(m)
+	if m.activeView != domain.ViewTag || m.tag != "work" {
+		t.Fatalf("active = (%s, %q), want (tag, work)", m.activeView, m.tag)
+	}
+	got := make([]string, 0, len(m.tasks))
+	for _, task := range m.tasks {
+		got = append(got, task.Title)
+	}
+	if len(got) != 1 || got[0] != "tagged work" {
+		t.Fatalf("tag view tasks = %v, want [tagged work]", got)
+	}
+	view := ansi.Strip(m.View())
+	if !strings.Contains(view, "#work") {
+		t.Fatalf("view should show tag meta and nav row, view=%q", view)
+	}
+
+	m = sendTab(m)
+	m = sendRunes(m, 'a')
+	m = sendText(m, "new tagged")
+	m = sendEnter(m)
+	last := r.tasks[len(r.tasks)-1]
+	if last.Title != "new tagged" || !containsString(last.Tags, "work") {
+		t.Fatalf("task added in tag view = %+v, want tag work", last)
+	}
+}
+
 func TestLongTaskListShouldKeepFooterAndSelectedVisible(t *testing.T) {
 	tasks := make([]domain.Task, 0, 80)
 	for i := 1; i <= 80; i++ {
@@ -1480,7 +1919,7 @@ func TestLongTaskListShouldKeepFooterAndSelectedVisible(t *testing.T) {
 		})
 	}
 	r := &fakeTaskRepo{tasks: tasks}
-	m := NewModelWithRepo(r)
+	m := NewModelWithRepo(r, domain.DefaultWorkflow())
 	m = setInboxView(m)
 	updated, _ := m.Update(tea.WindowSizeMsg{Width: 90, Height: 16})
 	m = updated.(Model)
@@ -1504,7 +1943,7 @@ func TestLongProjectNavShouldKeepFooterAndSelectedVisible(t *testing.T) {
 		projects = append(projects, fmt.Sprintf("proj-%02d", i))
 	}
 	r := &fakeTaskRepo{projects: projects}
-	m := NewModelWithRepo(r)
+	m := NewModelWithRepo(r, domain.DefaultWorkflow())
 	updated, _ := m.Update(tea.WindowSizeMsg{Width: 90, Height: 16})
 	m = updated.(Model)
 	for i := 0; i < 35; i++ {
@@ -1547,9 +1986,100 @@ type fakeTaskRepo struct {
 	nextID   int64
 	nowFunc  func() time.Time
 	projects []string
+	blockers map[int64][]int64
+	undone   []fakeRepoState
+	redone   []fakeRepoState
+	ops      []domain.Operation
+	workflow domain.Workflow
+}
+
+type fakeRepoState struct {
+	tasks    []domain.Task
+	projects []string
+	blockers map[int64][]int64
+	op       domain.Operation
+}
+
+func (f *fakeTaskRepo) state() fakeRepoState {
+	st := fakeRepoState{
+		tasks:    make([]domain.Task, len(f.tasks)),
+		projects: append([]string(nil), f.projects...),
+		blockers: make(map[int64][]int64, len(f.blockers)),
+	}
+	for i, task := range f.tasks {
+		task.Tags = append([]string(nil), task.Tags...)
+		st.tasks[i] = task
+	}
+	for id, blockers := range f.blockers {
+		st.blockers[id] = append([]int64(nil), blockers...)
+	}
+	return st
+}
+
+func (f *fakeTaskRepo) setState(st fakeRepoState) {
+	f.tasks = st.tasks
+	f.projects = st.projects
+	f.blockers = st.blockers
+}
+
+// checkpoint records the state before a mutation so Undo can restore it.
+func (f *fakeTaskRepo) checkpoint(summary string) {
+	st := f.state()
+	st.op = domain.Operation{ID: int64(len(f.ops) + 1), Summary: summary, State: domain.OperationDone}
+	f.ops = append(f.ops, st.op)
+	f.undone = append(f.undone, st)
+	f.redone = nil
+}
+
+func (f *fakeTaskRepo) Undo(context.Context) (domain.Operation, error) {
+	if len(f.undone) == 0 {
+		return domain.Operation{}, domain.ErrNothingToUndo
+	}
+	prev := f.undone[len(f.undone)-1]
+	f.undone = f.undone[:len(f.undone)-1]
+	cur := f.state()
+	cur.op = prev.op
+	f.redone = append(f.redone, cur)
+	f.setState(prev)
+	return prev.op, nil
+}
+
+func (f *fakeTaskRepo) Redo(context.Context) (domain.Operation, error) {
+	if len(f.redone) == 0 {
+		return domain.Operation{}, domain.ErrNothingToRedo
+	}
+	next := f.redone[len(f.redone)-1]
+	f.redone = f.redone[:len(f.redone)-1]
+	cur := f.state()
+	cur.op = next.op
+	f.undone = append(f.undone, cur)
+	f.setState(next)
+	return next.op, nil
+}
+
+func (f *fakeTaskRepo) ListOperations(_ context.Context, limit int) ([]domain.Operation, error) {
+	out := make([]domain.Operation, 0, len(f.ops))
+	for i := len(f.ops) - 1; i >= 0 && (limit <= 0 || len(out) < limit); i-- {
+		out = append(out, f.ops[i])
+	}
+	return out, nil
 }
 
 func (f *fakeTaskRepo) Create(_ context.Context, task domain.Task) (int64, error) {
+	f.checkpoint("add")
+	return f.create(task), nil
+}
+
+func (f *fakeTaskRepo) CreateMany(_ context.Context, tasks []domain.Task) ([]int64, error) {
+	f.checkpoint("add")
+	ids := make([]int64, 0, len(tasks))
+	for _, task := range tasks {
+		ids = append(ids, f.create(task))
+	}
+	return ids, nil
+}
+
+func (f *fakeTaskRepo) create(task domain.Task) int64 {
 	if f.nextID <= 0 {
 		f.nextID = 1
 		for _, item := range f.tasks {
@@ -1570,13 +2100,13 @@ func (f *fakeTaskRepo) Create(_ context.Context, task domain.Task) (int64, error
 	task.CreatedAt = now
 	task.UpdatedAt = now
 	f.tasks = append(f.tasks, task)
-	return task.ID, nil
+	return task.ID
 }
 
 func (f *fakeTaskRepo) GetByID(_ context.Context, id int64) (domain.Task, error) {
 	for _, task := range f.tasks {
 		if task.ID == id {
-			return task, nil
+			return f.withRelations(task), nil
 		}
 	}
 	return domain.Task{}, domain.ErrTaskNotFound
@@ -1585,15 +2115,66 @@ func (f *fakeTaskRepo) GetByID(_ context.Context, id int64) (domain.Task, error)
 func (f *fakeTaskRepo) List(_ context.Context, filter repo.TaskListFilter) ([]domain.Task, error) {
 	out := make([]domain.Task, 0, len(f.tasks))
 	for _, task := range f.tasks {
-		if filter.Project != "" && task.Project != filter.Project {
-			continue
+		task = f.withRelations(task)
+		if filter.Match(task) {
+			out = append(out, task)
 		}
-		out = append(out, task)
 	}
 	return out, nil
 }
 
+func (f *fakeTaskRepo) withRelations(task domain.Task) domain.Task {
+	task.Subtasks = domain.Progress{}
+	for _, child := range f.tasks {
+		if child.ParentID != task.ID || child.Status == domain.StatusDeleted {
+			continue
+		}
+		task.Subtasks.Total++
+		if child.Status == domain.StatusDone {
+			task.Subtasks.Done++
+		}
+	}
+	task.BlockedBy = nil
+	for _, blockerID := range f.blockers[task.ID] {
+		for _, blocker := range f.tasks {
+			if blocker.ID == blockerID && blocker.Status != domain.StatusDone && blocker.Status != domain.StatusDeleted {
+				task.BlockedBy = append(task.BlockedBy, blockerID)
+			}
+		}
+	}
+	return task
+}
+
+func (f *fakeTaskRepo) Search(ctx context.Context, terms []string, filter repo.TaskListFilter) ([]repo.SearchHit, error) {
+	tasks, err := f.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	hits := make([]repo.SearchHit, 0, len(tasks))
+	for _, task := range tasks {
+		text := strings.ToLower(task.Title + "\n" + task.Notes)
+		matched := true
+		for _, term := range terms {
+			matched = matched && strings.Contains(text, strings.ToLower(term))
+		}
+		if matched {
+			hits = append(hits, repo.SearchHit{Task: task, Snippet: task.Title})
+		}
+	}
+	return hits, nil
+}
+
+func (f *fakeTaskRepo) Upsert(context.Context, []domain.Task) ([]int64, error) {
+	return nil, nil
+}
+
+func (f *fakeTaskRepo) Count(ctx context.Context, filter repo.TaskListFilter) (int, error) {
+	tasks, err := f.List(ctx, filter)
+	return len(tasks), err
+}
+
 func (f *fakeTaskRepo) CreateProject(_ context.Context, name string) error {
+	f.checkpoint("add project")
 	name = strings.TrimSpace(name)
 	if name == "" {
 		return nil
@@ -1613,6 +2194,7 @@ func (f *fakeTaskRepo) ListProjects(context.Context) ([]string, error) {
 }
 
 func (f *fakeTaskRepo) RenameProject(_ context.Context, oldName, newName string) error {
+	f.checkpoint("rename project")
 	oldName = strings.TrimSpace(oldName)
 	newName = strings.TrimSpace(newName)
 	for i, name := range f.projects {
@@ -1630,6 +2212,7 @@ func (f *fakeTaskRepo) RenameProject(_ context.Context, oldName, newName string)
 }
 
 func (f *fakeTaskRepo) DeleteProject(_ context.Context, name string) error {
+	f.checkpoint("delete project")
 	name = strings.TrimSpace(name)
 	out := make([]string, 0, len(f.projects))
 	for _, item := range f.projects {
@@ -1648,6 +2231,7 @@ func (f *fakeTaskRepo) DeleteProject(_ context.Context, name string) error {
 }
 
 func (f *fakeTaskRepo) UpdateTitle(_ context.Context, id int64, title string) error {
+	f.checkpoint("edit")
 	for i := range f.tasks {
 		if f.tasks[i].ID == id {
 			f.tasks[i].Title = title
@@ -1658,6 +2242,7 @@ func (f *fakeTaskRepo) UpdateTitle(_ context.Context, id int64, title string) er
 }
 
 func (f *fakeTaskRepo) UpdateProject(_ context.Context, id int64, project string) error {
+	f.checkpoint("move")
 	for i := range f.tasks {
 		if f.tasks[i].ID == id {
 			f.tasks[i].Project = project
@@ -1672,6 +2257,7 @@ func (f *fakeTaskRepo) UpdateProject(_ context.Context, id int64, project string
 }
 
 func (f *fakeTaskRepo) UpdateDueAt(_ context.Context, id int64, dueAt *time.Time) error {
+	f.checkpoint("due")
 	for i := range f.tasks {
 		if f.tasks[i].ID == id {
 			f.tasks[i].DueAt = dueAt
@@ -1681,7 +2267,74 @@ func (f *fakeTaskRepo) UpdateDueAt(_ context.Context, id int64, dueAt *time.Time
 	return domain.ErrTaskNotFound
 }
 
+func (f *fakeTaskRepo) UpdateStartAt(_ context.Context, id int64, startAt *time.Time) error {
+	f.checkpoint("defer")
+	for i := range f.tasks {
+		if f.tasks[i].ID == id {
+			f.tasks[i].StartAt = startAt
+			return nil
+		}
+	}
+	return domain.ErrTaskNotFound
+}
+
+func (f *fakeTaskRepo) Wait(_ context.Context, id int64, on string, followUpAt *time.Time) error {
+	f.checkpoint("wait")
+	for i := range f.tasks {
+		if f.tasks[i].ID == id {
+			if !f.workflow.CanTransit(f.tasks[i].Status, domain.StatusWaiting) {
+				return domain.NewInvalidTransitionError(f.tasks[i].Status, domain.StatusWaiting)
+			}
+			f.tasks[i].Status = domain.StatusWaiting
+			f.tasks[i].WaitingOn = on
+			f.tasks[i].FollowUpAt = followUpAt
+			return nil
+		}
+	}
+	return domain.ErrTaskNotFound
+}
+
+func (f *fakeTaskRepo) Transit(_ context.Context, ids []int64, to domain.Status) error {
+	f.checkpoint(string(to))
+	for _, id := range ids {
+		found := false
+		for i := range f.tasks {
+			if f.tasks[i].ID == id {
+				if !f.workflow.CanTransit(f.tasks[i].Status, to) {
+					return domain.NewInvalidTransitionError(f.tasks[i].Status, to)
+				}
+				f.tasks[i].Status = to
+				found = true
+			}
+		}
+		if !found {
+			return domain.ErrTaskNotFound
+		}
+	}
+	return nil
+}
+
+func (f *fakeTaskRepo) Snooze(_ context.Context, dueAts map[int64]time.Time) error {
+	f.checkpoint("snooze")
+	for id, dueAt := range dueAts {
+		found := false
+		for i := range f.tasks {
+			if f.tasks[i].ID == id {
+				due := dueAt
+				f.tasks[i].DueAt = &due
+				f.tasks[i].SnoozeCount++
+				found = true
+			}
+		}
+		if !found {
+			return domain.ErrTaskNotFound
+		}
+	}
+	return nil
+}
+
 func (f *fakeTaskRepo) UpdatePriority(_ context.Context, id int64, priority string) error {
+	f.checkpoint("priority")
 	for i := range f.tasks {
 		if f.tasks[i].ID == id {
 			f.tasks[i].Priority = domain.NormalizePriority(priority)
@@ -1691,7 +2344,19 @@ func (f *fakeTaskRepo) UpdatePriority(_ context.Context, id int64, priority stri
 	return domain.ErrTaskNotFound
 }
 
+func (f *fakeTaskRepo) UpdateRecurrence(_ context.Context, id int64, recurrence domain.Recurrence) error {
+	f.checkpoint("repeat")
+	for i := range f.tasks {
+		if f.tasks[i].ID == id {
+			f.tasks[i].Recurrence = recurrence
+			return nil
+		}
+	}
+	return domain.ErrTaskNotFound
+}
+
 func (f *fakeTaskRepo) SetStatus(_ context.Context, id int64, status domain.Status) error {
+	f.checkpoint("status")
 	for i := range f.tasks {
 		if f.tasks[i].ID == id {
 			f.tasks[i].Status = status
@@ -1708,6 +2373,12 @@ func (f *fakeTaskRepo) SetStatus(_ context.Context, id int64, status domain.Stat
 }
 
 func (f *fakeTaskRepo) MarkDone(_ context.Context, ids []int64) error {
+	f.checkpoint("done")
+	f.markDone(ids)
+	return nil
+}
+
+func (f *fakeTaskRepo) markDone(ids []int64) {
 	for _, id := range ids {
 		for i := range f.tasks {
 			if f.tasks[i].ID == id {
@@ -1717,10 +2388,24 @@ func (f *fakeTaskRepo) MarkDone(_ context.Context, ids []int64) error {
 			}
 		}
 	}
+}
+
+func (f *fakeTaskRepo) MarkDoneWithSubtasks(_ context.Context, ids []int64) error {
+	f.checkpoint("done")
+	all := append([]int64(nil), ids...)
+	for i := 0; i < len(all); i++ {
+		for _, task := range f.tasks {
+			if task.ParentID == all[i] && task.Status != domain.StatusDone && task.Status != domain.StatusDeleted {
+				all = append(all, task.ID)
+			}
+		}
+	}
+	f.markDone(all)
 	return nil
 }
 
 func (f *fakeTaskRepo) MarkDoing(_ context.Context, ids []int64) error {
+	f.checkpoint("start")
 	for _, id := range ids {
 		for i := range f.tasks {
 			if f.tasks[i].ID == id {
@@ -1733,6 +2418,7 @@ func (f *fakeTaskRepo) MarkDoing(_ context.Context, ids []int64) error {
 }
 
 func (f *fakeTaskRepo) Reopen(_ context.Context, ids []int64) error {
+	f.checkpoint("reopen")
 	for _, id := range ids {
 		for i := range f.tasks {
 			if f.tasks[i].ID == id {
@@ -1745,6 +2431,7 @@ func (f *fakeTaskRepo) Reopen(_ context.Context, ids []int64) error {
 }
 
 func (f *fakeTaskRepo) SoftDelete(_ context.Context, ids []int64) error {
+	f.checkpoint("delete")
 	for _, id := range ids {
 		for i := range f.tasks {
 			if f.tasks[i].ID == id {
@@ -1756,6 +2443,7 @@ func (f *fakeTaskRepo) SoftDelete(_ context.Context, ids []int64) error {
 }
 
 func (f *fakeTaskRepo) Restore(_ context.Context, ids []int64) error {
+	f.checkpoint("restore")
 	for _, id := range ids {
 		for i := range f.tasks {
 			if f.tasks[i].ID == id {
@@ -1768,6 +2456,7 @@ func (f *fakeTaskRepo) Restore(_ context.Context, ids []int64) error {
 }
 
 func (f *fakeTaskRepo) Purge(_ context.Context, ids []int64) error {
+	f.checkpoint("purge")
 	drop := make(map[int64]struct{}, len(ids))
 	for _, id := range ids {
 		drop[id] = struct{}{}
@@ -1783,6 +2472,119 @@ func (f *fakeTaskRepo) Purge(_ context.Context, ids []int64) error {
 	return nil
 }
 
+func (f *fakeTaskRepo) AddTags(_ context.Context, id int64, tags []string) error {
+	f.checkpoint("tag")
+	for i := range f.tasks {
+		if f.tasks[i].ID == id {
+			for _, tag := range tags {
+				if !containsString(f.tasks[i].Tags, tag) {
+					f.tasks[i].Tags = append(f.tasks[i].Tags, tag)
+				}
+			}
+			sort.Strings(f.tasks[i].Tags)
+			return nil
+		}
+	}
+	return domain.ErrTaskNotFound
+}
+
+func (f *fakeTaskRepo) RemoveTags(_ context.Context, id int64, tags []string) error {
+	f.checkpoint("untag")
+	for i := range f.tasks {
+		if f.tasks[i].ID == id {
+			out := make([]string, 0, len(f.tasks[i].Tags))
+			for _, tag := range f.tasks[i].Tags {
+				if !containsString(tags, tag) {
+					out = append(out, tag)
+				}
+			}
+			f.tasks[i].Tags = out
+			return nil
+		}
+	}
+	return domain.ErrTaskNotFound
+}
+
+func (f *fakeTaskRepo) ListTags(context.Context) ([]string, error) {
+	out := make([]string, 0, 8)
+	for _, task := range f.tasks {
+		for _, tag := range task.Tags {
+			if !containsString(out, tag) {
+				out = append(out, tag)
+			}
+		}
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+func (f *fakeTaskRepo) RenameTag(_ context.Context, oldName, newName string) error {
+	f.checkpoint("rename tag")
+	for i := range f.tasks {
+		for j, tag := range f.tasks[i].Tags {
+			if tag == oldName {
+				f.tasks[i].Tags[j] = newName
+			}
+		}
+	}
+	return nil
+}
+
+func (f *fakeTaskRepo) MergeTags(_ context.Context, sources []string, target string) error {
+	f.checkpoint("merge tags")
+	for i := range f.tasks {
+		out := make([]string, 0, len(f.tasks[i].Tags))
+		merged := false
+		for _, tag := range f.tasks[i].Tags {
+			if containsString(sources, tag) {
+				merged = true
+				continue
+			}
+			out = append(out, tag)
+		}
+		if merged && !containsString(out, target) {
+			out = append(out, target)
+			sort.Strings(out)
+		}
+		f.tasks[i].Tags = out
+	}
+	return nil
+}
+
+func (f *fakeTaskRepo) AddDependencies(_ context.Context, taskID int64, blockerIDs []int64) error {
+	f.checkpoint("block")
+	if f.blockers == nil {
+		f.blockers = make(map[int64][]int64)
+	}
+	f.blockers[taskID] = append(f.blockers[taskID], blockerIDs...)
+	return nil
+}
+
+func (f *fakeTaskRepo) RemoveDependencies(_ context.Context, taskID int64, blockerIDs []int64) error {
+	f.checkpoint("unblock")
+	if len(blockerIDs) == 0 {
+		delete(f.blockers, taskID)
+		return nil
+	}
+	kept := f.blockers[taskID][:0]
+	for _, id := range f.blockers[taskID] {
+		if !containsInt64(blockerIDs, id) {
+			kept = append(kept, id)
+		}
+	}
+	f.blockers[taskID] = kept
+	return nil
+}
+
+func containsInt64(items []int64, id int64) bool {
+	for _, item := range items {
+		if item == id {
+			return true
+		}
+	}
+	return false
+}
+
 func setInboxView(m Model) Model {
 	m.activeView = domain.ViewInbox
 	m.reload()
@@ -1845,6 +2647,18 @@ func (f fakeParseProvider) ParseTask(_ context.Context, _ string) (string, error
 	return f.raw, f.err
 }
 
+type fakeMultiParseProvider struct {
+	raw string
+}
+
+func (f fakeMultiParseProvider) ParseTask(_ context.Context, _ string) (string, error) {
+	return "", errors.New("not used")
+}
+
+func (f fakeMultiParseProvider) ParseTasks(_ context.Context, _ string) (string, error) {
+	return f.raw, nil
+}
+
 func containsString(items []string, target string) bool {
 	for _, item := range items {
 		if item == target {
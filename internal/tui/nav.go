@@ -12,6 +12,7 @@ type navRowKind int
 const (
 	navRowView navRowKind = iota
 	navRowProject
+	navRowTag
 )
 
 type navRow struct {
@@ -19,20 +20,24 @@ type navRow struct {
 	View    domain.View
 	Label   string
 	Project string
+	Tag     string
 }
 
 func defaultNavItems() []navItem {
 	return []navItem{
 		{View: domain.ViewToday, Label: "Today"},
 		{View: domain.ViewInbox, Label: "Inbox"},
+		{View: domain.ViewUpcoming, Label: "Upcoming"},
+		{View: domain.ViewWaiting, Label: "Waiting"},
 		{View: domain.ViewLog, Label: "Log"},
 		{View: domain.ViewProject, Label: "Project"},
+		{View: domain.ViewTag, Label: "Tags"},
 		{View: domain.ViewTrash, Label: "Trash"},
 	}
 }
 
-func buildNavRows(items []navItem, projects []string) []navRow {
-	rows := make([]navRow, 0, len(items)+len(projects))
+func buildNavRows(items []navItem, projects, tags []string) []navRow {
+	rows := make([]navRow, 0, len(items)+len(projects)+len(tags))
 	for _, item := range items {
 		rows = append(rows, navRow{
 			Kind:  navRowView,
@@ -49,11 +54,21 @@ func buildNavRows(items []navItem, projects []string) []navRow {
 				})
 			}
 		}
+		if item.View == domain.ViewTag {
+			for _, tag := range tags {
+				rows = append(rows, navRow{
+					Kind:  navRowTag,
+					View:  domain.ViewTag,
+					Label: "#" + tag,
+					Tag:   tag,
+				})
+			}
+		}
 	}
 	return rows
 }
 
-func renderNav(rows []navRow, selected int, activeView domain.View, activeProject string, focused bool, width, height int) []string {
+func renderNav(rows []navRow, selected int, activeView domain.View, activeProject, activeTag string, focused bool, width, height int) []string {
 	contentWidth := paneContentWidth(width)
 	contentHeight := paneContentHeight(height)
 	lines := []string{truncateLineForPane(navTitleStyle.Render("Views"), contentWidth)}
@@ -67,15 +82,22 @@ func renderNav(rows []navRow, selected int, activeView domain.View, activeProjec
 				cursor = "> "
 			}
 			activeMark := " "
-			if row.Kind == navRowProject {
+			switch row.Kind {
+			case navRowProject:
 				if activeView == domain.ViewProject && row.Project == activeProject {
 					activeMark = "*"
 				}
-			} else if row.View == activeView && !(activeView == domain.ViewProject && activeProject != "") {
-				activeMark = "*"
+			case navRowTag:
+				if activeView == domain.ViewTag && row.Tag == activeTag {
+					activeMark = "*"
+				}
+			default:
+				if row.View == activeView && !(activeView == domain.ViewProject && activeProject != "") && !(activeView == domain.ViewTag && activeTag != "") {
+					activeMark = "*"
+				}
 			}
 			label := row.Label
-			if row.Kind == navRowProject {
+			if row.Kind == navRowProject || row.Kind == navRowTag {
 				label = "  " + label
 			}
 			line := truncateLineForPane(cursor+" "+activeMark+" "+label, contentWidth)